@@ -0,0 +1,180 @@
+// Command addressdata rebuilds internal/address/data/thai_admin_areas.json with every
+// อำเภอ/เขต and ตำบล/แขวง of the country, from the DOPA-derived data set published at
+// github.com/kongvut/thai-province-data (ข้อมูลจากกรมการปกครอง พร้อมรหัสไปรษณีย์).
+//
+//	go generate ./internal/address
+//
+// รหัสจังหวัด ชื่อภาษาอังกฤษ และเลขนำหน้ารหัสไปรษณีย์ยังอ่านจากไฟล์เดิม (-out) ส่วนอำเภอและตำบลแทนที่ทั้งหมด
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"backend/internal/address"
+)
+
+const defaultSource = "https://raw.githubusercontent.com/kongvut/thai-province-data/master/api_province_with_amphure_tambon.json"
+
+// sourceProvince is one province of the source data set (ฟิลด์ตามไฟล์ต้นทาง)
+type sourceProvince struct {
+	NameTh  string           `json:"name_th"`
+	NameEn  string           `json:"name_en"`
+	Amphure []sourceDistrict `json:"amphure"`
+}
+
+type sourceDistrict struct {
+	ID     int                 `json:"id"` // รหัส DOPA 4 หลัก สองหลักแรกคือรหัสจังหวัด
+	NameTh string              `json:"name_th"`
+	NameEn string              `json:"name_en"`
+	Tambon []sourceSubDistrict `json:"tambon"`
+}
+
+type sourceSubDistrict struct {
+	NameTh  string `json:"name_th"`
+	NameEn  string `json:"name_en"`
+	ZipCode int    `json:"zip_code"`
+}
+
+func main() {
+	in := flag.String("in", defaultSource, "source JSON file or URL")
+	out := flag.String("out", "data/thai_admin_areas.json", "data set to update")
+	flag.Parse()
+
+	source, err := readSource(*in)
+	if err != nil {
+		log.Fatal(err)
+	}
+	current, err := address.Load(*out)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ds, err := build(current, source)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	data, err := json.MarshalIndent(ds, "", " ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, append(data, '\n'), 0o644); err != nil {
+		log.Fatal(err)
+	}
+
+	districts, subDistricts := 0, 0
+	for _, p := range ds.Provinces {
+		districts += len(p.Districts)
+		for _, d := range p.Districts {
+			subDistricts += len(d.SubDistricts)
+		}
+	}
+	log.Printf("wrote %s: %d provinces, %d districts, %d sub-districts", *out, len(ds.Provinces), districts, subDistricts)
+}
+
+func readSource(in string) ([]sourceProvince, error) {
+	var r io.Reader
+	if strings.HasPrefix(in, "http://") || strings.HasPrefix(in, "https://") {
+		resp, err := http.Get(in)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download address source: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to download address source: %s", resp.Status)
+		}
+		r = resp.Body
+	} else {
+		f, err := os.Open(in)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open address source: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var source []sourceProvince
+	if err := json.NewDecoder(r).Decode(&source); err != nil {
+		return nil, fmt.Errorf("failed to parse address source: %w", err)
+	}
+	return source, nil
+}
+
+// build replaces the districts of every province in current with those of source.
+// จังหวัดที่ไม่มีในต้นทาง หรืออำเภอที่ไม่มีตำบล ถือว่าข้อมูลไม่ครบ (error)
+func build(current *address.Dataset, source []sourceProvince) (*address.Dataset, error) {
+	byCode := map[string]sourceProvince{}
+	for _, p := range source {
+		if len(p.Amphure) == 0 {
+			return nil, fmt.Errorf("province %s has no districts", p.NameTh)
+		}
+		byCode[fmt.Sprintf("%02d", p.Amphure[0].ID/100)] = p
+	}
+
+	ds := &address.Dataset{Complete: true}
+	for _, p := range current.Provinces {
+		src, ok := byCode[p.Code]
+		if !ok {
+			return nil, fmt.Errorf("province %s (%s) is missing from the source", p.NameTh, p.Code)
+		}
+
+		p.Districts = make([]address.District, 0, len(src.Amphure))
+		for _, a := range src.Amphure {
+			d, err := district(a)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", p.NameTh, err)
+			}
+			p.Districts = append(p.Districts, d)
+		}
+		ds.Provinces = append(ds.Provinces, p)
+	}
+	return ds, nil
+}
+
+// district converts one อำเภอ/เขต. รหัสไปรษณีย์ของอำเภอใส่เฉพาะเมื่อทุกตำบลใช้รหัสเดียวกัน
+func district(a sourceDistrict) (address.District, error) {
+	d := address.District{
+		NameTh: address.NormalizeDistrict(a.NameTh),
+		NameEn: trimEnglishPrefix(a.NameEn, "Khet ", "Amphoe "),
+	}
+	if len(a.Tambon) == 0 {
+		return d, fmt.Errorf("district %s has no sub-districts", a.NameTh)
+	}
+
+	shared := true
+	for i, t := range a.Tambon {
+		if t.ZipCode == 0 {
+			return d, fmt.Errorf("sub-district %s of %s has no postal code", t.NameTh, a.NameTh)
+		}
+		code := fmt.Sprintf("%05d", t.ZipCode)
+		d.SubDistricts = append(d.SubDistricts, address.SubDistrict{
+			NameTh:     address.NormalizeSubDistrict(t.NameTh),
+			NameEn:     trimEnglishPrefix(t.NameEn, "Khwaeng ", "Tambon "),
+			PostalCode: code,
+		})
+		if i > 0 && code != d.SubDistricts[0].PostalCode {
+			shared = false
+		}
+	}
+	if shared {
+		d.PostalCode = d.SubDistricts[0].PostalCode
+	}
+	return d, nil
+}
+
+func trimEnglishPrefix(s string, prefixes ...string) string {
+	s = strings.TrimSpace(s)
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return strings.TrimPrefix(s, prefix)
+		}
+	}
+	return s
+}
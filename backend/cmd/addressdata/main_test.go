@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"backend/internal/address"
+)
+
+const testSource = `[
+ {"id": 1, "name_th": "กรุงเทพมหานคร", "name_en": "Bangkok", "amphure": [
+  {"id": 1002, "name_th": "เขตดุสิต", "name_en": "Khet Dusit", "tambon": [
+   {"id": 100201, "zip_code": 10300, "name_th": "ดุสิต", "name_en": "Dusit"},
+   {"id": 100202, "zip_code": 10300, "name_th": "วชิรพยาบาล", "name_en": "Wachiraphayaban"}
+  ]}
+ ]},
+ {"id": 3, "name_th": "นนทบุรี", "name_en": "Nonthaburi", "amphure": [
+  {"id": 1201, "name_th": "เมืองนนทบุรี", "name_en": "Mueang Nonthaburi", "tambon": [
+   {"id": 120101, "zip_code": 11000, "name_th": "สวนใหญ่", "name_en": "Suan Yai"},
+   {"id": 120105, "zip_code": 11000, "name_th": "บางกระสอ", "name_en": "Bang Kraso"}
+  ]},
+  {"id": 1206, "name_th": "ปากเกร็ด", "name_en": "Pak Kret", "tambon": [
+   {"id": 120601, "zip_code": 11120, "name_th": "ปากเกร็ด", "name_en": "Pak Kret"},
+   {"id": 120603, "zip_code": 11120, "name_th": "บางตลาด", "name_en": "Bang Talat"}
+  ]}
+ ]}
+]`
+
+func TestBuild(t *testing.T) {
+	var source []sourceProvince
+	if err := json.Unmarshal([]byte(testSource), &source); err != nil {
+		t.Fatal(err)
+	}
+	current := &address.Dataset{Provinces: []address.Province{
+		{Code: "10", NameTh: "กรุงเทพมหานคร", NameEn: "Bangkok", PostalPrefix: "10",
+			Districts: []address.District{{NameTh: "ดุสิต", PostalCode: "10300"}}},
+		{Code: "12", NameTh: "นนทบุรี", NameEn: "Nonthaburi", PostalPrefix: "11"},
+	}}
+
+	ds, err := build(current, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ds.Complete || len(ds.Provinces) != 2 || ds.Provinces[1].PostalPrefix != "11" {
+		t.Fatalf("build() = %+v", ds)
+	}
+
+	dusit := ds.Provinces[0].FindDistrict("ดุสิต")
+	if dusit == nil || dusit.NameEn != "Dusit" || dusit.PostalCode != "10300" || len(dusit.SubDistricts) != 2 {
+		t.Errorf("ดุสิต = %+v", dusit)
+	}
+	if _, err := ds.Validate(address.Address{SubDistrict: "บางตลาด", District: "ปากเกร็ด", Province: "นนทบุรี", PostalCode: "11120"}); err != nil {
+		t.Errorf("Validate() of a generated address = %v", err)
+	}
+
+	// จังหวัดที่ไม่มีในต้นทางทำให้ข้อมูลไม่ครบ
+	current.Provinces = append(current.Provinces, address.Province{Code: "13", NameTh: "ปทุมธานี"})
+	if _, err := build(current, source); err == nil {
+		t.Error("build() accepted a source without ปทุมธานี")
+	}
+}
+
+func TestDistrictPostalCode(t *testing.T) {
+	d, err := district(sourceDistrict{ID: 1201, NameTh: "อำเภอเมืองนนทบุรี", NameEn: "Amphoe Mueang Nonthaburi", Tambon: []sourceSubDistrict{
+		{NameTh: "ตำบลสวนใหญ่", NameEn: "Tambon Suan Yai", ZipCode: 11000},
+		{NameTh: "ไทรม้า", ZipCode: 11001},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ตำบลใช้รหัสต่างกัน อำเภอจึงไม่มีรหัสของตัวเอง
+	if d.NameTh != "เมืองนนทบุรี" || d.NameEn != "Mueang Nonthaburi" || d.PostalCode != "" || d.SubDistricts[0].NameTh != "สวนใหญ่" {
+		t.Errorf("district() = %+v", d)
+	}
+
+	if _, err := district(sourceDistrict{NameTh: "ว่าง"}); err == nil {
+		t.Error("district() accepted a district without sub-districts")
+	}
+	if _, err := district(sourceDistrict{NameTh: "ไม่มีรหัส", Tambon: []sourceSubDistrict{{NameTh: "ก"}}}); err == nil {
+		t.Error("district() accepted a sub-district without a postal code")
+	}
+}
@@ -1,14 +1,30 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"flag"
 	"log"
 	"net/http"
+	"os"
 	"time"
+	_ "time/tzdata" // ให้โหลด timezone ได้แม้เครื่องไม่มี zoneinfo
 
+	"backend/internal/address"
+	"backend/internal/badge"
+	"backend/internal/clock"
 	"backend/internal/config"
 	"backend/internal/database"
+	"backend/internal/edge"
 	"backend/internal/handlers"
+	"backend/internal/notify"
 	"backend/internal/repository"
+	"backend/internal/retention"
+	"backend/internal/storage"
+	"backend/internal/tenant"
+	"backend/internal/thaidate"
+	"backend/internal/token"
 
 	"github.com/gorilla/mux"
 	"github.com/rs/cors"
@@ -16,40 +32,112 @@ import (
 
 func main() {
 	cfg := config.Load()
+
+	autoMigrate := flag.Bool("auto-migrate", cfg.Database.AutoMigrate, "apply pending database migrations before starting (DB_AUTO_MIGRATE)")
+	flag.Usage = usage
+	flag.Parse()
+	if flag.Arg(0) == "migrate" {
+		os.Exit(runMigrate(cfg, flag.Args()[1:]))
+	}
+	if flag.NArg() > 0 {
+		usage()
+		os.Exit(2)
+	}
+
 	log.Printf("Starting server on %s:%s", cfg.Server.Host, cfg.Server.Port)
 
-	db, err := database.NewDatabase(database.Config{
-		Host:     cfg.Database.Host,
-		Port:     cfg.Database.Port,
-		User:     cfg.Database.User,
-		Password: cfg.Database.Password,
-		DBName:   cfg.Database.DBName,
-	})
+	db, err := database.NewDatabase(databaseConfig(cfg))
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 	defer db.Close()
 
-	visitorRepo := repository.NewVisitorRepository(db.DB)
-	exportRepo := repository.NewExportRepository(db.DB)
+	if *autoMigrate {
+		if err := migrateUp(db.DB); err != nil {
+			log.Fatalf("Failed to migrate database: %v", err)
+		}
+	}
 
-	visitorHandler := handlers.NewVisitorHandler(visitorRepo)
-	exportHandler := handlers.NewExportHandler(visitorRepo, exportRepo)
+	addresses, err := address.Load(cfg.Address.DataFile)
+	if err != nil {
+		log.Fatalf("Failed to load address data: %v", err)
+	}
 
-	router := mux.NewRouter()
-	api := router.PathPrefix("/api").Subrouter()
+	if cfg.Security.TokenSecret == "" {
+		log.Println("TOKEN_SECRET is not set, QR invitations and badges will not survive a restart")
+	}
+	signer := token.NewSigner(tokenSecret(cfg.Security.TokenSecret))
+	notifier := notify.New(cfg.Notify.WebhookURL)
 
-	api.HandleFunc("/visitors/return-history", visitorHandler.GetReturnHistory).Methods("GET")
-	api.HandleFunc("/visitors/rfid/{cardId}", visitorHandler.SearchByRFID).Methods("GET")
-	api.HandleFunc("/visitors/return/{cardId}", visitorHandler.ReturnCard).Methods("POST")
-	api.HandleFunc("/visitors/export", visitorHandler.GetVisitors).Methods("GET") // ⭐ เพิ่มบรรทัดนี้
+	store, err := storage.NewLocal(cfg.Storage.Dir)
+	if err != nil {
+		log.Fatalf("Failed to open storage: %v", err)
+	}
 
-	api.HandleFunc("/visitors", visitorHandler.CreateVisitor).Methods("POST")
-	api.HandleFunc("/visitors", visitorHandler.ListVisitors).Methods("GET")
-	api.HandleFunc("/visitors/{id}", visitorHandler.GetVisitor).Methods("GET")
+	badgeLayout, err := badge.LoadLayout(cfg.Badge.LayoutFile)
+	if err != nil {
+		log.Fatalf("Failed to load badge layout: %v", err)
+	}
+	badgeRenderer, err := badge.NewRenderer(badgeLayout)
+	if err != nil {
+		log.Fatalf("Failed to load badge renderer: %v", err)
+	}
+	if !badgeRenderer.SupportsThai() {
+		log.Println("Badge font has no Thai glyphs, set fontFile in the badge layout or run go generate ./internal/badge")
+	}
 
-	api.HandleFunc("/export-history", exportHandler.GetExportHistory).Methods("GET")
-	api.HandleFunc("/export-history", exportHandler.CreateExportHistory).Methods("POST")
+	clk, err := clock.Load(cfg.Date.Timezone)
+	if err != nil {
+		log.Fatalf("Invalid timezone config: %v", err)
+	}
+	log.Printf("Default school timezone: %s", clk.Location())
+
+	// รูปแบบวันที่ของทุกโรงเรียน ต่างกันแค่ timezone
+	dates := thaidate.Formatter{
+		Location:   clk.Location(),
+		Calendar:   cfg.Date.Calendar,
+		Month:      cfg.Date.Month,
+		ThaiDigits: cfg.Date.ThaiDigits,
+	}
+	if err := dates.Validate(); err != nil {
+		log.Fatalf("Invalid date format config: %v", err)
+	}
+
+	// โหมด edge: บันทึกลงฐานข้อมูลของเครื่องนี้ก่อน แล้วส่งขึ้น server กลางเป็นระยะ
+	var syncQueue *repository.SyncQueueRepository
+	if cfg.Edge.CentralURL != "" {
+		if cfg.Edge.DeviceID == 0 || cfg.Edge.DeviceKey == "" {
+			log.Fatal("Edge mode needs EDGE_DEVICE_ID and EDGE_DEVICE_KEY of this gate at the central server")
+		}
+		if cfg.Edge.SyncSeconds < 1 {
+			log.Fatal("EDGE_SYNC_SECONDS must be at least 1")
+		}
+		syncQueue = repository.NewSyncQueueRepository(db.DB, clk)
+		syncer := edge.NewSyncer(syncQueue, cfg.Edge.CentralURL, cfg.Edge.DeviceID, cfg.Edge.DeviceKey).WithSchool(cfg.Edge.School)
+		interval := time.Duration(cfg.Edge.SyncSeconds) * time.Second
+		go syncer.Run(context.Background(), interval)
+		log.Printf("Edge mode: syncing to %s every %s", cfg.Edge.CentralURL, interval)
+	}
+
+	// แต่ละโรงเรียนได้ชุด handler ของตัวเอง สร้างเมื่อมี request แรกของโรงเรียนนั้น
+	schoolRepo := repository.NewSchoolRepository(db.DB)
+	schools := &schoolServer{
+		cfg:       cfg,
+		db:        db.DB,
+		addresses: addresses,
+		signer:    signer,
+		notifier:  notifier,
+		store:     store,
+		badges:    badgeRenderer,
+		clock:     clk,
+		syncQueue: syncQueue,
+	}
+	tenants := tenant.NewRouter(schoolRepo, cfg.Tenant.BaseDomain, schools.build).WithVerifier(schools.verify)
+
+	purger := retention.NewPurger(schoolRepo, repository.NewVisitorRepository(db.DB, clk), store, clk)
+	go purger.Run(context.Background())
+
+	router := mux.NewRouter()
 
 	router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -57,10 +145,20 @@ func main() {
 		w.Write([]byte(`{"status": "ok", "timestamp": "` + time.Now().Format(time.RFC3339) + `"}`))
 	}).Methods("GET")
 
+	handlers.DistrictRoutes{
+		District: handlers.NewDistrictHandler(schoolRepo, repository.NewStatsRepository(db.DB, clk), clk, cfg.Tenant.AdminKey, tenants.Reset),
+	}.Register(router.PathPrefix("/api").Subrouter())
+	if cfg.Tenant.AdminKey == "" {
+		log.Println("DISTRICT_ADMIN_KEY is not set, /api/district is disabled")
+	}
+
+	router.PathPrefix("/").Handler(tenants)
+
 	corsHandler := cors.New(cors.Options{
 		AllowedOrigins:   []string{"http://localhost:4200", "http://localhost:8080"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization", "X-Device-Key", "X-School", "Last-Event-ID", "If-Match", "Idempotency-Key"},
+		ExposedHeaders:   []string{"ETag", "Idempotent-Replayed"},
 		AllowCredentials: true,
 		MaxAge:           300,
 	})
@@ -78,14 +176,102 @@ func main() {
 	log.Printf("  - POST   /api/visitors")
 	log.Printf("  - GET    /api/visitors")
 	log.Printf("  - GET    /api/visitors/{id}")
+	log.Printf("  - PATCH  /api/visitors/{id}")
+	log.Printf("  - DELETE /api/visitors/{id}")
+	log.Printf("  - GET    /api/visitors/{id}/revisions")
+	log.Printf("  - POST   /api/visitors/{id}/approve")
+	log.Printf("  - POST   /api/visitors/{id}/reject")
+	log.Printf("  - POST   /api/visitors/{id}/card")
+	log.Printf("  - POST   /api/visitors/{id}/photo")
+	log.Printf("  - GET    /api/visitors/{id}/photo")
+	log.Printf("  - GET    /api/visitors/{id}/photo/thumbnail")
+	log.Printf("  - GET    /api/visitors/{id}/badge")
+	log.Printf("  - GET    /api/approvals")
+	log.Printf("  - POST   /api/smartcard/read")
+	log.Printf("  - GET    /api/events")
+	log.Printf("  - POST   /api/devices")
+	log.Printf("  - GET    /api/devices")
+	log.Printf("  - PUT    /api/devices/{id}")
+	log.Printf("  - POST   /api/devices/{id}/key")
+	log.Printf("  - POST   /api/devices/{id}/taps")
+	log.Printf("  - GET    /api/devices/{id}/taps")
+	log.Printf("  - POST   /api/devices/{id}/sync")
+	log.Printf("  - GET    /api/sync/status")
+	log.Printf("  - GET    /api/sync/queue")
+	log.Printf("  - GET    /api/purposes")
+	log.Printf("  - PUT    /api/purposes/{code}")
+	log.Printf("  - GET    /api/departments")
+	log.Printf("  - POST   /api/departments")
+	log.Printf("  - PUT    /api/departments/{id}")
+	log.Printf("  - DELETE /api/departments/{id}")
+	log.Printf("  - GET    /api/watchlist")
+	log.Printf("  - POST   /api/watchlist")
+	log.Printf("  - PUT    /api/watchlist/{id}")
+	log.Printf("  - DELETE /api/watchlist/{id}")
+	log.Printf("  - POST   /api/students")
+	log.Printf("  - GET    /api/students")
+	log.Printf("  - GET    /api/students/{id}")
+	log.Printf("  - GET    /api/students/{id}/guardians")
+	log.Printf("  - POST   /api/students/{id}/guardians")
+	log.Printf("  - DELETE /api/students/{id}/guardians/{authId}")
+	log.Printf("  - GET    /api/visitors/{id}/pickup-students")
+	log.Printf("  - POST   /api/pickups")
+	log.Printf("  - GET    /api/pickups")
+	log.Printf("  - GET    /api/pickup-alerts")
+	log.Printf("  - POST   /api/pickup-alerts/{id}/acknowledge")
 	log.Printf("  - GET    /api/visitors/export ✨") // ⭐ เพิ่มบรรทัดนี้
 	log.Printf("  - GET    /api/visitors/return-history")
 	log.Printf("  - GET    /api/visitors/rfid/{cardId}")
 	log.Printf("  - POST   /api/visitors/return/{cardId}")
+	log.Printf("  - POST   /api/return-logs/{id}/void")
 	log.Printf("  - GET    /api/export-history ✨") // ⭐ เพิ่มบรรทัดนี้
 	log.Printf("  - POST   /api/export-history ✨") // ⭐ เพิ่มบรรทัดนี้
+	log.Printf("  - GET    /api/search")
+	log.Printf("  - GET    /api/stats")
+	log.Printf("  - GET    /api/school")
+	log.Printf("  - GET    /api/district/schools")
+	log.Printf("  - POST   /api/district/schools")
+	log.Printf("  - PUT    /api/district/schools/{id}")
+	log.Printf("  - GET    /api/district/stats")
+	log.Printf("  - GET    /api/vehicles/parking")
+	log.Printf("  - GET    /api/vehicles/{plate}/visitors")
+	log.Printf("  - GET    /api/address/provinces")
+	log.Printf("  - GET    /api/address/provinces/{province}/districts")
+	log.Printf("  - GET    /api/address/provinces/{province}/districts/{district}/sub-districts")
+	log.Printf("  - GET    /api/address/postal-codes/{code}")
+	log.Printf("  - POST   /api/appointments")
+	log.Printf("  - GET    /api/appointments")
+	log.Printf("  - GET    /api/appointments/{id}")
+	log.Printf("  - GET    /api/appointments/{id}/qr")
+	log.Printf("  - POST   /api/appointments/{id}/cancel")
+	log.Printf("  - POST   /api/appointments/scan")
 
 	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
+
+// tokenSecret returns the configured secret, or a random one for development
+func tokenSecret(configured string) string {
+	if configured != "" {
+		return configured
+	}
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		log.Fatalf("Failed to generate token secret: %v", err)
+	}
+	return hex.EncodeToString(b)
+}
+
+func databaseConfig(cfg *config.Config) database.Config {
+	return database.Config{
+		Driver:   cfg.Database.Driver,
+		Host:     cfg.Database.Host,
+		Port:     cfg.Database.Port,
+		User:     cfg.Database.User,
+		Password: cfg.Database.Password,
+		DBName:   cfg.Database.DBName,
+		SSLMode:  cfg.Database.SSLMode,
+		Path:     cfg.Database.Path,
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"backend/internal/config"
+	"backend/internal/database"
+	"backend/internal/migrate"
+	"backend/migrations"
+)
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage:\n")
+	fmt.Fprintf(out, "  server [-auto-migrate]         start the API server\n")
+	fmt.Fprintf(out, "  server migrate up [version]    apply pending migrations (up to version)\n")
+	fmt.Fprintf(out, "  server migrate down [steps]    roll back the last migrations (default 1)\n")
+	fmt.Fprintf(out, "  server migrate status          list migrations and whether they are applied\n")
+	fmt.Fprintf(out, "  server migrate baseline <ver>  mark migrations up to ver as applied without running them\n\n")
+	flag.PrintDefaults()
+}
+
+// runMigrate runs the migrate subcommand and returns the exit code
+func runMigrate(cfg *config.Config, args []string) int {
+	if len(args) == 0 {
+		usage()
+		return 2
+	}
+
+	db, err := database.NewDatabase(databaseConfig(cfg))
+	if err != nil {
+		log.Printf("Failed to connect to database: %v", err)
+		return 1
+	}
+	defer db.Close()
+
+	runner, err := newRunner(db.DB)
+	if err != nil {
+		log.Printf("Failed to load migrations: %v", err)
+		return 1
+	}
+	ctx := context.Background()
+
+	// number reads the optional numeric argument of a command
+	number := func(name string, def int) (int, bool) {
+		if len(args) < 2 {
+			return def, def >= 0
+		}
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 0 {
+			log.Printf("%s must be a number, got %q", name, args[1])
+			return 0, false
+		}
+		return n, true
+	}
+
+	switch args[0] {
+	case "up":
+		target, ok := number("version", 0)
+		if !ok {
+			return 2
+		}
+		n, err := runner.Up(ctx, target)
+		if err != nil {
+			log.Printf("Failed to migrate: %v", err)
+			return 1
+		}
+		log.Printf("Applied %d migration(s)", n)
+
+	case "down":
+		steps, ok := number("steps", 1)
+		if !ok {
+			return 2
+		}
+		n, err := runner.Down(ctx, steps)
+		if err != nil {
+			log.Printf("Failed to roll back: %v", err)
+			return 1
+		}
+		log.Printf("Rolled back %d migration(s)", n)
+
+	case "baseline":
+		version, ok := number("version", -1)
+		if !ok {
+			log.Printf("baseline needs a version, e.g. migrate baseline %d", runner.Latest())
+			return 2
+		}
+		if err := runner.Baseline(ctx, version); err != nil {
+			log.Printf("Failed to baseline: %v", err)
+			return 1
+		}
+		log.Printf("Recorded migrations up to %d as applied", version)
+
+	case "status":
+		statuses, err := runner.Status(ctx)
+		if err != nil {
+			log.Printf("Failed to read migration status: %v", err)
+			return 1
+		}
+		printStatus(statuses)
+
+	default:
+		usage()
+		return 2
+	}
+	return 0
+}
+
+func printStatus(statuses []migrate.Status) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT (UTC)")
+	for _, s := range statuses {
+		state, appliedAt := "pending", ""
+		if s.Applied {
+			state = "applied"
+			appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		switch {
+		case s.Dirty:
+			state = "failed"
+		case s.Missing:
+			state = "applied, no file"
+		case s.Changed:
+			state = "applied, file changed"
+		}
+		fmt.Fprintf(w, "%03d\t%s\t%s\t%s\n", s.Version, s.Name, state, appliedAt)
+	}
+	w.Flush()
+}
+
+// newRunner loads the migrations written for the database behind db
+func newRunner(db *sql.DB) (*migrate.Runner, error) {
+	fsys, err := migrations.For(string(database.DialectOf(db)))
+	if err != nil {
+		return nil, err
+	}
+	return migrate.NewRunner(db, fsys)
+}
+
+// migrateUp applies every pending migration, used by -auto-migrate
+func migrateUp(db *sql.DB) error {
+	runner, err := newRunner(db)
+	if err != nil {
+		return err
+	}
+	n, err := runner.Up(context.Background(), 0)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		log.Printf("Applied %d migration(s)", n)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"net/http"
+	"time"
+
+	"backend/internal/address"
+	"backend/internal/badge"
+	"backend/internal/clock"
+	"backend/internal/config"
+	"backend/internal/events"
+	"backend/internal/handlers"
+	"backend/internal/models"
+	"backend/internal/notify"
+	"backend/internal/overstay"
+	"backend/internal/repository"
+	"backend/internal/storage"
+	"backend/internal/tenant"
+	"backend/internal/thaidate"
+	"backend/internal/token"
+
+	"github.com/gorilla/mux"
+)
+
+// schoolServer holds what the schools of the deployment share; build wires the API of one school
+type schoolServer struct {
+	cfg       *config.Config
+	db        *sql.DB
+	addresses *address.Dataset
+	signer    *token.Signer
+	notifier  notify.Notifier
+	store     storage.Storage
+	badges    *badge.Renderer                 // layout จาก BADGE_LAYOUT_FILE ใช้ร่วมกัน ชื่อบนหัวบัตรเป็นของแต่ละโรงเรียน
+	clock     *clock.Clock                    // ของโรงเรียนที่ไม่ได้ตั้ง timezone
+	syncQueue *repository.SyncQueueRepository // nil = ไม่ได้อยู่ในโหมด edge
+}
+
+// verify accepts the X-School header from a reader of that school (เครื่อง edge ที่ sync ขึ้นมา)
+// or from district staff with DISTRICT_ADMIN_KEY (tenant.Verifier)
+func (s *schoolServer) verify(r *http.Request, school *models.School) (bool, error) {
+	key := handlers.DeviceKey(r)
+	if key == "" {
+		return false, nil
+	}
+	if s.cfg.Tenant.AdminKey != "" && token.MatchAPIKey(key, token.HashAPIKey(s.cfg.Tenant.AdminKey)) {
+		return true, nil
+	}
+	return repository.NewDeviceRepository(s.db, s.clock).ForSchool(school.ID).HasKeyHash(token.HashAPIKey(key))
+}
+
+// build creates the handlers of school (tenant.Builder). งานเบื้องหลังหยุดเมื่อ ctx ถูกยกเลิก
+func (s *schoolServer) build(ctx context.Context, school *models.School) (http.Handler, error) {
+	clk, err := tenant.Clock(school, s.clock)
+	if err != nil {
+		return nil, err
+	}
+
+	dates := thaidate.Formatter{
+		Location:   clk.Location(),
+		Calendar:   s.cfg.Date.Calendar,
+		Month:      s.cfg.Date.Month,
+		ThaiDigits: s.cfg.Date.ThaiDigits,
+	}
+
+	db, id := s.db, school.ID
+	visitorRepo := repository.NewVisitorRepository(db, clk).ForSchool(id)
+	exportRepo := repository.NewExportRepository(db).ForSchool(id)
+	searchRepo := repository.NewSearchRepository(db, clk).ForSchool(id)
+	vehicleRepo := repository.NewVehicleRepository(db, clk).ForSchool(id)
+	appointmentRepo := repository.NewAppointmentRepository(db, clk).ForSchool(id)
+	purposeRepo := repository.NewPurposeRepository(db) // วัตถุประสงค์ใช้ร่วมกันทั้งเขต (migration 015)
+	departmentRepo := repository.NewDepartmentRepository(db).ForSchool(id)
+	studentRepo := repository.NewStudentRepository(db, clk).ForSchool(id)
+	pickupRepo := repository.NewPickupRepository(db, clk).ForSchool(id)
+	deviceRepo := repository.NewDeviceRepository(db, clk).ForSchool(id)
+	watchlistRepo := repository.NewWatchlistRepository(db).ForSchool(id)
+	statsRepo := repository.NewStatsRepository(db, clk).ForSchool(id)
+
+	if s.syncQueue != nil {
+		visitorRepo.QueueForSync()
+		pickupRepo.QueueForSync()
+	}
+
+	// โรงเรียนแรกใช้ schoolName ของไฟล์ layout เหมือน deployment โรงเรียนเดียว โรงเรียนอื่นใช้ชื่อในระเบียนของตัวเอง
+	badges := s.badges
+	if id != models.DefaultSchoolID {
+		badges = badges.WithSchoolName(school.Name)
+	}
+
+	bus := events.New(s.cfg.Events.History)
+	if s.cfg.Events.OverstayMinutes > 0 {
+		watcher := overstay.NewWatcher(visitorRepo, bus, clk, time.Duration(s.cfg.Events.OverstayMinutes)*time.Minute)
+		go watcher.Run(ctx)
+	}
+
+	router := mux.NewRouter()
+	handlers.Routes{
+		Visitor:     handlers.NewVisitorHandler(visitorRepo, visitorRepo, s.addresses, appointmentRepo, purposeRepo, departmentRepo, watchlistRepo, s.signer, s.notifier, dates, clk, bus),
+		Export:      handlers.NewExportHandler(visitorRepo, exportRepo, clk),
+		Search:      handlers.NewSearchHandler(searchRepo),
+		Vehicle:     handlers.NewVehicleHandler(vehicleRepo),
+		Address:     handlers.NewAddressHandler(s.addresses),
+		Appointment: handlers.NewAppointmentHandler(appointmentRepo, s.signer),
+		Purpose:     handlers.NewPurposeHandler(purposeRepo),
+		Department:  handlers.NewDepartmentHandler(departmentRepo),
+		Watchlist:   handlers.NewWatchlistHandler(watchlistRepo),
+		Student:     handlers.NewStudentHandler(studentRepo, pickupRepo, visitorRepo, s.notifier, clk, bus),
+		Photo:       handlers.NewPhotoHandler(visitorRepo, s.store, s.cfg.Storage.MaxPhotoBytes),
+		Badge:       handlers.NewBadgeHandler(visitorRepo, purposeRepo, s.store, s.signer, badges, clk),
+		SmartCard:   handlers.NewSmartCardHandler(visitorRepo, s.addresses, clk),
+		Device:      handlers.NewDeviceHandler(deviceRepo, visitorRepo, visitorRepo, clk, bus),
+		Event:       handlers.NewEventHandler(bus),
+		Stats:       handlers.NewStatsHandler(statsRepo, departmentRepo, clk, dates),
+		Sync:        handlers.NewSyncHandler(deviceRepo, visitorRepo, visitorRepo, departmentRepo, watchlistRepo, s.syncQueue, clk, bus),
+		School:      handlers.NewSchoolHandler(school),
+	}.Register(router.PathPrefix("/api").Subrouter())
+
+	log.Printf("School %s (%s) ready, timezone %s", school.Code, school.Name, clk.Location())
+	return router, nil
+}
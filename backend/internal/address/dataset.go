@@ -0,0 +1,175 @@
+package address
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// BangkokCode is the DOPA code of กรุงเทพมหานคร
+const BangkokCode = "10"
+
+//go:generate go run ../../cmd/addressdata -out data/thai_admin_areas.json
+
+//go:embed data/thai_admin_areas.json
+var embeddedData []byte
+
+// SubDistrict represents a ตำบล/แขวง
+type SubDistrict struct {
+	NameTh     string `json:"nameTh"`
+	NameEn     string `json:"nameEn,omitempty"`
+	PostalCode string `json:"postalCode"`
+}
+
+// District represents an อำเภอ/เขต
+type District struct {
+	NameTh       string        `json:"nameTh"`
+	NameEn       string        `json:"nameEn,omitempty"`
+	PostalCode   string        `json:"postalCode,omitempty"`
+	SubDistricts []SubDistrict `json:"subDistricts"`
+}
+
+// Province represents a จังหวัด
+type Province struct {
+	Code         string     `json:"code"`
+	NameTh       string     `json:"nameTh"`
+	NameEn       string     `json:"nameEn"`
+	PostalPrefix string     `json:"postalPrefix"`
+	Districts    []District `json:"districts"`
+}
+
+// IsBangkok reports whether the province uses แขวง/เขต instead of ตำบล/อำเภอ
+func (p *Province) IsBangkok() bool {
+	return p.Code == BangkokCode
+}
+
+// Dataset is the Thai administrative-area reference data
+type Dataset struct {
+	// Complete means every province lists all of its districts and every district all of its
+	// sub-districts (สร้างด้วย go generate), so Validate checks every level it is given
+	Complete  bool       `json:"complete,omitempty"`
+	Provinces []Province `json:"provinces"`
+}
+
+// Load reads the reference data from path, or the embedded data set when path is empty.
+// ข้อมูลที่ฝังมาสร้างด้วย cmd/addressdata (go generate ./internal/address) จากข้อมูลของกรมการปกครอง
+// ไฟล์ JSON รูปแบบเดียวกันระบุแทนได้ผ่าน ADDRESS_DATA_FILE
+func Load(path string) (*Dataset, error) {
+	data := embeddedData
+	if path != "" {
+		var err error
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read address data: %w", err)
+		}
+	}
+
+	var ds Dataset
+	if err := json.Unmarshal(data, &ds); err != nil {
+		return nil, fmt.Errorf("failed to parse address data: %w", err)
+	}
+	return &ds, nil
+}
+
+// FindProvince finds a province by DOPA code, Thai name or English name
+func (ds *Dataset) FindProvince(nameOrCode string) *Province {
+	key := NormalizeProvince(nameOrCode)
+	for i := range ds.Provinces {
+		p := &ds.Provinces[i]
+		if p.Code == key || p.NameTh == key || strings.EqualFold(p.NameEn, key) {
+			return p
+		}
+	}
+	return nil
+}
+
+// FindDistrict finds a district of p by Thai or English name
+func (p *Province) FindDistrict(name string) *District {
+	key := NormalizeDistrict(name)
+	for i := range p.Districts {
+		d := &p.Districts[i]
+		if d.NameTh == key || strings.EqualFold(d.NameEn, key) {
+			return d
+		}
+	}
+	return nil
+}
+
+// FindSubDistrict finds a sub-district of d by Thai or English name
+func (d *District) FindSubDistrict(name string) *SubDistrict {
+	key := NormalizeSubDistrict(name)
+	for i := range d.SubDistricts {
+		s := &d.SubDistricts[i]
+		if s.NameTh == key || strings.EqualFold(s.NameEn, key) {
+			return s
+		}
+	}
+	return nil
+}
+
+// PostalCodeMatch is one area served by a postal code
+type PostalCodeMatch struct {
+	Province    string `json:"province"`
+	District    string `json:"district"`
+	SubDistrict string `json:"subDistrict,omitempty"`
+	PostalCode  string `json:"postalCode"`
+}
+
+// FindByPostalCode lists the areas that use the given postal code
+func (ds *Dataset) FindByPostalCode(code string) []PostalCodeMatch {
+	matches := []PostalCodeMatch{}
+	for _, p := range ds.Provinces {
+		for _, d := range p.Districts {
+			if len(d.SubDistricts) == 0 {
+				if d.PostalCode == code {
+					matches = append(matches, PostalCodeMatch{Province: p.NameTh, District: d.NameTh, PostalCode: code})
+				}
+				continue
+			}
+			for _, s := range d.SubDistricts {
+				if s.PostalCode == code {
+					matches = append(matches, PostalCodeMatch{
+						Province:    p.NameTh,
+						District:    d.NameTh,
+						SubDistrict: s.NameTh,
+						PostalCode:  code,
+					})
+				}
+			}
+		}
+	}
+	return matches
+}
+
+// NormalizeProvince ตัดคำนำหน้า "จ." / "จังหวัด" และแปลงชื่อย่อกรุงเทพฯ ให้เป็นชื่อเต็ม
+func NormalizeProvince(s string) string {
+	s = trimPrefixes(s, "จังหวัด", "จ.")
+
+	switch s {
+	case "กทม", "กทม.", "กรุงเทพ", "กรุงเทพฯ":
+		return "กรุงเทพมหานคร"
+	}
+	return s
+}
+
+// NormalizeDistrict ตัดคำนำหน้า อำเภอ/อ./เขต ออก
+func NormalizeDistrict(s string) string {
+	return trimPrefixes(s, "อำเภอ", "อ.", "เขต")
+}
+
+// NormalizeSubDistrict ตัดคำนำหน้า ตำบล/ต./แขวง ออก
+func NormalizeSubDistrict(s string) string {
+	return trimPrefixes(s, "ตำบล", "ต.", "แขวง")
+}
+
+func trimPrefixes(s string, prefixes ...string) string {
+	s = strings.TrimSpace(s)
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return strings.TrimSpace(strings.TrimPrefix(s, prefix))
+		}
+	}
+	return s
+}
@@ -0,0 +1,115 @@
+package address
+
+import (
+	"reflect"
+	"testing"
+)
+
+// testDataset is a complete data set of two provinces, in the shape cmd/addressdata writes
+func testDataset() *Dataset {
+	return &Dataset{
+		Complete: true,
+		Provinces: []Province{
+			{
+				Code: "10", NameTh: "กรุงเทพมหานคร", NameEn: "Bangkok", PostalPrefix: "10",
+				Districts: []District{
+					{NameTh: "ดุสิต", NameEn: "Dusit", PostalCode: "10300", SubDistricts: []SubDistrict{
+						{NameTh: "ดุสิต", NameEn: "Dusit", PostalCode: "10300"},
+						{NameTh: "วชิรพยาบาล", NameEn: "Wachiraphayaban", PostalCode: "10300"},
+					}},
+					{NameTh: "บางซื่อ", NameEn: "Bang Sue", PostalCode: "10800", SubDistricts: []SubDistrict{
+						{NameTh: "บางซื่อ", NameEn: "Bang Sue", PostalCode: "10800"},
+						{NameTh: "วงศ์สว่าง", NameEn: "Wong Sawang", PostalCode: "10800"},
+					}},
+				},
+			},
+			{
+				Code: "12", NameTh: "นนทบุรี", NameEn: "Nonthaburi", PostalPrefix: "11",
+				Districts: []District{
+					{NameTh: "เมืองนนทบุรี", NameEn: "Mueang Nonthaburi", SubDistricts: []SubDistrict{
+						{NameTh: "บางกระสอ", NameEn: "Bang Kraso", PostalCode: "11000"},
+						{NameTh: "ท่าทราย", NameEn: "Tha Sai", PostalCode: "11000"},
+					}},
+					{NameTh: "ปากเกร็ด", NameEn: "Pak Kret", SubDistricts: []SubDistrict{
+						{NameTh: "ปากเกร็ด", NameEn: "Pak Kret", PostalCode: "11120"},
+						{NameTh: "บางตลาด", NameEn: "Bang Talat", PostalCode: "11120"},
+					}},
+				},
+			},
+		},
+	}
+}
+
+func TestLoadEmbedded(t *testing.T) {
+	ds, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ds.Provinces) != 77 {
+		t.Errorf("embedded data has %d provinces, want 77", len(ds.Provinces))
+	}
+	bangkok := ds.FindProvince("กทม.")
+	if bangkok == nil || !bangkok.IsBangkok() || len(bangkok.Districts) != 50 {
+		t.Fatalf("FindProvince(กทม.) = %+v", bangkok)
+	}
+	if d := bangkok.FindDistrict("เขตบางซื่อ"); d == nil || d.NameEn != "Bang Sue" {
+		t.Errorf("FindDistrict(เขตบางซื่อ) = %+v", d)
+	}
+}
+
+func TestFindByPostalCode(t *testing.T) {
+	ds := testDataset()
+
+	tests := []struct {
+		code string
+		want []PostalCodeMatch
+	}{
+		{"10300", []PostalCodeMatch{
+			{Province: "กรุงเทพมหานคร", District: "ดุสิต", SubDistrict: "ดุสิต", PostalCode: "10300"},
+			{Province: "กรุงเทพมหานคร", District: "ดุสิต", SubDistrict: "วชิรพยาบาล", PostalCode: "10300"},
+		}},
+		{"11120", []PostalCodeMatch{
+			{Province: "นนทบุรี", District: "ปากเกร็ด", SubDistrict: "ปากเกร็ด", PostalCode: "11120"},
+			{Province: "นนทบุรี", District: "ปากเกร็ด", SubDistrict: "บางตลาด", PostalCode: "11120"},
+		}},
+		{"99999", []PostalCodeMatch{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			if got := ds.FindByPostalCode(tt.code); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindByPostalCode(%s) = %+v, want %+v", tt.code, got, tt.want)
+			}
+		})
+	}
+
+	// อำเภอที่ไม่มีรายชื่อตำบล (ชุดข้อมูลไม่ครบ) ค้นด้วยรหัสของอำเภอ
+	partial := &Dataset{Provinces: []Province{{Code: "10", NameTh: "กรุงเทพมหานคร", PostalPrefix: "10",
+		Districts: []District{{NameTh: "ดุสิต", PostalCode: "10300"}}}}}
+	want := []PostalCodeMatch{{Province: "กรุงเทพมหานคร", District: "ดุสิต", PostalCode: "10300"}}
+	if got := partial.FindByPostalCode("10300"); !reflect.DeepEqual(got, want) {
+		t.Errorf("partial FindByPostalCode() = %+v", got)
+	}
+}
+
+func TestNormalizeNames(t *testing.T) {
+	tests := []struct {
+		fn   func(string) string
+		in   string
+		want string
+	}{
+		{NormalizeProvince, "จ.นนทบุรี", "นนทบุรี"},
+		{NormalizeProvince, "จังหวัด นนทบุรี", "นนทบุรี"},
+		{NormalizeProvince, "กทม.", "กรุงเทพมหานคร"},
+		{NormalizeProvince, "กรุงเทพฯ", "กรุงเทพมหานคร"},
+		{NormalizeDistrict, "อ.ปากเกร็ด", "ปากเกร็ด"},
+		{NormalizeDistrict, "เขตบางซื่อ", "บางซื่อ"},
+		{NormalizeSubDistrict, "ต.บางตลาด", "บางตลาด"},
+		{NormalizeSubDistrict, " แขวงวงศ์สว่าง ", "วงศ์สว่าง"},
+		{NormalizeSubDistrict, "ต้นธง", "ต้นธง"},
+	}
+	for _, tt := range tests {
+		if got := tt.fn(tt.in); got != tt.want {
+			t.Errorf("normalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
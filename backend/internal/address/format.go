@@ -0,0 +1,98 @@
+package address
+
+import (
+	"strings"
+
+	"backend/internal/models"
+)
+
+// Style selects how much of each prefix is written
+type Style int
+
+const (
+	// Short ใช้คำย่อ เช่น ม. ซ. ถ. ต. อ. จ. สำหรับตารางรายชื่อ
+	Short Style = iota
+	// Full ใช้คำเต็ม เช่น บ้านเลขที่ หมู่ ซอย ถนน ตำบล อำเภอ จังหวัด สำหรับ export/เอกสาร
+	Full
+)
+
+// Address is a structured Thai address
+type Address struct {
+	HouseNumber string
+	Moo         string
+	Soi         string
+	Road        string
+	SubDistrict string
+	District    string
+	Province    string
+	PostalCode  string
+}
+
+// FromVisitor builds an Address from the visitor's address columns
+func FromVisitor(v *models.Visitor) Address {
+	return Address{
+		HouseNumber: v.HouseNumber,
+		Moo:         v.Moo,
+		Soi:         v.Soi,
+		Road:        v.Road,
+		SubDistrict: v.SubDistrict,
+		District:    v.District,
+		Province:    v.Province,
+		PostalCode:  v.PostalCode,
+	}
+}
+
+// IsBangkok reports whether the address is in กรุงเทพมหานคร
+func (a Address) IsBangkok() bool {
+	return NormalizeProvince(a.Province) == "กรุงเทพมหานคร"
+}
+
+type prefixSet struct {
+	house, moo, soi, road           string
+	subDistrict, district, province string
+}
+
+var (
+	thaiShort = prefixSet{"", "ม.", "ซ.", "ถ.", "ต.", "อ.", "จ."}
+	thaiFull  = prefixSet{"บ้านเลขที่ ", "หมู่ ", "ซอย ", "ถนน ", "ตำบล ", "อำเภอ ", "จังหวัด "}
+
+	// กรุงเทพฯ ใช้ แขวง/เขต และไม่มีคำว่า จังหวัด
+	bangkokShort = prefixSet{"", "ม.", "ซ.", "ถ.", "แขวง", "เขต", ""}
+	bangkokFull  = prefixSet{"บ้านเลขที่ ", "หมู่ ", "ซอย ", "ถนน ", "แขวง", "เขต", ""}
+)
+
+// Format renders the address in Thai, using แขวง/เขต for Bangkok and ตำบล/อำเภอ elsewhere.
+// คืนค่า "-" เมื่อไม่มีข้อมูลที่อยู่เลย
+func Format(a Address, style Style) string {
+	prefixes := thaiShort
+	switch {
+	case a.IsBangkok() && style == Full:
+		prefixes = bangkokFull
+	case a.IsBangkok():
+		prefixes = bangkokShort
+	case style == Full:
+		prefixes = thaiFull
+	}
+
+	parts := []string{}
+	add := func(prefix, value string) {
+		if value = strings.TrimSpace(value); value != "" {
+			parts = append(parts, prefix+value)
+		}
+	}
+
+	add(prefixes.house, a.HouseNumber)
+	add(prefixes.moo, a.Moo)
+	add(prefixes.soi, a.Soi)
+	add(prefixes.road, a.Road)
+	add(prefixes.subDistrict, NormalizeSubDistrict(a.SubDistrict))
+	add(prefixes.district, NormalizeDistrict(a.District))
+	add(prefixes.province, NormalizeProvince(a.Province))
+	add("", a.PostalCode)
+
+	if len(parts) == 0 {
+		return "-"
+	}
+
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,31 @@
+package address
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	bangkok := Address{HouseNumber: "12/3", Soi: "ประชาราษฎร์บำเพ็ญ 5", Road: "ประชาราษฎร์บำเพ็ญ",
+		SubDistrict: "แขวงวงศ์สว่าง", District: "เขตบางซื่อ", Province: "กทม.", PostalCode: "10800"}
+	nonthaburi := Address{HouseNumber: "99", Moo: "4", SubDistrict: "บางตลาด", District: "อ.ปากเกร็ด",
+		Province: "จ.นนทบุรี", PostalCode: "11120"}
+
+	tests := []struct {
+		name  string
+		a     Address
+		style Style
+		want  string
+	}{
+		{"bangkok short", bangkok, Short, "12/3 ซ.ประชาราษฎร์บำเพ็ญ 5 ถ.ประชาราษฎร์บำเพ็ญ แขวงวงศ์สว่าง เขตบางซื่อ กรุงเทพมหานคร 10800"},
+		{"bangkok full", bangkok, Full, "บ้านเลขที่ 12/3 ซอย ประชาราษฎร์บำเพ็ญ 5 ถนน ประชาราษฎร์บำเพ็ญ แขวงวงศ์สว่าง เขตบางซื่อ กรุงเทพมหานคร 10800"},
+		{"province short", nonthaburi, Short, "99 ม.4 ต.บางตลาด อ.ปากเกร็ด จ.นนทบุรี 11120"},
+		{"province full", nonthaburi, Full, "บ้านเลขที่ 99 หมู่ 4 ตำบล บางตลาด อำเภอ ปากเกร็ด จังหวัด นนทบุรี 11120"},
+		{"bangkok without sub-district", Address{District: "ดุสิต", Province: "กรุงเทพมหานคร"}, Short, "เขตดุสิต กรุงเทพมหานคร"},
+		{"empty", Address{}, Full, "-"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Format(tt.a, tt.style); got != tt.want {
+				t.Errorf("Format() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
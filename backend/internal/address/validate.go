@@ -0,0 +1,95 @@
+package address
+
+import "fmt"
+
+// Validate checks the address against the reference data and returns it with
+// prefixes stripped and names in their canonical form.
+// ชุดข้อมูลที่ครบ (Complete) ตรวจทุกระดับที่ระบุมา ชุดข้อมูลไม่ครบจะข้ามระดับที่ไม่มีข้อมูลอ้างอิง
+func (ds *Dataset) Validate(a Address) (Address, error) {
+	a.SubDistrict = NormalizeSubDistrict(a.SubDistrict)
+	a.District = NormalizeDistrict(a.District)
+	a.Province = NormalizeProvince(a.Province)
+
+	if a.PostalCode != "" && !isPostalCode(a.PostalCode) {
+		return a, fmt.Errorf("รหัสไปรษณีย์ต้องเป็นตัวเลข 5 หลัก")
+	}
+
+	if a.Province == "" {
+		if a.District != "" || a.SubDistrict != "" {
+			return a, fmt.Errorf("กรุณาระบุจังหวัด")
+		}
+		return a, nil
+	}
+
+	province := ds.FindProvince(a.Province)
+	if province == nil {
+		return a, fmt.Errorf("ไม่พบจังหวัด %s", a.Province)
+	}
+	a.Province = province.NameTh
+
+	if a.PostalCode != "" && a.PostalCode[:2] != province.PostalPrefix {
+		return a, fmt.Errorf("รหัสไปรษณีย์ %s ไม่ตรงกับจังหวัด%s", a.PostalCode, province.NameTh)
+	}
+
+	if a.District == "" {
+		if a.SubDistrict != "" {
+			return a, fmt.Errorf("กรุณาระบุ%s", districtLabel(province))
+		}
+		return a, nil
+	}
+	if !ds.Complete && len(province.Districts) == 0 {
+		return a, nil
+	}
+
+	district := province.FindDistrict(a.District)
+	if district == nil {
+		return a, fmt.Errorf("ไม่พบ%s %s ใน%s", districtLabel(province), a.District, province.NameTh)
+	}
+	a.District = district.NameTh
+
+	// ชุดข้อมูลที่ครบมีรหัสของอำเภอเฉพาะเมื่อทุกตำบลใช้รหัสเดียวกัน
+	if ds.Complete && a.PostalCode != "" && district.PostalCode != "" && a.PostalCode != district.PostalCode {
+		return a, fmt.Errorf("รหัสไปรษณีย์ %s ไม่ตรงกับ%s%s", a.PostalCode, districtLabel(province), district.NameTh)
+	}
+	if a.SubDistrict == "" || (!ds.Complete && len(district.SubDistricts) == 0) {
+		return a, nil
+	}
+
+	subDistrict := district.FindSubDistrict(a.SubDistrict)
+	if subDistrict == nil {
+		return a, fmt.Errorf("ไม่พบ%s %s ใน%s%s", subDistrictLabel(province), a.SubDistrict, districtLabel(province), district.NameTh)
+	}
+	a.SubDistrict = subDistrict.NameTh
+
+	if a.PostalCode != "" && subDistrict.PostalCode != "" && a.PostalCode != subDistrict.PostalCode {
+		return a, fmt.Errorf("รหัสไปรษณีย์ %s ไม่ตรงกับ%s%s", a.PostalCode, subDistrictLabel(province), subDistrict.NameTh)
+	}
+
+	return a, nil
+}
+
+func districtLabel(p *Province) string {
+	if p.IsBangkok() {
+		return "เขต"
+	}
+	return "อำเภอ"
+}
+
+func subDistrictLabel(p *Province) string {
+	if p.IsBangkok() {
+		return "แขวง"
+	}
+	return "ตำบล"
+}
+
+func isPostalCode(s string) bool {
+	if len(s) != 5 {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,66 @@
+package address
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	ds := testDataset()
+
+	tests := []struct {
+		name string
+		in   Address
+		want Address
+		err  bool
+	}{
+		{
+			name: "bangkok with prefixes",
+			in:   Address{SubDistrict: "แขวงวงศ์สว่าง", District: "เขตบางซื่อ", Province: "กทม.", PostalCode: "10800"},
+			want: Address{SubDistrict: "วงศ์สว่าง", District: "บางซื่อ", Province: "กรุงเทพมหานคร", PostalCode: "10800"},
+		},
+		{
+			name: "english names",
+			in:   Address{SubDistrict: "Bang Talat", District: "pak kret", Province: "Nonthaburi"},
+			want: Address{SubDistrict: "บางตลาด", District: "ปากเกร็ด", Province: "นนทบุรี"},
+		},
+		{name: "province only", in: Address{Province: "จ.นนทบุรี"}, want: Address{Province: "นนทบุรี"}},
+		{name: "empty", in: Address{}, want: Address{}},
+		{name: "bad postal code", in: Address{Province: "นนทบุรี", PostalCode: "11O00"}, err: true},
+		{name: "district without province", in: Address{District: "ปากเกร็ด"}, err: true},
+		{name: "sub-district without district", in: Address{SubDistrict: "บางตลาด", Province: "นนทบุรี"}, err: true},
+		{name: "unknown province", in: Address{Province: "แอตแลนติส"}, err: true},
+		{name: "postal code of another province", in: Address{Province: "นนทบุรี", PostalCode: "10300"}, err: true},
+		{name: "district of another province", in: Address{District: "ดุสิต", Province: "นนทบุรี"}, err: true},
+		{name: "sub-district of another district", in: Address{SubDistrict: "ดุสิต", District: "บางซื่อ", Province: "กรุงเทพมหานคร"}, err: true},
+		{name: "postal code of another district", in: Address{District: "บางซื่อ", Province: "กรุงเทพมหานคร", PostalCode: "10300"}, err: true},
+		{name: "postal code of another sub-district", in: Address{SubDistrict: "ท่าทราย", District: "เมืองนนทบุรี", Province: "นนทบุรี", PostalCode: "11120"}, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ds.Validate(tt.in)
+			if tt.err {
+				if err == nil {
+					t.Errorf("Validate() = %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Validate() = %+v, %v, want %+v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+// ชุดข้อมูลที่ครบตรวจทุกระดับ ชุดข้อมูลไม่ครบข้ามระดับที่ไม่มีข้อมูล
+func TestValidateCompleteness(t *testing.T) {
+	ds := testDataset()
+	ds.Provinces[1].Districts[1].SubDistricts = nil
+	in := Address{SubDistrict: "คลองพระอุดม", District: "ปากเกร็ด", Province: "นนทบุรี"}
+
+	if _, err := ds.Validate(in); err == nil {
+		t.Error("complete data set accepted a sub-district it does not list")
+	}
+
+	ds.Complete = false
+	if got, err := ds.Validate(in); err != nil || got.SubDistrict != "คลองพระอุดม" {
+		t.Errorf("partial Validate() = %+v, %v", got, err)
+	}
+}
@@ -0,0 +1,38 @@
+package badge
+
+import (
+	"embed"
+
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+)
+
+//go:generate curl -fsSL -o fonts/Sarabun-Regular.ttf https://raw.githubusercontent.com/google/fonts/main/ofl/sarabun/Sarabun-Regular.ttf
+//go:generate curl -fsSL -o fonts/OFL.txt https://raw.githubusercontent.com/google/fonts/main/ofl/sarabun/OFL.txt
+
+// fonts holds the Thai font bundled with the server (Sarabun, SIL Open Font License 1.1)
+//
+//go:embed fonts
+var fonts embed.FS
+
+const defaultFontFile = "fonts/Sarabun-Regular.ttf"
+
+// defaultFont returns the bundled Sarabun, or the Go font when the build was made without it
+func defaultFont() []byte {
+	if data, err := fonts.ReadFile(defaultFontFile); err == nil {
+		return data
+	}
+	return goregular.TTF
+}
+
+// hasThai reports whether f has glyphs for Thai (ก and ์ เป็นตัวแทน)
+func hasThai(f *opentype.Font) bool {
+	var buf sfnt.Buffer
+	for _, r := range []rune{'ก', '์'} {
+		if i, err := f.GlyphIndex(&buf, r); err != nil || i == 0 {
+			return false
+		}
+	}
+	return true
+}
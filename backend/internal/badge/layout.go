@@ -0,0 +1,132 @@
+// Package badge renders the printable visitor pass (PNG for label printers, PDF for normal printers)
+package badge
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Badge fields that can be listed in Layout.Fields
+const (
+	FieldName       = "name"
+	FieldDepartment = "department"
+	FieldHost       = "host"
+	FieldPurpose    = "purpose"
+	FieldCheckIn    = "checkIn"
+	FieldExpires    = "expires"
+)
+
+// Photo positions; the QR code goes on the opposite side
+const (
+	PositionLeft  = "left"
+	PositionRight = "right"
+)
+
+// Layout describes the badge of one school.
+// โหลดจากไฟล์ JSON (BADGE_LAYOUT_FILE) ค่าที่ไม่ได้ระบุจะใช้ค่าจาก DefaultLayout
+type Layout struct {
+	SchoolName    string   `json:"schoolName"`
+	WidthMM       float64  `json:"widthMm"`
+	HeightMM      float64  `json:"heightMm"`
+	DPI           int      `json:"dpi"`
+	ValidHours    int      `json:"validHours"` // บัตรหมดอายุหลังเวลาเข้ากี่ชั่วโมง (0 = สิ้นวัน)
+	FontFile      string   `json:"fontFile"`   // ฟอนต์ TTF/OTF ที่มีอักษรไทย เช่น Sarabun
+	HeaderColor   string   `json:"headerColor"`
+	ShowPhoto     bool     `json:"showPhoto"`
+	PhotoPosition string   `json:"photoPosition"`
+	Fields        []string `json:"fields"`
+}
+
+// DefaultLayout is a CR80 card-sized badge (86 x 54 mm) at 300 dpi
+func DefaultLayout() Layout {
+	return Layout{
+		SchoolName:    "VISITOR",
+		WidthMM:       86,
+		HeightMM:      54,
+		DPI:           300,
+		ValidHours:    0,
+		HeaderColor:   "#1E3A8A",
+		ShowPhoto:     true,
+		PhotoPosition: PositionLeft,
+		Fields:        []string{FieldName, FieldDepartment, FieldHost, FieldCheckIn, FieldExpires},
+	}
+}
+
+// LoadLayout reads the layout file over the defaults; an empty path returns the defaults
+func LoadLayout(path string) (Layout, error) {
+	layout := DefaultLayout()
+	if path == "" {
+		return layout, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return layout, fmt.Errorf("failed to read badge layout: %w", err)
+	}
+	if err := json.Unmarshal(data, &layout); err != nil {
+		return layout, fmt.Errorf("failed to parse badge layout: %w", err)
+	}
+	if err := layout.Validate(); err != nil {
+		return layout, err
+	}
+
+	return layout, nil
+}
+
+// Validate checks the layout values
+func (l Layout) Validate() error {
+	if l.WidthMM < 20 || l.HeightMM < 20 || l.WidthMM > 300 || l.HeightMM > 300 {
+		return fmt.Errorf("badge size must be between 20 and 300 mm")
+	}
+	if l.DPI < 72 || l.DPI > 600 {
+		return fmt.Errorf("badge dpi must be between 72 and 600")
+	}
+	if l.ValidHours < 0 {
+		return fmt.Errorf("validHours must not be negative")
+	}
+	if l.PhotoPosition != PositionLeft && l.PhotoPosition != PositionRight {
+		return fmt.Errorf("photoPosition must be %q or %q", PositionLeft, PositionRight)
+	}
+	if _, err := parseHexColor(l.HeaderColor); err != nil {
+		return err
+	}
+	for _, f := range l.Fields {
+		switch f {
+		case FieldName, FieldDepartment, FieldHost, FieldPurpose, FieldCheckIn, FieldExpires:
+		default:
+			return fmt.Errorf("unknown badge field: %s", f)
+		}
+	}
+	return nil
+}
+
+// ExpiresAt returns when a badge issued at checkIn stops being valid
+func (l Layout) ExpiresAt(checkIn time.Time) time.Time {
+	if l.ValidHours > 0 {
+		return checkIn.Add(time.Duration(l.ValidHours) * time.Hour)
+	}
+	y, m, d := checkIn.Date()
+	return time.Date(y, m, d, 23, 59, 59, 0, checkIn.Location())
+}
+
+// pixels converts millimetres to pixels at the layout DPI
+func (l Layout) pixels(mm float64) int {
+	return int(mm / 25.4 * float64(l.DPI))
+}
+
+func parseHexColor(s string) (color.RGBA, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) != 6 {
+		return color.RGBA{}, fmt.Errorf("invalid color: %s", s)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid color: %s", s)
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}, nil
+}
@@ -0,0 +1,80 @@
+package badge
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadLayout(t *testing.T) {
+	layout, err := LoadLayout("")
+	if err != nil || layout.SchoolName != "VISITOR" || layout.WidthMM != 86 {
+		t.Fatalf("LoadLayout(\"\") = %+v, %v", layout, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "badge.json")
+	if err := os.WriteFile(path, []byte(`{"schoolName":"โรงเรียนวัดดุสิต","validHours":4,"photoPosition":"right","fields":["name","purpose"]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	layout, err = LoadLayout(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ค่าที่ไม่ได้ระบุมาจาก DefaultLayout
+	if layout.SchoolName != "โรงเรียนวัดดุสิต" || layout.ValidHours != 4 || layout.PhotoPosition != PositionRight ||
+		len(layout.Fields) != 2 || layout.DPI != 300 || layout.HeaderColor != "#1E3A8A" {
+		t.Errorf("LoadLayout() = %+v", layout)
+	}
+
+	if _, err := LoadLayout(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("LoadLayout() of a missing file should fail")
+	}
+	if err := os.WriteFile(path, []byte(`{"dpi":1200}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadLayout(path); err == nil {
+		t.Error("LoadLayout() accepted dpi 1200")
+	}
+}
+
+func TestLayoutValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Layout)
+	}{
+		{"too small", func(l *Layout) { l.WidthMM = 10 }},
+		{"too large", func(l *Layout) { l.HeightMM = 400 }},
+		{"low dpi", func(l *Layout) { l.DPI = 50 }},
+		{"negative valid hours", func(l *Layout) { l.ValidHours = -1 }},
+		{"photo position", func(l *Layout) { l.PhotoPosition = "top" }},
+		{"header color", func(l *Layout) { l.HeaderColor = "navy" }},
+		{"unknown field", func(l *Layout) { l.Fields = []string{FieldName, "idCard"} }},
+	}
+	if err := DefaultLayout().Validate(); err != nil {
+		t.Fatalf("DefaultLayout().Validate() = %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := DefaultLayout()
+			tt.modify(&l)
+			if err := l.Validate(); err == nil {
+				t.Error("Validate() = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestExpiresAt(t *testing.T) {
+	bangkok := time.FixedZone("ICT", 7*60*60)
+	checkIn := time.Date(2025, 6, 2, 9, 30, 0, 0, bangkok)
+
+	l := DefaultLayout()
+	if got, want := l.ExpiresAt(checkIn), time.Date(2025, 6, 2, 23, 59, 59, 0, bangkok); !got.Equal(want) {
+		t.Errorf("ExpiresAt() = %v, want end of day %v", got, want)
+	}
+	l.ValidHours = 4
+	if got := l.ExpiresAt(checkIn); !got.Equal(checkIn.Add(4 * time.Hour)) {
+		t.Errorf("ExpiresAt() with validHours 4 = %v", got)
+	}
+}
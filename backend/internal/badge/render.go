@@ -0,0 +1,279 @@
+package badge
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"time"
+
+	qrcode "github.com/skip2/go-qrcode"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Badge is the content printed on one visitor pass
+type Badge struct {
+	Name       string
+	Department string
+	Host       string
+	Purpose    string
+	CheckIn    time.Time
+	ExpiresAt  time.Time
+	QRContent  string      // token ของการเข้าเยี่ยม ให้ป้อมสแกนตอนคืนบัตร
+	Photo      image.Image // nil = ไม่มีรูป
+}
+
+// Renderer draws badges with one layout and font
+type Renderer struct {
+	layout Layout
+	font   *opentype.Font
+	header color.RGBA
+}
+
+// NewRenderer loads the layout font.
+// ถ้าไม่ได้ตั้ง fontFile จะใช้ฟอนต์ Sarabun ที่ฝังมากับ server (ดู fonts/README.md)
+func NewRenderer(layout Layout) (*Renderer, error) {
+	if err := layout.Validate(); err != nil {
+		return nil, err
+	}
+
+	fontData := defaultFont()
+	if layout.FontFile != "" {
+		data, err := os.ReadFile(layout.FontFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read badge font: %w", err)
+		}
+		fontData = data
+	}
+
+	f, err := opentype.Parse(fontData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse badge font: %w", err)
+	}
+
+	header, _ := parseHexColor(layout.HeaderColor)
+	return &Renderer{layout: layout, font: f, header: header}, nil
+}
+
+// WithSchoolName returns a renderer that prints name in the header, sharing the loaded font
+func (r *Renderer) WithSchoolName(name string) *Renderer {
+	named := *r
+	named.layout.SchoolName = name
+	return &named
+}
+
+// SupportsThai reports whether the loaded font can draw Thai names.
+// ฟอนต์ที่ไม่มีอักษรไทยจะแสดงชื่อเป็นกล่อง
+func (r *Renderer) SupportsThai() bool {
+	return hasThai(r.font)
+}
+
+// Layout returns the layout used by the renderer
+func (r *Renderer) Layout() Layout {
+	return r.layout
+}
+
+// Render draws the badge: header, photo on one side, text and QR code on the other
+func (r *Renderer) Render(b Badge) (*image.RGBA, error) {
+	l := r.layout
+	width, height := l.pixels(l.WidthMM), l.pixels(l.HeightMM)
+	margin := l.pixels(3)
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	// แถบหัวบัตร
+	headerHeight := height * 18 / 100
+	draw.Draw(img, image.Rect(0, 0, width, headerHeight), image.NewUniform(r.header), image.Point{}, draw.Src)
+	headerFace, err := r.face(float64(headerHeight) * 0.5)
+	if err != nil {
+		return nil, err
+	}
+	defer headerFace.Close()
+	r.drawText(img, headerFace, image.White, l.SchoolName, margin, headerHeight*70/100, width-2*margin)
+
+	body := image.Rect(margin, headerHeight+margin, width-margin, height-margin)
+
+	// รูปถ่าย (สัดส่วน 3:4) อยู่ด้าน photoPosition ข้อความใช้พื้นที่ที่เหลือ
+	text := body
+	if l.ShowPhoto {
+		photoWidth := body.Dy() * 3 / 4
+		photoRect := image.Rect(body.Min.X, body.Min.Y, body.Min.X+photoWidth, body.Max.Y)
+		text.Min.X = photoRect.Max.X + margin
+		if l.PhotoPosition == PositionRight {
+			photoRect = image.Rect(body.Max.X-photoWidth, body.Min.Y, body.Max.X, body.Max.Y)
+			text = image.Rect(body.Min.X, body.Min.Y, photoRect.Min.X-margin, body.Max.Y)
+		}
+		drawPhoto(img, photoRect, b.Photo)
+	}
+
+	// QR code อยู่มุมล่างของพื้นที่ข้อความ ฝั่งตรงข้ามกับรูป
+	qrSize := min(body.Dy()*45/100, text.Dx()/3)
+	qr, err := qrcode.New(b.QRContent, qrcode.Medium)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate QR code: %w", err)
+	}
+	qr.DisableBorder = true
+	qrRect := image.Rect(text.Max.X-qrSize, text.Max.Y-qrSize, text.Max.X, text.Max.Y)
+	if l.PhotoPosition == PositionRight {
+		qrRect = image.Rect(text.Min.X, text.Max.Y-qrSize, text.Min.X+qrSize, text.Max.Y)
+	}
+	draw.NearestNeighbor.Scale(img, qrRect, qr.Image(qrSize), image.Rect(0, 0, qrSize, qrSize), draw.Src, nil)
+
+	// ข้อความ: ชื่อตัวใหญ่ บรรทัดอื่นตัวเล็ก บรรทัดที่ลงมาถึงระดับ QR จะสั้นลงเพื่อหลบ QR
+	nameFace, err := r.face(float64(body.Dy()) / 7)
+	if err != nil {
+		return nil, err
+	}
+	defer nameFace.Close()
+	lineFace, err := r.face(float64(body.Dy()) / 10)
+	if err != nil {
+		return nil, err
+	}
+	defer lineFace.Close()
+
+	y := text.Min.Y
+	for _, field := range l.Fields {
+		face, value := lineFace, fieldText(field, b)
+		if field == FieldName {
+			face = nameFace
+		}
+		if value == "" {
+			continue
+		}
+		y += face.Metrics().Height.Ceil()
+		if y > text.Max.Y {
+			break
+		}
+
+		left, right := text.Min.X, text.Max.X
+		if y+face.Metrics().Descent.Ceil() > qrRect.Min.Y {
+			if l.PhotoPosition == PositionRight {
+				left = qrRect.Max.X + margin
+			} else {
+				right = qrRect.Min.X - margin
+			}
+		}
+		r.drawText(img, face, image.Black, value, left, y, right-left)
+	}
+
+	return img, nil
+}
+
+// PNG renders the badge as PNG
+func (r *Renderer) PNG(b Badge) ([]byte, error) {
+	img, err := r.Render(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode badge: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// PDF renders the badge as a one-page PDF of the badge size
+func (r *Renderer) PDF(b Badge) ([]byte, error) {
+	img, err := r.Render(b)
+	if err != nil {
+		return nil, err
+	}
+	return EncodePDF(img, r.layout.WidthMM, r.layout.HeightMM)
+}
+
+func fieldText(field string, b Badge) string {
+	switch field {
+	case FieldName:
+		return b.Name
+	case FieldDepartment:
+		return labelled("ติดต่อ", b.Department)
+	case FieldHost:
+		return labelled("พบ", b.Host)
+	case FieldPurpose:
+		return labelled("เรื่อง", b.Purpose)
+	case FieldCheckIn:
+		return labelled("เข้า", b.CheckIn.Format("02/01/06 15:04"))
+	case FieldExpires:
+		// ส่วนใหญ่หมดอายุวันเดียวกับที่เข้า แสดงแค่เวลาให้อ่านง่าย
+		if sameDay(b.CheckIn, b.ExpiresAt) {
+			return labelled("หมดอายุ", b.ExpiresAt.Format("15:04"))
+		}
+		return labelled("หมดอายุ", b.ExpiresAt.Format("02/01/06 15:04"))
+	}
+	return ""
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
+
+func labelled(label, value string) string {
+	if value == "" {
+		return ""
+	}
+	return label + ": " + value
+}
+
+func (r *Renderer) face(size float64) (font.Face, error) {
+	face, err := opentype.NewFace(r.font, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     72, // size เป็น pixel อยู่แล้ว
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create font face: %w", err)
+	}
+	return face, nil
+}
+
+// drawText draws s with its baseline at y, cut with "…" to fit maxWidth
+func (r *Renderer) drawText(dst draw.Image, face font.Face, src image.Image, s string, x, y, maxWidth int) {
+	d := &font.Drawer{Dst: dst, Src: src, Face: face, Dot: fixed.P(x, y)}
+	d.DrawString(fit(d, s, maxWidth))
+}
+
+func fit(d *font.Drawer, s string, maxWidth int) string {
+	limit := fixed.I(maxWidth)
+	if d.MeasureString(s) <= limit {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		if candidate := string(runes) + "…"; d.MeasureString(candidate) <= limit {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// drawPhoto fills rect with the photo cropped to the rect's aspect ratio,
+// or a grey placeholder when there is no photo
+func drawPhoto(dst *image.RGBA, rect image.Rectangle, photo image.Image) {
+	if photo == nil {
+		draw.Draw(dst, rect, image.NewUniform(color.Gray{Y: 0xE5}), image.Point{}, draw.Src)
+		return
+	}
+
+	src := photo.Bounds()
+	crop := src
+	if src.Dx()*rect.Dy() > src.Dy()*rect.Dx() {
+		w := src.Dy() * rect.Dx() / rect.Dy()
+		crop.Min.X = src.Min.X + (src.Dx()-w)/2
+		crop.Max.X = crop.Min.X + w
+	} else {
+		h := src.Dx() * rect.Dy() / rect.Dx()
+		crop.Min.Y = src.Min.Y + (src.Dy()-h)/2
+		crop.Max.Y = crop.Min.Y + h
+	}
+	draw.CatmullRom.Scale(dst, rect, photo, crop, draw.Src, nil)
+}
@@ -0,0 +1,170 @@
+package badge
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func testBadge() Badge {
+	checkIn := time.Date(2025, 6, 2, 9, 30, 0, 0, time.UTC)
+	return Badge{
+		Name:       "สมชาย ใจดี",
+		Department: "ฝ่ายวิชาการ",
+		Host:       "ครูสมศรี",
+		CheckIn:    checkIn,
+		ExpiresAt:  checkIn.Add(4 * time.Hour),
+		QRContent:  "v1.payload.signature",
+	}
+}
+
+func TestRender(t *testing.T) {
+	for _, position := range []string{PositionLeft, PositionRight} {
+		t.Run(position, func(t *testing.T) {
+			layout := DefaultLayout()
+			layout.DPI = 150
+			layout.PhotoPosition = position
+			r, err := NewRenderer(layout)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			img, err := r.Render(testBadge())
+			if err != nil {
+				t.Fatal(err)
+			}
+			width, height := layout.pixels(86), layout.pixels(54)
+			if img.Bounds() != image.Rect(0, 0, width, height) {
+				t.Fatalf("badge size = %v, want %dx%d", img.Bounds(), width, height)
+			}
+			if got := img.RGBAAt(1, 1); got != (color.RGBA{R: 0x1E, G: 0x3A, B: 0x8A, A: 0xff}) {
+				t.Errorf("header color = %v", got)
+			}
+
+			// ไม่มีรูปถ่าย ฝั่งของรูปเป็นกรอบสีเทา QR อยู่มุมล่างฝั่งตรงข้าม
+			photoX, qrX := width/6, width-layout.pixels(3)-2
+			if position == PositionRight {
+				photoX, qrX = width-width/6, layout.pixels(3)+1
+			}
+			if got := img.RGBAAt(photoX, height/2); got != (color.RGBA{R: 0xE5, G: 0xE5, B: 0xE5, A: 0xff}) {
+				t.Errorf("photo placeholder at x=%d = %v", photoX, got)
+			}
+			if !hasDark(img, image.Rect(qrX-1, height*3/4, qrX+1, height-layout.pixels(3))) {
+				t.Errorf("no QR code near x=%d", qrX)
+			}
+		})
+	}
+}
+
+func hasDark(img *image.RGBA, rect image.Rectangle) bool {
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			if c := img.RGBAAt(x, y); c.R < 0x40 && c.G < 0x40 && c.B < 0x40 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestPNGAndPDF(t *testing.T) {
+	layout := DefaultLayout()
+	layout.DPI = 100
+	r, err := NewRenderer(layout)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := r.PNG(testBadge())
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil || img.Bounds().Dx() != layout.pixels(86) {
+		t.Errorf("PNG() = %v, %v", img.Bounds(), err)
+	}
+
+	data, err = r.PDF(testBadge())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// หน้า PDF มีขนาดเท่าบัตร (86 x 54 mm เป็น point)
+	mediaBox := fmt.Sprintf("/MediaBox [0 0 %.2f %.2f]", 86/25.4*72, 54/25.4*72)
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4")) || !bytes.Contains(data, []byte(mediaBox)) || !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Errorf("PDF() does not look like a one-page badge PDF")
+	}
+}
+
+func TestFontFile(t *testing.T) {
+	// ฟอนต์ Go ไม่มีอักษรไทย
+	path := filepath.Join(t.TempDir(), "Go-Regular.ttf")
+	if err := os.WriteFile(path, goregular.TTF, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	layout := DefaultLayout()
+	layout.FontFile = path
+	r, err := NewRenderer(layout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.SupportsThai() {
+		t.Error("SupportsThai() = true for the Go font")
+	}
+
+	// ฟอนต์ที่ฝังมาต้องมีอักษรไทย (build ที่ยังไม่ได้ go generate ใช้ฟอนต์ Go แทน)
+	r, err = NewRenderer(DefaultLayout())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fonts.ReadFile(defaultFontFile); err == nil && !r.SupportsThai() {
+		t.Errorf("bundled %s has no Thai glyphs", defaultFontFile)
+	}
+	if named := r.WithSchoolName("โรงเรียนวัดดุสิต"); named.Layout().SchoolName != "โรงเรียนวัดดุสิต" || r.Layout().SchoolName != "VISITOR" {
+		t.Error("WithSchoolName() changed the shared renderer")
+	}
+
+	layout.FontFile = filepath.Join(t.TempDir(), "missing.ttf")
+	if _, err := NewRenderer(layout); err == nil {
+		t.Error("NewRenderer() with a missing font should fail")
+	}
+	layout.FontFile = filepath.Join(t.TempDir(), "broken.ttf")
+	if err := os.WriteFile(layout.FontFile, []byte("not a font"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewRenderer(layout); err == nil {
+		t.Error("NewRenderer() with a broken font should fail")
+	}
+}
+
+func TestFieldText(t *testing.T) {
+	b := testBadge()
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{FieldName, "สมชาย ใจดี"},
+		{FieldDepartment, "ติดต่อ: ฝ่ายวิชาการ"},
+		{FieldHost, "พบ: ครูสมศรี"},
+		{FieldPurpose, ""}, // ไม่มีข้อมูลไม่พิมพ์บรรทัดนั้น
+		{FieldCheckIn, "เข้า: 02/06/25 09:30"},
+		{FieldExpires, "หมดอายุ: 13:30"},
+	}
+	for _, tt := range tests {
+		if got := fieldText(tt.field, b); got != tt.want {
+			t.Errorf("fieldText(%s) = %q, want %q", tt.field, got, tt.want)
+		}
+	}
+
+	b.ExpiresAt = b.CheckIn.Add(24 * time.Hour)
+	if got := fieldText(FieldExpires, b); got != "หมดอายุ: 03/06/25 09:30" {
+		t.Errorf("fieldText(expires) on another day = %q", got)
+	}
+}
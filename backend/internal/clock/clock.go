@@ -0,0 +1,107 @@
+// Package clock is the single place that knows the school timezone.
+//
+// ฐานข้อมูลเก็บเวลาเป็น UTC ทั้งหมด ส่วน "วันนี้" และการแสดงผลคิดตามเวลาของโรงเรียน
+// (ค่าเริ่มต้น Asia/Bangkok) จึงไม่ขึ้นกับ timezone ของเครื่องที่รัน server หรือ MySQL
+package clock
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultTimezone is used when no school timezone is configured
+const DefaultTimezone = "Asia/Bangkok"
+
+const dateLayout = "2006-01-02"
+
+// Clock gives the current time and converts between UTC and the school timezone
+type Clock struct {
+	loc *time.Location
+	now func() time.Time
+}
+
+// New creates a clock for the school timezone
+func New(loc *time.Location) *Clock {
+	return &Clock{loc: loc, now: time.Now}
+}
+
+// Load creates a clock from a timezone name such as "Asia/Bangkok"
+func Load(name string) (*Clock, error) {
+	if name == "" {
+		name = DefaultTimezone
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load timezone %q: %w", name, err)
+	}
+	return New(loc), nil
+}
+
+// WithNow returns a copy of the clock that reads the time from now (ใช้ในเทสต์)
+func (c *Clock) WithNow(now func() time.Time) *Clock {
+	return &Clock{loc: c.loc, now: now}
+}
+
+// Location returns the school timezone
+func (c *Clock) Location() *time.Location {
+	return c.loc
+}
+
+// Now returns the current instant in UTC
+func (c *Clock) Now() time.Time {
+	return c.now().UTC()
+}
+
+// In converts an instant to the school timezone for display
+func (c *Clock) In(t time.Time) time.Time {
+	return t.In(c.loc)
+}
+
+// Today returns the school's current date as YYYY-MM-DD
+func (c *Clock) Today() string {
+	return c.In(c.Now()).Format(dateLayout)
+}
+
+// TodayDate returns the school's current date as midnight UTC, the form DATE columns are read back in
+func (c *Clock) TodayDate() time.Time {
+	return c.DateOf(c.Now())
+}
+
+// DateOf returns the school-local date of an instant as midnight UTC, like TodayDate
+func (c *Clock) DateOf(t time.Time) time.Time {
+	date, _ := time.Parse(dateLayout, c.In(t).Format(dateLayout))
+	return date
+}
+
+// DayRange returns the UTC instants [start, end) covering the school-local date YYYY-MM-DD
+func (c *Clock) DayRange(date string) (start, end time.Time, err error) {
+	day, err := time.ParseInLocation(dateLayout, date, c.loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid date: %q", date)
+	}
+	return day.UTC(), day.AddDate(0, 0, 1).UTC(), nil
+}
+
+// StartOfDay returns the UTC instant at which the school-local date begins
+func (c *Clock) StartOfDay(date string) (time.Time, error) {
+	start, _, err := c.DayRange(date)
+	return start, err
+}
+
+// EndOfDay returns the UTC instant at which the school-local date ends (exclusive)
+func (c *Clock) EndOfDay(date string) (time.Time, error) {
+	_, end, err := c.DayRange(date)
+	return end, err
+}
+
+// Offset returns the school's UTC offset at t as "+07:00", the form MySQL CONVERT_TZ accepts
+// without timezone tables
+func (c *Clock) Offset(t time.Time) string {
+	_, seconds := t.In(c.loc).Zone()
+	sign := '+'
+	if seconds < 0 {
+		sign = '-'
+		seconds = -seconds
+	}
+	return fmt.Sprintf("%c%02d:%02d", sign, seconds/3600, seconds%3600/60)
+}
@@ -0,0 +1,145 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+// localZones คือ timezone ของเครื่องที่ใช้ทดสอบ ผลต้องเหมือนกันทุกค่า (เหมือนรันด้วย TZ ต่างกัน)
+var localZones = []string{"UTC", "Asia/Bangkok", "America/Los_Angeles", "Asia/Tokyo", "Pacific/Kiritimati"}
+
+// forEachLocal runs fn with time.Local set to each zone, like running the tests with different TZ values
+func forEachLocal(t *testing.T, fn func(t *testing.T)) {
+	for _, name := range localZones {
+		t.Run("TZ="+name, func(t *testing.T) {
+			loc, err := time.LoadLocation(name)
+			if err != nil {
+				t.Skipf("timezone %s not available: %v", name, err)
+			}
+			saved := time.Local
+			time.Local = loc
+			defer func() { time.Local = saved }()
+
+			fn(t)
+		})
+	}
+}
+
+func bangkok(t *testing.T, now time.Time) *Clock {
+	t.Helper()
+	c, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c.WithNow(func() time.Time { return now.In(time.Local) })
+}
+
+func TestTodayFollowsSchoolTimezone(t *testing.T) {
+	tests := []struct {
+		now   time.Time
+		today string
+	}{
+		// 00:30 น. เวลาไทยของวันที่ 2 แต่ยังเป็นวันที่ 1 ตาม UTC
+		{now: time.Date(2025, 6, 1, 17, 30, 0, 0, time.UTC), today: "2025-06-02"},
+		// 23:59 น. เวลาไทย ยังเป็นวันเดิม
+		{now: time.Date(2025, 6, 1, 16, 59, 0, 0, time.UTC), today: "2025-06-01"},
+		// ข้ามปี
+		{now: time.Date(2024, 12, 31, 18, 0, 0, 0, time.UTC), today: "2025-01-01"},
+	}
+
+	forEachLocal(t, func(t *testing.T) {
+		for _, tt := range tests {
+			c := bangkok(t, tt.now)
+			if got := c.Today(); got != tt.today {
+				t.Errorf("Today() at %s = %s, want %s", tt.now, got, tt.today)
+			}
+
+			want, _ := time.Parse(dateLayout, tt.today)
+			if got := c.TodayDate(); !got.Equal(want) || got.Location() != time.UTC {
+				t.Errorf("TodayDate() at %s = %s, want %s", tt.now, got, want)
+			}
+			if got := c.Now(); !got.Equal(tt.now) || got.Location() != time.UTC {
+				t.Errorf("Now() = %s, want %s in UTC", got, tt.now)
+			}
+		}
+	})
+}
+
+func TestDayRange(t *testing.T) {
+	forEachLocal(t, func(t *testing.T) {
+		c := bangkok(t, time.Now())
+
+		start, end, err := c.DayRange("2025-06-02")
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantStart := time.Date(2025, 6, 1, 17, 0, 0, 0, time.UTC)
+		wantEnd := time.Date(2025, 6, 2, 17, 0, 0, 0, time.UTC)
+		if !start.Equal(wantStart) || !end.Equal(wantEnd) {
+			t.Errorf("DayRange() = [%s, %s), want [%s, %s)", start, end, wantStart, wantEnd)
+		}
+		if start.Location() != time.UTC || end.Location() != time.UTC {
+			t.Errorf("DayRange() should return UTC, got %s and %s", start.Location(), end.Location())
+		}
+
+		if _, _, err := c.DayRange("02/06/2025"); err == nil {
+			t.Error("DayRange() with invalid date should fail")
+		}
+	})
+}
+
+func TestDayRangeAcrossDST(t *testing.T) {
+	forEachLocal(t, func(t *testing.T) {
+		loc, err := time.LoadLocation("America/New_York")
+		if err != nil {
+			t.Skip(err)
+		}
+		c := New(loc)
+
+		// วันที่เปลี่ยนเป็นเวลาฤดูร้อนมีแค่ 23 ชั่วโมง
+		start, end, err := c.DayRange("2025-03-09")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := end.Sub(start); got != 23*time.Hour {
+			t.Errorf("DayRange() length = %s, want 23h", got)
+		}
+	})
+}
+
+func TestInConvertsForDisplay(t *testing.T) {
+	forEachLocal(t, func(t *testing.T) {
+		c := bangkok(t, time.Now())
+
+		registeredAt := time.Date(2025, 6, 1, 2, 15, 0, 0, time.UTC)
+		if got := c.In(registeredAt).Format("02/01/2006 15:04"); got != "01/06/2025 09:15" {
+			t.Errorf("In() = %s, want 01/06/2025 09:15", got)
+		}
+	})
+}
+
+func TestLoadInvalidTimezone(t *testing.T) {
+	if _, err := Load("Asia/Nowhere"); err == nil {
+		t.Error("Load() with unknown timezone should fail")
+	}
+}
+
+func TestOffset(t *testing.T) {
+	forEachLocal(t, func(t *testing.T) {
+		at := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+		for name, want := range map[string]string{
+			"Asia/Bangkok":        "+07:00",
+			"Asia/Kolkata":        "+05:30",
+			"America/Los_Angeles": "-07:00", // เวลาฤดูร้อน
+			"UTC":                 "+00:00",
+		} {
+			c, err := Load(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := c.Offset(at); got != want {
+				t.Errorf("Offset() in %s = %s, want %s", name, got, want)
+			}
+		}
+	})
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
 
 	"github.com/joho/godotenv"
 )
@@ -10,6 +11,15 @@ import (
 type Config struct {
 	Server   ServerConfig
 	Database DatabaseConfig
+	Address  AddressConfig
+	Security SecurityConfig
+	Notify   NotifyConfig
+	Storage  StorageConfig
+	Badge    BadgeConfig
+	Date     DateConfig
+	Events   EventsConfig
+	Edge     EdgeConfig
+	Tenant   TenantConfig
 }
 
 type ServerConfig struct {
@@ -18,11 +28,66 @@ type ServerConfig struct {
 }
 
 type DatabaseConfig struct {
+	Driver   string // mysql, postgres หรือ sqlite (ไฟล์เดียว ไม่ต้องมี server เหมาะกับโรงเรียนเล็ก)
 	Host     string
 	Port     string
 	User     string
 	Password string
 	DBName   string
+	SSLMode  string // sslmode ของ postgres เช่น disable, require, verify-full
+	Path     string // ไฟล์ฐานข้อมูลของ sqlite
+	// AutoMigrate applies pending migrations when the server starts (ไม่งั้นใช้คำสั่ง migrate)
+	AutoMigrate bool
+}
+
+type AddressConfig struct {
+	DataFile string // ไฟล์ข้อมูลเขตการปกครอง (ว่าง = ใช้ข้อมูลที่ฝังมากับโปรแกรม)
+}
+
+type SecurityConfig struct {
+	TokenSecret string // ใช้เซ็น QR นัดหมายและบัตรผู้มาติดต่อ
+}
+
+type NotifyConfig struct {
+	WebhookURL string // ว่าง = เขียนแจ้งเตือนลง log อย่างเดียว
+}
+
+type StorageConfig struct {
+	Dir           string // โฟลเดอร์เก็บไฟล์ที่อัปโหลด
+	MaxPhotoBytes int
+}
+
+type BadgeConfig struct {
+	LayoutFile string // ไฟล์ JSON ขนาดและรูปแบบบัตรของโรงเรียน (ว่าง = ค่าเริ่มต้น)
+}
+
+type DateConfig struct {
+	Timezone   string // timezone ของโรงเรียนที่ไม่ได้ตั้งไว้ในตาราง schools ใช้คิด "วันนี้" และแสดงเวลา (ฐานข้อมูลเก็บ UTC)
+	Calendar   string // ce = ค.ศ., be = พ.ศ.
+	Month      string // numeric, short, long
+	ThaiDigits bool
+}
+
+type EventsConfig struct {
+	History         int // จำนวนเหตุการณ์ล่าสุดที่เก็บไว้ให้ client ที่ต่อใหม่
+	OverstayMinutes int // อยู่เกินกี่นาทีจึงแจ้งเตือน (0 = ไม่ตรวจ)
+}
+
+// EdgeConfig turns on edge mode: the server runs at a gate on its own database and
+// sends what it records to the central server when the network allows (ว่าง CentralURL = ไม่ใช้)
+type EdgeConfig struct {
+	CentralURL  string // เช่น https://visitor.school.ac.th
+	DeviceID    int    // id ของเครื่องนี้ที่ server กลาง (ลงทะเบียนเป็น device role edge)
+	DeviceKey   string // API key ของเครื่องนี้ที่ server กลาง
+	School      string // code ของโรงเรียนที่ server กลาง ส่งเป็น header X-School คู่กับ DeviceKey (ว่าง = ตาม subdomain ของ CentralURL)
+	SyncSeconds int
+}
+
+// TenantConfig decides which school of the district a request belongs to.
+// ลำดับคือ subdomain ใต้ BaseDomain, header X-School (ต้องมี key ของเครื่องในโรงเรียนนั้นหรือ AdminKey) แล้วจึงเป็นโรงเรียนแรก (deployment โรงเรียนเดียว)
+type TenantConfig struct {
+	BaseDomain string // เช่น visitor.district.go.th ทำให้ school-a.visitor.district.go.th เป็นโรงเรียน code school-a
+	AdminKey   string // ใช้เรียก /api/district/... ของเจ้าหน้าที่เขต (ว่าง = ปิด)
 }
 
 // Load loads configuration from environment variables
@@ -38,11 +103,53 @@ func Load() *Config {
 			Host: getEnv("SERVER_HOST", "localhost"),
 		},
 		Database: DatabaseConfig{
+			Driver:   getEnv("DB_DRIVER", "mysql"),
 			Host:     getEnv("DB_HOST", "localhost"),
 			Port:     getEnv("DB_PORT", "3306"),
 			User:     getEnv("DB_USER", "root"),
 			Password: getEnv("DB_PASSWORD", ""),
 			DBName:   getEnv("DB_NAME", "visitor_system"),
+			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+			Path:     getEnv("DB_PATH", "./data/visitor_system.db"),
+
+			AutoMigrate: getEnv("DB_AUTO_MIGRATE", "false") == "true",
+		},
+		Address: AddressConfig{
+			DataFile: getEnv("ADDRESS_DATA_FILE", ""),
+		},
+		Security: SecurityConfig{
+			TokenSecret: getEnv("TOKEN_SECRET", ""),
+		},
+		Notify: NotifyConfig{
+			WebhookURL: getEnv("NOTIFY_WEBHOOK_URL", ""),
+		},
+		Storage: StorageConfig{
+			Dir:           getEnv("STORAGE_DIR", "./uploads"),
+			MaxPhotoBytes: getEnvInt("PHOTO_MAX_BYTES", 5<<20),
+		},
+		Badge: BadgeConfig{
+			LayoutFile: getEnv("BADGE_LAYOUT_FILE", ""),
+		},
+		Date: DateConfig{
+			Timezone:   getEnv("TIMEZONE", "Asia/Bangkok"),
+			Calendar:   getEnv("DATE_CALENDAR", "ce"),
+			Month:      getEnv("DATE_MONTH", "numeric"),
+			ThaiDigits: getEnv("DATE_THAI_DIGITS", "false") == "true",
+		},
+		Events: EventsConfig{
+			History:         getEnvInt("EVENT_HISTORY", 500),
+			OverstayMinutes: getEnvInt("OVERSTAY_MINUTES", 240),
+		},
+		Edge: EdgeConfig{
+			CentralURL:  getEnv("EDGE_CENTRAL_URL", ""),
+			DeviceID:    getEnvInt("EDGE_DEVICE_ID", 0),
+			DeviceKey:   getEnv("EDGE_DEVICE_KEY", ""),
+			School:      getEnv("EDGE_SCHOOL", ""),
+			SyncSeconds: getEnvInt("EDGE_SYNC_SECONDS", 30),
+		},
+		Tenant: TenantConfig{
+			BaseDomain: getEnv("TENANT_BASE_DOMAIN", ""),
+			AdminKey:   getEnv("DISTRICT_ADMIN_KEY", ""),
 		},
 	}
 }
@@ -53,3 +160,16 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Invalid %s=%q, using %d", key, value, defaultValue)
+		return defaultValue
+	}
+	return n
+}
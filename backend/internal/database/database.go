@@ -4,9 +4,13 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"net/url"
+	"os"
+	"path/filepath"
 	"time"
 
 	_ "github.com/go-sql-driver/mysql"
+	_ "modernc.org/sqlite"
 )
 
 type Database struct {
@@ -14,16 +18,50 @@ type Database struct {
 }
 
 type Config struct {
+	Driver   string // "mysql" (ค่าเริ่มต้น), "postgres" หรือ "sqlite"
 	Host     string
 	Port     string
 	User     string
 	Password string
 	DBName   string
+	SSLMode  string // sslmode ของ postgres ว่าง = disable
+	Path     string // ไฟล์ฐานข้อมูลเมื่อใช้ sqlite
 }
 
 // NewDatabase creates a new database connection
 func NewDatabase(config Config) (*Database, error) {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+	var (
+		db  *sql.DB
+		err error
+	)
+	switch Dialect(config.Driver) {
+	case MySQL, "":
+		db, err = openMySQL(config)
+	case Postgres:
+		db, err = openPostgres(config)
+	case SQLite:
+		db, err = openSQLite(config.Path)
+	default:
+		return nil, fmt.Errorf("unknown database driver %q, use mysql, postgres or sqlite", config.Driver)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Test connection
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	log.Println("Database connected successfully")
+	return &Database{DB: db}, nil
+}
+
+func openMySQL(config Config) (*sql.DB, error) {
+	// เก็บและอ่านเวลาเป็น UTC เสมอ ไม่ขึ้นกับ timezone ของเครื่อง server หรือ MySQL
+	// (time_zone ของ session ทำให้ NOW() และ TIMESTAMP เป็น UTC ด้วย) การแปลงเป็นเวลาโรงเรียนทำที่ clock
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=UTC&time_zone=%%27%%2B00%%3A00%%27",
 		config.User,
 		config.Password,
 		config.Host,
@@ -41,13 +79,37 @@ func NewDatabase(config Config) (*Database, error) {
 	db.SetMaxIdleConns(5)
 	db.SetConnMaxLifetime(5 * time.Minute)
 
-	// Test connection
-	if err := db.Ping(); err != nil {
-		return nil, fmt.Errorf("failed to ping database: %w", err)
+	return db, nil
+}
+
+// openSQLite opens the database file, creating it and its folder on first use.
+// เวลาเขียนเป็นข้อความ UTC รูปแบบเดียวกันทุกแถว (_time_format=sqlite) จึงเทียบช่วงเวลาแบบข้อความได้
+// transaction จอง lock เขียนตั้งแต่ BEGIN (_txlock=immediate) แทน SELECT ... FOR UPDATE ของ MySQL
+func openSQLite(path string) (*sql.DB, error) {
+	if path == "" {
+		return nil, fmt.Errorf("sqlite needs a database file, set DB_PATH")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create database folder: %w", err)
 	}
 
-	log.Println("Database connected successfully")
-	return &Database{DB: db}, nil
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "busy_timeout(5000)")
+	params.Add("_pragma", "journal_mode(WAL)")
+	params.Set("_txlock", "immediate")
+	params.Set("_time_format", "sqlite")
+
+	db, err := sql.Open("sqlite", "file:"+path+"?"+params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// เขียนได้ทีละ connection อยู่แล้ว ที่เหลือรอด้วย busy_timeout
+	db.SetMaxOpenConns(8)
+	db.SetMaxIdleConns(8)
+
+	return db, nil
 }
 
 // Close closes the database connection
@@ -0,0 +1,157 @@
+package database
+
+import (
+	"database/sql"
+	"strings"
+
+	"modernc.org/sqlite"
+)
+
+// Dialect is the SQL flavour of a database. repository ใช้สร้างส่วนของ query ที่แต่ละฐานข้อมูลเขียนต่างกัน
+// ส่วนที่เหมือนกัน (CASE, COALESCE, CONCAT, LIMIT/OFFSET) เขียนตรงใน query ได้เลย
+// placeholder เป็น ? ทุกฐานข้อมูล (postgresDriver แปลงเป็น $n ให้)
+type Dialect string
+
+const (
+	MySQL    Dialect = "mysql"
+	Postgres Dialect = "postgres"
+	SQLite   Dialect = "sqlite"
+)
+
+// DialectOf returns the dialect of the driver behind db. driver อื่น (เช่นใน test) ถือเป็น MySQL
+func DialectOf(db *sql.DB) Dialect {
+	switch db.Driver().(type) {
+	case *sqlite.Driver:
+		return SQLite
+	case postgresDriver:
+		return Postgres
+	}
+	return MySQL
+}
+
+// MinutesBetween returns the whole minutes from one time expression to another, rounded down
+func (d Dialect) MinutesBetween(from, to string) string {
+	switch d {
+	case SQLite:
+		return `((unixepoch(` + to + `) - unixepoch(` + from + `)) / 60)`
+	case Postgres:
+		return `FLOOR(EXTRACT(EPOCH FROM (` + to + ` - ` + from + `)) / 60)`
+	}
+	return `TIMESTAMPDIFF(MINUTE, ` + from + `, ` + to + `)`
+}
+
+// LocalTime shifts a UTC time column to school time by the offset bound to its ? (as "+07:00")
+func (d Dialect) LocalTime(column string) string {
+	switch d {
+	case SQLite:
+		return `datetime(` + column + `, ?)`
+	case Postgres:
+		// AT TIME ZONE '+07:00' ของ PostgreSQL กลับเครื่องหมายตาม POSIX จึงบวก interval เอง
+		return `((` + column + ` AT TIME ZONE 'UTC') + CAST(? AS INTERVAL))`
+	}
+	return `CONVERT_TZ(` + column + `, '+00:00', ?)`
+}
+
+// Day formats a time expression as YYYY-MM-DD
+func (d Dialect) Day(expr string) string {
+	switch d {
+	case SQLite:
+		return `date(` + expr + `)`
+	case Postgres:
+		return `to_char(` + expr + `, 'YYYY-MM-DD')`
+	}
+	return `DATE_FORMAT(` + expr + `, '%Y-%m-%d')`
+}
+
+// WeekStart returns the Monday of the week of a time expression as YYYY-MM-DD.
+// MySQL ใช้ expr สองครั้ง ผู้เรียกต้องนับ ? ใน SQL ที่ได้
+func (d Dialect) WeekStart(expr string) string {
+	switch d {
+	case SQLite:
+		return `date(` + expr + `, '-6 days', 'weekday 1')`
+	case Postgres:
+		return `to_char(date_trunc('week', ` + expr + `), 'YYYY-MM-DD')`
+	}
+	return `DATE_FORMAT(DATE_SUB(DATE(` + expr + `), INTERVAL WEEKDAY(` + expr + `) DAY), '%Y-%m-%d')`
+}
+
+// MonthStart returns the first day of the month of a time expression as YYYY-MM-DD
+func (d Dialect) MonthStart(expr string) string {
+	switch d {
+	case SQLite:
+		return `strftime('%Y-%m-01', ` + expr + `)`
+	case Postgres:
+		return `to_char(` + expr + `, 'YYYY-MM-01')`
+	}
+	return `DATE_FORMAT(` + expr + `, '%Y-%m-01')`
+}
+
+// Hour returns the hour (0-23) of a time expression
+func (d Dialect) Hour(expr string) string {
+	switch d {
+	case SQLite:
+		return `CAST(strftime('%H', ` + expr + `) AS INTEGER)`
+	case Postgres:
+		return `CAST(EXTRACT(HOUR FROM ` + expr + `) AS INTEGER)`
+	}
+	return `HOUR(` + expr + `)`
+}
+
+// Like matches column against the LIKE pattern bound to its ?, ignoring case like utf8mb4_unicode_ci.
+// pattern ใช้ \ เป็นตัว escape (search.Contains) ซึ่ง SQLite ต้องระบุเอง
+func (d Dialect) Like(column string) string {
+	switch d {
+	case SQLite:
+		return column + ` LIKE ? ESCAPE '\'`
+	case Postgres:
+		return column + ` ILIKE ?`
+	}
+	return column + ` LIKE ?`
+}
+
+// Excluded refers to the value an upsert tried to insert into column
+func (d Dialect) Excluded(column string) string {
+	if d == MySQL {
+		return `VALUES(` + column + `)`
+	}
+	return `excluded.` + column
+}
+
+// Upsert returns the clause that turns an INSERT into an update of the row with the same keys.
+// MySQL ใช้ unique key ใดก็ได้ที่ชน ส่วน SQLite/PostgreSQL ต้องระบุ keys ให้ตรงกับ unique index
+// คอลัมน์ของแถวเดิมใน assignments ให้ขึ้นต้นด้วยชื่อตาราง (PostgreSQL ถือว่าชื่อเปล่ากำกวม)
+func (d Dialect) Upsert(keys []string, assignments ...string) string {
+	if d == MySQL {
+		return ` ON DUPLICATE KEY UPDATE ` + strings.Join(assignments, `, `)
+	}
+	return ` ON CONFLICT (` + strings.Join(keys, `, `) + `) DO UPDATE SET ` + strings.Join(assignments, `, `)
+}
+
+// UpsertKeepingID is Upsert for tables with an id column, so that the id of an updated row
+// can still be read back. MySQL คืน id ของแถวเดิมทาง LastInsertId ด้วย LAST_INSERT_ID(id)
+// ฐานข้อมูลอื่นได้จาก RETURNING อยู่แล้ว
+func (d Dialect) UpsertKeepingID(keys []string, assignments ...string) string {
+	if d == MySQL {
+		assignments = append([]string{`id = LAST_INSERT_ID(id)`}, assignments...)
+	}
+	return d.Upsert(keys, assignments...)
+}
+
+// Returning reports whether INSERT ... RETURNING id gives the new id, instead of LastInsertId
+// (PostgreSQL ไม่มี LastInsertId)
+func (d Dialect) Returning() bool {
+	return d != MySQL
+}
+
+// ForUpdate locks the rows read inside a transaction. SQLite ล็อกทั้งฐานข้อมูลตั้งแต่ BEGIN อยู่แล้ว
+func (d Dialect) ForUpdate() string {
+	if d == SQLite {
+		return ``
+	}
+	return ` FOR UPDATE`
+}
+
+// FullText reports whether the database has the ngram FULLTEXT indexes used by search
+func (d Dialect) FullText() bool {
+	return d == MySQL
+}
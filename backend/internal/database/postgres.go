@@ -0,0 +1,179 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// postgresDriver is pgx with ? placeholders. repository เขียน query ด้วย ? แบบ MySQL/SQLite ทั้งหมด
+// connection ของ driver นี้แปลงเป็น $1, $2, ... ให้ก่อนส่งไป PostgreSQL
+type postgresDriver struct{}
+
+func (postgresDriver) Open(name string) (driver.Conn, error) {
+	conn, err := stdlib.GetDefaultDriver().Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &postgresConn{Conn: conn.(*stdlib.Conn)}, nil
+}
+
+// postgresConn rebinds every query; everything else (transactions, ping, reset) is pgx's own
+type postgresConn struct {
+	*stdlib.Conn
+}
+
+func (c *postgresConn) Prepare(query string) (driver.Stmt, error) {
+	return c.Conn.Prepare(rebind(query))
+}
+
+func (c *postgresConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return c.Conn.PrepareContext(ctx, rebind(query))
+}
+
+func (c *postgresConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.Conn.ExecContext(ctx, rebind(query), args)
+}
+
+func (c *postgresConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.Conn.QueryContext(ctx, rebind(query), args)
+}
+
+// rebind replaces each ? outside of strings, quoted identifiers, dollar-quoted bodies and comments with $n
+func rebind(query string) string {
+	if !strings.Contains(query, "?") {
+		return query
+	}
+
+	var b strings.Builder
+	b.Grow(len(query) + 16)
+	n := 0
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(query[i+1:], c)
+			if end < 0 {
+				b.WriteString(query[i:])
+				return b.String()
+			}
+			// '' ภายในสตริงคือ ' หนึ่งตัว วนต่อเป็นสตริงใหม่ติดกันได้ผลเหมือนกัน
+			b.WriteString(query[i : i+end+2])
+			i += end + 1
+
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query) - i
+			}
+			b.WriteString(query[i : i+end])
+			i += end - 1
+
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				b.WriteString(query[i:])
+				return b.String()
+			}
+			b.WriteString(query[i : i+end+4])
+			i += end + 3
+
+		case c == '$':
+			tag, ok := dollarTag(query, i)
+			if !ok {
+				b.WriteByte(c)
+				continue
+			}
+			end := strings.Index(query[i+len(tag):], tag)
+			if end < 0 {
+				b.WriteString(query[i:])
+				return b.String()
+			}
+			end += i + 2*len(tag)
+			b.WriteString(query[i:end])
+			i = end - 1
+
+		case c == '?':
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// dollarTag returns the $tag$ opening a dollar-quoted string at query[start], such as $$ or $body$.
+// $1 (placeholder ที่เขียนมาแล้ว) และ $ ที่อยู่ท้ายชื่อไม่ใช่
+func dollarTag(query string, start int) (string, bool) {
+	if start > 0 && isIdentByte(query[start-1]) {
+		return "", false
+	}
+	for i := start + 1; i < len(query); i++ {
+		c := query[i]
+		if c == '$' {
+			return query[start : i+1], true
+		}
+		if !isIdentByte(c) || (i == start+1 && c >= '0' && c <= '9') {
+			return "", false
+		}
+	}
+	return "", false
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+// openPostgres connects with the session time zone set to UTC, like MySQL's time_zone
+func openPostgres(config Config) (*sql.DB, error) {
+	sslMode := config.SSLMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(config.User, config.Password),
+		Host:     net.JoinHostPort(config.Host, config.Port),
+		Path:     "/" + config.DBName,
+		RawQuery: url.Values{"sslmode": {sslMode}, "timezone": {"UTC"}}.Encode(),
+	}
+
+	connector, err := stdlib.GetDefaultDriver().(driver.DriverContext).OpenConnector(dsn.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	db := sql.OpenDB(postgresConnector{connector})
+
+	db.SetMaxOpenConns(25)
+	db.SetMaxIdleConns(5)
+	db.SetConnMaxLifetime(5 * time.Minute)
+
+	return db, nil
+}
+
+type postgresConnector struct {
+	driver.Connector
+}
+
+func (c postgresConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &postgresConn{Conn: conn.(*stdlib.Conn)}, nil
+}
+
+func (postgresConnector) Driver() driver.Driver {
+	return postgresDriver{}
+}
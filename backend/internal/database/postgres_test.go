@@ -0,0 +1,25 @@
+package database
+
+import "testing"
+
+func TestRebind(t *testing.T) {
+	tests := []struct {
+		query, want string
+	}{
+		{`SELECT 1`, `SELECT 1`},
+		{`SELECT * FROM visitors WHERE id = ? AND rfid = ?`, `SELECT * FROM visitors WHERE id = $1 AND rfid = $2`},
+		// ? ในสตริง ชื่อในเครื่องหมายคำพูด และ comment ไม่ใช่ placeholder
+		{`UPDATE t SET note = 'ใช่ไหม?', "odd?" = ? WHERE a = ?`, `UPDATE t SET note = 'ใช่ไหม?', "odd?" = $1 WHERE a = $2`},
+		{`SELECT 'it''s ?' , ?`, `SELECT 'it''s ?' , $1`},
+		{"SELECT ? -- why?\nFROM t /* or? */ WHERE b = ?", "SELECT $1 -- why?\nFROM t /* or? */ WHERE b = $2"},
+		{`SELECT $$ ? $$, $body$ ? $body$, ?`, `SELECT $$ ? $$, $body$ ? $body$, $1`},
+		{`SELECT price$ FROM t WHERE a = ?`, `SELECT price$ FROM t WHERE a = $1`},
+		{`SELECT CAST(? AS INTERVAL) + ?`, `SELECT CAST($1 AS INTERVAL) + $2`},
+	}
+
+	for _, tt := range tests {
+		if got := rebind(tt.query); got != tt.want {
+			t.Errorf("rebind(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+// Package edge sends the records made at a gate running in edge mode to the central server.
+//
+// เครื่อง edge คือ backend ตัวเดียวกันที่รันที่ประตูบนฐานข้อมูลของตัวเอง (ปกติเป็น SQLite)
+// ลงทะเบียนและคืนบัตรได้ตามปกติแม้เน็ตไปยัง server กลางหลุด ทุกรายการเข้าคิว sync_queue
+// พร้อม client_id (UUID) แล้ว Syncer ส่งขึ้นไปที่ POST /api/devices/{id}/sync ตามลำดับเป็นระยะ
+// รายการที่ server กลางไม่รับ (เช่นเลขบัตรเดียวกันลงทะเบียนที่อีกประตู) ค้างไว้เป็น conflict ให้เจ้าหน้าที่ตรวจ
+package edge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/tenant"
+)
+
+// batchSize is the number of queued records sent per request
+const batchSize = 100
+
+// Syncer sends queued records to the central server
+type Syncer struct {
+	queue     *repository.SyncQueueRepository
+	client    *http.Client
+	url       string
+	deviceKey string
+	school    string
+}
+
+// NewSyncer sends to centralURL as the edge device deviceID (สร้างที่ server กลางด้วย role edge)
+func NewSyncer(queue *repository.SyncQueueRepository, centralURL string, deviceID int, deviceKey string) *Syncer {
+	return &Syncer{
+		queue:     queue,
+		client:    &http.Client{Timeout: 30 * time.Second},
+		url:       strings.TrimRight(centralURL, "/") + "/api/devices/" + strconv.Itoa(deviceID) + "/sync",
+		deviceKey: deviceKey,
+	}
+}
+
+// WithSchool sends the school code in the X-School header, for a central server that
+// serves several schools on one host name (ว่าง = ไม่ส่ง)
+func (s *Syncer) WithSchool(code string) *Syncer {
+	s.school = code
+	return s
+}
+
+// Run syncs every interval until ctx is cancelled. เน็ตหลุดก็แค่ลองใหม่รอบหน้า
+func (s *Syncer) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := s.Sync(ctx); err != nil {
+			log.Printf("Failed to sync with the central server (%d records sent): %v", n, err)
+		} else if n > 0 {
+			log.Printf("Synced %d records with the central server", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync sends batches until the queue is empty and returns how many entries were settled
+// (synced or conflict). หยุดที่ batch แรกที่มีรายการบันทึกไม่สำเร็จ เพื่อให้ส่งตามลำดับเดิมรอบหน้า
+func (s *Syncer) Sync(ctx context.Context) (int, error) {
+	settled := 0
+	for {
+		entries, err := s.queue.ListPending(batchSize)
+		if err != nil {
+			return settled, err
+		}
+		if len(entries) == 0 {
+			return settled, nil
+		}
+
+		n, err := s.syncBatch(ctx, entries)
+		settled += n
+		if err != nil {
+			return settled, err
+		}
+	}
+}
+
+// syncBatch sends one batch and records the result of each entry
+func (s *Syncer) syncBatch(ctx context.Context, entries []models.SyncQueueEntry) (int, error) {
+	settled := 0
+	sent := make([]models.SyncQueueEntry, 0, len(entries))
+	req := models.SyncRequest{Items: make([]models.SyncItem, 0, len(entries))}
+	for _, entry := range entries {
+		item, err := s.queue.Item(entry)
+		if err != nil {
+			// แถวต้นทางหายไปแล้ว ส่งไม่ได้อีก
+			if err := s.queue.MarkConflict(entry.ID, err.Error()); err != nil {
+				return settled, err
+			}
+			settled++
+			continue
+		}
+		sent = append(sent, entry)
+		req.Items = append(req.Items, *item)
+	}
+	if len(sent) == 0 {
+		return settled, nil
+	}
+
+	resp, err := s.post(ctx, req)
+	if err != nil {
+		return settled, err
+	}
+	if len(resp.Results) != len(sent) {
+		return settled, fmt.Errorf("central server answered %d results for %d items", len(resp.Results), len(sent))
+	}
+
+	failed := 0
+	for i, result := range resp.Results {
+		entry := sent[i]
+		switch result.Status {
+		case models.SyncApplied, models.SyncDuplicate:
+			err = s.queue.MarkSynced(entry.ID)
+			settled++
+		case models.SyncConflict:
+			err = s.queue.MarkConflict(entry.ID, result.Message)
+			settled++
+		default:
+			err = s.queue.MarkFailed(entry.ID, result.Message)
+			failed++
+		}
+		if err != nil {
+			return settled, err
+		}
+	}
+	if failed > 0 {
+		return settled, fmt.Errorf("central server could not record %d items", failed)
+	}
+	return settled, nil
+}
+
+func (s *Syncer) post(ctx context.Context, body models.SyncRequest) (*models.SyncResponse, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode sync request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sync request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Device-Key", s.deviceKey)
+	if s.school != "" {
+		req.Header.Set(tenant.Header, s.school)
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach central server: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return nil, fmt.Errorf("central server returned %s: %s", res.Status, strings.TrimSpace(string(msg)))
+	}
+
+	var resp models.SyncResponse
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to decode sync response: %w", err)
+	}
+	return &resp, nil
+}
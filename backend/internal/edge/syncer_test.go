@@ -0,0 +1,139 @@
+package edge
+
+import (
+	"context"
+	"database/sql"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"backend/internal/clock"
+	"backend/internal/database"
+	"backend/internal/events"
+	"backend/internal/handlers"
+	"backend/internal/migrate"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/token"
+	"backend/migrations"
+
+	"github.com/gorilla/mux"
+)
+
+// openSQLite returns a migrated SQLite database (เครื่อง edge และ server กลางแยกไฟล์กัน)
+func openSQLite(t *testing.T, name string) *sql.DB {
+	t.Helper()
+	db, err := database.NewDatabase(database.Config{Driver: "sqlite", Path: filepath.Join(t.TempDir(), name+".db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	fsys, err := migrations.For("sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner, err := migrate.NewRunner(db.DB, fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runner.Up(context.Background(), 0); err != nil {
+		t.Fatal(err)
+	}
+	return db.DB
+}
+
+func TestSyncer(t *testing.T) {
+	bangkok, err := clock.Load("Asia/Bangkok")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 6, 2, 3, 0, 0, 0, time.UTC)
+	clk := bangkok.WithNow(func() time.Time { return now })
+
+	// server กลาง กับเครื่อง edge ที่ลงทะเบียนไว้
+	centralDB := openSQLite(t, "central")
+	devices := repository.NewDeviceRepository(centralDB, clk)
+	key, hash, err := token.NewAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	device := &models.Device{Name: "ประตูหลัง", Role: models.DeviceRoleEdge, Active: true, KeyHash: hash}
+	if err := devices.Create(device); err != nil {
+		t.Fatal(err)
+	}
+	central := repository.NewVisitorRepository(centralDB, clk)
+	router := mux.NewRouter()
+	handlers.Routes{
+		Sync: handlers.NewSyncHandler(devices, central, central, repository.NewDepartmentRepository(centralDB), repository.NewWatchlistRepository(centralDB), nil, clk, events.New(10)),
+	}.Register(router.PathPrefix("/api").Subrouter())
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	// เครื่อง edge ลงทะเบียนสองคนและคืนบัตรหนึ่งใบระหว่างเน็ตหลุด
+	edgeDB := openSQLite(t, "edge")
+	gate := repository.NewVisitorRepository(edgeDB, clk).QueueForSync()
+	queue := repository.NewSyncQueueRepository(edgeDB, clk)
+	visitors := []*models.Visitor{
+		{ClientID: "3e4f5a6b-7c8d-4e9f-8a0b-1c2d3e4f5a01", IDCard: "1101234500001", FirstName: "สมชาย", LastName: "ใจดี", Phone: "0812345678", RFID: "RF0001", ApprovalStatus: models.ApprovalNotRequired},
+		{ClientID: "3e4f5a6b-7c8d-4e9f-8a0b-1c2d3e4f5a02", IDCard: "1101234500002", FirstName: "สมหญิง", LastName: "ใจดี", Phone: "0812345679", RFID: "RF0002", ApprovalStatus: models.ApprovalNotRequired},
+	}
+	for _, v := range visitors {
+		if err := gate.Create(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = gate.CreateReturnLog(&models.ReturnCardLog{
+		ClientID: "3e4f5a6b-7c8d-4e9f-8a0b-1c2d3e4f5a03", VisitorID: visitors[0].ID, CardID: "RF0001",
+		Name: "สมชาย ใจดี", CheckIn: "10:00", CheckOut: "10:00", ReturnDate: clk.TodayDate(), Status: "การคืนบัตรสำเร็จ",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// คนที่สองลงทะเบียนที่ server กลางไปแล้ว (อีกประตูหนึ่ง)
+	if err := central.Create(&models.Visitor{IDCard: "1101234500002", FirstName: "สมหญิง", LastName: "ใจดี", Phone: "0812345679", ApprovalStatus: models.ApprovalNotRequired}); err != nil {
+		t.Fatal(err)
+	}
+
+	// server กลางยังติดต่อไม่ได้
+	offline := NewSyncer(queue, "http://127.0.0.1:1", device.ID, key)
+	if _, err := offline.Sync(context.Background()); err == nil {
+		t.Error("Sync() to an unreachable server succeeded")
+	}
+	if pending, _ := queue.ListPending(10); len(pending) != 3 {
+		t.Fatalf("pending after a failed sync = %+v", pending)
+	}
+
+	if n, err := NewSyncer(queue, server.URL, device.ID, "wrong-key").Sync(context.Background()); err == nil || n != 0 {
+		t.Errorf("Sync() with a wrong key = %d, %v", n, err)
+	}
+
+	syncer := NewSyncer(queue, server.URL+"/", device.ID, key)
+	n, err := syncer.Sync(context.Background())
+	if err != nil || n != 3 {
+		t.Fatalf("Sync() = %d, %v", n, err)
+	}
+
+	counts, err := queue.CountByStatus()
+	if err != nil || counts[models.SyncPending] != 0 || counts[models.SyncSynced] != 2 || counts[models.SyncConflict] != 1 {
+		t.Errorf("CountByStatus() = %v, %v", counts, err)
+	}
+	conflicts, _ := queue.List(models.SyncConflict, 10)
+	if len(conflicts) != 1 || conflicts[0].ClientID != visitors[1].ClientID || conflicts[0].Message == "" {
+		t.Errorf("conflicts = %+v", conflicts)
+	}
+
+	synced, err := central.GetByClientID(visitors[0].ClientID)
+	if err != nil || synced.ExitTime == nil || !synced.ExitTime.Equal(now) {
+		t.Errorf("central visitor = %+v, %v", synced, err)
+	}
+	if d, _ := devices.GetByID(device.ID); d.LastSeenAt == nil {
+		t.Error("edge device was not marked as seen")
+	}
+
+	if n, err := syncer.Sync(context.Background()); err != nil || n != 0 {
+		t.Errorf("Sync() with nothing queued = %d, %v", n, err)
+	}
+}
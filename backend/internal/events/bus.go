@@ -0,0 +1,178 @@
+// Package events is an in-process event bus for live dashboards.
+//
+// handler publish เหตุการณ์ (ลงทะเบียน คืนบัตร อยู่เกินเวลา ...) แล้วป้อมยามแต่ละจุดรับผ่าน SSE
+// bus เก็บเหตุการณ์ล่าสุดไว้จำนวนหนึ่ง ให้ client ที่หลุดแล้วต่อใหม่ขอรับต่อจาก event ID สุดท้ายได้
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Event types
+const (
+	TypeVisitorRegistered = "visitor.registered"
+	TypeVisitorUpdated    = "visitor.updated"
+	TypeVisitorDeleted    = "visitor.deleted"
+	TypeCardReturned      = "card.returned"
+	TypeReturnVoided      = "card.return_voided"
+	TypeOverstay          = "visitor.overstay"
+	TypePickupRefused     = "pickup.refused"
+	TypeWatchlistHit      = "visitor.watchlist_hit"
+)
+
+// DefaultHistory is how many recent events are kept for resuming clients
+const DefaultHistory = 500
+
+// subscriberBuffer: client ที่อ่านไม่ทันจนเต็มจะถูกตัด แล้วต่อใหม่ด้วย Last-Event-ID
+const subscriberBuffer = 64
+
+// Event is one published event
+type Event struct {
+	ID         int64       `json:"id"`
+	Type       string      `json:"type"`
+	Department string      `json:"department,omitempty"` // ว่าง = เหตุการณ์ของทั้งโรงเรียน
+	Time       time.Time   `json:"time"`
+	Data       interface{} `json:"data"`
+}
+
+// Filter selects the events a subscriber receives; empty fields match everything
+type Filter struct {
+	Departments []string
+	Types       []string
+}
+
+// Match reports whether the event passes the filter.
+// เหตุการณ์ที่ไม่มีส่วนงานส่งให้ทุกจุด เพราะเป็นเรื่องที่ทุกป้อมควรเห็น
+func (f Filter) Match(e Event) bool {
+	if len(f.Types) > 0 && !contains(f.Types, e.Type) {
+		return false
+	}
+	if len(f.Departments) > 0 && e.Department != "" && !contains(f.Departments, e.Department) {
+		return false
+	}
+	return true
+}
+
+// Subscription receives events until it is closed.
+// C ถูกปิดเมื่อ Close หรือเมื่อ client อ่านไม่ทัน
+type Subscription struct {
+	C      <-chan Event
+	c      chan Event
+	filter Filter
+	bus    *Bus
+	once   sync.Once
+}
+
+// Close stops the subscription
+func (s *Subscription) Close() {
+	s.bus.remove(s)
+}
+
+// Bus fans events out to subscribers and keeps a short history
+type Bus struct {
+	mu      sync.Mutex
+	nextID  int64
+	history []Event
+	size    int
+	subs    map[*Subscription]struct{}
+	now     func() time.Time
+}
+
+// New creates a bus that keeps the last history events.
+// ID เริ่มจากเวลาที่เปิดโปรแกรม (มิลลิวินาที x 1000) จึงเพิ่มขึ้นเสมอแม้ restart
+// client ที่ส่ง ID ก่อน restart จะได้รับทุกเหตุการณ์ที่ยังเก็บไว้ พร้อมแจ้งว่าอาจไม่ครบ
+func New(history int) *Bus {
+	if history <= 0 {
+		history = DefaultHistory
+	}
+	return &Bus{
+		nextID: time.Now().UnixMilli() * 1000,
+		size:   history,
+		subs:   make(map[*Subscription]struct{}),
+		now:    time.Now,
+	}
+}
+
+// Publish sends an event to every matching subscriber
+func (b *Bus) Publish(eventType, department string, data interface{}) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	e := Event{ID: b.nextID, Type: eventType, Department: department, Time: b.now().UTC(), Data: data}
+
+	b.history = append(b.history, e)
+	if len(b.history) > b.size {
+		b.history = b.history[len(b.history)-b.size:]
+	}
+
+	for s := range b.subs {
+		if !s.filter.Match(e) {
+			continue
+		}
+		select {
+		case s.c <- e:
+		default:
+			b.drop(s)
+		}
+	}
+
+	return e
+}
+
+// Subscribe starts receiving events that match the filter.
+// lastID > 0 คือ ID สุดท้ายที่ client ได้รับ จะได้เหตุการณ์ที่พลาดไปคืนมาใน replay
+// complete = false แปลว่าเหตุการณ์บางส่วนหลุดจาก history ไปแล้ว client ควรโหลดข้อมูลใหม่ทั้งหมด
+func (b *Bus) Subscribe(filter Filter, lastID int64) (sub *Subscription, replay []Event, complete bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := make(chan Event, subscriberBuffer)
+	sub = &Subscription{C: c, c: c, filter: filter, bus: b}
+	b.subs[sub] = struct{}{}
+
+	complete = true
+	if lastID <= 0 {
+		return sub, nil, complete
+	}
+
+	// เหตุการณ์ถัดไปที่ client ต้องการต้องยังอยู่ใน history (หรือยังไม่เกิด)
+	// ID จากก่อน restart จะน้อยกว่าทุก ID ของรอบนี้ จึงถือว่าไม่ครบเช่นกัน
+	complete = lastID+1 >= b.oldestID()
+	for _, e := range b.history {
+		if e.ID > lastID && filter.Match(e) {
+			replay = append(replay, e)
+		}
+	}
+	return sub, replay, complete
+}
+
+// oldestID is the ID of the oldest event that can still be replayed
+func (b *Bus) oldestID() int64 {
+	if len(b.history) == 0 {
+		return b.nextID + 1
+	}
+	return b.history[0].ID
+}
+
+func (b *Bus) remove(s *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.drop(s)
+}
+
+// drop must be called with b.mu held
+func (b *Bus) drop(s *Subscription) {
+	delete(b.subs, s)
+	s.once.Do(func() { close(s.c) })
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package events
+
+import (
+	"testing"
+)
+
+func TestSubscribeFilters(t *testing.T) {
+	bus := New(10)
+	sub, _, _ := bus.Subscribe(Filter{Departments: []string{"ธุรการ"}, Types: []string{TypeVisitorRegistered, TypePickupRefused}}, 0)
+	defer sub.Close()
+
+	bus.Publish(TypeVisitorRegistered, "ธุรการ", nil)  // ส่ง
+	bus.Publish(TypeVisitorRegistered, "วิชาการ", nil) // ต่างส่วนงาน
+	bus.Publish(TypeCardReturned, "ธุรการ", nil)       // ไม่ได้ขอ type นี้
+	bus.Publish(TypePickupRefused, "", nil)            // ของทั้งโรงเรียน ส่งทุกจุด
+
+	var got []string
+	for len(sub.C) > 0 {
+		e := <-sub.C
+		got = append(got, e.Type+"/"+e.Department)
+	}
+	want := []string{TypeVisitorRegistered + "/ธุรการ", TypePickupRefused + "/"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("received %q, want %q", got, want)
+	}
+}
+
+func TestResumeFromLastEventID(t *testing.T) {
+	bus := New(3)
+	var ids []int64
+	for i := 0; i < 5; i++ {
+		ids = append(ids, bus.Publish(TypeVisitorRegistered, "", i).ID)
+	}
+
+	// ยังอยู่ใน history (เก็บ 3 ตัวล่าสุด: ids[2..4])
+	sub, replay, complete := bus.Subscribe(Filter{}, ids[2])
+	sub.Close()
+	if !complete || len(replay) != 2 || replay[0].ID != ids[3] || replay[1].ID != ids[4] {
+		t.Errorf("resume from %d = %v (complete %v), want ids %v", ids[2], replay, complete, ids[3:])
+	}
+
+	// ids[1] ตรงกับก่อน history ตัวแรกพอดี ยังครบ
+	sub, replay, complete = bus.Subscribe(Filter{}, ids[1])
+	sub.Close()
+	if !complete || len(replay) != 3 {
+		t.Errorf("resume from %d: complete %v, %d events, want complete with 3", ids[1], complete, len(replay))
+	}
+
+	// ids[0] ตามไม่ทันแล้ว ต้องบอกให้โหลดใหม่
+	sub, replay, complete = bus.Subscribe(Filter{}, ids[0])
+	sub.Close()
+	if complete || len(replay) != 3 {
+		t.Errorf("resume from %d: complete %v, %d events, want incomplete with 3", ids[0], complete, len(replay))
+	}
+
+	// ล่าสุดแล้ว ไม่มีอะไรต้องส่ง
+	sub, replay, complete = bus.Subscribe(Filter{}, ids[4])
+	sub.Close()
+	if !complete || len(replay) != 0 {
+		t.Errorf("resume from latest: complete %v, %d events", complete, len(replay))
+	}
+}
+
+func TestResumeAfterRestart(t *testing.T) {
+	before := New(10)
+	lastID := before.Publish(TypeVisitorRegistered, "", nil).ID
+
+	after := New(10)
+	after.nextID = lastID + 1000 // รอบใหม่เริ่มที่ ID มากกว่าเสมอ
+
+	sub, replay, complete := after.Subscribe(Filter{}, lastID)
+	sub.Close()
+	if complete || len(replay) != 0 {
+		t.Errorf("resume after restart: complete %v, %d events, want incomplete", complete, len(replay))
+	}
+
+	e := after.Publish(TypeCardReturned, "", nil)
+	sub, replay, complete = after.Subscribe(Filter{}, lastID)
+	sub.Close()
+	if complete || len(replay) != 1 || replay[0].ID != e.ID {
+		t.Errorf("resume after restart: complete %v, %v, want incomplete with %d", complete, replay, e.ID)
+	}
+}
+
+func TestSlowSubscriberIsDropped(t *testing.T) {
+	bus := New(10)
+	sub, _, _ := bus.Subscribe(Filter{}, 0)
+
+	for i := 0; i < subscriberBuffer+1; i++ {
+		bus.Publish(TypeVisitorRegistered, "", i)
+	}
+
+	n := 0
+	for range sub.C {
+		n++
+	}
+	if n != subscriberBuffer {
+		t.Errorf("received %d events before the channel closed, want %d", n, subscriberBuffer)
+	}
+	sub.Close() // ปิดซ้ำต้องไม่ panic
+}
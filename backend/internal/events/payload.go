@@ -0,0 +1,52 @@
+package events
+
+import (
+	"time"
+
+	"backend/internal/models"
+)
+
+// Visitor is the visitor summary sent with events.
+// ไม่ส่งเลขบัตรประชาชน รูป หรือที่อยู่ ไปยังจอของป้อม
+type Visitor struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	Department   string `json:"department"`
+	HostName     string `json:"hostName"`
+	RFID         string `json:"rfid"`
+	LicensePlate string `json:"licensePlate"`
+}
+
+// VisitorOf summarises a visitor for an event
+func VisitorOf(v *models.Visitor) Visitor {
+	return Visitor{
+		ID:           v.ID,
+		Name:         v.FirstName + " " + v.LastName,
+		Department:   v.Department,
+		HostName:     v.HostName,
+		RFID:         v.RFID,
+		LicensePlate: v.LicensePlate,
+	}
+}
+
+// CardReturned is the data of a card.returned event
+type CardReturned struct {
+	Visitor  Visitor `json:"visitor"`
+	CheckIn  string  `json:"checkIn"`
+	CheckOut string  `json:"checkOut"`
+	DeviceID *int    `json:"deviceId"` // nil = คืนที่หน้าจอเจ้าหน้าที่
+}
+
+// Overstay is the data of a visitor.overstay event
+type Overstay struct {
+	Visitor      Visitor   `json:"visitor"`
+	RegisteredAt time.Time `json:"registeredAt"`
+	Minutes      int       `json:"minutes"`
+}
+
+// WatchlistHit is the data of a visitor.watchlist_hit event (ส่งทุกจุด ไม่กรองตามส่วนงาน)
+type WatchlistHit struct {
+	Visitor Visitor `json:"visitor"`
+	EntryID int     `json:"entryId"`
+	Reason  string  `json:"reason"`
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"net/http"
+
+	"backend/internal/address"
+
+	"github.com/gorilla/mux"
+)
+
+type AddressHandler struct {
+	addresses *address.Dataset
+}
+
+func NewAddressHandler(addresses *address.Dataset) *AddressHandler {
+	return &AddressHandler{addresses: addresses}
+}
+
+// provinceItem is a province without its districts, for dropdowns
+type provinceItem struct {
+	Code         string `json:"code"`
+	NameTh       string `json:"nameTh"`
+	NameEn       string `json:"nameEn"`
+	PostalPrefix string `json:"postalPrefix"`
+	IsBangkok    bool   `json:"isBangkok"`
+}
+
+// districtItem is a district without its sub-districts, for dropdowns
+type districtItem struct {
+	NameTh     string `json:"nameTh"`
+	NameEn     string `json:"nameEn,omitempty"`
+	PostalCode string `json:"postalCode,omitempty"`
+}
+
+// GetProvinces handles GET /api/address/provinces
+func (h *AddressHandler) GetProvinces(w http.ResponseWriter, r *http.Request) {
+	response := make([]provinceItem, len(h.addresses.Provinces))
+	for i, p := range h.addresses.Provinces {
+		response[i] = provinceItem{
+			Code:         p.Code,
+			NameTh:       p.NameTh,
+			NameEn:       p.NameEn,
+			PostalPrefix: p.PostalPrefix,
+			IsBangkok:    p.IsBangkok(),
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// GetDistricts handles GET /api/address/provinces/{province}/districts
+// {province} รับได้ทั้งรหัสจังหวัดและชื่อ
+func (h *AddressHandler) GetDistricts(w http.ResponseWriter, r *http.Request) {
+	province := h.addresses.FindProvince(mux.Vars(r)["province"])
+	if province == nil {
+		respondWithError(w, http.StatusNotFound, "ไม่พบจังหวัดนี้")
+		return
+	}
+
+	response := make([]districtItem, len(province.Districts))
+	for i, d := range province.Districts {
+		response[i] = districtItem{NameTh: d.NameTh, NameEn: d.NameEn, PostalCode: d.PostalCode}
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// GetSubDistricts handles GET /api/address/provinces/{province}/districts/{district}/sub-districts
+func (h *AddressHandler) GetSubDistricts(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	province := h.addresses.FindProvince(vars["province"])
+	if province == nil {
+		respondWithError(w, http.StatusNotFound, "ไม่พบจังหวัดนี้")
+		return
+	}
+
+	district := province.FindDistrict(vars["district"])
+	if district == nil {
+		respondWithError(w, http.StatusNotFound, "ไม่พบอำเภอ/เขตนี้")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, district.SubDistricts)
+}
+
+// GetByPostalCode handles GET /api/address/postal-codes/{code}
+func (h *AddressHandler) GetByPostalCode(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, h.addresses.FindByPostalCode(mux.Vars(r)["code"]))
+}
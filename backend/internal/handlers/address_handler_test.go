@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestAddressLookups(t *testing.T) {
+	ts := newTestServer(t)
+
+	rec := ts.do("GET", "/api/address/provinces", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var provinces []provinceItem
+	decode(t, rec, &provinces)
+	if len(provinces) != 77 {
+		t.Errorf("got %d provinces, want 77", len(provinces))
+	}
+
+	// จังหวัดรับทั้งรหัสและชื่อ
+	rec = ts.do("GET", "/api/address/provinces/10/districts", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var byCode []districtItem
+	decode(t, rec, &byCode)
+	rec = ts.do("GET", "/api/address/provinces/"+url.PathEscape("กรุงเทพมหานคร")+"/districts", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var byName []districtItem
+	decode(t, rec, &byName)
+	if len(byCode) == 0 || len(byCode) != len(byName) {
+		t.Errorf("districts by code = %d, by name = %d", len(byCode), len(byName))
+	}
+
+	expectStatus(t, ts.do("GET", "/api/address/provinces/99/districts", nil), http.StatusNotFound)
+	expectStatus(t, ts.do("GET", "/api/address/provinces/10/districts/"+url.PathEscape("ดุสิต")+"/sub-districts", nil), http.StatusOK)
+	expectStatus(t, ts.do("GET", "/api/address/provinces/10/districts/nowhere/sub-districts", nil), http.StatusNotFound)
+
+	rec = ts.do("GET", "/api/address/postal-codes/10300", nil)
+	expectStatus(t, rec, http.StatusOK)
+	if rec.Body.Len() < 3 {
+		t.Errorf("postal code lookup = %s", rec.Body.String())
+	}
+}
@@ -0,0 +1,283 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/notify"
+	"backend/internal/repository"
+	"backend/internal/token"
+
+	"github.com/gorilla/mux"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// earlyArrivalGrace คือเวลาที่ยอมให้มาถึงก่อนเวลานัด
+const earlyArrivalGrace = 2 * time.Hour
+
+type AppointmentHandler struct {
+	repo   *repository.AppointmentRepository
+	signer *token.Signer
+}
+
+func NewAppointmentHandler(repo *repository.AppointmentRepository, signer *token.Signer) *AppointmentHandler {
+	return &AppointmentHandler{repo: repo, signer: signer}
+}
+
+// CreateAppointment handles POST /api/appointments
+func (h *AppointmentHandler) CreateAppointment(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateAppointmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.FirstName == "" || req.LastName == "" {
+		respondWithError(w, http.StatusBadRequest, "First name and last name are required")
+		return
+	}
+	if req.IDCard != "" && len(req.IDCard) != 13 {
+		respondWithError(w, http.StatusBadRequest, "ID card must be 13 digits")
+		return
+	}
+	if req.HostName == "" {
+		respondWithError(w, http.StatusBadRequest, "Host name is required")
+		return
+	}
+
+	expectedFrom, err := time.Parse(time.RFC3339, req.ExpectedFrom)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "expectedFrom must be an RFC3339 time")
+		return
+	}
+	expectedUntil, err := time.Parse(time.RFC3339, req.ExpectedUntil)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "expectedUntil must be an RFC3339 time")
+		return
+	}
+	if !expectedUntil.After(expectedFrom) {
+		respondWithError(w, http.StatusBadRequest, "expectedUntil must be after expectedFrom")
+		return
+	}
+
+	appt := &models.Appointment{
+		IDCard:        req.IDCard,
+		FirstName:     req.FirstName,
+		LastName:      req.LastName,
+		Phone:         req.Phone,
+		LicensePlate:  req.LicensePlate,
+		HostName:      req.HostName,
+		HostContact:   req.HostContact,
+		Department:    req.Department,
+		Purpose:       req.Purpose,
+		ExpectedFrom:  expectedFrom,
+		ExpectedUntil: expectedUntil,
+		Status:        models.AppointmentPending,
+		CreatedBy:     req.CreatedBy,
+		CreatedAt:     time.Now(),
+	}
+
+	if err := h.repo.Create(appt); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to create appointment")
+		return
+	}
+
+	invite, err := h.invite(appt)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to sign invitation")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, invite)
+}
+
+// ListAppointments handles GET /api/appointments?date=YYYY-MM-DD&status=pending
+func (h *AppointmentHandler) ListAppointments(w http.ResponseWriter, r *http.Request) {
+	date, err := parseDateParam("date", r.URL.Query().Get("date"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	appointments, err := h.repo.List(date, r.URL.Query().Get("status"))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to list appointments")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, appointments)
+}
+
+// GetAppointment handles GET /api/appointments/{id}
+func (h *AppointmentHandler) GetAppointment(w http.ResponseWriter, r *http.Request) {
+	appt, ok := h.appointmentFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	invite, err := h.invite(appt)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to sign invitation")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, invite)
+}
+
+// GetAppointmentQR handles GET /api/appointments/{id}/qr
+// คืนรูป PNG ของ QR code สำหรับส่งให้ผู้มาติดต่อ
+func (h *AppointmentHandler) GetAppointmentQR(w http.ResponseWriter, r *http.Request) {
+	appt, ok := h.appointmentFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	signed, err := h.signer.Sign(token.KindAppointment, appt.ID, appt.ExpectedUntil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to sign invitation")
+		return
+	}
+
+	png, err := qrcode.Encode(signed, qrcode.Medium, 320)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to generate QR code")
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.WriteHeader(http.StatusOK)
+	w.Write(png)
+}
+
+// CancelAppointment handles POST /api/appointments/{id}/cancel
+func (h *AppointmentHandler) CancelAppointment(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid appointment ID")
+		return
+	}
+
+	if err := h.repo.Cancel(id); err != nil {
+		if errors.Is(err, repository.ErrAppointmentNotPending) {
+			respondWithError(w, http.StatusConflict, "นัดหมายนี้ถูกใช้หรือยกเลิกไปแล้ว")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to cancel appointment")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "ยกเลิกนัดหมายสำเร็จ"})
+}
+
+// ScanAppointment handles POST /api/appointments/scan
+// ตรวจ QR ที่ประตูและคืนข้อมูลสำหรับเติมฟอร์มลงทะเบียน
+func (h *AppointmentHandler) ScanAppointment(w http.ResponseWriter, r *http.Request) {
+	var req models.ScanAppointmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	appt, status, err := resolveAppointment(h.repo, h.signer, req.Token, "")
+	if err != nil {
+		respondWithError(w, status, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.ScanAppointmentResponse{
+		Appointment: appt,
+		Visitor: models.CreateVisitorRequest{
+			IDCard:           appt.IDCard,
+			FirstName:        appt.FirstName,
+			LastName:         appt.LastName,
+			Phone:            appt.Phone,
+			LicensePlate:     appt.LicensePlate,
+			Department:       appt.Department,
+			HostName:         appt.HostName,
+			HostContact:      appt.HostContact,
+			PurposeNote:      appt.Purpose,
+			AppointmentToken: req.Token,
+		},
+	})
+}
+
+func (h *AppointmentHandler) appointmentFromPath(w http.ResponseWriter, r *http.Request) (*models.Appointment, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid appointment ID")
+		return nil, false
+	}
+
+	appt, err := h.repo.GetByID(id)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Appointment not found")
+		return nil, false
+	}
+
+	return appt, true
+}
+
+func (h *AppointmentHandler) invite(appt *models.Appointment) (*models.AppointmentInvite, error) {
+	signed, err := h.signer.Sign(token.KindAppointment, appt.ID, appt.ExpectedUntil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AppointmentInvite{
+		Appointment: appt,
+		Token:       signed,
+		QRCodeURL:   fmt.Sprintf("/api/appointments/%d/qr", appt.ID),
+	}, nil
+}
+
+// resolveAppointment verifies an invitation token and checks that the appointment can be used now.
+// idCard คือเลขบัตรของผู้ที่กำลังลงทะเบียน (ว่างตอน scan) ต้องตรงกับเลขบัตรในนัดหมายถ้านัดหมายระบุไว้
+// คืน HTTP status ที่เหมาะสมพร้อม error เพื่อให้ทั้ง scan และ CreateVisitor ใช้ร่วมกัน
+func resolveAppointment(repo *repository.AppointmentRepository, signer *token.Signer, raw, idCard string) (*models.Appointment, int, error) {
+	claims, err := signer.Verify(raw, token.KindAppointment)
+	if errors.Is(err, token.ErrExpired) {
+		return nil, http.StatusGone, fmt.Errorf("QR นัดหมายหมดอายุแล้ว")
+	}
+	if err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("QR นัดหมายไม่ถูกต้อง")
+	}
+
+	appt, err := repo.GetByID(claims.ID)
+	if err != nil {
+		return nil, http.StatusNotFound, fmt.Errorf("ไม่พบนัดหมาย")
+	}
+
+	if appt.Status != models.AppointmentPending {
+		return nil, http.StatusConflict, fmt.Errorf("นัดหมายนี้ถูกใช้หรือยกเลิกไปแล้ว")
+	}
+	if time.Now().Before(appt.ExpectedFrom.Add(-earlyArrivalGrace)) {
+		return nil, http.StatusBadRequest, fmt.Errorf("ยังไม่ถึงเวลานัดหมาย")
+	}
+	if appt.IDCard != "" && idCard != "" && idCard != appt.IDCard {
+		return nil, http.StatusForbidden, fmt.Errorf("เลขบัตรประชาชนไม่ตรงกับนัดหมาย")
+	}
+
+	return appt, http.StatusOK, nil
+}
+
+// notifyHostArrival tells the host that their visitor has arrived
+// arrivedAt เป็นเวลาที่จัดรูปแบบตามเวลาของโรงเรียนแล้ว
+func notifyHostArrival(notifier notify.Notifier, appt *models.Appointment, visitor *models.Visitor, arrivedAt string) {
+	err := notifier.Notify(notify.Message{
+		Type:      "appointment.arrived",
+		Recipient: appt.HostContact,
+		Title:     "ผู้มาติดต่อมาถึงแล้ว",
+		Body: fmt.Sprintf("%s %s มาพบ %s (%s) เวลา %s",
+			visitor.FirstName, visitor.LastName, appt.HostName, appt.Purpose, arrivedAt),
+		Data: map[string]int{"appointmentId": appt.ID, "visitorId": visitor.ID},
+	})
+	if err != nil {
+		log.Printf("Failed to notify host of appointment %d: %v", appt.ID, err)
+	}
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/token"
+)
+
+// นัดหมายที่ระบุเลขบัตรไว้ใช้ลงทะเบียนได้เฉพาะคนที่ถือบัตรนั้น
+func TestResolveAppointmentIDCard(t *testing.T) {
+	ts := newTestServer(t)
+	repo := repository.NewAppointmentRepository(newTestDB(t), ts.clock)
+	signer := token.NewSigner("test-secret")
+
+	withCard := &models.Appointment{IDCard: "1101234500001", FirstName: "สมชาย", LastName: "ใจดี", HostName: "ครูสมศรี", Status: models.AppointmentPending,
+		ExpectedFrom: time.Now(), ExpectedUntil: time.Now().Add(2 * time.Hour)}
+	withoutCard := &models.Appointment{FirstName: "สมหญิง", LastName: "ใจดี", HostName: "ครูสมศรี", Status: models.AppointmentPending,
+		ExpectedFrom: time.Now(), ExpectedUntil: time.Now().Add(2 * time.Hour)}
+	for _, appt := range []*models.Appointment{withCard, withoutCard} {
+		if err := repo.Create(appt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sign := func(appt *models.Appointment) string {
+		signed, err := signer.Sign(token.KindAppointment, appt.ID, appt.ExpectedUntil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+
+	tests := []struct {
+		name   string
+		appt   *models.Appointment
+		idCard string
+		status int
+	}{
+		{"same card", withCard, "1101234500001", http.StatusOK},
+		{"scan before the card is read", withCard, "", http.StatusOK},
+		{"another card", withCard, "1101234500002", http.StatusForbidden},
+		{"appointment without a card", withoutCard, "1101234500002", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appt, status, err := resolveAppointment(repo, signer, sign(tt.appt), tt.idCard)
+			if status != tt.status {
+				t.Fatalf("resolveAppointment() status = %d, %v, want %d", status, err, tt.status)
+			}
+			if tt.status == http.StatusOK && (err != nil || appt.ID != tt.appt.ID) {
+				t.Errorf("resolveAppointment() = %+v, %v", appt, err)
+			}
+		})
+	}
+}
+
+func TestAppointments(t *testing.T) {
+	ts := newSQLiteServer(t)
+	req := models.CreateAppointmentRequest{
+		IDCard: "1101234500001", FirstName: "สมชาย1", LastName: "ใจดี", HostName: "ครูสมศรี", Department: "ฝ่ายวิชาการ",
+		Purpose: "ประชุมผู้ปกครอง", ExpectedFrom: "2025-06-02T09:00:00+07:00", ExpectedUntil: "2025-06-02T12:00:00+07:00",
+	}
+	rec := ts.do("POST", "/api/appointments", req)
+	expectStatus(t, rec, http.StatusCreated)
+	var invite models.AppointmentInvite
+	decode(t, rec, &invite)
+	appt := invite.Appointment
+	path := "/api/appointments/" + strconv.Itoa(appt.ID)
+	if appt.Status != models.AppointmentPending || invite.QRCodeURL != path+"/qr" || invite.Token == "" {
+		t.Fatalf("invite = %+v", invite)
+	}
+
+	rec = ts.do("GET", "/api/appointments?date=2025-06-02&status=pending", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var list []models.Appointment
+	decode(t, rec, &list)
+	if len(list) != 1 || list[0].ID != appt.ID {
+		t.Errorf("appointments of the day = %+v", list)
+	}
+	rec = ts.do("GET", "/api/appointments?date=2025-06-03", nil)
+	decode(t, rec, &list)
+	if len(list) != 0 {
+		t.Errorf("appointments of another day = %+v", list)
+	}
+	expectStatus(t, ts.do("GET", "/api/appointments?date=bad", nil), http.StatusBadRequest)
+
+	rec = ts.do("GET", path, nil)
+	expectStatus(t, rec, http.StatusOK)
+	rec = ts.do("GET", path+"/qr", nil)
+	expectStatus(t, rec, http.StatusOK)
+	if !bytes.HasPrefix(rec.Body.Bytes(), []byte("\x89PNG")) {
+		t.Error("appointment QR is not a PNG")
+	}
+	expectStatus(t, ts.do("GET", "/api/appointments/99", nil), http.StatusNotFound)
+	expectStatus(t, ts.do("GET", "/api/appointments/99/qr", nil), http.StatusNotFound)
+	expectStatus(t, ts.do("GET", "/api/appointments/x", nil), http.StatusBadRequest)
+
+	// สแกน QR ที่ประตูได้ข้อมูลไปเติมฟอร์ม ลงทะเบียนแล้วนัดหมายถูกใช้
+	rec = ts.do("POST", "/api/appointments/scan", models.ScanAppointmentRequest{Token: invite.Token})
+	expectStatus(t, rec, http.StatusOK)
+	var scanned models.ScanAppointmentResponse
+	decode(t, rec, &scanned)
+	if scanned.Visitor.IDCard != req.IDCard || scanned.Visitor.HostName != "ครูสมศรี" || scanned.Visitor.AppointmentToken != invite.Token {
+		t.Errorf("scan = %+v", scanned)
+	}
+	expectStatus(t, ts.do("POST", "/api/appointments/scan", models.ScanAppointmentRequest{Token: "forged"}), http.StatusBadRequest)
+	expectStatus(t, ts.do("POST", "/api/appointments/scan", "{"), http.StatusBadRequest)
+
+	visit := newVisitorRequest(1)
+	visit.AppointmentToken = invite.Token
+	v := ts.createVisitor(visit)
+
+	rec = ts.do("GET", path, nil)
+	var arrived models.AppointmentInvite
+	decode(t, rec, &arrived)
+	if arrived.Appointment.Status != models.AppointmentArrived || arrived.Appointment.VisitorID == nil || *arrived.Appointment.VisitorID != v.ID {
+		t.Errorf("appointment after registering = %+v", arrived.Appointment)
+	}
+	expectStatus(t, ts.do("POST", "/api/appointments/scan", models.ScanAppointmentRequest{Token: invite.Token}), http.StatusConflict)
+	expectStatus(t, ts.do("POST", path+"/cancel", nil), http.StatusConflict)
+
+	req.IDCard = ""
+	rec = ts.do("POST", "/api/appointments", req)
+	expectStatus(t, rec, http.StatusCreated)
+	decode(t, rec, &invite)
+	expectStatus(t, ts.do("POST", "/api/appointments/"+strconv.Itoa(invite.Appointment.ID)+"/cancel", nil), http.StatusOK)
+	expectStatus(t, ts.do("POST", "/api/appointments/scan", models.ScanAppointmentRequest{Token: invite.Token}), http.StatusConflict)
+	expectStatus(t, ts.do("POST", "/api/appointments/x/cancel", nil), http.StatusBadRequest)
+
+	// QR หมดอายุเมื่อพ้นเวลานัด
+	ts.advance(3 * time.Hour)
+	expectStatus(t, ts.do("POST", "/api/appointments/scan", models.ScanAppointmentRequest{Token: invite.Token}), http.StatusGone)
+
+	req.ExpectedUntil = req.ExpectedFrom
+	expectStatus(t, ts.do("POST", "/api/appointments", req), http.StatusBadRequest)
+	expectStatus(t, ts.do("POST", "/api/appointments", models.CreateAppointmentRequest{FirstName: "สมชาย"}), http.StatusBadRequest)
+}
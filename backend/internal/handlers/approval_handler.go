@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"backend/internal/models"
+	"backend/internal/notify"
+	"backend/internal/repository"
+
+	"github.com/gorilla/mux"
+)
+
+// ListPendingApprovals handles GET /api/approvals?host=ชื่อผู้รับนัด
+// หน้าจอของผู้รับนัดใช้ดูรายการที่รออนุมัติ
+func (h *VisitorHandler) ListPendingApprovals(w http.ResponseWriter, r *http.Request) {
+	visitors, err := h.repo.ListPendingApprovals(r.URL.Query().Get("host"))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to list pending approvals")
+		return
+	}
+	for i := range visitors {
+		setPhotoURLs(&visitors[i])
+	}
+
+	respondWithJSON(w, http.StatusOK, visitors)
+}
+
+// ApproveVisit handles POST /api/visitors/{id}/approve
+func (h *VisitorHandler) ApproveVisit(w http.ResponseWriter, r *http.Request) {
+	h.decideVisit(w, r, models.ApprovalApproved)
+}
+
+// RejectVisit handles POST /api/visitors/{id}/reject
+func (h *VisitorHandler) RejectVisit(w http.ResponseWriter, r *http.Request) {
+	h.decideVisit(w, r, models.ApprovalRejected)
+}
+
+func (h *VisitorHandler) decideVisit(w http.ResponseWriter, r *http.Request, status string) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid visitor ID")
+		return
+	}
+
+	var req models.ApprovalRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.ApprovedBy == "" {
+		respondWithError(w, http.StatusBadRequest, "approvedBy is required")
+		return
+	}
+
+	visitor, err := h.repo.GetByID(id)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Visitor not found")
+		return
+	}
+	if !isHost(visitor, req.ApprovedBy) {
+		respondWithError(w, http.StatusForbidden, "ผู้อนุมัติต้องเป็นผู้รับนัดของรายการนี้")
+		return
+	}
+
+	if err := h.repo.SetApproval(id, status, req.ApprovedBy, req.Note); err != nil {
+		if errors.Is(err, repository.ErrNotPendingApproval) {
+			respondWithError(w, http.StatusConflict, "รายการนี้ไม่ได้อยู่ระหว่างรออนุมัติ")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to save approval")
+		return
+	}
+
+	visitor, err = h.repo.GetByID(id)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Visitor not found")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, visitor)
+}
+
+// isHost reports whether approver is the host of the visit, by name or contact (ไม่สนช่องว่างและตัวพิมพ์)
+func isHost(visitor *models.Visitor, approver string) bool {
+	approver = strings.TrimSpace(approver)
+	for _, host := range []string{visitor.HostName, visitor.HostContact} {
+		if host = strings.TrimSpace(host); host != "" && strings.EqualFold(host, approver) {
+			return true
+		}
+	}
+	return false
+}
+
+// IssueCard handles POST /api/visitors/{id}/card
+// ออกบัตร RFID ให้การเข้าเยี่ยมที่อนุมัติแล้ว (หรือไม่ต้องอนุมัติ) และยังไม่มีบัตร
+func (h *VisitorHandler) IssueCard(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid visitor ID")
+		return
+	}
+
+	var req models.IssueCardRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.RFID == "" {
+		respondWithError(w, http.StatusBadRequest, "RFID is required")
+		return
+	}
+
+	rfidExists, err := h.repo.CheckRFIDExists(req.RFID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to check RFID")
+		return
+	}
+	if rfidExists {
+		respondWithError(w, http.StatusConflict, "RFID already registered")
+		return
+	}
+
+	if err := h.repo.IssueCard(id, req.RFID); err != nil {
+		if errors.Is(err, repository.ErrCardNotIssuable) {
+			respondWithError(w, http.StatusConflict, "ยังออกบัตรไม่ได้ (รออนุมัติ ถูกปฏิเสธ หรือมีบัตรแล้ว)")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to issue card")
+		return
+	}
+
+	visitor, err := h.repo.GetByID(id)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Visitor not found")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, visitor)
+}
+
+// notifyHostApproval asks the host to approve a pending visit
+func notifyHostApproval(notifier notify.Notifier, visitor *models.Visitor, purpose *models.VisitPurpose) {
+	err := notifier.Notify(notify.Message{
+		Type:      "visit.approval_requested",
+		Recipient: visitor.HostContact,
+		Title:     "มีผู้มาติดต่อรออนุมัติ",
+		Body: fmt.Sprintf("%s %s ขอพบ %s (%s)",
+			visitor.FirstName, visitor.LastName, visitor.HostName, purpose.NameTh),
+		Data: map[string]int{"visitorId": visitor.ID},
+	})
+	if err != nil {
+		log.Printf("Failed to notify host of visit %d: %v", visitor.ID, err)
+	}
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"backend/internal/models"
+)
+
+// pendingVisitor stores a visit that waits for the host, like a purpose that requires approval
+func (ts *testServer) pendingVisitor(n int, host string) *models.Visitor {
+	ts.t.Helper()
+	req := newVisitorRequest(n)
+	v := &models.Visitor{
+		IDCard:         req.IDCard,
+		FirstName:      req.FirstName,
+		LastName:       req.LastName,
+		Phone:          req.Phone,
+		Department:     req.Department,
+		HostName:       host,
+		HostContact:    "line:" + host,
+		Purpose:        "meeting",
+		ApprovalStatus: models.ApprovalPending,
+	}
+	if err := ts.visitors.Create(v); err != nil {
+		ts.t.Fatal(err)
+	}
+	return v
+}
+
+func TestApproveAndIssueCard(t *testing.T) {
+	ts := newTestServer(t)
+	v := ts.pendingVisitor(1, "ครูสมศรี")
+	ts.pendingVisitor(2, "ครูสมหญิง")
+	path := "/api/visitors/" + strconv.Itoa(v.ID)
+
+	rec := ts.do("GET", "/api/approvals?host="+url.QueryEscape("ครูสมศรี"), nil)
+	expectStatus(t, rec, http.StatusOK)
+	var pending []models.Visitor
+	decode(t, rec, &pending)
+	if len(pending) != 1 || pending[0].ID != v.ID {
+		t.Fatalf("pending approvals = %+v", pending)
+	}
+
+	// ยังไม่อนุมัติออกบัตรไม่ได้
+	expectStatus(t, ts.do("POST", path+"/card", models.IssueCardRequest{RFID: "RF9000"}), http.StatusConflict)
+
+	expectStatus(t, ts.do("POST", path+"/approve", models.ApprovalRequest{}), http.StatusBadRequest)
+	// คนอื่นอนุมัติแทนผู้รับนัดไม่ได้
+	expectStatus(t, ts.do("POST", path+"/approve", models.ApprovalRequest{ApprovedBy: "ครูสมหญิง"}), http.StatusForbidden)
+	rec = ts.do("POST", path+"/approve", models.ApprovalRequest{ApprovedBy: "ครูสมศรี"})
+	expectStatus(t, rec, http.StatusOK)
+	var approved models.Visitor
+	decode(t, rec, &approved)
+	if approved.ApprovalStatus != models.ApprovalApproved {
+		t.Errorf("approval status = %q", approved.ApprovalStatus)
+	}
+
+	// ตัดสินแล้วเปลี่ยนใจไม่ได้
+	expectStatus(t, ts.do("POST", path+"/reject", models.ApprovalRequest{ApprovedBy: "ครูสมศรี"}), http.StatusConflict)
+
+	expectStatus(t, ts.do("POST", path+"/card", models.IssueCardRequest{}), http.StatusBadRequest)
+	rec = ts.do("POST", path+"/card", models.IssueCardRequest{RFID: "RF9000"})
+	expectStatus(t, rec, http.StatusOK)
+	var issued models.Visitor
+	decode(t, rec, &issued)
+	if issued.RFID != "RF9000" {
+		t.Errorf("issued RFID = %q", issued.RFID)
+	}
+	expectStatus(t, ts.do("POST", path+"/card", models.IssueCardRequest{RFID: "RF9001"}), http.StatusConflict)
+}
+
+func TestRejectVisit(t *testing.T) {
+	ts := newTestServer(t)
+	v := ts.pendingVisitor(1, "ครูสมศรี")
+	path := "/api/visitors/" + strconv.Itoa(v.ID)
+
+	// ผู้รับนัดยืนยันตัวด้วยช่องทางติดต่อได้
+	rec := ts.do("POST", path+"/reject", models.ApprovalRequest{ApprovedBy: "LINE:ครูสมศรี", Note: "ไม่ว่าง"})
+	expectStatus(t, rec, http.StatusOK)
+	var rejected models.Visitor
+	decode(t, rec, &rejected)
+	if rejected.ApprovalStatus != models.ApprovalRejected {
+		t.Errorf("approval status = %q", rejected.ApprovalStatus)
+	}
+
+	expectStatus(t, ts.do("POST", path+"/card", models.IssueCardRequest{RFID: "RF9000"}), http.StatusConflict)
+	expectStatus(t, ts.do("POST", "/api/visitors/x/reject", "{}"), http.StatusBadRequest)
+	expectStatus(t, ts.do("POST", "/api/visitors/99/approve", models.ApprovalRequest{ApprovedBy: "ครู"}), http.StatusNotFound)
+}
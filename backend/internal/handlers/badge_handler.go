@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"backend/internal/badge"
+	"backend/internal/clock"
+	"backend/internal/models"
+	"backend/internal/photo"
+	"backend/internal/repository"
+	"backend/internal/storage"
+	"backend/internal/token"
+
+	"github.com/gorilla/mux"
+)
+
+type BadgeHandler struct {
+	repo     repository.VisitorStore
+	purposes *repository.PurposeRepository
+	store    storage.Storage
+	signer   *token.Signer
+	renderer *badge.Renderer
+	clock    *clock.Clock
+}
+
+func NewBadgeHandler(
+	repo repository.VisitorStore,
+	purposes *repository.PurposeRepository,
+	store storage.Storage,
+	signer *token.Signer,
+	renderer *badge.Renderer,
+	clk *clock.Clock,
+) *BadgeHandler {
+	return &BadgeHandler{
+		repo:     repo,
+		purposes: purposes,
+		store:    store,
+		signer:   signer,
+		renderer: renderer,
+		clock:    clk,
+	}
+}
+
+// GetBadge handles GET /api/visitors/{id}/badge?format=pdf|png
+// พิมพ์บัตรผู้มาติดต่อแทนการเขียนมือ PNG สำหรับเครื่องพิมพ์ฉลาก PDF สำหรับเครื่องพิมพ์ทั่วไป
+func (h *BadgeHandler) GetBadge(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid visitor ID")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "pdf"
+	}
+	if format != "pdf" && format != "png" {
+		respondWithError(w, http.StatusBadRequest, "format must be pdf or png")
+		return
+	}
+
+	visitor, err := h.repo.GetByID(id)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Visitor not found")
+		return
+	}
+
+	switch {
+	case visitor.ExitTime != nil:
+		respondWithError(w, http.StatusConflict, "ผู้มาติดต่อออกจากพื้นที่แล้ว")
+		return
+	case visitor.ApprovalStatus == models.ApprovalPending:
+		respondWithError(w, http.StatusConflict, "ต้องรอผู้รับนัดอนุมัติก่อนพิมพ์บัตร")
+		return
+	case visitor.ApprovalStatus == models.ApprovalRejected:
+		respondWithError(w, http.StatusConflict, "การเข้าพบถูกปฏิเสธ")
+		return
+	}
+
+	// บัตรแสดงเวลาและหมดอายุตามเวลาของโรงเรียน
+	checkIn := h.clock.In(visitor.RegisteredAt)
+	expiresAt := h.renderer.Layout().ExpiresAt(checkIn)
+	if h.clock.Now().After(expiresAt) {
+		respondWithError(w, http.StatusGone, "บัตรของการเข้าเยี่ยมนี้หมดอายุแล้ว")
+		return
+	}
+
+	signed, err := h.signer.Sign(token.KindVisit, visitor.ID, expiresAt)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to sign visit token")
+		return
+	}
+
+	b := badge.Badge{
+		Name:       visitor.FirstName + " " + visitor.LastName,
+		Department: visitor.Department,
+		Host:       visitor.HostName,
+		Purpose:    visitor.PurposeNote,
+		CheckIn:    checkIn,
+		ExpiresAt:  expiresAt,
+		QRContent:  signed,
+		Photo:      h.loadPhoto(visitor),
+	}
+	if visitor.Purpose != "" {
+		if p, err := h.purposes.GetByCode(visitor.Purpose); err == nil {
+			b.Purpose = p.NameTh
+		}
+	}
+
+	var data []byte
+	contentType := "application/pdf"
+	if format == "png" {
+		data, err = h.renderer.PNG(b)
+		contentType = "image/png"
+	} else {
+		data, err = h.renderer.PDF(b)
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to render badge")
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"badge-%d.%s\"", visitor.ID, format))
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// ScanBadge handles POST /api/badges/scan
+// ป้อมสแกน QR บนบัตรผู้มาติดต่อ ตรวจลายเซ็นและวันหมดอายุ แล้วตอบว่าเป็นการเข้าเยี่ยมของใคร
+// บัตรของคนที่ออกไปแล้ว (คืนบัตรแล้ว) ใช้ไม่ได้อีก
+func (h *BadgeHandler) ScanBadge(w http.ResponseWriter, r *http.Request) {
+	var req models.ScanBadgeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	claims, err := h.signer.Verify(req.Token, token.KindVisit)
+	if errors.Is(err, token.ErrExpired) {
+		respondWithError(w, http.StatusGone, "บัตรของการเข้าเยี่ยมนี้หมดอายุแล้ว")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "QR บัตรผู้มาติดต่อไม่ถูกต้อง")
+		return
+	}
+
+	visitor, err := h.repo.GetByID(claims.ID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Visitor not found")
+		return
+	}
+	if visitor.ExitTime != nil {
+		respondWithError(w, http.StatusConflict, "ผู้มาติดต่อออกจากพื้นที่แล้ว")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.ScanBadgeResponse{
+		Visitor:   visitor,
+		ExpiresAt: time.Unix(claims.ExpiresAt, 0).In(h.clock.Location()),
+	})
+}
+
+// loadPhoto reads the visitor photo; a missing or broken photo only leaves the badge without it
+func (h *BadgeHandler) loadPhoto(v *models.Visitor) image.Image {
+	if v.PhotoKey == "" {
+		return nil
+	}
+
+	data, err := readStored(h.store, v.PhotoKey)
+	if err != nil {
+		log.Printf("Failed to read photo of visitor %d: %v", v.ID, err)
+		return nil
+	}
+
+	img, err := photo.Decode(data)
+	if err != nil {
+		log.Printf("Failed to decode photo of visitor %d: %v", v.ID, err)
+		return nil
+	}
+	return img
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+
+	"backend/internal/thaidate"
+)
+
+// dateFormatter applies the per-request overrides ?calendar=be|ce&month=numeric|short|long&digits=thai|arabic
+// on top of the configured default format
+func dateFormatter(values url.Values, defaults thaidate.Formatter) (thaidate.Formatter, error) {
+	f := defaults
+	if calendar := values.Get("calendar"); calendar != "" {
+		f.Calendar = calendar
+	}
+	if month := values.Get("month"); month != "" {
+		f.Month = month
+	}
+	switch values.Get("digits") {
+	case "":
+	case "thai":
+		f.ThaiDigits = true
+	case "arabic":
+		f.ThaiDigits = false
+	default:
+		return f, fmt.Errorf("digits must be thai or arabic")
+	}
+
+	if err := f.Validate(); err != nil {
+		return f, err
+	}
+	return f, nil
+}
+
+// parseDateParam converts a date filter in either calendar to YYYY-MM-DD (ค.ศ.)
+func parseDateParam(name, value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	normalized, err := thaidate.NormalizeDate(value)
+	if err != nil {
+		return "", fmt.Errorf("%s must be YYYY-MM-DD or DD/MM/YYYY (ค.ศ. or พ.ศ.)", name)
+	}
+	return normalized, nil
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"backend/internal/models"
+	"backend/internal/repository"
+
+	"github.com/gorilla/mux"
+)
+
+type DepartmentHandler struct {
+	repo repository.DepartmentStore
+}
+
+func NewDepartmentHandler(repo repository.DepartmentStore) *DepartmentHandler {
+	return &DepartmentHandler{repo: repo}
+}
+
+// ListDepartments handles GET /api/departments (all=true รวมรายการที่ปิดใช้งาน)
+func (h *DepartmentHandler) ListDepartments(w http.ResponseWriter, r *http.Request) {
+	activeOnly := r.URL.Query().Get("all") != "true"
+
+	departments, err := h.repo.List(activeOnly)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to list departments")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, departments)
+}
+
+// CreateDepartment handles POST /api/departments
+func (h *DepartmentHandler) CreateDepartment(w http.ResponseWriter, r *http.Request) {
+	var req models.SaveDepartmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	department := &models.Department{Active: true}
+	if status, err := h.apply(department, req); err != nil {
+		respondWithError(w, status, err.Error())
+		return
+	}
+
+	if err := h.repo.Create(department); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to create department")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, department)
+}
+
+// UpdateDepartment handles PUT /api/departments/{id}.
+// เปลี่ยนชื่อแล้วการมาติดต่อเดิมยังเก็บชื่อเดิมไว้ แต่กรองด้วย id ได้เหมือนเดิม
+func (h *DepartmentHandler) UpdateDepartment(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid department ID")
+		return
+	}
+
+	var req models.SaveDepartmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	department, err := h.repo.GetByID(id)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Department not found")
+		return
+	}
+	if status, err := h.apply(department, req); err != nil {
+		respondWithError(w, status, err.Error())
+		return
+	}
+
+	if err := h.repo.Update(department); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to update department")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, department)
+}
+
+// DeleteDepartment handles DELETE /api/departments/{id}. ส่วนงานที่มีคนมาติดต่อแล้วลบไม่ได้ ให้ปิดใช้งานแทน
+func (h *DepartmentHandler) DeleteDepartment(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid department ID")
+		return
+	}
+
+	if _, err := h.repo.GetByID(id); err != nil {
+		respondWithError(w, http.StatusNotFound, "Department not found")
+		return
+	}
+
+	if err := h.repo.Delete(id); err != nil {
+		if errors.Is(err, repository.ErrDepartmentInUse) {
+			respondWithError(w, http.StatusConflict, "Department has visits, deactivate it instead")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete department")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// apply validates req and copies it onto department, returning the status to respond with on error
+func (h *DepartmentHandler) apply(department *models.Department, req models.SaveDepartmentRequest) (int, error) {
+	req.NameTh = strings.TrimSpace(req.NameTh)
+	req.NameEn = strings.TrimSpace(req.NameEn)
+	if req.NameTh == "" {
+		return http.StatusBadRequest, fmt.Errorf("nameTh is required")
+	}
+	if req.DisplayOrder < 0 {
+		return http.StatusBadRequest, fmt.Errorf("displayOrder must not be negative")
+	}
+
+	// ชื่อไทยหรืออังกฤษต้องไม่ซ้ำกับส่วนงานอื่น เพราะใช้ชื่อค้นหาส่วนงานตอนลงทะเบียน
+	for _, name := range []string{req.NameTh, req.NameEn} {
+		if name == "" {
+			continue
+		}
+		if other, err := h.repo.GetByName(name); err == nil && other.ID != department.ID {
+			return http.StatusConflict, fmt.Errorf("Department %s already exists", name)
+		}
+	}
+
+	department.NameTh = req.NameTh
+	department.NameEn = req.NameEn
+	department.DisplayOrder = req.DisplayOrder
+	if req.Active != nil {
+		department.Active = *req.Active
+	}
+	return 0, nil
+}
+
+// resolveDepartment finds the department a visit is for, by id or else by Thai/English name.
+// ต้องระบุส่วนงานและต้องเป็นส่วนงานที่เปิดใช้งาน
+func resolveDepartment(departments repository.DepartmentStore, id int, name string) (*models.Department, error) {
+	var department *models.Department
+	var err error
+	switch {
+	case id != 0:
+		department, err = departments.GetByID(id)
+	case strings.TrimSpace(name) != "":
+		department, err = departments.GetByName(name)
+	default:
+		return nil, fmt.Errorf("Department is required")
+	}
+	if err != nil || !department.Active {
+		return nil, fmt.Errorf("Invalid department")
+	}
+	return department, nil
+}
+
+// allDepartments is the department filter value that selects every department (เหมือนไม่ส่งมา)
+const allDepartments = "all"
+
+// isAllDepartments reports whether a department filter selects every department
+func isAllDepartments(name string) bool {
+	name = strings.TrimSpace(name)
+	return name == "" || strings.EqualFold(name, allDepartments)
+}
+
+// resolveDepartmentFilter turns the department name of a visitor query into DepartmentIDs.
+// ไม่ระบุหรือส่ง "all" = ทุกส่วนงาน ส่วนชื่อที่ไม่มีในรายการถือว่าผิด (ส่วนงานที่ปิดแล้วยังกรองได้)
+func resolveDepartmentFilter(departments repository.DepartmentStore, params *models.QueryParams) error {
+	if isAllDepartments(params.Department) {
+		params.Department = ""
+		return nil
+	}
+	department, err := departments.GetByName(params.Department)
+	if err != nil {
+		return fmt.Errorf("unknown department: %s", params.Department)
+	}
+	params.DepartmentIDs = append(params.DepartmentIDs, department.ID)
+	return nil
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"backend/internal/models"
+)
+
+func TestDepartments(t *testing.T) {
+	ts := newTestServer(t)
+
+	rec := ts.do("POST", "/api/departments", models.SaveDepartmentRequest{NameTh: " งานพัสดุ ", NameEn: "Supplies", DisplayOrder: 3})
+	expectStatus(t, rec, http.StatusCreated)
+	var created models.Department
+	decode(t, rec, &created)
+	if created.ID == 0 || created.NameTh != "งานพัสดุ" || !created.Active {
+		t.Fatalf("created department = %+v", created)
+	}
+
+	expectStatus(t, ts.do("POST", "/api/departments", models.SaveDepartmentRequest{NameEn: "No Thai name"}), http.StatusBadRequest)
+	expectStatus(t, ts.do("POST", "/api/departments", models.SaveDepartmentRequest{NameTh: "ฝ่ายวิชาการ"}), http.StatusConflict)
+	expectStatus(t, ts.do("POST", "/api/departments", models.SaveDepartmentRequest{NameTh: "งานพัสดุ 2", NameEn: "supplies"}), http.StatusConflict)
+
+	// ลงทะเบียนด้วยชื่ออังกฤษได้ เก็บชื่อไทยไว้
+	req := newVisitorRequest(1)
+	req.Department = "SUPPLIES"
+	v := ts.createVisitor(req)
+	if v.Department != "งานพัสดุ" || v.DepartmentID == nil || *v.DepartmentID != created.ID {
+		t.Errorf("visitor department = %s %v", v.Department, v.DepartmentID)
+	}
+
+	// ปิดใช้งานแล้วลงทะเบียนไม่ได้ แต่ยังกรองรายการเดิมได้
+	inactive := false
+	rec = ts.do("PUT", "/api/departments/"+strconv.Itoa(created.ID), models.SaveDepartmentRequest{NameTh: "งานพัสดุ", NameEn: "Supplies", Active: &inactive, DisplayOrder: 3})
+	expectStatus(t, rec, http.StatusOK)
+	req = newVisitorRequest(2)
+	req.DepartmentID = created.ID
+	expectStatus(t, ts.do("POST", "/api/visitors", req), http.StatusBadRequest)
+
+	rec = ts.do("GET", "/api/visitors?department=Supplies", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var list []models.VisitorListResponse
+	decode(t, rec, &list)
+	if len(list) != 1 || list[0].ID != v.ID {
+		t.Errorf("visitors of an inactive department = %+v", list)
+	}
+
+	var departments []models.Department
+	rec = ts.do("GET", "/api/departments", nil)
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &departments)
+	if len(departments) != 2 {
+		t.Errorf("active departments = %+v", departments)
+	}
+	rec = ts.do("GET", "/api/departments?all=true", nil)
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &departments)
+	if len(departments) != 3 || departments[2].ID != created.ID {
+		t.Errorf("all departments = %+v", departments)
+	}
+
+	// ส่วนงานที่มีคนมาติดต่อแล้วลบไม่ได้
+	expectStatus(t, ts.do("DELETE", "/api/departments/"+strconv.Itoa(created.ID), nil), http.StatusConflict)
+	expectStatus(t, ts.do("DELETE", "/api/departments/2", nil), http.StatusNoContent)
+	expectStatus(t, ts.do("DELETE", "/api/departments/2", nil), http.StatusNotFound)
+	expectStatus(t, ts.do("PUT", "/api/departments/99", models.SaveDepartmentRequest{NameTh: "ไม่มี"}), http.StatusNotFound)
+	expectStatus(t, ts.do("PUT", "/api/departments/abc", models.SaveDepartmentRequest{NameTh: "ไม่มี"}), http.StatusBadRequest)
+}
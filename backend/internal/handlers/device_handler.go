@@ -0,0 +1,337 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/clock"
+	"backend/internal/events"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/token"
+
+	"github.com/gorilla/mux"
+)
+
+// tapRepeatWindow: แตะบัตรเดิมที่เครื่องเดิมภายในช่วงนี้ถือเป็นการแตะซ้ำ ไม่ทำรายการอีก
+const tapRepeatWindow = 3 * time.Second
+
+const (
+	defaultTapLogLimit = 100
+	maxTapLogLimit     = 1000
+)
+
+type DeviceHandler struct {
+	devices  *repository.DeviceRepository
+	visitors repository.VisitorStore
+	returns  repository.ReturnLogStore
+	clock    *clock.Clock
+	bus      *events.Bus
+}
+
+func NewDeviceHandler(devices *repository.DeviceRepository, visitors repository.VisitorStore, returns repository.ReturnLogStore, clk *clock.Clock, bus *events.Bus) *DeviceHandler {
+	return &DeviceHandler{devices: devices, visitors: visitors, returns: returns, clock: clk, bus: bus}
+}
+
+// CreateDevice handles POST /api/devices
+// คืน API key ของเครื่องครั้งเดียว ให้ผู้ดูแลนำไปตั้งในเครื่องอ่านบัตร
+func (h *DeviceHandler) CreateDevice(w http.ResponseWriter, r *http.Request) {
+	var req models.SaveDeviceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	device := &models.Device{Active: true}
+	if err := applyDeviceRequest(device, req); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	key, hash, err := token.NewAPIKey()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to generate API key")
+		return
+	}
+	device.KeyHash = hash
+	device.KeyHint = token.APIKeyHint(key)
+
+	if err := h.devices.Create(device); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to create device")
+		return
+	}
+
+	created, err := h.devices.GetByID(device.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get device")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, models.DeviceKeyResponse{Device: created, APIKey: key})
+}
+
+// ListDevices handles GET /api/devices
+func (h *DeviceHandler) ListDevices(w http.ResponseWriter, r *http.Request) {
+	devices, err := h.devices.List()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to list devices")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, devices)
+}
+
+// UpdateDevice handles PUT /api/devices/{id}
+func (h *DeviceHandler) UpdateDevice(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid device ID")
+		return
+	}
+
+	var req models.SaveDeviceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	device, err := h.devices.GetByID(id)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Device not found")
+		return
+	}
+	if err := applyDeviceRequest(device, req); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.devices.Update(device); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to update device")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, device)
+}
+
+// RotateKey handles POST /api/devices/{id}/key
+// ออก key ใหม่ (เช่น key หลุดหรือเปลี่ยนเครื่อง) key เดิมใช้ไม่ได้ทันที
+func (h *DeviceHandler) RotateKey(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid device ID")
+		return
+	}
+
+	device, err := h.devices.GetByID(id)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Device not found")
+		return
+	}
+
+	key, hash, err := token.NewAPIKey()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to generate API key")
+		return
+	}
+	if err := h.devices.SetKey(id, hash, token.APIKeyHint(key)); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to set API key")
+		return
+	}
+	device.KeyHash = hash
+	device.KeyHint = token.APIKeyHint(key)
+
+	respondWithJSON(w, http.StatusOK, models.DeviceKeyResponse{Device: device, APIKey: key})
+}
+
+// ListTaps handles GET /api/devices/{id}/taps?limit=
+func (h *DeviceHandler) ListTaps(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid device ID")
+		return
+	}
+
+	limit := defaultTapLogLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxTapLogLimit {
+			respondWithError(w, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxTapLogLimit))
+			return
+		}
+	}
+
+	if _, err := h.devices.GetByID(id); err != nil {
+		respondWithError(w, http.StatusNotFound, "Device not found")
+		return
+	}
+
+	taps, err := h.devices.ListTaps(id, limit)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to list taps")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, taps)
+}
+
+// RecordTap handles POST /api/devices/{id}/taps
+// เครื่องอ่านส่ง UID ของบัตรพร้อม API key (header X-Device-Key หรือ Authorization: Bearer)
+// แล้วทำรายการตาม role ของเครื่อง ผลลัพธ์อยู่ใน result และ message ให้เครื่องแสดง/ส่งเสียง
+func (h *DeviceHandler) RecordTap(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid device ID")
+		return
+	}
+
+	device, err := h.devices.GetByID(id)
+	if err != nil || !token.MatchAPIKey(DeviceKey(r), device.KeyHash) {
+		// ไม่บอกว่าเครื่องไม่มีอยู่หรือ key ผิด
+		respondWithError(w, http.StatusUnauthorized, "Invalid device or API key")
+		return
+	}
+	if !device.Active {
+		respondWithError(w, http.StatusForbidden, "Device is disabled")
+		return
+	}
+	if device.Role == models.DeviceRoleEdge {
+		respondWithError(w, http.StatusForbidden, "Edge gates sync records, they do not report taps")
+		return
+	}
+
+	var req models.TapRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	cardUID := strings.TrimSpace(req.CardUID)
+	if cardUID == "" {
+		respondWithError(w, http.StatusBadRequest, "cardUid is required")
+		return
+	}
+
+	tap := &models.DeviceTap{
+		DeviceID: device.ID,
+		CardUID:  cardUID,
+		Role:     device.Role,
+		TappedAt: h.clock.Now(),
+	}
+
+	repeat, err := h.devices.HasRecentTap(device.ID, cardUID, tapRepeatWindow)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to check recent taps")
+		return
+	}
+	if repeat {
+		tap.Result = models.TapRepeat
+		tap.Message = "แตะซ้ำ"
+	} else if err := h.route(tap); err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := h.devices.RecordTap(tap); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to record tap")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, tap)
+}
+
+// route performs the action of the reader's role and fills in the tap result
+func (h *DeviceHandler) route(tap *models.DeviceTap) error {
+	visitor, err := h.visitors.GetByRFID(tap.CardUID)
+	if err != nil {
+		tap.Result = models.TapUnknownCard
+		tap.Message = "ไม่พบบัตรนี้ในระบบ"
+		return nil
+	}
+	tap.VisitorID = &visitor.ID
+	tap.VisitorName = visitor.FirstName + " " + visitor.LastName
+
+	if visitor.ExitTime != nil {
+		tap.Result = models.TapRejected
+		tap.Message = "บัตรนี้คืนแล้ว เวลา " + h.clock.In(*visitor.ExitTime).Format("15:04")
+		return nil
+	}
+
+	switch tap.Role {
+	case models.DeviceRoleCheckIn:
+		switch visitor.ApprovalStatus {
+		case models.ApprovalPending:
+			tap.Result = models.TapRejected
+			tap.Message = "รอผู้รับนัดอนุมัติ"
+		case models.ApprovalRejected:
+			tap.Result = models.TapRejected
+			tap.Message = "การเข้าพบถูกปฏิเสธ"
+		default:
+			tap.Result = models.TapAccepted
+			tap.Message = "เชิญผ่าน " + tap.VisitorName
+		}
+
+	case models.DeviceRoleCheckOut:
+		clientID, err := newClientID("")
+		if err != nil {
+			return err
+		}
+		returned, err := returnVisitorCard(h.returns, h.clock, visitor, h.clock.In(tap.TappedAt).Format("15:04"), clientID)
+		if errors.Is(err, repository.ErrDuplicateReturn) {
+			tap.Result = models.TapRejected
+			tap.Message = "บัตรนี้ถูกคืนไปแล้ววันนี้"
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("Failed to return card")
+		}
+		publishCardReturned(h.bus, visitor, returned, &tap.DeviceID)
+		tap.Result = models.TapAccepted
+		tap.Message = "คืนบัตรสำเร็จ"
+
+	case models.DeviceRoleRollCall:
+		tap.Result = models.TapAccepted
+		tap.Message = "อยู่ในพื้นที่ เข้าเมื่อ " + h.clock.In(visitor.RegisteredAt).Format("15:04")
+
+	default:
+		return fmt.Errorf("Device role %q is not supported", tap.Role)
+	}
+
+	return nil
+}
+
+// DeviceKey reads the API key sent by a reader (X-Device-Key or Authorization: Bearer)
+func DeviceKey(r *http.Request) string {
+	if key := r.Header.Get("X-Device-Key"); key != "" {
+		return key
+	}
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimPrefix(auth, "Bearer ")
+	}
+	return ""
+}
+
+// applyDeviceRequest validates the request and copies it onto the device
+func applyDeviceRequest(d *models.Device, req models.SaveDeviceRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return fmt.Errorf("name is required")
+	}
+	switch req.Role {
+	case models.DeviceRoleCheckIn, models.DeviceRoleCheckOut, models.DeviceRoleRollCall, models.DeviceRoleEdge:
+	default:
+		return fmt.Errorf("role must be %s, %s, %s or %s",
+			models.DeviceRoleCheckIn, models.DeviceRoleCheckOut, models.DeviceRoleRollCall, models.DeviceRoleEdge)
+	}
+
+	d.Name = name
+	d.Location = strings.TrimSpace(req.Location)
+	d.Role = req.Role
+	if req.Active != nil {
+		d.Active = *req.Active
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"backend/internal/events"
+	"backend/internal/models"
+)
+
+// createDevice registers a reader through the API and returns it with its API key
+func (ts *testServer) createDevice(name, role string) (*models.Device, string) {
+	ts.t.Helper()
+	rec := ts.do("POST", "/api/devices", models.SaveDeviceRequest{Name: name, Location: "หน้าโรงเรียน", Role: role})
+	expectStatus(ts.t, rec, http.StatusCreated)
+	var created models.DeviceKeyResponse
+	decode(ts.t, rec, &created)
+	return created.Device, created.APIKey
+}
+
+// tap reports a card tap from the reader and returns what the reader is told
+func (ts *testServer) tap(device *models.Device, key, cardUID string) models.DeviceTap {
+	ts.t.Helper()
+	rec := ts.do("POST", "/api/devices/"+strconv.Itoa(device.ID)+"/taps", models.TapRequest{CardUID: cardUID}, "X-Device-Key", key)
+	expectStatus(ts.t, rec, http.StatusOK)
+	var tap models.DeviceTap
+	decode(ts.t, rec, &tap)
+	return tap
+}
+
+func TestDevices(t *testing.T) {
+	ts := newSQLiteServer(t)
+
+	device, key := ts.createDevice(" ประตูหน้า ", models.DeviceRoleCheckIn)
+	if device.Name != "ประตูหน้า" || !device.Active || device.KeyHint != key[:8] {
+		t.Errorf("created device = %+v, key %s", device, key)
+	}
+	expectStatus(t, ts.do("POST", "/api/devices", models.SaveDeviceRequest{Name: "ประตูหลัง", Role: "exit"}), http.StatusBadRequest)
+	expectStatus(t, ts.do("POST", "/api/devices", models.SaveDeviceRequest{Role: models.DeviceRoleCheckOut}), http.StatusBadRequest)
+	expectStatus(t, ts.do("POST", "/api/devices", "{"), http.StatusBadRequest)
+
+	path := "/api/devices/" + strconv.Itoa(device.ID)
+	inactive := false
+	rec := ts.do("PUT", path, models.SaveDeviceRequest{Name: "ประตูหลัง", Role: models.DeviceRoleCheckOut, Active: &inactive})
+	expectStatus(t, rec, http.StatusOK)
+	var updated models.Device
+	decode(t, rec, &updated)
+	if updated.Name != "ประตูหลัง" || updated.Role != models.DeviceRoleCheckOut || updated.Active {
+		t.Errorf("updated device = %+v", updated)
+	}
+	expectStatus(t, ts.do("PUT", path, models.SaveDeviceRequest{Name: "ประตูหลัง"}), http.StatusBadRequest)
+	expectStatus(t, ts.do("PUT", "/api/devices/99", models.SaveDeviceRequest{Name: "ประตูหลัง", Role: models.DeviceRoleCheckOut}), http.StatusNotFound)
+	expectStatus(t, ts.do("PUT", "/api/devices/x", models.SaveDeviceRequest{}), http.StatusBadRequest)
+
+	rec = ts.do("GET", "/api/devices", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var devices []models.Device
+	decode(t, rec, &devices)
+	if len(devices) != 1 || devices[0].ID != device.ID || devices[0].Active {
+		t.Errorf("devices = %+v", devices)
+	}
+
+	// key ใหม่ใช้แทน key เดิมทันที
+	rec = ts.do("POST", path+"/key", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var rotated models.DeviceKeyResponse
+	decode(t, rec, &rotated)
+	if rotated.APIKey == key || rotated.Device.KeyHint != rotated.APIKey[:8] {
+		t.Errorf("rotated key = %+v", rotated)
+	}
+	expectStatus(t, ts.do("POST", "/api/devices/99/key", nil), http.StatusNotFound)
+	expectStatus(t, ts.do("POST", "/api/devices/x/key", nil), http.StatusBadRequest)
+
+	taps := path + "/taps"
+	expectStatus(t, ts.do("POST", taps, models.TapRequest{CardUID: "RF0001"}, "X-Device-Key", key), http.StatusUnauthorized)
+	expectStatus(t, ts.do("POST", taps, models.TapRequest{CardUID: "RF0001"}, "Authorization", "Bearer "+rotated.APIKey), http.StatusForbidden)
+	expectStatus(t, ts.do("POST", "/api/devices/99/taps", models.TapRequest{CardUID: "RF0001"}, "X-Device-Key", rotated.APIKey), http.StatusUnauthorized)
+	expectStatus(t, ts.do("POST", "/api/devices/x/taps", models.TapRequest{CardUID: "RF0001"}), http.StatusBadRequest)
+
+	edge, edgeKey := ts.createDevice("edge หน้าโรงเรียน", models.DeviceRoleEdge)
+	expectStatus(t, ts.do("POST", "/api/devices/"+strconv.Itoa(edge.ID)+"/taps", models.TapRequest{CardUID: "RF0001"}, "X-Device-Key", edgeKey), http.StatusForbidden)
+
+	expectStatus(t, ts.do("GET", taps+"?limit=0", nil), http.StatusBadRequest)
+	expectStatus(t, ts.do("GET", "/api/devices/99/taps", nil), http.StatusNotFound)
+	expectStatus(t, ts.do("GET", "/api/devices/x/taps", nil), http.StatusBadRequest)
+}
+
+func TestRecordTap(t *testing.T) {
+	ts := newSQLiteServer(t)
+	v := ts.createVisitor(newVisitorRequest(1))
+	pending := ts.pendingVisitor(2, "ครูสมศรี")
+	if _, err := ts.db.Exec(`UPDATE visitors SET rfid = 'RF0002' WHERE id = ?`, pending.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	checkIn, checkInKey := ts.createDevice("ประตูเข้า", models.DeviceRoleCheckIn)
+	checkOut, checkOutKey := ts.createDevice("ประตูออก", models.DeviceRoleCheckOut)
+	rollCall, rollCallKey := ts.createDevice("จุดรวมพล", models.DeviceRoleRollCall)
+
+	t.Run("check-in", func(t *testing.T) {
+		tap := ts.tap(checkIn, checkInKey, "RF0001")
+		if tap.Result != models.TapAccepted || tap.VisitorID == nil || *tap.VisitorID != v.ID || tap.Message != "เชิญผ่าน สมชาย1 ใจดี" {
+			t.Errorf("check-in tap = %+v", tap)
+		}
+		if tap := ts.tap(checkIn, checkInKey, "RF0002"); tap.Result != models.TapRejected || tap.Message != "รอผู้รับนัดอนุมัติ" {
+			t.Errorf("tap of a pending visit = %+v", tap)
+		}
+		if tap := ts.tap(checkIn, checkInKey, "RF9999"); tap.Result != models.TapUnknownCard || tap.VisitorID != nil {
+			t.Errorf("tap of an unknown card = %+v", tap)
+		}
+	})
+
+	t.Run("repeat window", func(t *testing.T) {
+		// แตะค้างไว้ภายใน 3 วินาทีเป็นการแตะซ้ำ เกินจากนั้นทำรายการใหม่
+		ts.advance(2 * time.Second)
+		if tap := ts.tap(checkIn, checkInKey, "RF0001"); tap.Result != models.TapRepeat {
+			t.Errorf("tap within the window = %+v", tap)
+		}
+		ts.advance(2 * time.Second)
+		if tap := ts.tap(checkIn, checkInKey, "RF0001"); tap.Result != models.TapAccepted {
+			t.Errorf("tap after the window = %+v", tap)
+		}
+		// เครื่องอื่นไม่นับรวม
+		if tap := ts.tap(rollCall, rollCallKey, "RF0001"); tap.Result != models.TapAccepted {
+			t.Errorf("tap on another reader = %+v", tap)
+		}
+	})
+
+	t.Run("roll-call", func(t *testing.T) {
+		ts.advance(time.Minute)
+		if tap := ts.tap(rollCall, rollCallKey, "RF0001"); tap.Result != models.TapAccepted || tap.Message != "อยู่ในพื้นที่ เข้าเมื่อ 10:00" {
+			t.Errorf("roll-call tap = %+v", tap)
+		}
+	})
+
+	t.Run("check-out", func(t *testing.T) {
+		sub, _, _ := ts.bus.Subscribe(events.Filter{}, 0)
+		defer sub.Close()
+
+		ts.advance(time.Hour)
+		tap := ts.tap(checkOut, checkOutKey, "RF0001")
+		if tap.Result != models.TapAccepted || tap.Message != "คืนบัตรสำเร็จ" {
+			t.Fatalf("check-out tap = %+v", tap)
+		}
+		got, err := ts.visitors.GetByID(v.ID)
+		if err != nil || got.ExitTime == nil || !got.ExitTime.Equal(ts.clock.Now()) {
+			t.Errorf("visitor after check-out = %+v, %v", got, err)
+		}
+		if len(sub.C) != 1 {
+			t.Fatalf("%d events after check-out", len(sub.C))
+		}
+		if e := <-sub.C; e.Type != events.TypeCardReturned || *e.Data.(events.CardReturned).DeviceID != checkOut.ID {
+			t.Errorf("event = %+v", e)
+		}
+
+		// บัตรที่คืนแล้วแตะที่ไหนก็ไม่ผ่าน
+		ts.advance(time.Minute)
+		for _, device := range []*models.Device{checkIn, checkOut} {
+			key := checkInKey
+			if device == checkOut {
+				key = checkOutKey
+			}
+			if tap := ts.tap(device, key, "RF0001"); tap.Result != models.TapRejected || tap.Message != "บัตรนี้คืนแล้ว เวลา 11:01" {
+				t.Errorf("tap of a returned card on %s = %+v", device.Name, tap)
+			}
+		}
+	})
+
+	rec := ts.do("GET", "/api/devices/"+strconv.Itoa(checkIn.ID)+"/taps?limit=2", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var taps []models.DeviceTap
+	decode(t, rec, &taps)
+	if len(taps) != 2 || taps[0].Result != models.TapRejected || taps[1].Result != models.TapAccepted || taps[1].VisitorName != "สมชาย1 ใจดี" {
+		t.Errorf("latest check-in taps = %+v", taps)
+	}
+	expectStatus(t, ts.do("POST", "/api/devices/"+strconv.Itoa(checkIn.ID)+"/taps", models.TapRequest{CardUID: " "}, "X-Device-Key", checkInKey), http.StatusBadRequest)
+}
@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/clock"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/tenant"
+	"backend/internal/token"
+
+	"github.com/gorilla/mux"
+)
+
+// schoolCodePattern: code ใช้เป็น subdomain จึงรับเฉพาะตัวพิมพ์เล็ก ตัวเลข และขีด
+var schoolCodePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,30}[a-z0-9])?$`)
+
+// DistrictHandler serves the district staff: the schools and the cross-school report.
+// ทุก request ต้องส่ง DISTRICT_ADMIN_KEY เป็น Authorization: Bearer
+type DistrictHandler struct {
+	schools  *repository.SchoolRepository
+	stats    *repository.StatsRepository
+	clock    *clock.Clock
+	adminKey string
+	reset    func(schoolID int)
+}
+
+// NewDistrictHandler creates the handler. reset ถูกเรียกหลังแก้ค่าของโรงเรียน ให้ชุด handler เดิมถูกสร้างใหม่
+func NewDistrictHandler(schools *repository.SchoolRepository, stats *repository.StatsRepository, clk *clock.Clock, adminKey string, reset func(schoolID int)) *DistrictHandler {
+	if reset == nil {
+		reset = func(int) {}
+	}
+	return &DistrictHandler{schools: schools, stats: stats, clock: clk, adminKey: adminKey, reset: reset}
+}
+
+// authorize checks the admin key; ไม่ได้ตั้ง key = ปิด API ของเขต (ตอบ 404 เหมือนไม่มี route)
+func (h *DistrictHandler) authorize(w http.ResponseWriter, r *http.Request) bool {
+	if h.adminKey == "" {
+		respondWithError(w, http.StatusNotFound, "District API is disabled")
+		return false
+	}
+	key := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !token.MatchAPIKey(key, token.HashAPIKey(h.adminKey)) {
+		respondWithError(w, http.StatusUnauthorized, "Invalid district admin key")
+		return false
+	}
+	return true
+}
+
+// ListSchools handles GET /api/district/schools
+func (h *DistrictHandler) ListSchools(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+
+	schools, err := h.schools.List(false)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to list schools")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, schools)
+}
+
+// CreateSchool handles POST /api/district/schools
+func (h *DistrictHandler) CreateSchool(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+
+	var req models.SaveSchoolRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	school := &models.School{Active: true}
+	if err := applySchoolRequest(school, req); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if _, err := h.schools.GetByCode(school.Code); err == nil {
+		respondWithError(w, http.StatusConflict, "School code already exists")
+		return
+	}
+
+	if err := h.schools.Create(school); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to create school")
+		return
+	}
+
+	created, err := h.schools.GetByID(school.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get school")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, created)
+}
+
+// UpdateSchool handles PUT /api/district/schools/{id}
+func (h *DistrictHandler) UpdateSchool(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid school ID")
+		return
+	}
+
+	var req models.SaveSchoolRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	school, err := h.schools.GetByID(id)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "School not found")
+		return
+	}
+	if err := applySchoolRequest(school, req); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if other, err := h.schools.GetByCode(school.Code); err == nil && other.ID != school.ID {
+		respondWithError(w, http.StatusConflict, "School code already exists")
+		return
+	}
+
+	if err := h.schools.Update(school); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to update school")
+		return
+	}
+	h.reset(school.ID)
+
+	respondWithJSON(w, http.StatusOK, school)
+}
+
+// GetDistrictStats handles GET /api/district/stats?startDate=&endDate=
+// ยอดรวมของแต่ละโรงเรียนในช่วงวันที่ นับ "วัน" ตาม timezone ของแต่ละโรงเรียน (ค่าเริ่มต้น 30 วันล่าสุด)
+func (h *DistrictHandler) GetDistrictStats(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+
+	query := r.URL.Query()
+	startDate, err := parseDateParam("startDate", query.Get("startDate"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	endDate, err := parseDateParam("endDate", query.Get("endDate"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if endDate == "" {
+		endDate = h.clock.Today()
+	}
+	end, _ := time.Parse("2006-01-02", endDate)
+	if startDate == "" {
+		startDate = end.AddDate(0, 0, -(defaultStatsDays - 1)).Format("2006-01-02")
+	}
+	start, _ := time.Parse("2006-01-02", startDate)
+	if start.After(end) {
+		respondWithError(w, http.StatusBadRequest, "startDate must not be after endDate")
+		return
+	}
+
+	schools, err := h.schools.List(false)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to list schools")
+		return
+	}
+
+	resp := models.DistrictStatsResponse{
+		StartDate: startDate,
+		EndDate:   endDate,
+		Schools:   []models.DistrictSchoolStats{},
+	}
+	var totalMinutes float64
+	for i := range schools {
+		school := &schools[i]
+		clk, err := tenant.Clock(school, h.clock)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		from, _ := clk.StartOfDay(startDate)
+		before, _ := clk.EndOfDay(endDate)
+
+		totals, err := h.stats.ForSchool(school.ID).Totals(from, before)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Failed to get statistics")
+			return
+		}
+
+		resp.Schools = append(resp.Schools, models.DistrictSchoolStats{
+			SchoolID: school.ID,
+			Code:     school.Code,
+			Name:     school.Name,
+			Totals:   totals,
+		})
+		resp.Totals.Visits += totals.Visits
+		resp.Totals.CardsReturned += totals.CardsReturned
+		resp.Totals.CardsUnreturned += totals.CardsUnreturned
+		totalMinutes += totals.AverageDurationMinutes * float64(totals.CardsReturned)
+	}
+	if resp.Totals.CardsReturned > 0 {
+		resp.Totals.AverageDurationMinutes = totalMinutes / float64(resp.Totals.CardsReturned)
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// applySchoolRequest validates the request and copies it onto the school
+func applySchoolRequest(s *models.School, req models.SaveSchoolRequest) error {
+	code := strings.ToLower(strings.TrimSpace(req.Code))
+	if !schoolCodePattern.MatchString(code) {
+		return fmt.Errorf("code must be 1-32 lowercase letters, digits or '-'")
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return fmt.Errorf("name is required")
+	}
+	timezone := strings.TrimSpace(req.Timezone)
+	if timezone != "" {
+		if _, err := clock.Load(timezone); err != nil {
+			return fmt.Errorf("Invalid timezone %q", timezone)
+		}
+	}
+	if req.RetentionDays < 0 {
+		return fmt.Errorf("retentionDays must not be negative")
+	}
+
+	s.Code = code
+	s.Name = name
+	s.Timezone = timezone
+	s.RetentionDays = req.RetentionDays
+	if req.Active != nil {
+		s.Active = *req.Active
+	}
+	return nil
+}
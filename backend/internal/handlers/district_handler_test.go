@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"backend/internal/clock"
+	"backend/internal/database"
+	"backend/internal/migrate"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/migrations"
+
+	"github.com/gorilla/mux"
+)
+
+const testAdminKey = "district-secret"
+
+// newDistrictServer serves the district routes on a migrated SQLite database
+func newDistrictServer(t *testing.T, adminKey string, reset func(int)) (*testServer, *repository.VisitorRepository) {
+	t.Helper()
+
+	db, err := database.NewDatabase(database.Config{Driver: "sqlite", Path: filepath.Join(t.TempDir(), "district.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	fsys, err := migrations.For("sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner, err := migrate.NewRunner(db.DB, fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runner.Up(context.Background(), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// วันที่ของรายงานใช้เวลาจริง เพราะ registered_at มาจาก DEFAULT ของฐานข้อมูล
+	clk, err := clock.Load("Asia/Bangkok")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts := &testServer{t: t, router: mux.NewRouter(), clock: clk}
+	DistrictRoutes{
+		District: NewDistrictHandler(repository.NewSchoolRepository(db.DB), repository.NewStatsRepository(db.DB, clk), clk, adminKey, reset),
+	}.Register(ts.router.PathPrefix("/api").Subrouter())
+
+	return ts, repository.NewVisitorRepository(db.DB, clk)
+}
+
+func TestDistrictAdminKey(t *testing.T) {
+	ts, _ := newDistrictServer(t, testAdminKey, nil)
+	expectStatus(t, ts.do("GET", "/api/district/schools", nil), http.StatusUnauthorized)
+	expectStatus(t, ts.do("GET", "/api/district/schools", nil, "Authorization", "Bearer wrong"), http.StatusUnauthorized)
+	expectStatus(t, ts.do("GET", "/api/district/schools", nil, "Authorization", "Bearer "+testAdminKey), http.StatusOK)
+
+	// ไม่ได้ตั้ง DISTRICT_ADMIN_KEY = ปิด
+	disabled, _ := newDistrictServer(t, "", nil)
+	expectStatus(t, disabled.do("GET", "/api/district/stats", nil, "Authorization", "Bearer "), http.StatusNotFound)
+}
+
+func TestDistrictSchools(t *testing.T) {
+	var reset []int
+	ts, _ := newDistrictServer(t, testAdminKey, func(id int) { reset = append(reset, id) })
+	auth := []string{"Authorization", "Bearer " + testAdminKey}
+
+	rec := ts.do("POST", "/api/district/schools", models.SaveSchoolRequest{
+		Code:          " School-B ",
+		Name:          "โรงเรียนบ้านเหนือ",
+		Timezone:      "Asia/Bangkok",
+		RetentionDays: 365,
+	}, auth...)
+	expectStatus(t, rec, http.StatusCreated)
+	var created models.School
+	decode(t, rec, &created)
+	if created.Code != "school-b" || !created.Active || created.RetentionDays != 365 {
+		t.Errorf("created = %+v", created)
+	}
+
+	invalid := []models.SaveSchoolRequest{
+		{Code: "school b", Name: "x"},
+		{Code: "school-c"},
+		{Code: "school-c", Name: "x", Timezone: "Mars/Olympus"},
+		{Code: "school-c", Name: "x", RetentionDays: -1},
+	}
+	for _, req := range invalid {
+		expectStatus(t, ts.do("POST", "/api/district/schools", req, auth...), http.StatusBadRequest)
+	}
+	expectStatus(t, ts.do("POST", "/api/district/schools", models.SaveSchoolRequest{Code: "main", Name: "ซ้ำ"}, auth...), http.StatusConflict)
+
+	inactive := false
+	rec = ts.do("PUT", "/api/district/schools/2", models.SaveSchoolRequest{Code: "school-b", Name: "โรงเรียนบ้านใต้", Active: &inactive}, auth...)
+	expectStatus(t, rec, http.StatusOK)
+	if len(reset) != 1 || reset[0] != 2 {
+		t.Errorf("reset = %v, want [2]", reset)
+	}
+	expectStatus(t, ts.do("PUT", "/api/district/schools/2", models.SaveSchoolRequest{Code: "main", Name: "x"}, auth...), http.StatusConflict)
+	expectStatus(t, ts.do("PUT", "/api/district/schools/99", models.SaveSchoolRequest{Code: "x", Name: "x"}, auth...), http.StatusNotFound)
+	expectStatus(t, ts.do("PUT", "/api/district/schools/x", `{}`, auth...), http.StatusBadRequest)
+
+	rec = ts.do("GET", "/api/district/schools", nil, auth...)
+	expectStatus(t, rec, http.StatusOK)
+	var schools []models.School
+	decode(t, rec, &schools)
+	if len(schools) != 2 {
+		t.Fatalf("schools = %+v", schools)
+	}
+	for _, s := range schools {
+		if s.Code == "school-b" && (s.Active || s.Name != "โรงเรียนบ้านใต้") {
+			t.Errorf("school-b = %+v", s)
+		}
+	}
+}
+
+func TestDistrictStats(t *testing.T) {
+	ts, visitors := newDistrictServer(t, testAdminKey, nil)
+	auth := []string{"Authorization", "Bearer " + testAdminKey}
+
+	expectStatus(t, ts.do("POST", "/api/district/schools", models.SaveSchoolRequest{Code: "school-b", Name: "โรงเรียนบ้านเหนือ"}, auth...), http.StatusCreated)
+
+	// บัตรประชาชนเดียวกันลงทะเบียนได้ทั้งสองโรงเรียน
+	for i, school := range []int{models.DefaultSchoolID, 2, 2} {
+		v := &models.Visitor{
+			IDCard: "1101234500001", FirstName: "สมชาย", LastName: "ใจดี", Phone: "0812345678",
+			Province: "กรุงเทพมหานคร", District: "ดุสิต", PostalCode: "10300",
+			Department: "ฝ่ายวิชาการ", OfficerName: "ยามหน้า", ApprovalStatus: models.ApprovalNotRequired,
+		}
+		if i == 2 {
+			v.IDCard = "1101234500002"
+		}
+		if err := visitors.ForSchool(school).Create(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expectStatus(t, ts.do("GET", "/api/district/stats?startDate=2025-06-02&endDate=2025-06-01", nil, auth...), http.StatusBadRequest)
+	expectStatus(t, ts.do("GET", "/api/district/stats?endDate=bad", nil, auth...), http.StatusBadRequest)
+
+	rec := ts.do("GET", "/api/district/stats", nil, auth...)
+	expectStatus(t, rec, http.StatusOK)
+	var stats models.DistrictStatsResponse
+	decode(t, rec, &stats)
+
+	visits := map[string]int{}
+	for _, s := range stats.Schools {
+		visits[s.Code] = s.Totals.Visits
+	}
+	if visits["main"] != 1 || visits["school-b"] != 2 || stats.Totals.Visits != 3 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestGetSchool(t *testing.T) {
+	ts := newTestServer(t)
+	rec := ts.do("GET", "/api/school", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var school models.School
+	decode(t, rec, &school)
+	if school.ID != models.DefaultSchoolID || school.Code != "main" {
+		t.Errorf("school = %+v", school)
+	}
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/events"
+)
+
+// sseHeartbeat ส่ง comment เป็นระยะ กัน proxy/firewall ตัดการเชื่อมต่อที่เงียบนาน
+const sseHeartbeat = 25 * time.Second
+
+// sseRetry คือเวลาที่ EventSource รอก่อนต่อใหม่ (มิลลิวินาที)
+const sseRetry = 3000
+
+type EventHandler struct {
+	bus *events.Bus
+}
+
+func NewEventHandler(bus *events.Bus) *EventHandler {
+	return &EventHandler{bus: bus}
+}
+
+// StreamEvents handles GET /api/events?department=&type=
+// ส่งเหตุการณ์แบบ Server-Sent Events ให้จอของป้อมยาม
+// department และ type ใส่ได้หลายค่า (ซ้ำ key หรือคั่นด้วย ,)
+// ต่อใหม่ด้วย header Last-Event-ID (EventSource ส่งให้เอง) หรือ ?lastEventId= จะได้เหตุการณ์ที่พลาดไป
+// ถ้าพลาดเกินที่เก็บไว้ จะได้ event "reset" ให้โหลดรายการใหม่จาก REST API
+func (h *EventHandler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := events.Filter{
+		Departments: splitValues(query["department"]),
+		Types:       splitValues(query["type"]),
+	}
+
+	lastID, err := lastEventID(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Last-Event-ID")
+		return
+	}
+
+	// stream อยู่นานกว่า WriteTimeout ของ server จึงต้องปิด deadline ของ request นี้
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Streaming is not supported")
+		return
+	}
+
+	sub, replay, complete := h.bus.Subscribe(filter, lastID)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // nginx ไม่ต้อง buffer
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", sseRetry)
+	if !complete {
+		fmt.Fprint(w, "event: reset\ndata: {}\n\n")
+	}
+	for _, e := range replay {
+		if err := writeEvent(w, e); err != nil {
+			return
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, ok := <-sub.C:
+			if !ok {
+				// อ่านไม่ทัน bus ตัดการเชื่อมต่อ client จะต่อใหม่พร้อม Last-Event-ID
+				return
+			}
+			if err := writeEvent(w, e); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, e events.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		log.Printf("Failed to encode event %d: %v", e.ID, err)
+		return nil
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+	return err
+}
+
+func lastEventID(r *http.Request) (int64, error) {
+	raw := r.Header.Get("Last-Event-ID")
+	if raw == "" {
+		raw = r.URL.Query().Get("lastEventId")
+	}
+	if raw == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+}
+
+// splitValues accepts both ?x=a&x=b and ?x=a,b
+func splitValues(values []string) []string {
+	var out []string
+	for _, v := range values {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				out = append(out, part)
+			}
+		}
+	}
+	return out
+}
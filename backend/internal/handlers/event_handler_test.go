@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/events"
+)
+
+// ต้องใช้ server จริง เพราะ ResponseRecorder ตั้ง write deadline ไม่ได้
+func TestStreamEvents(t *testing.T) {
+	ts := newTestServer(t)
+	srv := httptest.NewServer(ts.router)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", srv.URL+"/api/events?type="+events.TypeVisitorRegistered, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts.cover(req)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("stream status = %d, Content-Type = %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	ts.createVisitor(newVisitorRequest(1))
+
+	lines := bufio.NewScanner(resp.Body)
+	var got []string
+	for lines.Scan() {
+		line := lines.Text()
+		if line == "" && len(got) > 0 {
+			break
+		}
+		if strings.HasPrefix(line, "event:") || strings.HasPrefix(line, "data:") {
+			got = append(got, line)
+		}
+	}
+	if len(got) != 2 || got[0] != "event: "+events.TypeVisitorRegistered || !strings.Contains(got[1], "RF0001") {
+		t.Errorf("streamed event = %q (scan error %v)", got, lines.Err())
+	}
+
+	expectStatus(t, ts.do("GET", "/api/events", nil, "Last-Event-ID", "abc"), http.StatusBadRequest)
+}
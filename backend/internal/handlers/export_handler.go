@@ -3,21 +3,23 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
-	"time"
 
+	"backend/internal/clock"
 	"backend/internal/models"
 	"backend/internal/repository"
 )
 
 type ExportHandler struct {
-	visitorRepo *repository.VisitorRepository
-	exportRepo  *repository.ExportRepository
+	visitorRepo repository.VisitorStore
+	exportRepo  repository.ExportStore
+	clock       *clock.Clock
 }
 
-func NewExportHandler(visitorRepo *repository.VisitorRepository, exportRepo *repository.ExportRepository) *ExportHandler {
+func NewExportHandler(visitorRepo repository.VisitorStore, exportRepo repository.ExportStore, clk *clock.Clock) *ExportHandler {
 	return &ExportHandler{
 		visitorRepo: visitorRepo,
 		exportRepo:  exportRepo,
+		clock:       clk,
 	}
 }
 
@@ -42,7 +44,7 @@ func (h *ExportHandler) CreateExportHistory(w http.ResponseWriter, r *http.Reque
 
 	// Set created time if not provided
 	if req.ExportDate == "" {
-		req.ExportDate = time.Now().Format("2006-01-02 15:04:05")
+		req.ExportDate = h.clock.In(h.clock.Now()).Format("2006-01-02 15:04:05")
 	}
 
 	record, err := h.exportRepo.Create(&req)
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"backend/internal/models"
+)
+
+func TestExportHistory(t *testing.T) {
+	ts := newTestServer(t)
+
+	rec := ts.do("GET", "/api/export-history", nil)
+	expectStatus(t, rec, http.StatusOK)
+	// ExportRepository ตอบ null เมื่อยังไม่มีประวัติ
+	if body := rec.Body.String(); body != "null" {
+		t.Errorf("empty history = %q", body)
+	}
+
+	rec = ts.do("POST", "/api/export-history", models.ExportRecord{Department: "ฝ่ายวิชาการ", Format: "xlsx"})
+	expectStatus(t, rec, http.StatusCreated)
+	var created models.ExportRecord
+	decode(t, rec, &created)
+	if created.ID == 0 || created.ExportDate != "2025-06-02 10:00:00" {
+		t.Errorf("created record = %+v", created)
+	}
+
+	ts.do("POST", "/api/export-history", models.ExportRecord{Department: "ฝ่ายบุคคล", Format: "csv", ExportDate: "2025-05-01 09:00:00"})
+	rec = ts.do("GET", "/api/export-history", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var history []models.ExportRecord
+	decode(t, rec, &history)
+	if len(history) != 2 || history[0].Department != "ฝ่ายวิชาการ" {
+		t.Errorf("history = %+v", history)
+	}
+
+	expectStatus(t, ts.do("POST", "/api/export-history", "{"), http.StatusBadRequest)
+}
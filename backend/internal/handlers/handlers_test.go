@@ -0,0 +1,337 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/internal/address"
+	"backend/internal/badge"
+	"backend/internal/clock"
+	"backend/internal/database"
+	"backend/internal/events"
+	"backend/internal/migrate"
+	"backend/internal/models"
+	"backend/internal/notify"
+	"backend/internal/repository"
+	"backend/internal/repository/memory"
+	"backend/internal/storage"
+	"backend/internal/thaidate"
+	"backend/internal/token"
+	"backend/migrations"
+
+	"github.com/gorilla/mux"
+)
+
+// testServer is the API wired like main.
+// newTestServer เก็บผู้มาติดต่อ การส่งออก ส่วนงาน และรายชื่อเฝ้าระวังใน memory store
+// newSQLiteServer เก็บทุกอย่างใน SQLite ส่วน repository ที่ไม่มี memory store (นักเรียน เครื่องอ่านบัตร นัดหมาย ...)
+// ใช้ SQLite ของ test ทั้งสองแบบ
+type testServer struct {
+	t           *testing.T
+	router      *mux.Router
+	db          *sql.DB
+	visitors    visitStore
+	exports     repository.ExportStore
+	departments repository.DepartmentStore
+	watchlist   repository.WatchlistStore
+	files       storage.Storage
+	signer      *token.Signer
+	bus         *events.Bus
+	clock       *clock.Clock
+
+	mu  sync.Mutex
+	now time.Time
+}
+
+// visitStore is what both the memory store and VisitorRepository provide to the handlers
+type visitStore interface {
+	repository.VisitorStore
+	repository.ReturnLogStore
+}
+
+// testNow is 10:00 school time (Asia/Bangkok) on 2 June 2025
+var testNow = time.Date(2025, 6, 2, 3, 0, 0, 0, time.UTC)
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	ts := newClockedServer(t)
+	visitors := memory.NewStore(ts.clock)
+	ts.register(visitors, memory.NewExportStore(), memory.NewDepartmentStore(visitors), memory.NewWatchlistStore(ts.clock.Now))
+	return ts
+}
+
+// newSQLiteServer keeps the visits in SQLite too, for the routes whose queries join the visitors table
+// (การรับนักเรียน ค้นหา สถิติ รถ และเครื่องอ่านบัตร)
+func newSQLiteServer(t *testing.T) *testServer {
+	t.Helper()
+	ts := newClockedServer(t)
+	ts.register(repository.NewVisitorRepository(ts.db, ts.clock), repository.NewExportRepository(ts.db),
+		repository.NewDepartmentRepository(ts.db), repository.NewWatchlistRepository(ts.db))
+	return ts
+}
+
+// newClockedServer returns a server with its test clock and database, before the routes are registered
+func newClockedServer(t *testing.T) *testServer {
+	t.Helper()
+
+	ts := &testServer{t: t, now: testNow, db: newTestDB(t)}
+	bangkok, err := clock.Load("Asia/Bangkok")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts.clock = bangkok.WithNow(func() time.Time {
+		ts.mu.Lock()
+		defer ts.mu.Unlock()
+		return ts.now
+	})
+	return ts
+}
+
+func (ts *testServer) register(visitors visitStore, exports repository.ExportStore, departments repository.DepartmentStore, watchlist repository.WatchlistStore) {
+	t := ts.t
+	t.Helper()
+
+	addresses, err := address.Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := storage.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	renderer, err := badge.NewRenderer(badge.DefaultLayout())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts.visitors = visitors
+	ts.exports = exports
+	ts.departments = departments
+	for i, name := range []string{"ฝ่ายวิชาการ", "ฝ่ายบุคคล"} {
+		if err := ts.departments.Create(&models.Department{NameTh: name, Active: true, DisplayOrder: i + 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ts.watchlist = watchlist
+	ts.files = files
+	ts.bus = events.New(50)
+
+	db, clk := ts.db, ts.clock
+	signer := token.NewSigner("test-secret").WithNow(clk.Now)
+	ts.signer = signer
+	notifier := discardNotifier{}
+	dates := thaidate.Formatter{Location: clk.Location()}
+	appointments := repository.NewAppointmentRepository(db, clk)
+	purposes := repository.NewPurposeRepository(db)
+	devices := repository.NewDeviceRepository(db, clk)
+
+	ts.router = mux.NewRouter()
+	Routes{
+		Visitor:     NewVisitorHandler(visitors, visitors, addresses, appointments, purposes, departments, watchlist, signer, notifier, dates, clk, ts.bus),
+		Export:      NewExportHandler(visitors, exports, clk),
+		Search:      NewSearchHandler(repository.NewSearchRepository(db, clk)),
+		Vehicle:     NewVehicleHandler(repository.NewVehicleRepository(db, clk)),
+		Address:     NewAddressHandler(addresses),
+		Appointment: NewAppointmentHandler(appointments, signer),
+		Purpose:     NewPurposeHandler(purposes),
+		Department:  NewDepartmentHandler(departments),
+		Watchlist:   NewWatchlistHandler(watchlist),
+		Student:     NewStudentHandler(repository.NewStudentRepository(db, clk), repository.NewPickupRepository(db, clk), visitors, notifier, clk, ts.bus),
+		Photo:       NewPhotoHandler(visitors, files, 0),
+		Badge:       NewBadgeHandler(visitors, purposes, files, signer, renderer, clk),
+		SmartCard:   NewSmartCardHandler(visitors, addresses, clk),
+		Device:      NewDeviceHandler(devices, visitors, visitors, clk, ts.bus),
+		Event:       NewEventHandler(ts.bus),
+		Stats:       NewStatsHandler(repository.NewStatsRepository(db, clk), departments, clk, dates),
+		Sync:        NewSyncHandler(devices, visitors, visitors, departments, watchlist, nil, clk, ts.bus),
+		School:      NewSchoolHandler(&models.School{ID: models.DefaultSchoolID, Code: "main", Name: "โรงเรียน", Active: true}),
+	}.Register(ts.router.PathPrefix("/api").Subrouter())
+}
+
+// advance moves the clock forward
+func (ts *testServer) advance(d time.Duration) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.now = ts.now.Add(d)
+}
+
+// do sends a request through the router; body is JSON-encoded unless it is already an io.Reader
+func (ts *testServer) do(method, path string, body interface{}, headers ...string) *httptest.ResponseRecorder {
+	ts.t.Helper()
+
+	var reader io.Reader
+	switch b := body.(type) {
+	case nil:
+	case io.Reader:
+		reader = b
+	case string:
+		reader = strings.NewReader(b)
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			ts.t.Fatal(err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	ts.cover(req)
+
+	rec := httptest.NewRecorder()
+	ts.router.ServeHTTP(rec, req)
+	return rec
+}
+
+// createVisitor registers a visitor through the API and returns it
+func (ts *testServer) createVisitor(req models.CreateVisitorRequest) models.Visitor {
+	ts.t.Helper()
+	rec := ts.do("POST", "/api/visitors", req)
+	expectStatus(ts.t, rec, http.StatusCreated)
+	var v models.Visitor
+	decode(ts.t, rec, &v)
+	return v
+}
+
+// newVisitorRequest is a valid registration; n makes the ID card and RFID unique
+func newVisitorRequest(n int) models.CreateVisitorRequest {
+	return models.CreateVisitorRequest{
+		IDCard:      fmt.Sprintf("11012345%05d", n),
+		FirstName:   fmt.Sprintf("สมชาย%d", n),
+		LastName:    "ใจดี",
+		Phone:       "0812345678",
+		Province:    "กรุงเทพมหานคร",
+		District:    "ดุสิต",
+		PostalCode:  "10300",
+		RFID:        fmt.Sprintf("RF%04d", n),
+		Department:  "ฝ่ายวิชาการ",
+		OfficerName: "ยามหน้า",
+	}
+}
+
+func expectStatus(t *testing.T, rec *httptest.ResponseRecorder, want int) {
+	t.Helper()
+	if rec.Code != want {
+		t.Fatalf("status = %d, want %d, body: %s", rec.Code, want, rec.Body.String())
+	}
+}
+
+func decode(t *testing.T, rec *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("failed to decode %s: %v", rec.Body.String(), err)
+	}
+}
+
+// errorMessage returns the "error" field of an error response
+func errorMessage(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body map[string]string
+	decode(t, rec, &body)
+	return body["error"]
+}
+
+type discardNotifier struct{}
+
+func (discardNotifier) Notify(notify.Message) error { return nil }
+
+// Route coverage: ทุก route ที่ Register เพิ่มต้องมี test เรียกอย่างน้อยหนึ่งครั้ง
+
+var (
+	coveredMu sync.Mutex
+	covered   = map[string]bool{}
+)
+
+// cover records which route a request matched
+func (ts *testServer) cover(req *http.Request) {
+	var match mux.RouteMatch
+	if !ts.router.Match(req, &match) || match.Route == nil {
+		return
+	}
+	tpl, err := match.Route.GetPathTemplate()
+	if err != nil {
+		return
+	}
+	coveredMu.Lock()
+	covered[req.Method+" "+tpl] = true
+	coveredMu.Unlock()
+}
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+
+	// ตรวจเฉพาะตอนรันทุก test (ไม่ได้ใช้ -run)
+	if code == 0 && flag.Lookup("test.run").Value.String() == "" {
+		if missing := uncoveredRoutes(); len(missing) > 0 {
+			fmt.Fprintf(os.Stderr, "routes without a handler test:\n  %s\n", strings.Join(missing, "\n  "))
+			code = 1
+		}
+	}
+	os.Exit(code)
+}
+
+func uncoveredRoutes() []string {
+	router := mux.NewRouter()
+	Routes{}.Register(router.PathPrefix("/api").Subrouter())
+	DistrictRoutes{}.Register(router.PathPrefix("/api").Subrouter())
+
+	var missing []string
+	router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		tpl, err1 := route.GetPathTemplate()
+		methods, err2 := route.GetMethods()
+		if err1 != nil || err2 != nil {
+			return nil
+		}
+		for _, method := range methods {
+			if !covered[method+" "+tpl] {
+				missing = append(missing, method+" "+tpl)
+			}
+		}
+		return nil
+	})
+	sort.Strings(missing)
+	return missing
+}
+
+// newTestDB returns an empty SQLite database migrated to the latest version
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := database.NewDatabase(database.Config{
+		Driver: string(database.SQLite),
+		Path:   filepath.Join(t.TempDir(), "visitor_system.db"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	fsys, err := migrations.For(string(database.SQLite))
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner, err := migrate.NewRunner(db.DB, fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runner.Up(context.Background(), 0); err != nil {
+		t.Fatal(err)
+	}
+	return db.DB
+}
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/photo"
+	"backend/internal/repository"
+	"backend/internal/storage"
+
+	"github.com/gorilla/mux"
+)
+
+type PhotoHandler struct {
+	repo     repository.VisitorStore
+	store    storage.Storage
+	maxBytes int
+}
+
+func NewPhotoHandler(repo repository.VisitorStore, store storage.Storage, maxBytes int) *PhotoHandler {
+	if maxBytes <= 0 {
+		maxBytes = photo.DefaultMaxBytes
+	}
+	return &PhotoHandler{repo: repo, store: store, maxBytes: maxBytes}
+}
+
+// UploadPhoto handles POST /api/visitors/{id}/photo (multipart field "photo")
+// รับรูปจากกล้องหน้าป้อม ถ้ามีรูปเดิมอยู่แล้วจะถูกแทนที่
+func (h *PhotoHandler) UploadPhoto(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid visitor ID")
+		return
+	}
+
+	// เผื่อที่ให้ส่วนหัวของ multipart
+	r.Body = http.MaxBytesReader(w, r.Body, int64(h.maxBytes)+64<<10)
+	file, _, err := r.FormFile("photo")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondWithError(w, http.StatusRequestEntityTooLarge, photo.ErrTooLarge.Error())
+			return
+		}
+		respondWithError(w, http.StatusBadRequest, "photo file is required")
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, int64(h.maxBytes)+1))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Failed to read photo")
+		return
+	}
+
+	p, err := photo.Process(data, h.maxBytes)
+	if err != nil {
+		switch {
+		case errors.Is(err, photo.ErrTooLarge):
+			respondWithError(w, http.StatusRequestEntityTooLarge, err.Error())
+		case errors.Is(err, photo.ErrUnsupportedType):
+			respondWithError(w, http.StatusUnsupportedMediaType, err.Error())
+		case errors.Is(err, photo.ErrInvalidImage):
+			respondWithError(w, http.StatusBadRequest, err.Error())
+		default:
+			respondWithError(w, http.StatusInternalServerError, "Failed to process photo")
+		}
+		return
+	}
+
+	visitor, err := h.repo.GetByID(id)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Visitor not found")
+		return
+	}
+
+	// key มีเวลากำกับ เพื่อไม่ให้ browser ใช้รูปเก่าที่ cache ไว้
+	stamp := time.Now().UnixNano()
+	photoKey := fmt.Sprintf("visitors/%d/photo-%d%s", id, stamp, p.Ext())
+	thumbnailKey := fmt.Sprintf("visitors/%d/thumb-%d.jpg", id, stamp)
+
+	if err := h.store.Put(photoKey, p.Data); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to store photo")
+		return
+	}
+	if err := h.store.Put(thumbnailKey, p.Thumbnail); err != nil {
+		h.store.Delete(photoKey)
+		respondWithError(w, http.StatusInternalServerError, "Failed to store thumbnail")
+		return
+	}
+
+	oldPhoto, oldThumbnail, err := h.repo.SetPhoto(id, photoKey, thumbnailKey)
+	if err != nil {
+		h.store.Delete(photoKey)
+		h.store.Delete(thumbnailKey)
+		respondWithError(w, http.StatusInternalServerError, "Failed to save photo")
+		return
+	}
+	for _, key := range []string{oldPhoto, oldThumbnail} {
+		if key == "" {
+			continue
+		}
+		if err := h.store.Delete(key); err != nil {
+			log.Printf("Failed to delete old photo %s: %v", key, err)
+		}
+	}
+
+	now := time.Now()
+	visitor.PhotoKey = photoKey
+	visitor.PhotoThumbnailKey = thumbnailKey
+	visitor.PhotoTakenAt = &now
+	setPhotoURLs(visitor)
+
+	respondWithJSON(w, http.StatusCreated, visitor)
+}
+
+// GetPhoto handles GET /api/visitors/{id}/photo
+func (h *PhotoHandler) GetPhoto(w http.ResponseWriter, r *http.Request) {
+	h.servePhoto(w, r, false)
+}
+
+// GetThumbnail handles GET /api/visitors/{id}/photo/thumbnail
+func (h *PhotoHandler) GetThumbnail(w http.ResponseWriter, r *http.Request) {
+	h.servePhoto(w, r, true)
+}
+
+func (h *PhotoHandler) servePhoto(w http.ResponseWriter, r *http.Request, thumbnail bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid visitor ID")
+		return
+	}
+
+	visitor, err := h.repo.GetByID(id)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Visitor not found")
+		return
+	}
+
+	key := visitor.PhotoKey
+	if thumbnail {
+		key = visitor.PhotoThumbnailKey
+	}
+	if key == "" {
+		respondWithError(w, http.StatusNotFound, "Photo not found")
+		return
+	}
+
+	data, err := readStored(h.store, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			respondWithError(w, http.StatusNotFound, "Photo not found")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to read photo")
+		return
+	}
+
+	w.Header().Set("Content-Type", http.DetectContentType(data))
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// readStored reads a whole file from storage
+func readStored(store storage.Storage, key string) ([]byte, error) {
+	f, err := store.Open(key)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// setPhotoURLs fills the photo links of a visitor that has a photo
+func setPhotoURLs(v *models.Visitor) {
+	if v.PhotoKey != "" {
+		v.PhotoURL = fmt.Sprintf("/api/visitors/%d/photo", v.ID)
+	}
+	v.ThumbnailURL = thumbnailURL(v)
+}
+
+func thumbnailURL(v *models.Visitor) string {
+	if v.PhotoThumbnailKey == "" {
+		return ""
+	}
+	return fmt.Sprintf("/api/visitors/%d/photo/thumbnail", v.ID)
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/token"
+)
+
+// photoUpload builds the multipart body sent by the gate camera
+func photoUpload(t *testing.T, data []byte) (*bytes.Buffer, string) {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile("photo", "photo.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(data)
+	mw.Close()
+	return &body, mw.FormDataContentType()
+}
+
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 320, 240))
+	for x := 0; x < 320; x++ {
+		for y := 0; y < 240; y++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 128, 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestUploadPhoto(t *testing.T) {
+	ts := newTestServer(t)
+	v := ts.createVisitor(newVisitorRequest(1))
+	path := "/api/visitors/" + strconv.Itoa(v.ID) + "/photo"
+
+	expectStatus(t, ts.do("GET", path, nil), http.StatusNotFound)
+
+	body, contentType := photoUpload(t, []byte("not an image"))
+	expectStatus(t, ts.do("POST", path, body, "Content-Type", contentType), http.StatusUnsupportedMediaType)
+
+	body, contentType = photoUpload(t, testPNG(t))
+	expectStatus(t, ts.do("POST", "/api/visitors/99/photo", body, "Content-Type", contentType), http.StatusNotFound)
+
+	body, contentType = photoUpload(t, testPNG(t))
+	rec := ts.do("POST", path, body, "Content-Type", contentType)
+	expectStatus(t, rec, http.StatusCreated)
+	var uploaded models.Visitor
+	decode(t, rec, &uploaded)
+	if uploaded.PhotoURL != path || uploaded.ThumbnailURL != path+"/thumbnail" {
+		t.Errorf("photo URLs = %q, %q", uploaded.PhotoURL, uploaded.ThumbnailURL)
+	}
+
+	rec = ts.do("GET", path, nil)
+	expectStatus(t, rec, http.StatusOK)
+	if ct := rec.Header().Get("Content-Type"); ct != "image/png" {
+		t.Errorf("photo Content-Type = %q", ct)
+	}
+	rec = ts.do("GET", path+"/thumbnail", nil)
+	expectStatus(t, rec, http.StatusOK)
+	if ct := rec.Header().Get("Content-Type"); ct != "image/jpeg" {
+		t.Errorf("thumbnail Content-Type = %q", ct)
+	}
+
+	// รูปใหม่แทนที่รูปเดิม และลบไฟล์เดิมทิ้ง
+	old, _ := ts.visitors.GetByID(v.ID)
+	body, contentType = photoUpload(t, testPNG(t))
+	expectStatus(t, ts.do("POST", path, body, "Content-Type", contentType), http.StatusCreated)
+	if _, err := ts.files.Open(old.PhotoKey); err == nil {
+		t.Errorf("old photo %s was not deleted", old.PhotoKey)
+	}
+}
+
+func TestGetBadge(t *testing.T) {
+	ts := newTestServer(t)
+	v := ts.createVisitor(newVisitorRequest(1))
+	path := "/api/visitors/" + strconv.Itoa(v.ID) + "/badge"
+
+	rec := ts.do("GET", path, nil)
+	expectStatus(t, rec, http.StatusOK)
+	if ct := rec.Header().Get("Content-Type"); ct != "application/pdf" || !bytes.HasPrefix(rec.Body.Bytes(), []byte("%PDF")) {
+		t.Errorf("badge Content-Type = %q", ct)
+	}
+
+	rec = ts.do("GET", path+"?format=png", nil)
+	expectStatus(t, rec, http.StatusOK)
+	if _, err := png.Decode(rec.Body); err != nil {
+		t.Errorf("badge PNG: %v", err)
+	}
+
+	expectStatus(t, ts.do("GET", path+"?format=gif", nil), http.StatusBadRequest)
+	expectStatus(t, ts.do("GET", "/api/visitors/99/badge", nil), http.StatusNotFound)
+
+	pending := ts.pendingVisitor(2, "ครูสมศรี")
+	expectStatus(t, ts.do("GET", "/api/visitors/"+strconv.Itoa(pending.ID)+"/badge", nil), http.StatusConflict)
+
+	// บัตรหมดอายุเมื่อข้ามวัน
+	ts.advance(24 * time.Hour)
+	expectStatus(t, ts.do("GET", path, nil), http.StatusGone)
+}
+
+func TestScanBadge(t *testing.T) {
+	ts := newTestServer(t)
+	v := ts.createVisitor(newVisitorRequest(1))
+	expiresAt := ts.clock.Now().Add(2 * time.Hour)
+	signed, err := ts.signer.Sign(token.KindVisit, v.ID, expiresAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := ts.do("POST", "/api/badges/scan", models.ScanBadgeRequest{Token: signed})
+	expectStatus(t, rec, http.StatusOK)
+	var got models.ScanBadgeResponse
+	decode(t, rec, &got)
+	if got.Visitor == nil || got.Visitor.ID != v.ID || !got.ExpiresAt.Equal(expiresAt.Truncate(time.Second)) {
+		t.Errorf("scan = %+v", got)
+	}
+
+	// QR นัดหมายหรือ token ที่ถูกแก้ไม่ใช่บัตรผู้มาติดต่อ
+	appointment, _ := ts.signer.Sign(token.KindAppointment, v.ID, expiresAt)
+	expectStatus(t, ts.do("POST", "/api/badges/scan", models.ScanBadgeRequest{Token: appointment}), http.StatusBadRequest)
+	expectStatus(t, ts.do("POST", "/api/badges/scan", models.ScanBadgeRequest{Token: signed + "x"}), http.StatusBadRequest)
+	expectStatus(t, ts.do("POST", "/api/badges/scan", "{"), http.StatusBadRequest)
+
+	missing, _ := ts.signer.Sign(token.KindVisit, 99, expiresAt)
+	expectStatus(t, ts.do("POST", "/api/badges/scan", models.ScanBadgeRequest{Token: missing}), http.StatusNotFound)
+
+	// คืนบัตรแล้ว บัตรใช้ไม่ได้อีก
+	expectStatus(t, ts.do("POST", "/api/visitors/return/"+v.RFID, map[string]string{"checkOut": "11:00"}), http.StatusOK)
+	expectStatus(t, ts.do("POST", "/api/badges/scan", models.ScanBadgeRequest{Token: signed}), http.StatusConflict)
+
+	ts.advance(3 * time.Hour)
+	expectStatus(t, ts.do("POST", "/api/badges/scan", models.ScanBadgeRequest{Token: signed}), http.StatusGone)
+}
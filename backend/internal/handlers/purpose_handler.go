@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"backend/internal/models"
+	"backend/internal/repository"
+
+	"github.com/gorilla/mux"
+)
+
+type PurposeHandler struct {
+	repo *repository.PurposeRepository
+}
+
+func NewPurposeHandler(repo *repository.PurposeRepository) *PurposeHandler {
+	return &PurposeHandler{repo: repo}
+}
+
+// ListPurposes handles GET /api/purposes (all=true รวมรายการที่ปิดใช้งาน)
+func (h *PurposeHandler) ListPurposes(w http.ResponseWriter, r *http.Request) {
+	activeOnly := r.URL.Query().Get("all") != "true"
+
+	purposes, err := h.repo.List(activeOnly)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to list visit purposes")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, purposes)
+}
+
+// SavePurpose handles PUT /api/purposes/{code}
+func (h *PurposeHandler) SavePurpose(w http.ResponseWriter, r *http.Request) {
+	var req models.VisitPurpose
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	req.Code = mux.Vars(r)["code"]
+	if req.Code == "" || req.NameTh == "" {
+		respondWithError(w, http.StatusBadRequest, "code and nameTh are required")
+		return
+	}
+
+	if err := h.repo.Save(&req); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to save visit purpose")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, req)
+}
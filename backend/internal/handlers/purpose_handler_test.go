@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"backend/internal/models"
+)
+
+func TestPurposes(t *testing.T) {
+	ts := newSQLiteServer(t)
+
+	list := func(path string) []models.VisitPurpose {
+		t.Helper()
+		rec := ts.do("GET", path, nil)
+		expectStatus(t, rec, http.StatusOK)
+		var purposes []models.VisitPurpose
+		decode(t, rec, &purposes)
+		return purposes
+	}
+
+	purposes := list("/api/purposes")
+	if len(purposes) != 6 || purposes[0].Code != "pickup_student" || purposes[5].Code != "other" {
+		t.Fatalf("seeded purposes = %+v", purposes)
+	}
+
+	rec := ts.do("PUT", "/api/purposes/meeting", models.VisitPurpose{NameTh: "ประชุมผู้ปกครอง", NameEn: "Parent meeting", RequiresApproval: true, Active: true, DisplayOrder: 2})
+	expectStatus(t, rec, http.StatusOK)
+	expectStatus(t, ts.do("PUT", "/api/purposes/delivery", models.VisitPurpose{NameTh: "ส่งของ", DisplayOrder: 4}), http.StatusOK)
+	expectStatus(t, ts.do("PUT", "/api/purposes/tour", models.VisitPurpose{NameTh: "ศึกษาดูงาน", Active: true, DisplayOrder: 7}), http.StatusOK)
+
+	purposes = list("/api/purposes")
+	names := map[string]models.VisitPurpose{}
+	for _, p := range purposes {
+		names[p.Code] = p
+	}
+	if len(purposes) != 6 || names["meeting"].NameTh != "ประชุมผู้ปกครอง" || !names["meeting"].RequiresApproval || names["tour"].NameTh != "ศึกษาดูงาน" {
+		t.Errorf("purposes = %+v", purposes)
+	}
+	if _, ok := names["delivery"]; ok {
+		t.Error("inactive purpose is listed")
+	}
+	if all := list("/api/purposes?all=true"); len(all) != 7 {
+		t.Errorf("all purposes = %+v", all)
+	}
+
+	expectStatus(t, ts.do("PUT", "/api/purposes/meeting", "{"), http.StatusBadRequest)
+	expectStatus(t, ts.do("PUT", "/api/purposes/meeting", models.VisitPurpose{}), http.StatusBadRequest)
+}
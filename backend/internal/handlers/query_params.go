@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"backend/internal/models"
+)
+
+// visitorQueryKeys lists every query parameter accepted by ListVisitors and GetVisitors
+var visitorQueryKeys = map[string]bool{
+	"search":       true,
+	"startDate":    true,
+	"endDate":      true,
+	"sortBy":       true,
+	"sortOrder":    true,
+	"department":   true,
+	"departmentId": true,
+	"province":     true,
+	"licensePlate": true,
+	"officer":      true,
+	"status":       true,
+	"minDuration":  true,
+	"maxDuration":  true,
+	"hasRfid":      true,
+	"page":         true,
+	"limit":        true,
+	"calendar":     true,
+	"month":        true,
+	"digits":       true,
+}
+
+// parseVisitorQueryParams validates the visitor filter query string.
+// Unknown parameters and malformed values are rejected so typos do not silently return everything.
+func parseVisitorQueryParams(values url.Values) (models.QueryParams, error) {
+	for key := range values {
+		if !visitorQueryKeys[key] {
+			return models.QueryParams{}, fmt.Errorf("unknown query parameter: %s", key)
+		}
+	}
+
+	params := models.QueryParams{
+		Search:       values.Get("search"),
+		StartDate:    values.Get("startDate"),
+		EndDate:      values.Get("endDate"),
+		SortBy:       values.Get("sortBy"),
+		SortOrder:    values.Get("sortOrder"),
+		Department:   values.Get("department"),
+		Province:     values.Get("province"),
+		LicensePlate: values.Get("licensePlate"),
+		OfficerName:  values.Get("officer"),
+		Status:       values.Get("status"),
+	}
+
+	var err error
+	if params.StartDate, err = parseDateParam("startDate", params.StartDate); err != nil {
+		return params, err
+	}
+	if params.EndDate, err = parseDateParam("endDate", params.EndDate); err != nil {
+		return params, err
+	}
+
+	switch params.SortBy {
+	case "", models.SortByRegisteredAt, models.SortByName, models.SortByExitTime:
+	default:
+		return params, fmt.Errorf("sortBy must be registeredAt, name or exitTime")
+	}
+
+	switch params.SortOrder {
+	case "", "latest", "oldest", "asc", "desc":
+	default:
+		return params, fmt.Errorf("sortOrder must be latest, oldest, asc or desc")
+	}
+
+	switch params.Status {
+	case "", models.VisitorStatusOnSite, models.VisitorStatusCheckedOut:
+	default:
+		return params, fmt.Errorf("status must be onsite or checkedout")
+	}
+
+	if params.MinDuration, err = parseOptionalMinutes(values, "minDuration"); err != nil {
+		return params, err
+	}
+	if params.MaxDuration, err = parseOptionalMinutes(values, "maxDuration"); err != nil {
+		return params, err
+	}
+	if params.MinDuration != nil && params.MaxDuration != nil && *params.MinDuration > *params.MaxDuration {
+		return params, fmt.Errorf("minDuration must not be greater than maxDuration")
+	}
+
+	if hasRFID := values.Get("hasRfid"); hasRFID != "" {
+		parsed, err := strconv.ParseBool(hasRFID)
+		if err != nil {
+			return params, fmt.Errorf("hasRfid must be true or false")
+		}
+		params.HasRFID = &parsed
+	}
+
+	// departmentId ใส่ได้หลายค่า (ซ้ำ key หรือคั่นด้วย ,) ไม่ใส่ = ทุกส่วนงาน
+	for _, raw := range splitValues(values["departmentId"]) {
+		id, err := strconv.Atoi(raw)
+		if err != nil || id <= 0 {
+			return params, fmt.Errorf("departmentId must be a positive number")
+		}
+		params.DepartmentIDs = append(params.DepartmentIDs, id)
+	}
+
+	if params.Page, err = parseOptionalPositive(values, "page"); err != nil {
+		return params, err
+	}
+	if params.Limit, err = parseOptionalPositive(values, "limit"); err != nil {
+		return params, err
+	}
+
+	return params, nil
+}
+
+func parseOptionalMinutes(values url.Values, key string) (*int, error) {
+	raw := values.Get(key)
+	if raw == "" {
+		return nil, nil
+	}
+	minutes, err := strconv.Atoi(raw)
+	if err != nil || minutes < 0 {
+		return nil, fmt.Errorf("%s must be a non-negative number of minutes", key)
+	}
+	return &minutes, nil
+}
+
+func parseOptionalPositive(values url.Values, key string) (int, error) {
+	raw := values.Get(key)
+	if raw == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%s must be a positive number", key)
+	}
+	return n, nil
+}
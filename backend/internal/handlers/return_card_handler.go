@@ -2,10 +2,17 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
+	"backend/internal/clock"
+	"backend/internal/events"
 	"backend/internal/models"
+	"backend/internal/repository"
 
 	"github.com/gorilla/mux"
 )
@@ -26,19 +33,20 @@ func (h *VisitorHandler) SearchByRFID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Create response with check-in/out times
-	now := time.Now()
+	// Create response with check-in/out times (เวลาของโรงเรียน)
 	response := models.RFIDCardResponse{
 		ID:       visitor.RFID,
 		Name:     visitor.FirstName + " " + visitor.LastName,
-		CheckIn:  visitor.RegisteredAt.Format("15:04"),
-		CheckOut: now.Format("15:04"),
+		CheckIn:  h.clock.In(visitor.RegisteredAt).Format("15:04"),
+		CheckOut: h.clock.In(h.clock.Now()).Format("15:04"),
 	}
 
 	respondWithJSON(w, http.StatusOK, response)
 }
 
 // ReturnCard handles POST /api/visitors/return/{cardId}
+// ส่ง Idempotency-Key (UUID ที่หน้าจอสร้างครั้งเดียวต่อการคืนบัตร) แล้วส่งซ้ำหลังเน็ตหลุดจะได้ผลเดิมกลับไป
+// แทน 409 คืนซ้ำ key นี้ใช้เป็น client ID ของการคืนบัตรเหมือน clientId ใน body
 func (h *VisitorHandler) ReturnCard(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	cardId := vars["cardId"]
@@ -53,16 +61,25 @@ func (h *VisitorHandler) ReturnCard(w http.ResponseWriter, r *http.Request) {
 		respondWithError(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
-
-	// 🔍 เช็คว่าบัตรนี้ถูกคืนวันนี้แล้วหรือยัง
-	isDuplicate, err := h.repo.CheckDuplicateReturn(cardId)
+	key := strings.TrimSpace(r.Header.Get("Idempotency-Key"))
+	if key != "" {
+		if req.ClientID != "" && req.ClientID != key {
+			respondWithError(w, http.StatusBadRequest, "Idempotency-Key and clientId differ")
+			return
+		}
+		req.ClientID = key
+	}
+	clientID, err := newClientID(req.ClientID)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to check duplicate return")
+		if key != "" {
+			err = fmt.Errorf("Idempotency-Key must be a UUID")
+		}
+		respondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	if isDuplicate {
-		respondWithError(w, http.StatusConflict, "บัตรนี้ถูกคืนไปแล้ววันนี้ ไม่สามารถคืนบัตรซ้ำได้")
+	// คืนซ้ำด้วย key เดิม ได้ผลเดิมกลับไป
+	if req.ClientID != "" && h.replayReturn(w, cardId, clientID) {
 		return
 	}
 
@@ -73,22 +90,19 @@ func (h *VisitorHandler) ReturnCard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Create return log
-	now := time.Now()
-	log := &models.ReturnCardLog{
-		VisitorID:  visitor.ID,
-		CardID:     visitor.RFID,
-		Name:       visitor.FirstName + " " + visitor.LastName,
-		CheckIn:    visitor.RegisteredAt.Format("15:04"),
-		CheckOut:   req.CheckOut,
-		ReturnDate: now,
-		Status:     "การคืนบัตรสำเร็จ",
+	log, err := returnVisitorCard(h.returns, h.clock, visitor, req.CheckOut, clientID)
+	if err != nil && req.ClientID != "" && h.replayReturn(w, cardId, clientID) {
+		return // คำขออื่นที่ใช้ key เดียวกันบันทึกไปก่อนหน้านี้ไม่กี่มิลลิวินาที
 	}
-
-	if err := h.repo.CreateReturnLog(log); err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to create return log")
+	if errors.Is(err, repository.ErrDuplicateReturn) {
+		respondWithError(w, http.StatusConflict, "บัตรนี้ถูกคืนไปแล้ววันนี้ ไม่สามารถคืนบัตรซ้ำได้")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to return card")
 		return
 	}
+	publishCardReturned(h.bus, visitor, log, nil)
 
 	respondWithJSON(w, http.StatusOK, map[string]interface{}{
 		"message": "คืนบัตรสำเร็จ",
@@ -96,15 +110,130 @@ func (h *VisitorHandler) ReturnCard(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// VoidReturn handles POST /api/return-logs/{id}/void
+// ยกเลิกการคืนบัตรที่บันทึกผิด (ยามสแกนบัตรผิดใบ) ต้องระบุผู้ยกเลิกและเหตุผล
+// ผู้มาติดต่อกลับเป็นยังอยู่ในโรงเรียน และคืนบัตรใบที่ถูกต้องในวันเดียวกันได้
+func (h *VisitorHandler) VoidReturn(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid return log ID")
+		return
+	}
+
+	var req models.VoidReturnRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	req.VoidedBy = strings.TrimSpace(req.VoidedBy)
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.VoidedBy == "" || req.Reason == "" {
+		respondWithError(w, http.StatusBadRequest, "voidedBy and reason are required")
+		return
+	}
+
+	revision := &models.VisitorRevision{Action: models.RevisionVoidReturn, ChangedBy: req.VoidedBy, Reason: req.Reason}
+	log, err := h.returns.VoidReturnLog(id, revision)
+	switch {
+	case errors.Is(err, repository.ErrReturnLogNotFound):
+		respondWithError(w, http.StatusNotFound, "Return log not found")
+		return
+	case errors.Is(err, repository.ErrReturnAlreadyVoided):
+		respondWithError(w, http.StatusConflict, "การคืนบัตรนี้ถูกยกเลิกไปแล้ว")
+		return
+	case err != nil:
+		respondWithError(w, http.StatusInternalServerError, "Failed to void return")
+		return
+	}
+
+	if visitor, err := h.repo.GetByID(log.VisitorID); err == nil {
+		h.bus.Publish(events.TypeReturnVoided, visitor.Department, events.VisitorOf(visitor))
+	}
+
+	respondWithJSON(w, http.StatusOK, log)
+}
+
+// replayReturn responds with the return already recorded under clientID, if there is one
+func (h *VisitorHandler) replayReturn(w http.ResponseWriter, cardID, clientID string) bool {
+	existing, err := h.returns.GetReturnLogByClientID(clientID)
+	if err != nil {
+		return false
+	}
+	if !strings.EqualFold(existing.CardID, cardID) {
+		respondWithError(w, http.StatusUnprocessableEntity, "Idempotency-Key was used for another card")
+		return true
+	}
+
+	w.Header().Set("Idempotent-Replayed", "true")
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "คืนบัตรสำเร็จ",
+		"log":     existing,
+	})
+	return true
+}
+
+// returnVisitorCard records the visitor handing back their card now.
+// ใช้ทั้งหน้าคืนบัตรและเครื่องอ่านบัตรที่ประตูออก คืนซ้ำในวันเดียวกันได้ repository.ErrDuplicateReturn
+// (เช็คใน transaction เดียวกับที่บันทึก แตะบัตรรัว ๆ ก็ได้บันทึกเดียว)
+func returnVisitorCard(repo repository.ReturnLogStore, clk *clock.Clock, visitor *models.Visitor, checkOut, clientID string) (*models.ReturnCardLog, error) {
+	log := newReturnLog(clk, visitor, checkOut, clk.Now())
+	log.ClientID = clientID
+	if err := repo.CreateReturnLog(log); err != nil {
+		return nil, fmt.Errorf("failed to create return log: %w", err)
+	}
+
+	return log, nil
+}
+
+// newReturnLog builds the log of a card returned at returnedAt.
+// return_date เป็นวันตามเวลาของโรงเรียน ใช้คู่กับ CheckDuplicateReturn
+func newReturnLog(clk *clock.Clock, visitor *models.Visitor, checkOut string, returnedAt time.Time) *models.ReturnCardLog {
+	return &models.ReturnCardLog{
+		VisitorID:  visitor.ID,
+		CardID:     visitor.RFID,
+		Name:       visitor.FirstName + " " + visitor.LastName,
+		CheckIn:    clk.In(visitor.RegisteredAt).Format("15:04"),
+		CheckOut:   checkOut,
+		ReturnDate: clk.DateOf(returnedAt),
+		Status:     "การคืนบัตรสำเร็จ",
+		ReturnedAt: returnedAt,
+	}
+}
+
+// publishCardReturned tells the guard dashboards that a card came back
+func publishCardReturned(bus *events.Bus, visitor *models.Visitor, log *models.ReturnCardLog, deviceID *int) {
+	bus.Publish(events.TypeCardReturned, visitor.Department, events.CardReturned{
+		Visitor:  events.VisitorOf(visitor),
+		CheckIn:  log.CheckIn,
+		CheckOut: log.CheckOut,
+		DeviceID: deviceID,
+	})
+}
+
 // GetReturnHistory handles GET /api/visitors/return-history
 func (h *VisitorHandler) GetReturnHistory(w http.ResponseWriter, r *http.Request) {
-	// Parse query parameters
-	search := r.URL.Query().Get("search")
-	startDate := r.URL.Query().Get("startDate")
-	endDate := r.URL.Query().Get("endDate")
-	sortOrder := r.URL.Query().Get("sortOrder")
+	// Parse query parameters (วันที่รับทั้ง ค.ศ. และ พ.ศ.)
+	query := r.URL.Query()
+	search := query.Get("search")
+	sortOrder := query.Get("sortOrder")
+
+	startDate, err := parseDateParam("startDate", query.Get("startDate"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	endDate, err := parseDateParam("endDate", query.Get("endDate"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	dates, err := dateFormatter(query, h.dates)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	logs, err := h.repo.GetReturnLogs(search, startDate, endDate, sortOrder)
+	logs, err := h.returns.GetReturnLogs(search, startDate, endDate, sortOrder)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Failed to get return history")
 		return
@@ -114,12 +243,13 @@ func (h *VisitorHandler) GetReturnHistory(w http.ResponseWriter, r *http.Request
 	response := make([]models.ReturnCardHistoryResponse, len(logs))
 	for i, log := range logs {
 		response[i] = models.ReturnCardHistoryResponse{
+			ID:      log.ID,
 			No:      i + 1,
 			CardID:  log.CardID,
 			Name:    log.Name,
-			TimeIn:  log.CheckIn,
-			TimeOut: log.CheckOut,
-			Date:    log.ReturnDate.Format("02/01/2006"),
+			TimeIn:  dates.Digits(log.CheckIn),
+			TimeOut: dates.Digits(log.CheckOut),
+			Date:    dates.CalendarDate(log.ReturnDate),
 			Status:  log.Status,
 		}
 	}
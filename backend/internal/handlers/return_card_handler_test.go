@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+)
+
+func TestReturnCard(t *testing.T) {
+	ts := newTestServer(t)
+	ts.createVisitor(newVisitorRequest(1))
+	ts.advance(90 * time.Minute)
+
+	rec := ts.do("GET", "/api/visitors/rfid/RF0001", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var card models.RFIDCardResponse
+	decode(t, rec, &card)
+	if card.CheckIn != "10:00" || card.CheckOut != "11:30" {
+		t.Errorf("RFID lookup = %+v", card)
+	}
+	expectStatus(t, ts.do("GET", "/api/visitors/rfid/NOPE", nil), http.StatusNotFound)
+
+	expectStatus(t, ts.do("POST", "/api/visitors/return/RF0001", "{"), http.StatusBadRequest)
+	expectStatus(t, ts.do("POST", "/api/visitors/return/NOPE", models.ReturnCardRequest{CheckOut: "11:30"}), http.StatusNotFound)
+	expectStatus(t, ts.do("POST", "/api/visitors/return/RF0001", models.ReturnCardRequest{CheckOut: "11:30"}), http.StatusOK)
+
+	// คืนซ้ำในวันเดียวกันไม่ได้
+	rec = ts.do("POST", "/api/visitors/return/RF0001", models.ReturnCardRequest{CheckOut: "11:31"})
+	expectStatus(t, rec, http.StatusConflict)
+	if msg := errorMessage(t, rec); msg == "" {
+		t.Error("duplicate return has no error message")
+	}
+
+	rec = ts.do("GET", "/api/visitors/1", nil)
+	var v models.Visitor
+	decode(t, rec, &v)
+	if v.ExitTime == nil || !v.ExitTime.Equal(testNow.Add(90*time.Minute)) {
+		t.Errorf("exit time = %v", v.ExitTime)
+	}
+}
+
+func TestReturnHistory(t *testing.T) {
+	ts := newTestServer(t)
+	for i := 1; i <= 2; i++ {
+		ts.createVisitor(newVisitorRequest(i))
+		ts.advance(time.Hour)
+	}
+	expectStatus(t, ts.do("POST", "/api/visitors/return/RF0001", models.ReturnCardRequest{CheckOut: "12:00"}), http.StatusOK)
+	expectStatus(t, ts.do("POST", "/api/visitors/return/RF0002", models.ReturnCardRequest{CheckOut: "12:05"}), http.StatusOK)
+
+	rec := ts.do("GET", "/api/visitors/return-history?sortOrder=asc", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var history []models.ReturnCardHistoryResponse
+	decode(t, rec, &history)
+	if len(history) != 2 || history[0].CardID != "RF0001" || history[0].TimeIn != "10:00" || history[1].No != 2 {
+		t.Fatalf("return history = %+v", history)
+	}
+
+	rec = ts.do("GET", "/api/visitors/return-history?search=RF0002&startDate=2568-06-02&endDate=2568-06-02", nil)
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &history)
+	if len(history) != 1 || history[0].CardID != "RF0002" {
+		t.Errorf("filtered history = %+v", history)
+	}
+
+	expectStatus(t, ts.do("GET", "/api/visitors/return-history?startDate=yesterday", nil), http.StatusBadRequest)
+}
+
+func TestVoidReturn(t *testing.T) {
+	ts := newTestServer(t)
+	wrong := ts.createVisitor(newVisitorRequest(1))
+	ts.createVisitor(newVisitorRequest(2))
+	ts.advance(time.Hour)
+
+	// ยามสแกนบัตรผิดใบ
+	rec := ts.do("POST", "/api/visitors/return/RF0001", models.ReturnCardRequest{CheckOut: "11:00"})
+	expectStatus(t, rec, http.StatusOK)
+	var returned struct {
+		Log models.ReturnCardLog `json:"log"`
+	}
+	decode(t, rec, &returned)
+	path := "/api/return-logs/" + strconv.Itoa(returned.Log.ID) + "/void"
+
+	expectStatus(t, ts.do("POST", path, models.VoidReturnRequest{VoidedBy: "ยามหน้า"}), http.StatusBadRequest)
+	expectStatus(t, ts.do("POST", "/api/return-logs/99/void", models.VoidReturnRequest{VoidedBy: "ยามหน้า", Reason: "สแกนผิดใบ"}), http.StatusNotFound)
+	expectStatus(t, ts.do("POST", "/api/return-logs/abc/void", models.VoidReturnRequest{VoidedBy: "ยามหน้า", Reason: "สแกนผิดใบ"}), http.StatusBadRequest)
+
+	rec = ts.do("POST", path, models.VoidReturnRequest{VoidedBy: "ยามหน้า", Reason: "สแกนผิดใบ"})
+	expectStatus(t, rec, http.StatusOK)
+	var voided models.ReturnCardLog
+	decode(t, rec, &voided)
+	if voided.VoidedAt == nil || voided.VoidedBy != "ยามหน้า" || voided.VoidReason != "สแกนผิดใบ" {
+		t.Errorf("voided log = %+v", voided)
+	}
+	expectStatus(t, ts.do("POST", path, models.VoidReturnRequest{VoidedBy: "ยามหน้า", Reason: "สแกนผิดใบ"}), http.StatusConflict)
+
+	// ผู้มาติดต่อกลับเป็นยังอยู่ในโรงเรียน และมีประวัติการยกเลิก
+	rec = ts.do("GET", "/api/visitors/"+strconv.Itoa(wrong.ID), nil)
+	var v models.Visitor
+	decode(t, rec, &v)
+	if v.ExitTime != nil || v.Version != 3 {
+		t.Errorf("visitor after void = exit %v version %d", v.ExitTime, v.Version)
+	}
+	var revisions []models.VisitorRevision
+	decode(t, ts.do("GET", "/api/visitors/"+strconv.Itoa(wrong.ID)+"/revisions", nil), &revisions)
+	if len(revisions) != 1 || revisions[0].Action != models.RevisionVoidReturn || revisions[0].ChangedBy != "ยามหน้า" ||
+		len(revisions[0].Changes) != 1 || revisions[0].Changes[0].Field != "exitTime" {
+		t.Errorf("void revision = %+v", revisions)
+	}
+
+	// ไม่แสดงในประวัติการคืนบัตร และคืนบัตรทั้งสองใบในวันเดียวกันได้ตามปกติ
+	var history []models.ReturnCardHistoryResponse
+	decode(t, ts.do("GET", "/api/visitors/return-history", nil), &history)
+	if len(history) != 0 {
+		t.Errorf("history shows the voided return: %+v", history)
+	}
+	expectStatus(t, ts.do("POST", "/api/visitors/return/RF0002", models.ReturnCardRequest{CheckOut: "11:01"}), http.StatusOK)
+	expectStatus(t, ts.do("POST", "/api/visitors/return/RF0001", models.ReturnCardRequest{CheckOut: "12:00"}), http.StatusOK)
+	decode(t, ts.do("GET", "/api/visitors/return-history", nil), &history)
+	if len(history) != 2 || history[0].ID == returned.Log.ID || history[1].ID == returned.Log.ID {
+		t.Errorf("return history = %+v", history)
+	}
+}
+
+// แตะบัตรรัว ๆ พร้อมกันต้องคืนได้ครั้งเดียว ที่เหลือได้ 409
+func TestReturnCardConcurrent(t *testing.T) {
+	ts := newTestServer(t)
+	ts.createVisitor(newVisitorRequest(1))
+
+	const taps = 20
+	codes := make([]int, taps)
+	var wg sync.WaitGroup
+	for i := 0; i < taps; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i] = ts.do("POST", "/api/visitors/return/RF0001", models.ReturnCardRequest{CheckOut: "11:00"}).Code
+		}(i)
+	}
+	wg.Wait()
+
+	returned := 0
+	for _, code := range codes {
+		switch code {
+		case http.StatusOK:
+			returned++
+		case http.StatusConflict:
+		default:
+			t.Errorf("concurrent return status = %d", code)
+		}
+	}
+	if returned != 1 {
+		t.Errorf("%d of %d concurrent returns succeeded, want 1", returned, taps)
+	}
+}
+
+func TestReturnCardIdempotencyKey(t *testing.T) {
+	ts := newTestServer(t)
+	ts.createVisitor(newVisitorRequest(1))
+	ts.createVisitor(newVisitorRequest(2))
+	key := "6f1c2a7e-3b7d-4c1e-9a55-0d4f3e2b1a90"
+
+	// ส่งซ้ำพร้อมกันด้วย key เดียว ได้การคืนบัตรเดียวกันทุกคำขอ
+	const retries = 10
+	logs := make([]int, retries)
+	var wg sync.WaitGroup
+	for i := 0; i < retries; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rec := ts.do("POST", "/api/visitors/return/RF0001", models.ReturnCardRequest{CheckOut: "11:00"}, "Idempotency-Key", key)
+			if rec.Code != http.StatusOK {
+				t.Errorf("retry status = %d, body: %s", rec.Code, rec.Body.String())
+				return
+			}
+			var body struct {
+				Log models.ReturnCardLog `json:"log"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Error(err)
+				return
+			}
+			logs[i] = body.Log.ID
+		}(i)
+	}
+	wg.Wait()
+	for _, id := range logs {
+		if id == 0 || id != logs[0] {
+			t.Fatalf("retries got return logs %v, want one", logs)
+		}
+	}
+
+	rec := ts.do("POST", "/api/visitors/return/RF0001", models.ReturnCardRequest{CheckOut: "11:05"}, "Idempotency-Key", key)
+	expectStatus(t, rec, http.StatusOK)
+	if rec.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("replayed return is missing Idempotent-Replayed")
+	}
+	var replay struct {
+		Log models.ReturnCardLog `json:"log"`
+	}
+	decode(t, rec, &replay)
+	if replay.Log.ID != logs[0] || replay.Log.CheckOut != "11:00" {
+		t.Errorf("replayed log = %+v", replay.Log)
+	}
+	var history []models.ReturnCardHistoryResponse
+	decode(t, ts.do("GET", "/api/visitors/return-history", nil), &history)
+	if len(history) != 1 {
+		t.Errorf("return history = %+v", history)
+	}
+
+	// key ใหม่ของบัตรเดิมยังเป็นการคืนซ้ำ, key เดิมกับบัตรอื่นใช้ไม่ได้
+	expectStatus(t, ts.do("POST", "/api/visitors/return/RF0001", models.ReturnCardRequest{CheckOut: "11:06"}, "Idempotency-Key", "0b6f7f0e-8c7d-4f5e-a1b2-c3d4e5f60718"), http.StatusConflict)
+	expectStatus(t, ts.do("POST", "/api/visitors/return/RF0002", models.ReturnCardRequest{CheckOut: "11:06"}, "Idempotency-Key", key), http.StatusUnprocessableEntity)
+	expectStatus(t, ts.do("POST", "/api/visitors/return/RF0002", models.ReturnCardRequest{CheckOut: "11:06"}, "Idempotency-Key", "retry-1"), http.StatusBadRequest)
+	expectStatus(t, ts.do("POST", "/api/visitors/return/RF0002", models.ReturnCardRequest{CheckOut: "11:06", ClientID: key}, "Idempotency-Key", "0b6f7f0e-8c7d-4f5e-a1b2-c3d4e5f60718"), http.StatusBadRequest)
+}
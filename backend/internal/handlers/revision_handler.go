@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"backend/internal/address"
+	"backend/internal/events"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/thaidate"
+
+	"github.com/gorilla/mux"
+)
+
+// visitorETag is the ETag of a visit: its version, which changes with every update
+func visitorETag(v *models.Visitor) string {
+	return `"` + strconv.Itoa(v.Version) + `"`
+}
+
+// ifMatchVersion reads the version in the If-Match header (ETag จาก GET /api/visitors/{id}).
+// ok = false when the header is missing
+func ifMatchVersion(r *http.Request) (version int, ok bool, err error) {
+	raw := strings.TrimSpace(r.Header.Get("If-Match"))
+	if raw == "" {
+		return 0, false, nil
+	}
+	raw = strings.Trim(strings.TrimPrefix(raw, "W/"), `"`)
+	version, err = strconv.Atoi(raw)
+	if err != nil {
+		return 0, false, fmt.Errorf("Invalid If-Match header")
+	}
+	return version, true, nil
+}
+
+// visitorFields are the fields PATCH can change, in the order they are listed in a revision
+var visitorFields = []struct {
+	name  string
+	value func(v *models.Visitor) string
+}{
+	{"idCard", func(v *models.Visitor) string { return v.IDCard }},
+	{"firstName", func(v *models.Visitor) string { return v.FirstName }},
+	{"lastName", func(v *models.Visitor) string { return v.LastName }},
+	{"birthDate", func(v *models.Visitor) string {
+		if v.BirthDate == nil {
+			return ""
+		}
+		return v.BirthDate.Format("2006-01-02")
+	}},
+	{"phone", func(v *models.Visitor) string { return v.Phone }},
+	{"houseNumber", func(v *models.Visitor) string { return v.HouseNumber }},
+	{"moo", func(v *models.Visitor) string { return v.Moo }},
+	{"soi", func(v *models.Visitor) string { return v.Soi }},
+	{"road", func(v *models.Visitor) string { return v.Road }},
+	{"subDistrict", func(v *models.Visitor) string { return v.SubDistrict }},
+	{"district", func(v *models.Visitor) string { return v.District }},
+	{"province", func(v *models.Visitor) string { return v.Province }},
+	{"postalCode", func(v *models.Visitor) string { return v.PostalCode }},
+	{"department", func(v *models.Visitor) string { return v.Department }},
+	{"officerName", func(v *models.Visitor) string { return v.OfficerName }},
+	{"hostName", func(v *models.Visitor) string { return v.HostName }},
+	{"hostContact", func(v *models.Visitor) string { return v.HostContact }},
+	{"purposeNote", func(v *models.Visitor) string { return v.PurposeNote }},
+}
+
+// diffVisitor lists the fields that differ between before and after
+func diffVisitor(before, after *models.Visitor) []models.VisitorChange {
+	var changes []models.VisitorChange
+	for _, f := range visitorFields {
+		if old, updated := f.value(before), f.value(after); old != updated {
+			changes = append(changes, models.VisitorChange{Field: f.name, Old: old, New: updated})
+		}
+	}
+	return changes
+}
+
+// UpdateVisitor handles PATCH /api/visitors/{id}
+// แก้ข้อมูลที่พิมพ์ผิด ต้องส่ง If-Match เป็น ETag ที่อ่านมา ถ้ามีคนแก้ก่อนจะได้ 412 ให้โหลดใหม่
+// ทุกการแก้ไขถูกเก็บในประวัติ (GET /api/visitors/{id}/revisions) พร้อมค่าเดิม ค่าใหม่ และผู้แก้
+func (h *VisitorHandler) UpdateVisitor(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid visitor ID")
+		return
+	}
+
+	var req models.UpdateVisitorRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields() // เช่น rfid หรือ licensePlate ที่แก้ที่นี่ไม่ได้
+	if err := decoder.Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+		return
+	}
+	req.ChangedBy = strings.TrimSpace(req.ChangedBy)
+	if req.ChangedBy == "" {
+		respondWithError(w, http.StatusBadRequest, "changedBy is required")
+		return
+	}
+
+	version, ok, err := ifMatchVersion(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !ok {
+		respondWithError(w, http.StatusPreconditionRequired, "If-Match header is required")
+		return
+	}
+
+	current, err := h.repo.GetByID(id)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Visitor not found")
+		return
+	}
+	if current.Version != version {
+		respondWithError(w, http.StatusPreconditionFailed, "รายการนี้ถูกแก้ไขไปแล้ว กรุณาโหลดข้อมูลใหม่")
+		return
+	}
+
+	updated := *current
+	if status, err := h.applyVisitorUpdate(&updated, req); err != nil {
+		respondWithError(w, status, err.Error())
+		return
+	}
+
+	changes := diffVisitor(current, &updated)
+	if len(changes) > 0 {
+		revision := &models.VisitorRevision{Action: models.RevisionUpdate, Changes: changes, ChangedBy: req.ChangedBy, Reason: strings.TrimSpace(req.Reason)}
+		if err := h.repo.Update(&updated, revision); err != nil {
+			if errors.Is(err, repository.ErrVersionConflict) {
+				respondWithError(w, http.StatusPreconditionFailed, "รายการนี้ถูกแก้ไขไปแล้ว กรุณาโหลดข้อมูลใหม่")
+				return
+			}
+			respondWithError(w, http.StatusInternalServerError, "Failed to update visitor")
+			return
+		}
+		h.bus.Publish(events.TypeVisitorUpdated, updated.Department, events.VisitorOf(&updated))
+	}
+
+	setPhotoURLs(&updated)
+	w.Header().Set("ETag", visitorETag(&updated))
+	respondWithJSON(w, http.StatusOK, updated)
+}
+
+// applyVisitorUpdate validates the fields sent in req and sets them on v, like CreateVisitor does.
+// Returns the status to respond with on error
+func (h *VisitorHandler) applyVisitorUpdate(v *models.Visitor, req models.UpdateVisitorRequest) (int, error) {
+	required := []struct {
+		field  *string
+		target *string
+		name   string
+	}{
+		{req.FirstName, &v.FirstName, "First name"},
+		{req.LastName, &v.LastName, "Last name"},
+		{req.Phone, &v.Phone, "Phone"},
+	}
+	for _, f := range required {
+		if f.field == nil {
+			continue
+		}
+		value := strings.TrimSpace(*f.field)
+		if value == "" {
+			return http.StatusBadRequest, fmt.Errorf("%s is required", f.name)
+		}
+		*f.target = value
+	}
+
+	if req.IDCard != nil && *req.IDCard != v.IDCard {
+		if len(*req.IDCard) != 13 {
+			return http.StatusBadRequest, fmt.Errorf("ID card must be 13 digits")
+		}
+		exists, err := h.repo.CheckIDCardExists(*req.IDCard)
+		if err != nil {
+			return http.StatusInternalServerError, fmt.Errorf("Failed to check ID card")
+		}
+		if exists {
+			return http.StatusConflict, fmt.Errorf("ID card already registered")
+		}
+		v.IDCard = *req.IDCard
+	}
+
+	if req.BirthDate != nil {
+		v.BirthDate = nil
+		if *req.BirthDate != "" {
+			parsed, err := thaidate.ParseDate(*req.BirthDate)
+			if err != nil {
+				return http.StatusBadRequest, fmt.Errorf("Invalid birth date format")
+			}
+			v.BirthDate = &parsed
+		}
+	}
+
+	// ที่อยู่ตรวจกับข้อมูลอ้างอิงทั้งชุด เมื่อแก้ส่วนใดส่วนหนึ่ง
+	optional := []struct {
+		field  *string
+		target *string
+	}{
+		{req.HouseNumber, &v.HouseNumber},
+		{req.Moo, &v.Moo},
+		{req.Soi, &v.Soi},
+		{req.Road, &v.Road},
+		{req.SubDistrict, &v.SubDistrict},
+		{req.District, &v.District},
+		{req.Province, &v.Province},
+		{req.PostalCode, &v.PostalCode},
+		{req.OfficerName, &v.OfficerName},
+		{req.HostName, &v.HostName},
+		{req.HostContact, &v.HostContact},
+		{req.PurposeNote, &v.PurposeNote},
+	}
+	for _, f := range optional {
+		if f.field != nil {
+			*f.target = strings.TrimSpace(*f.field)
+		}
+	}
+	if req.SubDistrict != nil || req.District != nil || req.Province != nil || req.PostalCode != nil {
+		addr, err := h.addresses.Validate(address.FromVisitor(v))
+		if err != nil {
+			return http.StatusBadRequest, err
+		}
+		v.SubDistrict, v.District, v.Province, v.PostalCode = addr.SubDistrict, addr.District, addr.Province, addr.PostalCode
+	}
+
+	if req.DepartmentID != nil || req.Department != nil {
+		id, name := 0, ""
+		if req.DepartmentID != nil {
+			id = *req.DepartmentID
+		}
+		if req.Department != nil {
+			name = *req.Department
+		}
+		department, err := resolveDepartment(h.departments, id, name)
+		if err != nil {
+			return http.StatusBadRequest, err
+		}
+		v.Department = department.NameTh
+		v.DepartmentID = &department.ID
+	}
+
+	return 0, nil
+}
+
+// DeleteVisitor handles DELETE /api/visitors/{id}
+// ลบแบบ soft delete ต้องระบุผู้ลบและเหตุผล รายการหายจากรายการ รายงาน และการค้นหา แต่ยังดูประวัติได้
+// ส่ง If-Match มาด้วยเพื่อไม่ลบรายการที่เพิ่งถูกแก้ (ไม่บังคับ)
+func (h *VisitorHandler) DeleteVisitor(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid visitor ID")
+		return
+	}
+
+	var req models.DeleteVisitorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	req.DeletedBy = strings.TrimSpace(req.DeletedBy)
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.DeletedBy == "" || req.Reason == "" {
+		respondWithError(w, http.StatusBadRequest, "deletedBy and reason are required")
+		return
+	}
+
+	version, checkVersion, err := ifMatchVersion(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	visitor, err := h.repo.GetByID(id)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Visitor not found")
+		return
+	}
+	if checkVersion && visitor.Version != version {
+		respondWithError(w, http.StatusPreconditionFailed, "รายการนี้ถูกแก้ไขไปแล้ว กรุณาโหลดข้อมูลใหม่")
+		return
+	}
+
+	var changes []models.VisitorChange
+	if visitor.RFID != "" {
+		changes = append(changes, models.VisitorChange{Field: "rfid", Old: visitor.RFID, New: ""})
+	}
+	revision := &models.VisitorRevision{Action: models.RevisionDelete, Changes: changes, ChangedBy: req.DeletedBy, Reason: req.Reason}
+	summary := events.VisitorOf(visitor)
+	if err := h.repo.Delete(visitor, revision); err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			respondWithError(w, http.StatusPreconditionFailed, "รายการนี้ถูกแก้ไขไปแล้ว กรุณาโหลดข้อมูลใหม่")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete visitor")
+		return
+	}
+	h.bus.Publish(events.TypeVisitorDeleted, visitor.Department, summary)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListRevisions handles GET /api/visitors/{id}/revisions (ดูได้ทั้งรายการที่ถูกลบแล้ว)
+func (h *VisitorHandler) ListRevisions(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid visitor ID")
+		return
+	}
+
+	revisions, err := h.repo.ListRevisions(id)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to list revisions")
+		return
+	}
+	if len(revisions) == 0 {
+		if _, err := h.repo.GetByID(id); err != nil {
+			respondWithError(w, http.StatusNotFound, "Visitor not found")
+			return
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, revisions)
+}
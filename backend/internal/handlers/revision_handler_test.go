@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"backend/internal/models"
+)
+
+func TestUpdateVisitor(t *testing.T) {
+	ts := newTestServer(t)
+	v := ts.createVisitor(newVisitorRequest(1))
+	other := ts.createVisitor(newVisitorRequest(2))
+	path := "/api/visitors/" + strconv.Itoa(v.ID)
+
+	rec := ts.do("GET", path, nil)
+	expectStatus(t, rec, http.StatusOK)
+	etag := rec.Header().Get("ETag")
+	if etag != `"1"` {
+		t.Fatalf("ETag = %s, want \"1\"", etag)
+	}
+
+	phone := "0899999999"
+	fix := map[string]interface{}{"phone": phone, "changedBy": "ธุรการ", "reason": "พิมพ์เบอร์ผิด"}
+	expectStatus(t, ts.do("PATCH", path, fix), http.StatusPreconditionRequired)
+
+	rec = ts.do("PATCH", path, fix, "If-Match", etag)
+	expectStatus(t, rec, http.StatusOK)
+	var updated models.Visitor
+	decode(t, rec, &updated)
+	if updated.Phone != phone || updated.Version != 2 || rec.Header().Get("ETag") != `"2"` {
+		t.Fatalf("updated = %+v, ETag %s", updated, rec.Header().Get("ETag"))
+	}
+
+	// แก้จาก ETag เก่าไม่ได้ ต้องโหลดใหม่ก่อน
+	expectStatus(t, ts.do("PATCH", path, map[string]interface{}{"lastName": "ใจงาม", "changedBy": "ยาม"}, "If-Match", etag), http.StatusPreconditionFailed)
+
+	tests := []struct {
+		name string
+		body map[string]interface{}
+		want int
+	}{
+		{"missing changedBy", map[string]interface{}{"phone": phone}, http.StatusBadRequest},
+		{"read-only field", map[string]interface{}{"rfid": "RF9999", "changedBy": "ธุรการ"}, http.StatusBadRequest},
+		{"empty first name", map[string]interface{}{"firstName": " ", "changedBy": "ธุรการ"}, http.StatusBadRequest},
+		{"short id card", map[string]interface{}{"idCard": "123", "changedBy": "ธุรการ"}, http.StatusBadRequest},
+		{"taken id card", map[string]interface{}{"idCard": other.IDCard, "changedBy": "ธุรการ"}, http.StatusConflict},
+		{"bad birth date", map[string]interface{}{"birthDate": "31/02", "changedBy": "ธุรการ"}, http.StatusBadRequest},
+		{"bad address", map[string]interface{}{"postalCode": "99999", "changedBy": "ธุรการ"}, http.StatusBadRequest},
+		{"unknown department", map[string]interface{}{"department": "ฝ่ายไม่มี", "changedBy": "ธุรการ"}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expectStatus(t, ts.do("PATCH", path, tt.body, "If-Match", `"2"`), tt.want)
+		})
+	}
+
+	rec = ts.do("PATCH", path, map[string]interface{}{"departmentId": 2, "firstName": v.FirstName, "changedBy": "ธุรการ"}, "If-Match", `W/"2"`)
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &updated)
+	if updated.Department != "ฝ่ายบุคคล" || updated.Version != 3 {
+		t.Errorf("department change = %s version %d", updated.Department, updated.Version)
+	}
+
+	// ไม่มีอะไรเปลี่ยนก็ไม่เกิดประวัติ
+	rec = ts.do("PATCH", path, map[string]interface{}{"phone": phone, "changedBy": "ธุรการ"}, "If-Match", `"3"`)
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &updated)
+	if updated.Version != 3 {
+		t.Errorf("no-op update bumped version to %d", updated.Version)
+	}
+
+	var revisions []models.VisitorRevision
+	rec = ts.do("GET", path+"/revisions", nil)
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &revisions)
+	if len(revisions) != 2 {
+		t.Fatalf("revisions = %+v", revisions)
+	}
+	first := revisions[0]
+	if first.Action != models.RevisionUpdate || first.Version != 2 || first.ChangedBy != "ธุรการ" || first.Reason != "พิมพ์เบอร์ผิด" ||
+		len(first.Changes) != 1 || first.Changes[0] != (models.VisitorChange{Field: "phone", Old: "0812345678", New: phone}) {
+		t.Errorf("first revision = %+v", first)
+	}
+	if changes := revisions[1].Changes; len(changes) != 1 || changes[0].Field != "department" || changes[0].Old != "ฝ่ายวิชาการ" {
+		t.Errorf("department revision = %+v", changes)
+	}
+
+	expectStatus(t, ts.do("PATCH", "/api/visitors/99", fix, "If-Match", `"1"`), http.StatusNotFound)
+	expectStatus(t, ts.do("PATCH", path, fix, "If-Match", "abc"), http.StatusBadRequest)
+	expectStatus(t, ts.do("GET", "/api/visitors/99/revisions", nil), http.StatusNotFound)
+	expectStatus(t, ts.do("GET", "/api/visitors/abc/revisions", nil), http.StatusBadRequest)
+}
+
+func TestDeleteVisitor(t *testing.T) {
+	ts := newTestServer(t)
+	v := ts.createVisitor(newVisitorRequest(1))
+	kept := ts.createVisitor(newVisitorRequest(2))
+	path := "/api/visitors/" + strconv.Itoa(v.ID)
+
+	expectStatus(t, ts.do("DELETE", path, models.DeleteVisitorRequest{DeletedBy: "ธุรการ"}), http.StatusBadRequest)
+	expectStatus(t, ts.do("DELETE", path, models.DeleteVisitorRequest{DeletedBy: "ธุรการ", Reason: "ลงทะเบียนซ้ำ"}, "If-Match", `"5"`), http.StatusPreconditionFailed)
+	expectStatus(t, ts.do("DELETE", path, models.DeleteVisitorRequest{DeletedBy: "ธุรการ", Reason: "ลงทะเบียนซ้ำ"}, "If-Match", `"1"`), http.StatusNoContent)
+
+	expectStatus(t, ts.do("GET", path, nil), http.StatusNotFound)
+	expectStatus(t, ts.do("DELETE", path, models.DeleteVisitorRequest{DeletedBy: "ธุรการ", Reason: "ลงทะเบียนซ้ำ"}), http.StatusNotFound)
+	expectStatus(t, ts.do("GET", "/api/visitors/rfid/"+v.RFID, nil), http.StatusNotFound)
+
+	rec := ts.do("GET", "/api/visitors", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var list []models.VisitorListResponse
+	decode(t, rec, &list)
+	if len(list) != 1 || list[0].ID != kept.ID {
+		t.Errorf("visitors after delete = %+v", list)
+	}
+
+	// บัตร RFID ใช้กับคนอื่นได้ทันที
+	req := newVisitorRequest(3)
+	req.RFID = v.RFID
+	ts.createVisitor(req)
+
+	// ลงทะเบียนคนที่ถูกลบใหม่ด้วยเลขบัตรประชาชนเดิมได้
+	req = newVisitorRequest(4)
+	req.IDCard = v.IDCard
+	ts.createVisitor(req)
+
+	// ประวัติยังดูได้หลังลบ
+	var revisions []models.VisitorRevision
+	rec = ts.do("GET", path+"/revisions", nil)
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &revisions)
+	if len(revisions) != 1 || revisions[0].Action != models.RevisionDelete || revisions[0].Reason != "ลงทะเบียนซ้ำ" ||
+		len(revisions[0].Changes) != 1 || revisions[0].Changes[0].Old != v.RFID {
+		t.Errorf("delete revision = %+v", revisions)
+	}
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import "github.com/gorilla/mux"
+
+// Routes holds the API handlers of one school. main สร้างจากฐานข้อมูลจริงแยกตามโรงเรียน ส่วน test สร้างจาก memory.Store
+type Routes struct {
+	Visitor     *VisitorHandler
+	Export      *ExportHandler
+	Search      *SearchHandler
+	Vehicle     *VehicleHandler
+	Address     *AddressHandler
+	Appointment *AppointmentHandler
+	Purpose     *PurposeHandler
+	Department  *DepartmentHandler
+	Watchlist   *WatchlistHandler
+	Student     *StudentHandler
+	Photo       *PhotoHandler
+	Badge       *BadgeHandler
+	SmartCard   *SmartCardHandler
+	Device      *DeviceHandler
+	Event       *EventHandler
+	Stats       *StatsHandler
+	Sync        *SyncHandler
+	School      *SchoolHandler
+}
+
+// Register adds the API routes to api (the /api subrouter)
+func (rt Routes) Register(api *mux.Router) {
+	api.HandleFunc("/visitors/return-history", rt.Visitor.GetReturnHistory).Methods("GET")
+	api.HandleFunc("/visitors/rfid/{cardId}", rt.Visitor.SearchByRFID).Methods("GET")
+	api.HandleFunc("/visitors/return/{cardId}", rt.Visitor.ReturnCard).Methods("POST")
+	api.HandleFunc("/return-logs/{id}/void", rt.Visitor.VoidReturn).Methods("POST")
+	api.HandleFunc("/visitors/export", rt.Visitor.GetVisitors).Methods("GET") // ⭐ เพิ่มบรรทัดนี้
+
+	api.HandleFunc("/visitors", rt.Visitor.CreateVisitor).Methods("POST")
+	api.HandleFunc("/visitors", rt.Visitor.ListVisitors).Methods("GET")
+	api.HandleFunc("/visitors/{id}", rt.Visitor.GetVisitor).Methods("GET")
+	api.HandleFunc("/visitors/{id}", rt.Visitor.UpdateVisitor).Methods("PATCH")
+	api.HandleFunc("/visitors/{id}", rt.Visitor.DeleteVisitor).Methods("DELETE")
+	api.HandleFunc("/visitors/{id}/revisions", rt.Visitor.ListRevisions).Methods("GET")
+	api.HandleFunc("/visitors/{id}/approve", rt.Visitor.ApproveVisit).Methods("POST")
+	api.HandleFunc("/visitors/{id}/reject", rt.Visitor.RejectVisit).Methods("POST")
+	api.HandleFunc("/visitors/{id}/card", rt.Visitor.IssueCard).Methods("POST")
+	api.HandleFunc("/visitors/{id}/photo", rt.Photo.UploadPhoto).Methods("POST")
+	api.HandleFunc("/visitors/{id}/photo", rt.Photo.GetPhoto).Methods("GET")
+	api.HandleFunc("/visitors/{id}/photo/thumbnail", rt.Photo.GetThumbnail).Methods("GET")
+	api.HandleFunc("/visitors/{id}/badge", rt.Badge.GetBadge).Methods("GET")
+	api.HandleFunc("/badges/scan", rt.Badge.ScanBadge).Methods("POST")
+	api.HandleFunc("/approvals", rt.Visitor.ListPendingApprovals).Methods("GET")
+
+	api.HandleFunc("/smartcard/read", rt.SmartCard.ReadCard).Methods("POST")
+
+	api.HandleFunc("/events", rt.Event.StreamEvents).Methods("GET")
+
+	api.HandleFunc("/devices", rt.Device.CreateDevice).Methods("POST")
+	api.HandleFunc("/devices", rt.Device.ListDevices).Methods("GET")
+	api.HandleFunc("/devices/{id}", rt.Device.UpdateDevice).Methods("PUT")
+	api.HandleFunc("/devices/{id}/key", rt.Device.RotateKey).Methods("POST")
+	api.HandleFunc("/devices/{id}/taps", rt.Device.RecordTap).Methods("POST")
+	api.HandleFunc("/devices/{id}/taps", rt.Device.ListTaps).Methods("GET")
+	api.HandleFunc("/devices/{id}/sync", rt.Sync.Sync).Methods("POST")
+
+	api.HandleFunc("/sync/status", rt.Sync.GetSyncStatus).Methods("GET")
+	api.HandleFunc("/sync/queue", rt.Sync.ListSyncQueue).Methods("GET")
+
+	api.HandleFunc("/purposes", rt.Purpose.ListPurposes).Methods("GET")
+	api.HandleFunc("/purposes/{code}", rt.Purpose.SavePurpose).Methods("PUT")
+
+	api.HandleFunc("/departments", rt.Department.ListDepartments).Methods("GET")
+	api.HandleFunc("/departments", rt.Department.CreateDepartment).Methods("POST")
+	api.HandleFunc("/departments/{id}", rt.Department.UpdateDepartment).Methods("PUT")
+	api.HandleFunc("/departments/{id}", rt.Department.DeleteDepartment).Methods("DELETE")
+	api.HandleFunc("/watchlist", rt.Watchlist.ListWatchlist).Methods("GET")
+	api.HandleFunc("/watchlist", rt.Watchlist.CreateWatchlistEntry).Methods("POST")
+	api.HandleFunc("/watchlist/{id}", rt.Watchlist.UpdateWatchlistEntry).Methods("PUT")
+	api.HandleFunc("/watchlist/{id}", rt.Watchlist.DeleteWatchlistEntry).Methods("DELETE")
+
+	api.HandleFunc("/students", rt.Student.CreateStudent).Methods("POST")
+	api.HandleFunc("/students", rt.Student.ListStudents).Methods("GET")
+	api.HandleFunc("/students/{id}", rt.Student.GetStudent).Methods("GET")
+	api.HandleFunc("/students/{id}/guardians", rt.Student.ListGuardians).Methods("GET")
+	api.HandleFunc("/students/{id}/guardians", rt.Student.AuthoriseGuardian).Methods("POST")
+	api.HandleFunc("/students/{id}/guardians/{authId}", rt.Student.RevokeGuardian).Methods("DELETE")
+	api.HandleFunc("/visitors/{id}/pickup-students", rt.Student.GetAuthorisedStudents).Methods("GET")
+	api.HandleFunc("/pickups", rt.Student.CreatePickup).Methods("POST")
+	api.HandleFunc("/pickups", rt.Student.ListPickups).Methods("GET")
+	api.HandleFunc("/pickup-alerts", rt.Student.ListPickupAlerts).Methods("GET")
+	api.HandleFunc("/pickup-alerts/{id}/acknowledge", rt.Student.AcknowledgePickupAlert).Methods("POST")
+
+	api.HandleFunc("/export-history", rt.Export.GetExportHistory).Methods("GET")
+	api.HandleFunc("/export-history", rt.Export.CreateExportHistory).Methods("POST")
+
+	api.HandleFunc("/school", rt.School.GetSchool).Methods("GET")
+
+	api.HandleFunc("/search", rt.Search.Search).Methods("GET")
+	api.HandleFunc("/stats", rt.Stats.GetStats).Methods("GET")
+
+	api.HandleFunc("/vehicles/parking", rt.Vehicle.GetParking).Methods("GET")
+	api.HandleFunc("/vehicles/{plate}/visitors", rt.Vehicle.GetVisitsByPlate).Methods("GET")
+
+	api.HandleFunc("/address/provinces", rt.Address.GetProvinces).Methods("GET")
+	api.HandleFunc("/address/provinces/{province}/districts", rt.Address.GetDistricts).Methods("GET")
+	api.HandleFunc("/address/provinces/{province}/districts/{district}/sub-districts", rt.Address.GetSubDistricts).Methods("GET")
+	api.HandleFunc("/address/postal-codes/{code}", rt.Address.GetByPostalCode).Methods("GET")
+
+	api.HandleFunc("/appointments/scan", rt.Appointment.ScanAppointment).Methods("POST")
+	api.HandleFunc("/appointments", rt.Appointment.CreateAppointment).Methods("POST")
+	api.HandleFunc("/appointments", rt.Appointment.ListAppointments).Methods("GET")
+	api.HandleFunc("/appointments/{id}", rt.Appointment.GetAppointment).Methods("GET")
+	api.HandleFunc("/appointments/{id}/qr", rt.Appointment.GetAppointmentQR).Methods("GET")
+	api.HandleFunc("/appointments/{id}/cancel", rt.Appointment.CancelAppointment).Methods("POST")
+}
+
+// DistrictRoutes are the routes of the district staff, served outside any school
+type DistrictRoutes struct {
+	District *DistrictHandler
+}
+
+// Register adds the district routes to api (the /api subrouter)
+func (rt DistrictRoutes) Register(api *mux.Router) {
+	api.HandleFunc("/district/schools", rt.District.ListSchools).Methods("GET")
+	api.HandleFunc("/district/schools", rt.District.CreateSchool).Methods("POST")
+	api.HandleFunc("/district/schools/{id}", rt.District.UpdateSchool).Methods("PUT")
+	api.HandleFunc("/district/stats", rt.District.GetDistrictStats).Methods("GET")
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+)
+
+// Request validation and lookups of missing rows on the routes backed by the SQL repositories;
+// the happy paths are in the test file of each handler.
+func TestDatabaseBackedRoutes(t *testing.T) {
+	ts := newSQLiteServer(t)
+	ts.createVisitor(newVisitorRequest(1))
+
+	tests := []struct {
+		method, path string
+		body         interface{}
+		want         int
+	}{
+		// Devices
+		{"POST", "/api/devices", "{", http.StatusBadRequest},
+		{"PUT", "/api/devices/x", `{}`, http.StatusBadRequest},
+		{"PUT", "/api/devices/1", `{"name":"ประตูหลัง"}`, http.StatusNotFound},
+		{"POST", "/api/devices/x/key", nil, http.StatusBadRequest},
+		{"POST", "/api/devices/1/key", nil, http.StatusNotFound},
+		{"GET", "/api/devices/1/taps?limit=0", nil, http.StatusBadRequest},
+		{"GET", "/api/devices/1/taps", nil, http.StatusNotFound},
+		{"POST", "/api/devices/x/taps", `{"cardUid":"RF0001"}`, http.StatusBadRequest},
+		{"POST", "/api/devices/1/taps", `{"cardUid":"RF0001"}`, http.StatusUnauthorized},
+		{"POST", "/api/devices/x/sync", `{"items":[]}`, http.StatusBadRequest},
+		{"POST", "/api/devices/1/sync", `{"items":[]}`, http.StatusUnauthorized},
+
+		// Edge sync queue (server นี้ไม่ได้อยู่ในโหมด edge)
+		{"GET", "/api/sync/status", nil, http.StatusNotFound},
+		{"GET", "/api/sync/queue", nil, http.StatusNotFound},
+
+		// Visit purposes
+		{"PUT", "/api/purposes/meeting", `{}`, http.StatusBadRequest},
+
+		// Students and pickups
+		{"POST", "/api/students", `{"studentCode":"S001"}`, http.StatusBadRequest},
+		{"GET", "/api/students/x", nil, http.StatusBadRequest},
+		{"GET", "/api/students/1", nil, http.StatusNotFound},
+		{"POST", "/api/students/1/guardians", `{"idCard":"123"}`, http.StatusBadRequest},
+		{"POST", "/api/students/1/guardians", `{"idCard":"1101234500001","guardianName":"สมชาย ใจดี","relationship":"บิดา"}`, http.StatusNotFound},
+		{"DELETE", "/api/students/1/guardians/x", nil, http.StatusBadRequest},
+		{"DELETE", "/api/students/1/guardians/1", nil, http.StatusNotFound},
+		{"GET", "/api/visitors/99/pickup-students", nil, http.StatusNotFound},
+		{"POST", "/api/pickups", `{}`, http.StatusBadRequest},
+		{"POST", "/api/pickups", `{"visitorId":99,"studentIds":[1]}`, http.StatusNotFound},
+		{"POST", "/api/pickups", `{"visitorId":1,"studentIds":[1]}`, http.StatusNotFound},
+		{"GET", "/api/pickups?date=bad", nil, http.StatusBadRequest},
+		{"POST", "/api/pickup-alerts/x/acknowledge", nil, http.StatusBadRequest},
+		{"POST", "/api/pickup-alerts/1/acknowledge", nil, http.StatusNotFound},
+
+		// Appointments
+		{"POST", "/api/appointments", `{"firstName":"สมชาย"}`, http.StatusBadRequest},
+		{"POST", "/api/appointments", `{"firstName":"สมชาย","lastName":"ใจดี","hostName":"ครูสมศรี","expectedFrom":"2025-06-03T09:00:00+07:00","expectedUntil":"2025-06-03T08:00:00+07:00"}`, http.StatusBadRequest},
+		{"GET", "/api/appointments/x", nil, http.StatusBadRequest},
+		{"GET", "/api/appointments/1", nil, http.StatusNotFound},
+		{"GET", "/api/appointments/1/qr", nil, http.StatusNotFound},
+		{"POST", "/api/appointments/x/cancel", nil, http.StatusBadRequest},
+		{"POST", "/api/appointments/scan", "{", http.StatusBadRequest},
+		{"POST", "/api/appointments/scan", `{"token":"forged"}`, http.StatusBadRequest},
+
+		// Search and statistics
+		{"GET", "/api/search", nil, http.StatusBadRequest},
+		{"GET", "/api/search?q=abc&type=students", nil, http.StatusBadRequest},
+		{"GET", "/api/stats?groupBy=year", nil, http.StatusBadRequest},
+		{"GET", "/api/stats?startDate=2025-06-02&endDate=2025-06-01", nil, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			expectStatus(t, ts.do(tt.method, tt.path, tt.body), tt.want)
+		})
+	}
+}
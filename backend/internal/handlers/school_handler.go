@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"net/http"
+
+	"backend/internal/models"
+)
+
+// SchoolHandler serves the configuration of the school a request belongs to
+type SchoolHandler struct {
+	school *models.School
+}
+
+func NewSchoolHandler(school *models.School) *SchoolHandler {
+	return &SchoolHandler{school: school}
+}
+
+// GetSchool handles GET /api/school (ชื่อ timezone และหน่วยงานของโรงเรียน ให้หน้าเว็บใช้)
+func (h *SchoolHandler) GetSchool(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, h.school)
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/search"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+type SearchHandler struct {
+	repo *repository.SearchRepository
+}
+
+func NewSearchHandler(repo *repository.SearchRepository) *SearchHandler {
+	return &SearchHandler{repo: repo}
+}
+
+// Search handles GET /api/search
+// ค้นหาผู้มาติดต่อและประวัติการคืนบัตรพร้อมกัน (q, type=all|visitors|returns, limit)
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	q := search.Parse(r.URL.Query().Get("q"))
+	if q.IsEmpty() {
+		respondWithError(w, http.StatusBadRequest, "q is required")
+		return
+	}
+
+	limit := defaultSearchLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			respondWithError(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+		limit = min(parsed, maxSearchLimit)
+	}
+
+	searchType := r.URL.Query().Get("type")
+	if searchType == "" {
+		searchType = "all"
+	}
+	if searchType != "all" && searchType != "visitors" && searchType != "returns" {
+		respondWithError(w, http.StatusBadRequest, "type must be all, visitors or returns")
+		return
+	}
+
+	response := models.SearchResponse{
+		Query:      q.Raw,
+		Visitors:   []models.VisitorSearchHit{},
+		ReturnLogs: []models.ReturnLogSearchHit{},
+	}
+
+	if searchType != "returns" {
+		visitors, err := h.repo.SearchVisitors(q, limit)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Failed to search visitors")
+			return
+		}
+		response.Visitors = visitors
+	}
+
+	if searchType != "visitors" {
+		logs, err := h.repo.SearchReturnLogs(q, limit)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Failed to search return history")
+			return
+		}
+		response.ReturnLogs = logs
+	}
+
+	response.Total = len(response.Visitors) + len(response.ReturnLogs)
+	respondWithJSON(w, http.StatusOK, response)
+}
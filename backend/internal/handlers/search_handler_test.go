@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"backend/internal/models"
+)
+
+func TestSearch(t *testing.T) {
+	ts := newSQLiteServer(t)
+	first := ts.createVisitor(newVisitorRequest(1))
+	req := newVisitorRequest(2)
+	req.FirstName = "มานี"
+	req.Phone = "0898765432"
+	ts.createVisitor(req)
+	expectStatus(t, ts.do("POST", "/api/visitors/return/RF0001", models.ReturnCardRequest{CheckOut: "11:30"}), http.StatusOK)
+
+	search := func(path string) models.SearchResponse {
+		t.Helper()
+		rec := ts.do("GET", path, nil)
+		expectStatus(t, rec, http.StatusOK)
+		var resp models.SearchResponse
+		decode(t, rec, &resp)
+		return resp
+	}
+
+	resp := search("/api/search?q=สมชาย1")
+	if len(resp.Visitors) != 1 || resp.Visitors[0].ID != first.ID || len(resp.ReturnLogs) != 1 ||
+		resp.ReturnLogs[0].CardID != "RF0001" || resp.Total != 2 {
+		t.Errorf("search สมชาย1 = %+v", resp)
+	}
+	// เบอร์โทรที่พิมพ์มีขีดค้นเจอเหมือนพิมพ์ติดกัน
+	if resp = search("/api/search?q=089-876-5432&type=visitors"); len(resp.Visitors) != 1 || resp.Visitors[0].Name != "มานี ใจดี" || len(resp.ReturnLogs) != 0 {
+		t.Errorf("search by phone = %+v", resp)
+	}
+	if resp = search("/api/search?q=RF0001&type=returns"); len(resp.Visitors) != 0 || len(resp.ReturnLogs) != 1 {
+		t.Errorf("search returns = %+v", resp)
+	}
+	if resp = search("/api/search?q=ใจดี&limit=1"); len(resp.Visitors) != 1 {
+		t.Errorf("search with limit=1 = %+v", resp)
+	}
+	if resp = search("/api/search?q=ไม่มีใคร"); resp.Total != 0 || resp.Visitors == nil || resp.ReturnLogs == nil {
+		t.Errorf("search without hits = %+v", resp)
+	}
+
+	expectStatus(t, ts.do("GET", "/api/search", nil), http.StatusBadRequest)
+	expectStatus(t, ts.do("GET", "/api/search?q=abc&type=students", nil), http.StatusBadRequest)
+	expectStatus(t, ts.do("GET", "/api/search?q=abc&limit=0", nil), http.StatusBadRequest)
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"backend/internal/address"
+	"backend/internal/clock"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/smartcard"
+)
+
+// maxSmartCardPayload รวมรูปบนบัตร (ประมาณ 5 KB) แล้วยังเหลือที่เผื่อมาก
+const maxSmartCardPayload = 256 << 10
+
+type SmartCardHandler struct {
+	repo      repository.VisitorStore
+	addresses *address.Dataset
+	clock     *clock.Clock
+}
+
+func NewSmartCardHandler(repo repository.VisitorStore, addresses *address.Dataset, clk *clock.Clock) *SmartCardHandler {
+	return &SmartCardHandler{repo: repo, addresses: addresses, clock: clk}
+}
+
+// SmartCardReadResponse is the registration form prefilled from the card
+type SmartCardReadResponse struct {
+	Visitor           models.CreateVisitorRequest `json:"visitor"`
+	Card              *smartcard.Card             `json:"card"`
+	Warnings          []string                    `json:"warnings"`
+	AlreadyRegistered bool                        `json:"alreadyRegistered"`
+}
+
+// ReadCard handles POST /api/smartcard/read
+// รับข้อมูลดิบจากโปรแกรมอ่านบัตรประชาชน แล้วคืนฟอร์มลงทะเบียนที่กรอกไว้ให้ (ยังไม่บันทึก)
+// เจ้าหน้าที่ตรวจ/เพิ่มเบอร์โทร แล้วส่งต่อไปที่ POST /api/visitors
+func (h *SmartCardHandler) ReadCard(w http.ResponseWriter, r *http.Request) {
+	var payload smartcard.Payload
+	r.Body = http.MaxBytesReader(w, r.Body, maxSmartCardPayload)
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	card, err := smartcard.Parse(payload, h.clock.In(h.clock.Now()))
+	if err != nil {
+		if errors.Is(err, smartcard.ErrInvalidCID) || errors.Is(err, smartcard.ErrNoName) {
+			respondWithError(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	req := card.ToCreateVisitorRequest()
+	warnings := card.Warnings
+
+	// ใช้ชื่อตามข้อมูลอ้างอิง ถ้าตรวจไม่ผ่านให้เจ้าหน้าที่แก้เองในฟอร์ม
+	addr, err := h.addresses.Validate(address.Address{
+		SubDistrict: req.SubDistrict,
+		District:    req.District,
+		Province:    req.Province,
+	})
+	if err != nil {
+		warnings = append(warnings, err.Error())
+	} else {
+		req.SubDistrict = addr.SubDistrict
+		req.District = addr.District
+		req.Province = addr.Province
+	}
+
+	exists, err := h.repo.CheckIDCardExists(card.CID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to check ID card")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, SmartCardReadResponse{
+		Visitor:           req,
+		Card:              card,
+		Warnings:          warnings,
+		AlreadyRegistered: exists,
+	})
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"testing"
+)
+
+func TestReadSmartCard(t *testing.T) {
+	ts := newTestServer(t)
+	payload, err := os.ReadFile("../smartcard/testdata/bangkok.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := ts.do("POST", "/api/smartcard/read", bytes.NewReader(payload))
+	expectStatus(t, rec, http.StatusOK)
+	var read SmartCardReadResponse
+	decode(t, rec, &read)
+	if read.Visitor.IDCard != "3100500987657" || read.Visitor.FirstName == "" || read.AlreadyRegistered {
+		t.Fatalf("card read = %+v", read)
+	}
+
+	// ลงทะเบียนจากฟอร์มที่ได้ แล้วอ่านบัตรซ้ำต้องรู้ว่าลงทะเบียนแล้ว
+	form := read.Visitor
+	form.Phone = "0812345678"
+	form.Department = "ฝ่ายวิชาการ"
+	ts.createVisitor(form)
+
+	rec = ts.do("POST", "/api/smartcard/read", bytes.NewReader(payload))
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &read)
+	if !read.AlreadyRegistered {
+		t.Error("second read should report the ID card as registered")
+	}
+
+	expectStatus(t, ts.do("POST", "/api/smartcard/read", "{"), http.StatusBadRequest)
+	expectStatus(t, ts.do("POST", "/api/smartcard/read", `{"cid":"1234567890123"}`), http.StatusUnprocessableEntity)
+}
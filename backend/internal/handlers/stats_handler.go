@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"backend/internal/clock"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/thaidate"
+)
+
+// defaultStatsDays คือช่วงเริ่มต้นของหน้าแรก (30 วันล่าสุด รวมวันนี้)
+const defaultStatsDays = 30
+
+// maxStatsBuckets กันไม่ให้ขอกราฟรายวันหลายปี ให้ใช้ groupBy=week หรือ month แทน
+const maxStatsBuckets = 400
+
+type StatsHandler struct {
+	repo        *repository.StatsRepository
+	departments repository.DepartmentStore
+	clock       *clock.Clock
+	dates       thaidate.Formatter
+}
+
+func NewStatsHandler(repo *repository.StatsRepository, departments repository.DepartmentStore, clk *clock.Clock, dates thaidate.Formatter) *StatsHandler {
+	return &StatsHandler{repo: repo, departments: departments, clock: clk, dates: dates}
+}
+
+// GetStats handles GET /api/stats?startDate=&endDate=&groupBy=day|week|month&departmentId=
+// (หรือ department=ชื่อส่วนงาน ไม่ระบุ = ทุกส่วนงาน)
+// สรุปจำนวนผู้มาติดต่อตามช่วงเวลา ส่วนงาน จังหวัด ชั่วโมงที่มาถึง เวลาเฉลี่ย และการคืนบัตร
+// ค่าเริ่มต้นคือ 30 วันล่าสุดแบบรายวัน วันที่รับทั้ง ค.ศ. และ พ.ศ. ตามเวลาของโรงเรียน
+func (h *StatsHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	startDate, err := parseDateParam("startDate", query.Get("startDate"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	endDate, err := parseDateParam("endDate", query.Get("endDate"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	dates, err := dateFormatter(query, h.dates)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if endDate == "" {
+		endDate = h.clock.Today()
+	}
+	end, _ := time.Parse("2006-01-02", endDate)
+	if startDate == "" {
+		startDate = end.AddDate(0, 0, -(defaultStatsDays - 1)).Format("2006-01-02")
+	}
+	start, _ := time.Parse("2006-01-02", startDate)
+	if start.After(end) {
+		respondWithError(w, http.StatusBadRequest, "startDate must not be after endDate")
+		return
+	}
+
+	groupBy := query.Get("groupBy")
+	if groupBy == "" {
+		groupBy = models.StatsByDay
+	}
+	buckets := 0
+	switch groupBy {
+	case models.StatsByDay:
+		buckets = int(end.Sub(start).Hours()/24) + 1
+	case models.StatsByWeek:
+		buckets = int(end.Sub(start).Hours()/24)/7 + 2
+	case models.StatsByMonth:
+		buckets = (end.Year()-start.Year())*12 + int(end.Month()-start.Month()) + 1
+	default:
+		respondWithError(w, http.StatusBadRequest, "groupBy must be day, week or month")
+		return
+	}
+	if buckets > maxStatsBuckets {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Range is too long for groupBy=%s, use a larger groupBy", groupBy))
+		return
+	}
+
+	var department *models.Department
+	if raw := query.Get("departmentId"); raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil || id <= 0 {
+			respondWithError(w, http.StatusBadRequest, "departmentId must be a positive number")
+			return
+		}
+		if department, err = h.departments.GetByID(id); err != nil {
+			respondWithError(w, http.StatusBadRequest, "unknown departmentId")
+			return
+		}
+	} else if name := query.Get("department"); !isAllDepartments(name) {
+		if department, err = h.departments.GetByName(name); err != nil {
+			respondWithError(w, http.StatusBadRequest, "unknown department: "+name)
+			return
+		}
+	}
+
+	params := models.StatsParams{StartDate: startDate, EndDate: endDate, GroupBy: groupBy}
+	if department != nil {
+		params.DepartmentID = department.ID
+	}
+
+	stats, err := h.repo.Summary(params)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get statistics")
+		return
+	}
+	if department != nil {
+		stats.Department = department.NameTh
+	}
+
+	for i := range stats.Series {
+		stats.Series[i].Label = statsLabel(dates, stats.Series[i].Start, groupBy)
+	}
+
+	respondWithJSON(w, http.StatusOK, stats)
+}
+
+// statsLabel formats a bucket for chart axes, e.g. 01/06/2568 or มิ.ย. 2568 for months
+func statsLabel(dates thaidate.Formatter, bucketStart, groupBy string) string {
+	day, err := time.Parse("2006-01-02", bucketStart)
+	if err != nil {
+		return bucketStart
+	}
+	if groupBy == models.StatsByMonth {
+		return dates.MonthYear(day)
+	}
+	return dates.CalendarDate(day)
+}
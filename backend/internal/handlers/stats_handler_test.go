@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+)
+
+func TestGetStats(t *testing.T) {
+	ts := newSQLiteServer(t)
+	ts.createVisitor(newVisitorRequest(1))
+	req := newVisitorRequest(2)
+	req.Department = "ฝ่ายบุคคล"
+	req.Province = "นนทบุรี"
+	req.District = "ปากเกร็ด"
+	req.PostalCode = "11120"
+	ts.createVisitor(req)
+	ts.advance(time.Hour)
+	expectStatus(t, ts.do("POST", "/api/visitors/return/RF0001", models.ReturnCardRequest{CheckOut: "11:00"}), http.StatusOK)
+
+	rec := ts.do("GET", "/api/stats", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var stats models.StatsResponse
+	decode(t, rec, &stats)
+	if stats.StartDate != "2025-05-04" || stats.EndDate != "2025-06-02" || len(stats.Series) != 30 {
+		t.Fatalf("default range = %s..%s with %d buckets", stats.StartDate, stats.EndDate, len(stats.Series))
+	}
+	totals := models.StatsTotals{Visits: 2, CardsReturned: 1, CardsUnreturned: 1, AverageDurationMinutes: 60}
+	if stats.Totals != totals {
+		t.Errorf("totals = %+v, want %+v", stats.Totals, totals)
+	}
+	if last := stats.Series[29]; last.Label != "02/06/2025" || last.Visits != 2 || last.CardsReturned != 1 {
+		t.Errorf("today = %+v", last)
+	}
+	if len(stats.Provinces) != 2 || len(stats.Departments) != 2 || len(stats.Hours) != 24 || stats.Hours[10].Visits != 2 {
+		t.Errorf("breakdown = %+v %+v %+v", stats.Provinces, stats.Departments, stats.Hours)
+	}
+	if stats.PeakHour == nil || *stats.PeakHour != 10 {
+		t.Errorf("peak hour = %v", stats.PeakHour)
+	}
+
+	rec = ts.do("GET", "/api/stats?startDate=2568-06-01&endDate=2568-06-30&groupBy=month&department=ฝ่ายบุคคล", nil)
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &stats)
+	if stats.Department != "ฝ่ายบุคคล" || stats.Totals.Visits != 1 || len(stats.Series) != 1 || stats.Series[0].Label != "06/2025" {
+		t.Errorf("June for ฝ่ายบุคคล = %+v", stats)
+	}
+
+	rec = ts.do("GET", "/api/stats?startDate=2025-06-03&endDate=2025-06-09&groupBy=week", nil)
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &stats)
+	if stats.Totals.Visits != 0 || stats.PeakHour != nil {
+		t.Errorf("empty week = %+v", stats)
+	}
+
+	for _, path := range []string{
+		"/api/stats?groupBy=year",
+		"/api/stats?startDate=2025-06-02&endDate=2025-06-01",
+		"/api/stats?startDate=2020-01-01&endDate=2025-06-01",
+		"/api/stats?endDate=bad",
+		"/api/stats?departmentId=x",
+		"/api/stats?department=ฝ่ายที่ไม่มี",
+	} {
+		expectStatus(t, ts.do("GET", path, nil), http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,397 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"backend/internal/clock"
+	"backend/internal/events"
+	"backend/internal/models"
+	"backend/internal/notify"
+	"backend/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type StudentHandler struct {
+	students *repository.StudentRepository
+	pickups  *repository.PickupRepository
+	visitors repository.VisitorStore
+	notifier notify.Notifier
+	clock    *clock.Clock
+	bus      *events.Bus
+}
+
+func NewStudentHandler(
+	students *repository.StudentRepository,
+	pickups *repository.PickupRepository,
+	visitors repository.VisitorStore,
+	notifier notify.Notifier,
+	clk *clock.Clock,
+	bus *events.Bus,
+) *StudentHandler {
+	return &StudentHandler{
+		students: students,
+		pickups:  pickups,
+		visitors: visitors,
+		notifier: notifier,
+		clock:    clk,
+		bus:      bus,
+	}
+}
+
+// CreateStudent handles POST /api/students
+func (h *StudentHandler) CreateStudent(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateStudentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.StudentCode == "" || req.FirstName == "" || req.LastName == "" {
+		respondWithError(w, http.StatusBadRequest, "studentCode, firstName and lastName are required")
+		return
+	}
+
+	student := &models.Student{
+		StudentCode: req.StudentCode,
+		FirstName:   req.FirstName,
+		LastName:    req.LastName,
+		ClassRoom:   req.ClassRoom,
+		Active:      true,
+		CreatedAt:   h.clock.Now(),
+	}
+
+	if err := h.students.Create(student); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to create student")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, student)
+}
+
+// ListStudents handles GET /api/students?search=
+func (h *StudentHandler) ListStudents(w http.ResponseWriter, r *http.Request) {
+	students, err := h.students.List(r.URL.Query().Get("search"))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to list students")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, students)
+}
+
+// GetStudent handles GET /api/students/{id}
+func (h *StudentHandler) GetStudent(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid student ID")
+		return
+	}
+
+	student, err := h.students.GetByID(id)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Student not found")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, student)
+}
+
+// ListGuardians handles GET /api/students/{id}/guardians
+func (h *StudentHandler) ListGuardians(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid student ID")
+		return
+	}
+
+	guardians, err := h.students.ListGuardians(id)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to list guardians")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, guardians)
+}
+
+// AuthoriseGuardian handles POST /api/students/{id}/guardians
+func (h *StudentHandler) AuthoriseGuardian(w http.ResponseWriter, r *http.Request) {
+	studentID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid student ID")
+		return
+	}
+
+	var req models.AuthoriseGuardianRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if len(req.IDCard) != 13 {
+		respondWithError(w, http.StatusBadRequest, "ID card must be 13 digits")
+		return
+	}
+	if req.GuardianName == "" || req.Relationship == "" {
+		respondWithError(w, http.StatusBadRequest, "guardianName and relationship are required")
+		return
+	}
+
+	if _, err := h.students.GetByID(studentID); err != nil {
+		respondWithError(w, http.StatusNotFound, "Student not found")
+		return
+	}
+
+	validFrom := h.clock.TodayDate()
+	if req.ValidFrom != "" {
+		validFrom, err = time.Parse("2006-01-02", req.ValidFrom)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "validFrom must be in YYYY-MM-DD format")
+			return
+		}
+	}
+
+	var validUntil *time.Time
+	if req.ValidUntil != nil && *req.ValidUntil != "" {
+		parsed, err := time.Parse("2006-01-02", *req.ValidUntil)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "validUntil must be in YYYY-MM-DD format")
+			return
+		}
+		if parsed.Before(validFrom) {
+			respondWithError(w, http.StatusBadRequest, "validUntil must not be before validFrom")
+			return
+		}
+		validUntil = &parsed
+	}
+
+	auth := &models.GuardianAuthorisation{
+		StudentID:    studentID,
+		IDCard:       req.IDCard,
+		GuardianName: req.GuardianName,
+		Relationship: req.Relationship,
+		ValidFrom:    validFrom,
+		ValidUntil:   validUntil,
+		CreatedAt:    h.clock.Now(),
+	}
+
+	if err := h.students.Authorise(auth); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to authorise guardian")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, auth)
+}
+
+// RevokeGuardian handles DELETE /api/students/{id}/guardians/{authId}
+func (h *StudentHandler) RevokeGuardian(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	studentID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid student ID")
+		return
+	}
+	authID, err := strconv.Atoi(vars["authId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid authorisation ID")
+		return
+	}
+
+	if err := h.students.Revoke(studentID, authID); err != nil {
+		respondWithError(w, http.StatusNotFound, "Authorisation not found")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "ยกเลิกสิทธิ์รับนักเรียนสำเร็จ"})
+}
+
+// GetAuthorisedStudents handles GET /api/visitors/{id}/pickup-students
+// รายชื่อนักเรียนที่ผู้มาติดต่อคนนี้มีสิทธิ์รับในวันนี้
+func (h *StudentHandler) GetAuthorisedStudents(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid visitor ID")
+		return
+	}
+
+	visitor, err := h.visitors.GetByID(id)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Visitor not found")
+		return
+	}
+
+	students, err := h.students.ListAuthorisedStudents(visitor.IDCard)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to list authorised students")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, students)
+}
+
+// CreatePickup handles POST /api/pickups
+// ตรวจสิทธิ์ทุกคนก่อน ถ้ามีนักเรียนที่ผู้มาติดต่อไม่มีสิทธิ์รับ จะปฏิเสธทั้งหมด บันทึกการแจ้งเตือน และแจ้งเจ้าหน้าที่
+func (h *StudentHandler) CreatePickup(w http.ResponseWriter, r *http.Request) {
+	var req models.PickupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.VisitorID == 0 || len(req.StudentIDs) == 0 {
+		respondWithError(w, http.StatusBadRequest, "visitorId and studentIds are required")
+		return
+	}
+
+	visitor, err := h.visitors.GetByID(req.VisitorID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Visitor not found")
+		return
+	}
+	visitorName := visitor.FirstName + " " + visitor.LastName
+	now := h.clock.Now()
+
+	pickups := []models.StudentPickup{}
+	refused := []models.PickupAlert{}
+
+	for _, studentID := range req.StudentIDs {
+		student, err := h.students.GetByID(studentID)
+		if err != nil {
+			respondWithError(w, http.StatusNotFound, fmt.Sprintf("Student %d not found", studentID))
+			return
+		}
+
+		auth, err := h.students.FindValidAuthorisation(studentID, visitor.IDCard)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Failed to check authorisation")
+			return
+		}
+
+		studentName := student.FirstName + " " + student.LastName
+		if auth == nil {
+			refused = append(refused, models.PickupAlert{
+				StudentID:   studentID,
+				StudentName: studentName,
+				VisitorID:   &visitor.ID,
+				IDCard:      visitor.IDCard,
+				VisitorName: visitorName,
+				Reason:      "ไม่อยู่ในรายชื่อผู้มีสิทธิ์รับนักเรียน",
+				OfficerName: req.OfficerName,
+				CreatedAt:   now,
+			})
+			continue
+		}
+
+		pickups = append(pickups, models.StudentPickup{
+			StudentID:       studentID,
+			StudentName:     studentName,
+			VisitorID:       visitor.ID,
+			VisitorName:     visitorName,
+			AuthorisationID: auth.ID,
+			Relationship:    auth.Relationship,
+			OfficerName:     req.OfficerName,
+			PickedUpAt:      now,
+		})
+	}
+
+	if len(refused) > 0 {
+		for i := range refused {
+			if err := h.pickups.CreateAlert(&refused[i]); err != nil {
+				log.Printf("Failed to record pickup alert: %v", err)
+			}
+			go notifyPickupRefused(h.notifier, refused[i])
+			h.bus.Publish(events.TypePickupRefused, "", refused[i])
+		}
+
+		respondWithJSON(w, http.StatusForbidden, map[string]interface{}{
+			"error":  "ผู้มาติดต่อไม่มีสิทธิ์รับนักเรียน",
+			"alerts": refused,
+		})
+		return
+	}
+
+	// ผู้มารับออกไปพร้อมนักเรียน ปิดการเข้าพบ (และคืนบัตร) ไปพร้อมกัน เว้นแต่คืนบัตรไปก่อนแล้ว
+	var returned *models.ReturnCardLog
+	if visitor.ExitTime == nil {
+		returned = newReturnLog(h.clock, visitor, h.clock.In(now).Format("15:04"), now)
+		returned.ClientID = uuid.NewString()
+	}
+
+	if err := h.pickups.CreatePickups(pickups, returned); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to record pickup")
+		return
+	}
+	if returned != nil && returned.CardID != "" {
+		publishCardReturned(h.bus, visitor, returned, nil)
+	}
+
+	respondWithJSON(w, http.StatusCreated, pickups)
+}
+
+// ListPickups handles GET /api/pickups?date=YYYY-MM-DD (ค่าเริ่มต้นคือวันนี้)
+func (h *StudentHandler) ListPickups(w http.ResponseWriter, r *http.Request) {
+	date, err := parseDateParam("date", r.URL.Query().Get("date"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if date == "" {
+		date = h.clock.Today()
+	}
+
+	pickups, err := h.pickups.ListPickups(date)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to list pickups")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, pickups)
+}
+
+// ListPickupAlerts handles GET /api/pickup-alerts?all=true
+func (h *StudentHandler) ListPickupAlerts(w http.ResponseWriter, r *http.Request) {
+	alerts, err := h.pickups.ListAlerts(r.URL.Query().Get("all") != "true")
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to list pickup alerts")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, alerts)
+}
+
+// AcknowledgePickupAlert handles POST /api/pickup-alerts/{id}/acknowledge
+func (h *StudentHandler) AcknowledgePickupAlert(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid alert ID")
+		return
+	}
+
+	if err := h.pickups.AcknowledgeAlert(id); err != nil {
+		respondWithError(w, http.StatusNotFound, "Alert not found")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "รับทราบการแจ้งเตือนแล้ว"})
+}
+
+// notifyPickupRefused alerts the guard station about a refused pickup
+func notifyPickupRefused(notifier notify.Notifier, alert models.PickupAlert) {
+	err := notifier.Notify(notify.Message{
+		Type:      "pickup.refused",
+		Recipient: "security",
+		Title:     "⚠️ มีผู้พยายามรับนักเรียนโดยไม่มีสิทธิ์",
+		Body: fmt.Sprintf("%s (บัตร %s) พยายามรับ %s: %s",
+			alert.VisitorName, alert.IDCard, alert.StudentName, alert.Reason),
+		Data: alert,
+	})
+	if err != nil {
+		log.Printf("Failed to send pickup alert %d: %v", alert.ID, err)
+	}
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"backend/internal/events"
+	"backend/internal/models"
+)
+
+// createStudent adds a student through the API and returns it
+func (ts *testServer) createStudent(code, firstName string) models.Student {
+	ts.t.Helper()
+	rec := ts.do("POST", "/api/students", models.CreateStudentRequest{StudentCode: code, FirstName: firstName, LastName: "ใจดี", ClassRoom: "ป.1/1"})
+	expectStatus(ts.t, rec, http.StatusCreated)
+	var s models.Student
+	decode(ts.t, rec, &s)
+	return s
+}
+
+func TestStudentsAndGuardians(t *testing.T) {
+	ts := newSQLiteServer(t)
+	student := ts.createStudent("S001", "ด.ช.ต้น")
+	ts.createStudent("S002", "ด.ญ.ฝน")
+	expectStatus(t, ts.do("POST", "/api/students", models.CreateStudentRequest{StudentCode: "S003"}), http.StatusBadRequest)
+
+	rec := ts.do("GET", "/api/students?search=S001", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var students []models.Student
+	decode(t, rec, &students)
+	if len(students) != 1 || students[0].ID != student.ID {
+		t.Errorf("students matching S001 = %+v", students)
+	}
+	path := "/api/students/" + strconv.Itoa(student.ID)
+	rec = ts.do("GET", path, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var got models.Student
+	decode(t, rec, &got)
+	if got.StudentCode != "S001" || got.FirstName != "ด.ช.ต้น" || !got.Active {
+		t.Errorf("student = %+v", got)
+	}
+	expectStatus(t, ts.do("GET", "/api/students/99", nil), http.StatusNotFound)
+	expectStatus(t, ts.do("GET", "/api/students/x", nil), http.StatusBadRequest)
+
+	v := ts.createVisitor(newVisitorRequest(1))
+	guardian := models.AuthoriseGuardianRequest{IDCard: v.IDCard, GuardianName: "สมชาย ใจดี", Relationship: "บิดา"}
+	rec = ts.do("POST", path+"/guardians", guardian)
+	expectStatus(t, rec, http.StatusCreated)
+	var auth models.GuardianAuthorisation
+	decode(t, rec, &auth)
+	if !auth.ValidFrom.Equal(ts.clock.TodayDate()) || auth.ValidUntil != nil {
+		t.Errorf("authorisation = %+v", auth)
+	}
+
+	until := "2025-06-01"
+	tests := []struct {
+		name string
+		req  models.AuthoriseGuardianRequest
+	}{
+		{"short ID card", models.AuthoriseGuardianRequest{IDCard: "123", GuardianName: "แม่", Relationship: "มารดา"}},
+		{"no relationship", models.AuthoriseGuardianRequest{IDCard: v.IDCard, GuardianName: "แม่"}},
+		{"bad date", models.AuthoriseGuardianRequest{IDCard: v.IDCard, GuardianName: "แม่", Relationship: "มารดา", ValidFrom: "02/06/2025"}},
+		{"ends before it starts", models.AuthoriseGuardianRequest{IDCard: v.IDCard, GuardianName: "แม่", Relationship: "มารดา", ValidUntil: &until}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expectStatus(t, ts.do("POST", path+"/guardians", tt.req), http.StatusBadRequest)
+		})
+	}
+	expectStatus(t, ts.do("POST", "/api/students/99/guardians", guardian), http.StatusNotFound)
+
+	// ผู้มาติดต่อเห็นรายชื่อนักเรียนที่รับได้วันนี้
+	visitorPath := "/api/visitors/" + strconv.Itoa(v.ID) + "/pickup-students"
+	rec = ts.do("GET", visitorPath, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var authorised []models.AuthorisedStudent
+	decode(t, rec, &authorised)
+	if len(authorised) != 1 || authorised[0].Student.ID != student.ID || authorised[0].Relationship != "บิดา" {
+		t.Errorf("authorised students = %+v", authorised)
+	}
+	expectStatus(t, ts.do("GET", "/api/visitors/99/pickup-students", nil), http.StatusNotFound)
+
+	authPath := path + "/guardians/" + strconv.Itoa(auth.ID)
+	expectStatus(t, ts.do("DELETE", authPath, nil), http.StatusOK)
+	expectStatus(t, ts.do("DELETE", authPath, nil), http.StatusNotFound)
+	expectStatus(t, ts.do("DELETE", path+"/guardians/x", nil), http.StatusBadRequest)
+
+	rec = ts.do("GET", path+"/guardians", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var guardians []models.GuardianAuthorisation
+	decode(t, rec, &guardians)
+	if len(guardians) != 1 || guardians[0].RevokedAt == nil {
+		t.Errorf("guardians after revoking = %+v", guardians)
+	}
+	rec = ts.do("GET", visitorPath, nil)
+	decode(t, rec, &authorised)
+	if len(authorised) != 0 {
+		t.Errorf("authorised students after revoking = %+v", authorised)
+	}
+}
+
+func TestCreatePickup(t *testing.T) {
+	ts := newSQLiteServer(t)
+	son := ts.createStudent("S001", "ด.ช.ต้น")
+	daughter := ts.createStudent("S002", "ด.ญ.ฝน")
+	v := ts.createVisitor(newVisitorRequest(1))
+	expectStatus(t, ts.do("POST", "/api/students/"+strconv.Itoa(son.ID)+"/guardians",
+		models.AuthoriseGuardianRequest{IDCard: v.IDCard, GuardianName: "สมชาย ใจดี", Relationship: "บิดา"}), http.StatusCreated)
+
+	sub, _, _ := ts.bus.Subscribe(events.Filter{}, 0)
+	defer sub.Close()
+
+	// ไม่มีสิทธิ์รับคนใดคนหนึ่ง ปฏิเสธทั้งหมดและแจ้งเตือน
+	ts.advance(5 * time.Hour)
+	rec := ts.do("POST", "/api/pickups", models.PickupRequest{VisitorID: v.ID, StudentIDs: []int{son.ID, daughter.ID}, OfficerName: "ยามหน้า"})
+	expectStatus(t, rec, http.StatusForbidden)
+	if e := <-sub.C; e.Type != events.TypePickupRefused {
+		t.Errorf("event = %+v", e)
+	}
+	rec = ts.do("GET", "/api/pickup-alerts", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var alerts []models.PickupAlert
+	decode(t, rec, &alerts)
+	if len(alerts) != 1 || alerts[0].StudentID != daughter.ID || alerts[0].VisitorID == nil || *alerts[0].VisitorID != v.ID || !alerts[0].CreatedAt.Equal(ts.clock.Now()) {
+		t.Fatalf("pickup alerts = %+v", alerts)
+	}
+	alertPath := "/api/pickup-alerts/" + strconv.Itoa(alerts[0].ID) + "/acknowledge"
+	expectStatus(t, ts.do("POST", alertPath, nil), http.StatusOK)
+	expectStatus(t, ts.do("POST", alertPath, nil), http.StatusNotFound)
+	expectStatus(t, ts.do("POST", "/api/pickup-alerts/x/acknowledge", nil), http.StatusBadRequest)
+	rec = ts.do("GET", "/api/pickup-alerts?all=true", nil)
+	decode(t, rec, &alerts)
+	if len(alerts) != 1 || alerts[0].AcknowledgedAt == nil {
+		t.Errorf("all pickup alerts = %+v", alerts)
+	}
+	if visitor, _ := ts.visitors.GetByID(v.ID); visitor.ExitTime != nil {
+		t.Errorf("refused pickup closed the visit at %v", visitor.ExitTime)
+	}
+
+	// รับได้: บันทึกการรับ ปิดการเข้าพบและคืนบัตรไปพร้อมกัน
+	rec = ts.do("POST", "/api/pickups", models.PickupRequest{VisitorID: v.ID, StudentIDs: []int{son.ID}, OfficerName: "ยามหน้า"})
+	expectStatus(t, rec, http.StatusCreated)
+	var pickups []models.StudentPickup
+	decode(t, rec, &pickups)
+	if len(pickups) != 1 || pickups[0].Relationship != "บิดา" || !pickups[0].PickedUpAt.Equal(ts.clock.Now()) {
+		t.Errorf("pickups = %+v", pickups)
+	}
+	visitor, err := ts.visitors.GetByID(v.ID)
+	if err != nil || visitor.ExitTime == nil || !visitor.ExitTime.Equal(ts.clock.Now()) {
+		t.Fatalf("visitor after pickup = %+v, %v", visitor, err)
+	}
+	if duplicate, err := ts.visitors.CheckDuplicateReturn(v.RFID); err != nil || !duplicate {
+		t.Errorf("card %s was not returned with the pickup: %v", v.RFID, err)
+	}
+	if e := <-sub.C; e.Type != events.TypeCardReturned {
+		t.Errorf("event = %+v", e)
+	}
+
+	rec = ts.do("GET", "/api/pickups", nil)
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &pickups)
+	if len(pickups) != 1 || pickups[0].StudentName != "ด.ช.ต้น ใจดี" || pickups[0].VisitorName != "สมชาย1 ใจดี" {
+		t.Errorf("today's pickups = %+v", pickups)
+	}
+	rec = ts.do("GET", "/api/pickups?date=2025-06-01", nil)
+	decode(t, rec, &pickups)
+	if len(pickups) != 0 {
+		t.Errorf("pickups of yesterday = %+v", pickups)
+	}
+
+	expectStatus(t, ts.do("GET", "/api/pickups?date=bad", nil), http.StatusBadRequest)
+	expectStatus(t, ts.do("POST", "/api/pickups", models.PickupRequest{}), http.StatusBadRequest)
+	expectStatus(t, ts.do("POST", "/api/pickups", models.PickupRequest{VisitorID: 99, StudentIDs: []int{son.ID}}), http.StatusNotFound)
+	expectStatus(t, ts.do("POST", "/api/pickups", models.PickupRequest{VisitorID: v.ID, StudentIDs: []int{99}}), http.StatusNotFound)
+}
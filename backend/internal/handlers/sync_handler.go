@@ -0,0 +1,347 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"backend/internal/clock"
+	"backend/internal/events"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/token"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+const (
+	maxSyncItems         = 500
+	defaultSyncListLimit = 100
+	maxSyncListLimit     = 1000
+)
+
+// SyncHandler receives the records made at edge gates (server กลาง)
+// และแสดงคิวที่ยังไม่ได้ส่งขึ้นไป (เครื่อง edge)
+type SyncHandler struct {
+	devices     *repository.DeviceRepository
+	visitors    repository.VisitorStore
+	returns     repository.ReturnLogStore
+	departments repository.DepartmentStore
+	watchlist   repository.WatchlistStore
+	queue       *repository.SyncQueueRepository // nil = ไม่ได้อยู่ในโหมด edge
+	clock       *clock.Clock
+	bus         *events.Bus
+}
+
+func NewSyncHandler(
+	devices *repository.DeviceRepository,
+	visitors repository.VisitorStore,
+	returns repository.ReturnLogStore,
+	departments repository.DepartmentStore,
+	watchlist repository.WatchlistStore,
+	queue *repository.SyncQueueRepository,
+	clk *clock.Clock,
+	bus *events.Bus,
+) *SyncHandler {
+	return &SyncHandler{devices: devices, visitors: visitors, returns: returns, departments: departments, watchlist: watchlist, queue: queue, clock: clk, bus: bus}
+}
+
+// newClientID returns the UUID a client sent, or a new one when it sent none
+func newClientID(requested string) (string, error) {
+	if requested == "" {
+		return uuid.NewString(), nil
+	}
+	id, err := uuid.Parse(requested)
+	if err != nil {
+		return "", fmt.Errorf("clientId must be a UUID")
+	}
+	return id.String(), nil
+}
+
+// Sync handles POST /api/devices/{id}/sync
+// เครื่อง edge ส่งรายการที่บันทึกไว้ (เรียงตามเวลา) พร้อม API key ของเครื่อง role edge
+// แต่ละรายการได้ผล applied, duplicate (เคยรับแล้ว), conflict (ไม่รับ พร้อมเหตุผล) หรือ error (ส่งใหม่ได้)
+func (h *SyncHandler) Sync(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid device ID")
+		return
+	}
+
+	device, err := h.devices.GetByID(id)
+	if err != nil || !token.MatchAPIKey(DeviceKey(r), device.KeyHash) {
+		respondWithError(w, http.StatusUnauthorized, "Invalid device or API key")
+		return
+	}
+	if !device.Active {
+		respondWithError(w, http.StatusForbidden, "Device is disabled")
+		return
+	}
+	if device.Role != models.DeviceRoleEdge {
+		respondWithError(w, http.StatusForbidden, "Device is not an edge gate")
+		return
+	}
+
+	var req models.SyncRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if len(req.Items) > maxSyncItems {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("at most %d items per sync", maxSyncItems))
+		return
+	}
+
+	if err := h.devices.Touch(device.ID); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to update device")
+		return
+	}
+
+	// หยุดที่รายการแรกที่บันทึกไม่สำเร็จ รายการหลังจากนั้น (เช่นการคืนบัตรของคนที่ยังลงทะเบียนไม่ได้)
+	// ให้เครื่อง edge ส่งใหม่ตามลำดับเดิมรอบหน้า
+	response := models.SyncResponse{Results: make([]models.SyncItemResult, len(req.Items))}
+	failed := false
+	for i, item := range req.Items {
+		if failed {
+			response.Results[i] = models.SyncItemResult{
+				ClientID: item.ClientID,
+				Status:   models.SyncFailed,
+				Message:  "ยังไม่ได้บันทึก เพราะรายการก่อนหน้าบันทึกไม่สำเร็จ",
+			}
+			continue
+		}
+		response.Results[i] = h.apply(item, device.ID)
+		failed = response.Results[i].Status == models.SyncFailed
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// apply records one item from an edge gate unless the central server already has it or it conflicts
+func (h *SyncHandler) apply(item models.SyncItem, deviceID int) models.SyncItemResult {
+	result := models.SyncItemResult{ClientID: item.ClientID}
+	if _, err := uuid.Parse(item.ClientID); err != nil {
+		result.Status = models.SyncConflict
+		result.Message = "clientId must be a UUID"
+		return result
+	}
+
+	switch {
+	case item.Kind == models.SyncKindVisitor && item.Visitor != nil:
+		h.applyVisitor(item.ClientID, item.Visitor, &result)
+	case item.Kind == models.SyncKindReturn && item.Return != nil:
+		h.applyReturn(item.ClientID, item.Return, deviceID, &result)
+	case item.Kind == models.SyncKindVoid && item.Void != nil:
+		h.applyVoid(item.Void, &result)
+	default:
+		result.Status = models.SyncConflict
+		result.Message = fmt.Sprintf("unknown sync item kind %q", item.Kind)
+	}
+	return result
+}
+
+// applyVisitor creates a registration made at the gate, keeping the time it was made.
+// เลขบัตรประชาชนหรือบัตร RFID ที่ลงทะเบียนที่อื่นแล้ว (เช่นอีกประตูหนึ่ง) ถือว่าขัดแย้ง ไม่เขียนทับ
+func (h *SyncHandler) applyVisitor(clientID string, sent *models.Visitor, result *models.SyncItemResult) {
+	if existing, err := h.visitors.GetByClientID(clientID); err == nil {
+		result.Status = models.SyncDuplicate
+		result.ID = existing.ID
+		return
+	}
+
+	exists, err := h.visitors.CheckIDCardExists(sent.IDCard)
+	if err != nil {
+		result.Status = models.SyncFailed
+		result.Message = "Failed to check ID card"
+		return
+	}
+	if exists {
+		result.Status = models.SyncConflict
+		result.Message = "เลขบัตรประชาชนนี้ลงทะเบียนที่ server กลางแล้ว"
+		return
+	}
+	if sent.RFID != "" {
+		exists, err := h.visitors.CheckRFIDExists(sent.RFID)
+		if err != nil {
+			result.Status = models.SyncFailed
+			result.Message = "Failed to check RFID"
+			return
+		}
+		if exists {
+			result.Status = models.SyncConflict
+			result.Message = "บัตร RFID นี้ถูกใช้ที่ server กลางแล้ว"
+			return
+		}
+	}
+
+	// id รถ นัดหมาย ส่วนงาน และรูปเป็นของฐานข้อมูลที่ประตู ไม่ส่งต่อ (รถสร้างใหม่จากทะเบียน ส่วนงานหาจากชื่อ)
+	visitor := *sent
+	visitor.ID = 0
+	visitor.ClientID = clientID
+	visitor.VehicleID = nil
+	visitor.Vehicle = nil
+	visitor.AppointmentID = nil
+	visitor.DepartmentID = nil
+	visitor.ExitTime = nil
+	if department, err := h.departments.GetByName(visitor.Department); err == nil {
+		visitor.DepartmentID = &department.ID
+	}
+	if visitor.RegisteredAt.IsZero() {
+		visitor.RegisteredAt = h.clock.Now()
+	}
+	if visitor.LicensePlate != "" {
+		if v, err := buildVehicle(&models.VehicleRequest{PlateNumber: visitor.LicensePlate}); err == nil {
+			visitor.Vehicle = v
+			visitor.LicensePlate = v.PlateDisplay
+		}
+	}
+
+	if err := h.visitors.Create(&visitor); err != nil {
+		result.Status = models.SyncFailed
+		result.Message = fmt.Sprintf("Failed to create visitor: %v", err)
+		return
+	}
+	h.bus.Publish(events.TypeVisitorRegistered, visitor.Department, events.VisitorOf(&visitor))
+	screenVisitor(h.watchlist, h.bus, &visitor)
+
+	result.Status = models.SyncApplied
+	result.ID = visitor.ID
+}
+
+// applyReturn records a card returned at the gate, at the time it was returned.
+// การเข้าเยี่ยมที่คืนบัตรไปแล้ว (คืนซ้ำ หรือคืนที่อื่นระหว่างเน็ตหลุด) ถือว่าขัดแย้ง
+func (h *SyncHandler) applyReturn(clientID string, sent *models.SyncReturn, deviceID int, result *models.SyncItemResult) {
+	exists, err := h.returns.CheckReturnLogExists(clientID)
+	if err != nil {
+		result.Status = models.SyncFailed
+		result.Message = "Failed to check return log"
+		return
+	}
+	if exists {
+		result.Status = models.SyncDuplicate
+		return
+	}
+
+	visitor, err := h.visitors.GetByClientID(sent.VisitorClientID)
+	if err != nil {
+		result.Status = models.SyncConflict
+		result.Message = "ไม่พบการลงทะเบียนของบัตรนี้ที่ server กลาง"
+		return
+	}
+	if visitor.ExitTime != nil {
+		result.Status = models.SyncConflict
+		result.Message = "บัตรนี้คืนที่ server กลางไปแล้ว เวลา " + h.clock.In(*visitor.ExitTime).Format("15:04")
+		return
+	}
+
+	returnedAt := sent.ReturnedAt
+	if returnedAt.IsZero() {
+		returnedAt = h.clock.Now()
+	}
+	log := newReturnLog(h.clock, visitor, sent.CheckOut, returnedAt)
+	log.ClientID = clientID
+	if err := h.returns.CreateReturnLog(log); err != nil {
+		if errors.Is(err, repository.ErrDuplicateReturn) {
+			result.Status = models.SyncConflict
+			result.Message = "บัตรนี้ถูกคืนไปแล้ววันนั้น"
+			return
+		}
+		result.Status = models.SyncFailed
+		result.Message = fmt.Sprintf("Failed to create return log: %v", err)
+		return
+	}
+	publishCardReturned(h.bus, visitor, log, &deviceID)
+
+	result.Status = models.SyncApplied
+	result.ID = log.ID
+}
+
+// applyVoid voids a return the gate sent earlier and then voided (สแกนบัตรผิดใบที่ประตู)
+// การคืนที่ server กลางยกเลิกไปแล้วถือว่าเคยรับ
+func (h *SyncHandler) applyVoid(sent *models.SyncVoid, result *models.SyncItemResult) {
+	log, err := h.returns.GetReturnLogByClientID(sent.ReturnClientID)
+	if err != nil {
+		result.Status = models.SyncConflict
+		result.Message = "ไม่พบการคืนบัตรนี้ที่ server กลาง"
+		return
+	}
+
+	revision := &models.VisitorRevision{Action: models.RevisionVoidReturn, ChangedBy: sent.VoidedBy, Reason: sent.Reason}
+	voided, err := h.returns.VoidReturnLog(log.ID, revision)
+	switch {
+	case errors.Is(err, repository.ErrReturnAlreadyVoided):
+		result.Status = models.SyncDuplicate
+		result.ID = log.ID
+		return
+	case errors.Is(err, repository.ErrReturnLogNotFound):
+		result.Status = models.SyncConflict
+		result.Message = "ไม่พบการลงทะเบียนของการคืนบัตรนี้ที่ server กลาง"
+		return
+	case err != nil:
+		result.Status = models.SyncFailed
+		result.Message = fmt.Sprintf("Failed to void return: %v", err)
+		return
+	}
+	if visitor, err := h.visitors.GetByID(voided.VisitorID); err == nil {
+		h.bus.Publish(events.TypeReturnVoided, visitor.Department, events.VisitorOf(visitor))
+	}
+
+	result.Status = models.SyncApplied
+	result.ID = voided.ID
+}
+
+// GetSyncStatus handles GET /api/sync/status (เครื่อง edge)
+// จำนวนรายการที่รอส่ง ส่งแล้ว และขัดแย้ง
+func (h *SyncHandler) GetSyncStatus(w http.ResponseWriter, r *http.Request) {
+	if h.queue == nil {
+		respondWithError(w, http.StatusNotFound, "Edge mode is not enabled")
+		return
+	}
+
+	counts, err := h.queue.CountByStatus()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to count sync queue")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, counts)
+}
+
+// ListSyncQueue handles GET /api/sync/queue?status=&limit= (เครื่อง edge)
+// status=conflict ใช้ดูรายการที่ server กลางไม่รับ ให้เจ้าหน้าที่ตรวจเอง
+func (h *SyncHandler) ListSyncQueue(w http.ResponseWriter, r *http.Request) {
+	if h.queue == nil {
+		respondWithError(w, http.StatusNotFound, "Edge mode is not enabled")
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", models.SyncPending, models.SyncSynced, models.SyncConflict:
+	default:
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("status must be %s, %s or %s",
+			models.SyncPending, models.SyncSynced, models.SyncConflict))
+		return
+	}
+
+	limit := defaultSyncListLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		var err error
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxSyncListLimit {
+			respondWithError(w, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxSyncListLimit))
+			return
+		}
+	}
+
+	entries, err := h.queue.List(status, limit)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to list sync queue")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, entries)
+}
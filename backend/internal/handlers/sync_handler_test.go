@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+)
+
+// การรับรายการจากเครื่อง edge (ไม่ผ่าน route เพราะการยืนยันเครื่องต้องใช้ฐานข้อมูล)
+func TestSyncApply(t *testing.T) {
+	ts := newTestServer(t)
+	h := NewSyncHandler(nil, ts.visitors, ts.visitors, ts.departments, ts.watchlist, nil, ts.clock, ts.bus)
+
+	const (
+		visitorID = "0b6b7f52-3d5e-4d8a-9a7f-1f0e2d3c4b01"
+		returnID  = "0b6b7f52-3d5e-4d8a-9a7f-1f0e2d3c4b02"
+	)
+	registeredAt := testNow.Add(-2 * time.Hour)
+	returnedAt := testNow.Add(-30 * time.Minute)
+	sent := &models.Visitor{
+		IDCard: "1101234500001", FirstName: "สมชาย", LastName: "ใจดี", Phone: "0812345678",
+		LicensePlate: "กข-1234", RFID: "RF0001", Department: "ฝ่ายวิชาการ",
+		ApprovalStatus: models.ApprovalNotRequired, RegisteredAt: registeredAt,
+	}
+	visitorItem := models.SyncItem{ClientID: visitorID, Kind: models.SyncKindVisitor, Visitor: sent}
+	returnItem := models.SyncItem{ClientID: returnID, Kind: models.SyncKindReturn, Return: &models.SyncReturn{
+		VisitorClientID: visitorID, CardID: "RF0001", CheckOut: "09:30", ReturnedAt: returnedAt,
+	}}
+
+	// คืนบัตรก่อนการลงทะเบียนมาถึงไม่ได้
+	if result := h.apply(returnItem, 1); result.Status != models.SyncConflict {
+		t.Errorf("return before its registration = %+v", result)
+	}
+
+	result := h.apply(visitorItem, 1)
+	if result.Status != models.SyncApplied || result.ID == 0 {
+		t.Fatalf("apply(visitor) = %+v", result)
+	}
+	visitor, err := ts.visitors.GetByID(result.ID)
+	if err != nil || visitor.ClientID != visitorID || !visitor.RegisteredAt.Equal(registeredAt) || visitor.VehicleID == nil || visitor.DepartmentID == nil {
+		t.Errorf("synced visitor = %+v, %v", visitor, err)
+	}
+	if again := h.apply(visitorItem, 1); again.Status != models.SyncDuplicate || again.ID != result.ID {
+		t.Errorf("apply(visitor) again = %+v", again)
+	}
+
+	// เลขบัตรเดียวกันลงทะเบียนที่อีกประตู
+	other := *sent
+	other.RFID = "RF0002"
+	result = h.apply(models.SyncItem{ClientID: "0b6b7f52-3d5e-4d8a-9a7f-1f0e2d3c4b03", Kind: models.SyncKindVisitor, Visitor: &other}, 2)
+	if result.Status != models.SyncConflict || result.Message == "" {
+		t.Errorf("same ID card from another gate = %+v", result)
+	}
+
+	result = h.apply(returnItem, 1)
+	if result.Status != models.SyncApplied {
+		t.Fatalf("apply(return) = %+v", result)
+	}
+	visitor, _ = ts.visitors.GetByClientID(visitorID)
+	if visitor.ExitTime == nil || !visitor.ExitTime.Equal(returnedAt) {
+		t.Errorf("exit time = %v, want %v", visitor.ExitTime, returnedAt)
+	}
+	if again := h.apply(returnItem, 1); again.Status != models.SyncDuplicate {
+		t.Errorf("apply(return) again = %+v", again)
+	}
+
+	// บัตรเดียวกันคืนสองครั้ง (อีกรายการจากประตูอื่น)
+	secondReturn := returnItem
+	secondReturn.ClientID = "0b6b7f52-3d5e-4d8a-9a7f-1f0e2d3c4b04"
+	if result := h.apply(secondReturn, 2); result.Status != models.SyncConflict {
+		t.Errorf("card returned twice = %+v", result)
+	}
+
+	// ประตูยกเลิกการคืนที่ส่งมาแล้ว ผู้มาติดต่อกลับเป็นยังอยู่ในโรงเรียน
+	voidItem := models.SyncItem{ClientID: "0b6b7f52-3d5e-4d8a-9a7f-1f0e2d3c4b05", Kind: models.SyncKindVoid, Void: &models.SyncVoid{
+		ReturnClientID: returnID, VoidedBy: "ยามประตูหลัง", Reason: "สแกนผิดใบ",
+	}}
+	if result := h.apply(voidItem, 1); result.Status != models.SyncApplied {
+		t.Fatalf("apply(void) = %+v", result)
+	}
+	visitor, _ = ts.visitors.GetByClientID(visitorID)
+	if visitor.ExitTime != nil {
+		t.Errorf("exit time after void = %v", visitor.ExitTime)
+	}
+	if again := h.apply(voidItem, 1); again.Status != models.SyncDuplicate {
+		t.Errorf("apply(void) again = %+v", again)
+	}
+	unknown := voidItem
+	unknown.Void = &models.SyncVoid{ReturnClientID: "0b6b7f52-3d5e-4d8a-9a7f-1f0e2d3c4b99", VoidedBy: "ยาม", Reason: "ผิด"}
+	if result := h.apply(unknown, 1); result.Status != models.SyncConflict {
+		t.Errorf("void of an unknown return = %+v", result)
+	}
+
+	if result := h.apply(models.SyncItem{ClientID: "not-a-uuid", Kind: models.SyncKindVisitor, Visitor: sent}, 1); result.Status != models.SyncConflict {
+		t.Errorf("invalid client ID = %+v", result)
+	}
+	if result := h.apply(models.SyncItem{ClientID: visitorID, Kind: "photo"}, 1); result.Status != models.SyncConflict {
+		t.Errorf("unknown kind = %+v", result)
+	}
+}
+
+// หน้าจอลงทะเบียนส่งซ้ำด้วย clientId เดิม ได้การลงทะเบียนเดิมกลับ ไม่ใช่ 409
+func TestCreateVisitorClientID(t *testing.T) {
+	ts := newTestServer(t)
+
+	req := newVisitorRequest(1)
+	req.ClientID = "not-a-uuid"
+	expectStatus(t, ts.do("POST", "/api/visitors", req), http.StatusBadRequest)
+
+	req.ClientID = "0B6B7F52-3D5E-4D8A-9A7F-1F0E2D3C4B01"
+	created := ts.createVisitor(req)
+	if created.ClientID != "0b6b7f52-3d5e-4d8a-9a7f-1f0e2d3c4b01" {
+		t.Errorf("client ID = %q", created.ClientID)
+	}
+
+	rec := ts.do("POST", "/api/visitors", req)
+	expectStatus(t, rec, http.StatusOK)
+	var again models.Visitor
+	decode(t, rec, &again)
+	if again.ID != created.ID {
+		t.Errorf("retry created visitor %d, want %d", again.ID, created.ID)
+	}
+
+	// ไม่ส่ง clientId มา server สร้างให้
+	if v := ts.createVisitor(newVisitorRequest(2)); v.ClientID == "" {
+		t.Error("visitor without clientId got no client ID")
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"backend/internal/address"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/vehicle"
+
+	"github.com/gorilla/mux"
+)
+
+type VehicleHandler struct {
+	repo *repository.VehicleRepository
+}
+
+func NewVehicleHandler(repo *repository.VehicleRepository) *VehicleHandler {
+	return &VehicleHandler{repo: repo}
+}
+
+// GetVisitsByPlate handles GET /api/vehicles/{plate}/visitors
+func (h *VehicleHandler) GetVisitsByPlate(w http.ResponseWriter, r *http.Request) {
+	plate := vehicle.NormalizePlate(mux.Vars(r)["plate"])
+	if plate == "" {
+		respondWithError(w, http.StatusBadRequest, "Plate number is required")
+		return
+	}
+
+	province := address.NormalizeProvince(r.URL.Query().Get("province"))
+
+	visits, err := h.repo.GetVisitsByPlate(plate, province)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get visitors by plate")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, visits)
+}
+
+// GetParking handles GET /api/vehicles/parking
+// จำนวนรถผู้มาติดต่อที่ยังจอดอยู่ในโรงเรียนขณะนี้
+func (h *VehicleHandler) GetParking(w http.ResponseWriter, r *http.Request) {
+	vehicles, err := h.repo.GetParked()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get parking status")
+		return
+	}
+
+	byType := map[string]int{}
+	for _, v := range vehicles {
+		byType[v.VehicleType]++
+	}
+
+	respondWithJSON(w, http.StatusOK, models.ParkingStatusResponse{
+		Total:    len(vehicles),
+		ByType:   byType,
+		Vehicles: vehicles,
+	})
+}
+
+// buildVehicle validates and normalizes the vehicle part of a registration
+func buildVehicle(req *models.VehicleRequest) (*models.Vehicle, error) {
+	plate := vehicle.NormalizePlate(req.PlateNumber)
+	if plate == "" {
+		return nil, fmt.Errorf("Vehicle plate number is required")
+	}
+
+	// ประเภทว่างปล่อยไว้ ไม่ทับประเภทของรถที่เคยบันทึก (รถใหม่เป็น car)
+	if req.VehicleType != "" && !vehicle.IsValidType(req.VehicleType) {
+		return nil, fmt.Errorf("Invalid vehicle type: %s", req.VehicleType)
+	}
+
+	return &models.Vehicle{
+		PlateNumber:  plate,
+		PlateDisplay: vehicle.FormatPlate(plate),
+		Province:     address.NormalizeProvince(req.Province),
+		VehicleType:  req.VehicleType,
+		Color:        req.Color,
+	}, nil
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"backend/internal/models"
+)
+
+func TestVehicles(t *testing.T) {
+	ts := newSQLiteServer(t)
+	for i, v := range []models.VehicleRequest{
+		{PlateNumber: "กข ๑๒๓๔", Province: "กทม.", VehicleType: "car", Color: "ขาว"},
+		{PlateNumber: "กข-1234", Province: "นนทบุรี", VehicleType: "motorcycle"},
+		{PlateNumber: "กข 1234", Province: "กรุงเทพมหานคร"},
+	} {
+		req := newVisitorRequest(i + 1)
+		req.Vehicle = &v
+		ts.createVisitor(req)
+	}
+	ts.createVisitor(newVisitorRequest(4))
+	expectStatus(t, ts.do("POST", "/api/visitors/return/RF0003", models.ReturnCardRequest{CheckOut: "11:00"}), http.StatusOK)
+
+	rec := ts.do("GET", "/api/vehicles/parking", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var parking models.ParkingStatusResponse
+	decode(t, rec, &parking)
+	if parking.Total != 2 || parking.ByType["car"] != 1 || parking.ByType["motorcycle"] != 1 || len(parking.Vehicles) != 2 {
+		t.Errorf("parking = %+v", parking)
+	}
+
+	// ทะเบียนเดียวกันคนละจังหวัดเป็นคนละคัน รถที่บันทึกซ้ำไม่ระบุประเภทยังเป็น car
+	rec = ts.do("GET", "/api/vehicles/"+url.PathEscape("กข1234")+"/visitors?province=กทม.", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var visits []models.VehicleVisitResponse
+	decode(t, rec, &visits)
+	if len(visits) != 2 {
+		t.Fatalf("visits of กข 1234 กรุงเทพมหานคร = %+v", visits)
+	}
+	for _, v := range visits {
+		if v.PlateNumber != "กข 1234" || v.Province != "กรุงเทพมหานคร" || v.VehicleType != "car" || v.Color != "ขาว" {
+			t.Errorf("visit = %+v", v)
+		}
+	}
+
+	rec = ts.do("GET", "/api/vehicles/"+url.PathEscape("กข 1234")+"/visitors", nil)
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &visits)
+	if len(visits) != 3 {
+		t.Errorf("visits of กข 1234 in every province = %+v", visits)
+	}
+
+	expectStatus(t, ts.do("GET", "/api/vehicles/"+url.PathEscape("--")+"/visitors", nil), http.StatusBadRequest)
+	req := newVisitorRequest(5)
+	req.Vehicle = &models.VehicleRequest{PlateNumber: "กข 5678", VehicleType: "tank"}
+	expectStatus(t, ts.do("POST", "/api/visitors", req), http.StatusBadRequest)
+}
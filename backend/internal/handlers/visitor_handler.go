@@ -2,23 +2,67 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
+	"backend/internal/address"
+	"backend/internal/clock"
+	"backend/internal/events"
 	"backend/internal/models"
+	"backend/internal/notify"
 	"backend/internal/repository"
+	"backend/internal/thaidate"
+	"backend/internal/token"
 
 	"github.com/gorilla/mux"
 )
 
 type VisitorHandler struct {
-	repo *repository.VisitorRepository
+	repo         repository.VisitorStore
+	returns      repository.ReturnLogStore
+	addresses    *address.Dataset
+	appointments *repository.AppointmentRepository
+	purposes     *repository.PurposeRepository
+	departments  repository.DepartmentStore
+	watchlist    repository.WatchlistStore
+	signer       *token.Signer
+	notifier     notify.Notifier
+	dates        thaidate.Formatter
+	clock        *clock.Clock
+	bus          *events.Bus
 }
 
-func NewVisitorHandler(repo *repository.VisitorRepository) *VisitorHandler {
-	return &VisitorHandler{repo: repo}
+func NewVisitorHandler(
+	repo repository.VisitorStore,
+	returns repository.ReturnLogStore,
+	addresses *address.Dataset,
+	appointments *repository.AppointmentRepository,
+	purposes *repository.PurposeRepository,
+	departments repository.DepartmentStore,
+	watchlist repository.WatchlistStore,
+	signer *token.Signer,
+	notifier notify.Notifier,
+	dates thaidate.Formatter,
+	clk *clock.Clock,
+	bus *events.Bus,
+) *VisitorHandler {
+	return &VisitorHandler{
+		repo:         repo,
+		returns:      returns,
+		addresses:    addresses,
+		appointments: appointments,
+		purposes:     purposes,
+		departments:  departments,
+		watchlist:    watchlist,
+		signer:       signer,
+		notifier:     notifier,
+		dates:        dates,
+		clock:        clk,
+		bus:          bus,
+	}
 }
 
 // CreateVisitor handles POST /api/visitors
@@ -47,6 +91,84 @@ func (h *VisitorHandler) CreateVisitor(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// ลงทะเบียนซ้ำด้วย clientId เดิม (หน้าจอส่งใหม่หลังเน็ตหลุด) ได้ผลเดิมกลับไป
+	clientID, err := newClientID(req.ClientID)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.ClientID != "" {
+		if existing, err := h.repo.GetByClientID(clientID); err == nil {
+			respondWithJSON(w, http.StatusOK, existing)
+			return
+		}
+	}
+
+	// Appointment QR (ถ้ามาตามนัด)
+	var appt *models.Appointment
+	if req.AppointmentToken != "" {
+		resolved, status, err := resolveAppointment(h.appointments, h.signer, req.AppointmentToken, req.IDCard)
+		if err != nil {
+			respondWithError(w, status, err.Error())
+			return
+		}
+		appt = resolved
+
+		// ผู้รับนัดระบุไว้แล้วตอนสร้างนัดหมาย
+		if req.HostName == "" {
+			req.HostName = appt.HostName
+		}
+		if req.HostContact == "" {
+			req.HostContact = appt.HostContact
+		}
+		if req.PurposeNote == "" {
+			req.PurposeNote = appt.Purpose
+		}
+	}
+
+	// Visit purpose and approval (นัดหมายล่วงหน้าถือว่าผู้รับนัดอนุมัติแล้ว)
+	approvalStatus := models.ApprovalNotRequired
+	var purpose *models.VisitPurpose
+	if req.Purpose != "" {
+		found, err := h.purposes.GetByCode(req.Purpose)
+		if err != nil || !found.Active {
+			respondWithError(w, http.StatusBadRequest, "Invalid visit purpose")
+			return
+		}
+		purpose = found
+
+		if purpose.RequiresApproval && appt == nil {
+			if req.HostName == "" {
+				respondWithError(w, http.StatusBadRequest, "Host name is required for this purpose")
+				return
+			}
+			if req.RFID != "" {
+				respondWithError(w, http.StatusBadRequest, "ต้องรอผู้รับนัดอนุมัติก่อนออกบัตร")
+				return
+			}
+			approvalStatus = models.ApprovalPending
+		}
+	}
+
+	// ส่วนงานต้องอยู่ในรายการของโรงเรียน เก็บชื่อไทย ณ วันที่มาติดต่อไว้คู่กับ id
+	department, err := resolveDepartment(h.departments, req.DepartmentID, req.Department)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Validate address against the reference data
+	addr, err := h.addresses.Validate(address.Address{
+		SubDistrict: req.SubDistrict,
+		District:    req.District,
+		Province:    req.Province,
+		PostalCode:  req.PostalCode,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	// Check if ID card already exists
 	exists, err := h.repo.CheckIDCardExists(req.IDCard)
 	if err != nil {
@@ -71,10 +193,10 @@ func (h *VisitorHandler) CreateVisitor(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Parse birth date
+	// Parse birth date (รับทั้ง ค.ศ. และ พ.ศ.)
 	var birthDate *time.Time
 	if req.BirthDate != nil && *req.BirthDate != "" {
-		parsed, err := time.Parse("2006-01-02", *req.BirthDate)
+		parsed, err := thaidate.ParseDate(*req.BirthDate)
 		if err != nil {
 			respondWithError(w, http.StatusBadRequest, "Invalid birth date format")
 			return
@@ -84,30 +206,70 @@ func (h *VisitorHandler) CreateVisitor(w http.ResponseWriter, r *http.Request) {
 
 	// Create visitor
 	visitor := &models.Visitor{
-		IDCard:       req.IDCard,
-		FirstName:    req.FirstName,
-		LastName:     req.LastName,
-		BirthDate:    birthDate,
-		Phone:        req.Phone,
-		LicensePlate: req.LicensePlate,
-		HouseNumber:  req.HouseNumber,
-		Moo:          req.Moo,
-		Soi:          req.Soi,
-		Road:         req.Road,
-		SubDistrict:  req.SubDistrict,
-		District:     req.District,
-		Province:     req.Province,
-		RFID:         req.RFID,
-		Department:   req.Department,
-		OfficerName:  req.OfficerName,
-		IDCardImage:  req.IDCardImage,
+		ClientID:       clientID,
+		IDCard:         req.IDCard,
+		FirstName:      req.FirstName,
+		LastName:       req.LastName,
+		BirthDate:      birthDate,
+		Phone:          req.Phone,
+		LicensePlate:   req.LicensePlate,
+		HouseNumber:    req.HouseNumber,
+		Moo:            req.Moo,
+		Soi:            req.Soi,
+		Road:           req.Road,
+		SubDistrict:    addr.SubDistrict,
+		District:       addr.District,
+		Province:       addr.Province,
+		PostalCode:     addr.PostalCode,
+		RFID:           req.RFID,
+		Department:     department.NameTh,
+		DepartmentID:   &department.ID,
+		OfficerName:    req.OfficerName,
+		IDCardImage:    req.IDCardImage,
+		HostName:       req.HostName,
+		HostContact:    req.HostContact,
+		Purpose:        req.Purpose,
+		PurposeNote:    req.PurposeNote,
+		ApprovalStatus: approvalStatus,
+	}
+
+	// Vehicle (รองรับ licensePlate แบบเดิมถ้าไม่ได้ส่ง vehicle มา)
+	vehicleReq := req.Vehicle
+	if vehicleReq == nil && req.LicensePlate != "" {
+		vehicleReq = &models.VehicleRequest{PlateNumber: req.LicensePlate}
+	}
+	if vehicleReq != nil {
+		v, err := buildVehicle(vehicleReq)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		visitor.Vehicle = v
+		visitor.LicensePlate = v.PlateDisplay
+	}
+
+	if appt != nil {
+		visitor.AppointmentID = &appt.ID
 	}
 
 	if err := h.repo.Create(visitor); err != nil {
+		if errors.Is(err, repository.ErrAppointmentNotPending) {
+			respondWithError(w, http.StatusConflict, "นัดหมายนี้ถูกใช้หรือยกเลิกไปแล้ว")
+			return
+		}
 		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to create visitor: %v", err))
 		return
 	}
 
+	if appt != nil {
+		go notifyHostArrival(h.notifier, appt, visitor, h.dates.Time(h.clock.Now()))
+	}
+	if approvalStatus == models.ApprovalPending {
+		go notifyHostApproval(h.notifier, visitor, purpose)
+	}
+	h.bus.Publish(events.TypeVisitorRegistered, visitor.Department, events.VisitorOf(visitor))
+	screenVisitor(h.watchlist, h.bus, visitor)
+
 	respondWithJSON(w, http.StatusCreated, visitor)
 }
 
@@ -125,38 +287,32 @@ func (h *VisitorHandler) GetVisitor(w http.ResponseWriter, r *http.Request) {
 		respondWithError(w, http.StatusNotFound, "Visitor not found")
 		return
 	}
+	setPhotoURLs(visitor)
 
+	w.Header().Set("ETag", visitorETag(visitor))
 	respondWithJSON(w, http.StatusOK, visitor)
 }
 
 // ListVisitors handles GET /api/visitors
 func (h *VisitorHandler) ListVisitors(w http.ResponseWriter, r *http.Request) {
-	// Parse query parameters
-	params := models.QueryParams{
-		Search:     r.URL.Query().Get("search"),
-		StartDate:  r.URL.Query().Get("startDate"),
-		EndDate:    r.URL.Query().Get("endDate"),
-		SortOrder:  r.URL.Query().Get("sortOrder"),
-		Department: r.URL.Query().Get("department"),
-	}
-
-	// Parse pagination
-	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
-		page, err := strconv.Atoi(pageStr)
-		if err == nil && page > 0 {
-			params.Page = page
-		}
+	params, err := parseVisitorQueryParams(r.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := resolveDepartmentFilter(h.departments, &params); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-		limit, err := strconv.Atoi(limitStr)
-		if err == nil && limit > 0 {
-			params.Limit = limit
-		}
+	dates, err := dateFormatter(r.URL.Query(), h.dates)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
 	}
 
 	// Set default sort order
-	if params.SortOrder == "" {
+	if params.SortOrder == "" && params.SortBy == "" {
 		params.SortOrder = "latest"
 	}
 
@@ -171,7 +327,7 @@ func (h *VisitorHandler) ListVisitors(w http.ResponseWriter, r *http.Request) {
 	for i, v := range visitors {
 		birthDate := "-"
 		if v.BirthDate != nil {
-			birthDate = v.BirthDate.Format("02/01/06")
+			birthDate = dates.ShortCalendarDate(*v.BirthDate)
 		}
 
 		phone := v.Phone
@@ -186,22 +342,26 @@ func (h *VisitorHandler) ListVisitors(w http.ResponseWriter, r *http.Request) {
 
 		exitTime := "-"
 		if v.ExitTime != nil {
-			exitTime = v.ExitTime.Format("02/01/2006 15:04:05")
+			exitTime = dates.DateTime(*v.ExitTime)
 		}
 
 		response[i] = models.VisitorListResponse{
-			ID:           v.ID,
-			IDCard:       v.IDCard,
-			Name:         v.FirstName + " " + v.LastName,
-			BirthDate:    birthDate,
-			Phone:        phone,
-			LicensePlate: licensePlate,                 // ⭐ เพิ่มบรรทัดนี้
-			Address:      repository.FormatAddress(&v), // ⭐ ใช้แบบเต็ม
-			RFID:         v.RFID,
-			Department:   v.Department,
-			OfficerName:  v.OfficerName,
-			RegisteredAt: v.RegisteredAt.Format("02/01/2006 15:04:05"),
-			ExitTime:     exitTime,
+			ID:             v.ID,
+			IDCard:         v.IDCard,
+			Name:           v.FirstName + " " + v.LastName,
+			BirthDate:      birthDate,
+			Phone:          phone,
+			LicensePlate:   licensePlate, // ⭐ เพิ่มบรรทัดนี้
+			Address:        address.Format(address.FromVisitor(&v), address.Short),
+			RFID:           v.RFID,
+			Department:     v.Department,
+			OfficerName:    v.OfficerName,
+			HostName:       v.HostName,
+			Purpose:        v.Purpose,
+			ApprovalStatus: v.ApprovalStatus,
+			ThumbnailURL:   thumbnailURL(&v),
+			RegisteredAt:   dates.DateTime(v.RegisteredAt),
+			ExitTime:       exitTime,
 		}
 	}
 
@@ -210,16 +370,28 @@ func (h *VisitorHandler) ListVisitors(w http.ResponseWriter, r *http.Request) {
 
 // GetVisitors สำหรับ Export - GET /api/visitors/export
 func (h *VisitorHandler) GetVisitors(w http.ResponseWriter, r *http.Request) {
-	startDate := r.URL.Query().Get("startDate")
-	endDate := r.URL.Query().Get("endDate")
-	department := r.URL.Query().Get("department")
+	params, err := parseVisitorQueryParams(r.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := resolveDepartmentFilter(h.departments, &params); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	dates, err := dateFormatter(r.URL.Query(), h.dates)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	if startDate == "" || endDate == "" {
+	if params.StartDate == "" || params.EndDate == "" {
 		respondWithError(w, http.StatusBadRequest, "startDate and endDate are required")
 		return
 	}
 
-	visitors, err := h.repo.GetVisitorsForExport(startDate, endDate, department)
+	visitors, err := h.repo.GetVisitorsForExport(params)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Failed to get visitors")
 		return
@@ -229,19 +401,19 @@ func (h *VisitorHandler) GetVisitors(w http.ResponseWriter, r *http.Request) {
 	response := make([]map[string]interface{}, len(visitors))
 	for i, v := range visitors {
 		// ⭐ แยกวันที่และเวลาจาก registered_at
-		timeIn := v.RegisteredAt.Format("15:04")              // เวลาเข้า (เช่น "09:36")
-		dateRegistered := v.RegisteredAt.Format("02/01/2006") // วันที่ลงทะเบียน
+		timeIn := dates.Time(v.RegisteredAt)         // เวลาเข้า (เช่น "09:36")
+		dateRegistered := dates.Date(v.RegisteredAt) // วันที่ลงทะเบียน
 
 		var timeOut string
 		if v.ExitTime != nil {
-			timeOut = v.ExitTime.Format("15:04") // เวลาออก (เช่น "17:45")
+			timeOut = dates.Time(*v.ExitTime) // เวลาออก (เช่น "17:45")
 		} else {
 			timeOut = "-"
 		}
 
 		var birthDate string
 		if v.BirthDate != nil {
-			birthDate = v.BirthDate.Format("02/01/2006")
+			birthDate = dates.CalendarDate(*v.BirthDate)
 		} else {
 			birthDate = "-"
 		}
@@ -257,6 +429,8 @@ func (h *VisitorHandler) GetVisitors(w http.ResponseWriter, r *http.Request) {
 			"rfid":         v.RFID,
 			"department":   v.Department,
 			"officerName":  v.OfficerName,
+			"hostName":     v.HostName,
+			"purpose":      v.Purpose,
 			"registeredAt": dateRegistered, // วันที่ลงทะเบียน
 			"timeIn":       timeIn,         // ⭐ เวลาเข้า
 			"exitTime":     timeOut,        // ⭐ เวลาออก
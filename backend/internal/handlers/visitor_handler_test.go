@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+)
+
+func TestCreateVisitor(t *testing.T) {
+	ts := newTestServer(t)
+
+	req := newVisitorRequest(1)
+	req.Vehicle = &models.VehicleRequest{PlateNumber: "กข 1234", Province: "กรุงเทพมหานคร"}
+	v := ts.createVisitor(req)
+
+	if v.ID == 0 || v.RFID != "RF0001" || v.ApprovalStatus != models.ApprovalNotRequired {
+		t.Errorf("created visitor = %+v", v)
+	}
+	rec := ts.do("GET", "/api/visitors/"+strconv.Itoa(v.ID), nil)
+	expectStatus(t, rec, http.StatusOK)
+	var got models.Visitor
+	decode(t, rec, &got)
+	if got.IDCard != req.IDCard || got.LicensePlate == "" || !got.RegisteredAt.Equal(testNow) {
+		t.Errorf("GET visitor = %+v", got)
+	}
+}
+
+func TestCreateVisitorValidation(t *testing.T) {
+	ts := newTestServer(t)
+	ts.createVisitor(newVisitorRequest(1))
+
+	shortID := newVisitorRequest(2)
+	shortID.IDCard = "123"
+	noPhone := newVisitorRequest(3)
+	noPhone.Phone = ""
+	badPostal := newVisitorRequest(4)
+	badPostal.PostalCode = "50000"
+	sameIDCard := newVisitorRequest(5)
+	sameIDCard.IDCard = newVisitorRequest(1).IDCard
+	sameRFID := newVisitorRequest(6)
+	sameRFID.RFID = "RF0001"
+	// ตรวจวัตถุประสงค์ต้องอ่านจากฐานข้อมูล
+	withPurpose := newVisitorRequest(7)
+	withPurpose.Purpose = "meeting"
+	noDepartment := newVisitorRequest(8)
+	noDepartment.Department = ""
+	unknownDepartment := newVisitorRequest(9)
+	unknownDepartment.Department = "ฝ่ายที่ไม่มี"
+	unknownDepartmentID := newVisitorRequest(10)
+	unknownDepartmentID.DepartmentID = 99
+
+	tests := []struct {
+		name string
+		body interface{}
+		want int
+	}{
+		{"malformed body", "{", http.StatusBadRequest},
+		{"short ID card", shortID, http.StatusBadRequest},
+		{"missing phone", noPhone, http.StatusBadRequest},
+		{"postal code outside the district", badPostal, http.StatusBadRequest},
+		{"ID card already registered", sameIDCard, http.StatusConflict},
+		{"RFID already registered", sameRFID, http.StatusConflict},
+		{"unknown purpose", withPurpose, http.StatusBadRequest},
+		{"missing department", noDepartment, http.StatusBadRequest},
+		{"unknown department", unknownDepartment, http.StatusBadRequest},
+		{"unknown department ID", unknownDepartmentID, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expectStatus(t, ts.do("POST", "/api/visitors", tt.body), tt.want)
+		})
+	}
+}
+
+func TestGetVisitorNotFound(t *testing.T) {
+	ts := newTestServer(t)
+	expectStatus(t, ts.do("GET", "/api/visitors/99", nil), http.StatusNotFound)
+	expectStatus(t, ts.do("GET", "/api/visitors/abc", nil), http.StatusBadRequest)
+}
+
+func TestListVisitors(t *testing.T) {
+	ts := newTestServer(t)
+	for i := 1; i <= 3; i++ {
+		req := newVisitorRequest(i)
+		if i == 2 {
+			req.Department = "ฝ่ายบุคคล"
+		}
+		ts.createVisitor(req)
+		ts.advance(time.Minute)
+	}
+
+	rec := ts.do("GET", "/api/visitors", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var all []models.VisitorListResponse
+	decode(t, rec, &all)
+	if len(all) != 3 || all[0].ID != 3 || all[2].ID != 1 {
+		t.Fatalf("default order should be latest first, got %+v", all)
+	}
+	if all[0].Phone != "081-2345678" || all[0].LicensePlate != "-" || all[0].ExitTime != "-" {
+		t.Errorf("list row = %+v", all[0])
+	}
+
+	rec = ts.do("GET", "/api/visitors?department=%E0%B8%9D%E0%B9%88%E0%B8%B2%E0%B8%A2%E0%B8%9A%E0%B8%B8%E0%B8%84%E0%B8%84%E0%B8%A5", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var filtered []models.VisitorListResponse
+	decode(t, rec, &filtered)
+	if len(filtered) != 1 || filtered[0].ID != 2 {
+		t.Errorf("department filter = %+v", filtered)
+	}
+
+	// ไม่ระบุส่วนงาน = ทุกส่วนงาน ระบุ id ได้หลายค่า
+	rec = ts.do("GET", "/api/visitors?departmentId=1,2", nil)
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &filtered)
+	if len(filtered) != 3 {
+		t.Errorf("departmentId=1,2 = %+v", filtered)
+	}
+	// "all" เลือกทุกส่วนงานเหมือนไม่ส่งมา ทั้งรายการและการส่งออก
+	for _, path := range []string{"/api/visitors?department=all", "/api/visitors/export?startDate=2025-06-01&endDate=2025-06-02&department=ALL"} {
+		rec = ts.do("GET", path, nil)
+		expectStatus(t, rec, http.StatusOK)
+		var rows []map[string]interface{}
+		decode(t, rec, &rows)
+		if len(rows) != 3 {
+			t.Errorf("%s = %d rows, want 3", path, len(rows))
+		}
+	}
+	expectStatus(t, ts.do("GET", "/api/visitors?department=%E0%B8%97%E0%B8%B1%E0%B9%89%E0%B8%87%E0%B8%AB%E0%B8%A1%E0%B8%94", nil), http.StatusBadRequest)
+	expectStatus(t, ts.do("GET", "/api/visitors?departmentId=abc", nil), http.StatusBadRequest)
+
+	rec = ts.do("GET", "/api/visitors?sortOrder=oldest&page=2&limit=2", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var page []models.VisitorListResponse
+	decode(t, rec, &page)
+	if len(page) != 1 || page[0].ID != 3 {
+		t.Errorf("page 2 = %+v", page)
+	}
+
+	expectStatus(t, ts.do("GET", "/api/visitors?sortby=name", nil), http.StatusBadRequest)
+	expectStatus(t, ts.do("GET", "/api/visitors?status=gone", nil), http.StatusBadRequest)
+}
+
+func TestExportVisitors(t *testing.T) {
+	ts := newTestServer(t)
+	ts.createVisitor(newVisitorRequest(1))
+
+	expectStatus(t, ts.do("GET", "/api/visitors/export", nil), http.StatusBadRequest)
+
+	rec := ts.do("GET", "/api/visitors/export?startDate=2025-06-01&endDate=2025-06-02", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var rows []map[string]interface{}
+	decode(t, rec, &rows)
+	if len(rows) != 1 {
+		t.Fatalf("export rows = %+v", rows)
+	}
+	if rows[0]["name"] != "สมชาย1 ใจดี" || rows[0]["timeIn"] != "10:00" || rows[0]["exitTime"] != "-" {
+		t.Errorf("export row = %+v", rows[0])
+	}
+
+	// วันที่ พ.ศ. ก็รับได้
+	rec = ts.do("GET", "/api/visitors/export?startDate=2568-06-03&endDate=2568-06-04", nil)
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &rows)
+	if len(rows) != 0 {
+		t.Errorf("export outside the range = %+v", rows)
+	}
+}
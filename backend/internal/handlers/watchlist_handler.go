@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"backend/internal/events"
+	"backend/internal/models"
+	"backend/internal/repository"
+
+	"github.com/gorilla/mux"
+)
+
+type WatchlistHandler struct {
+	repo repository.WatchlistStore
+}
+
+func NewWatchlistHandler(repo repository.WatchlistStore) *WatchlistHandler {
+	return &WatchlistHandler{repo: repo}
+}
+
+// ListWatchlist handles GET /api/watchlist (all=true รวมรายการที่ปิดแล้ว)
+func (h *WatchlistHandler) ListWatchlist(w http.ResponseWriter, r *http.Request) {
+	activeOnly := r.URL.Query().Get("all") != "true"
+
+	entries, err := h.repo.List(activeOnly)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to list watchlist")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, entries)
+}
+
+// CreateWatchlistEntry handles POST /api/watchlist
+func (h *WatchlistHandler) CreateWatchlistEntry(w http.ResponseWriter, r *http.Request) {
+	var req models.SaveWatchlistRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	entry := &models.WatchlistEntry{Active: true}
+	if status, err := h.apply(entry, req); err != nil {
+		respondWithError(w, status, err.Error())
+		return
+	}
+
+	if err := h.repo.Create(entry); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to create watchlist entry")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, entry)
+}
+
+// UpdateWatchlistEntry handles PUT /api/watchlist/{id}
+func (h *WatchlistHandler) UpdateWatchlistEntry(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid watchlist entry ID")
+		return
+	}
+
+	var req models.SaveWatchlistRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	entry, err := h.repo.GetByID(id)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Watchlist entry not found")
+		return
+	}
+	if status, err := h.apply(entry, req); err != nil {
+		respondWithError(w, status, err.Error())
+		return
+	}
+
+	if err := h.repo.Update(entry); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to update watchlist entry")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, entry)
+}
+
+// DeleteWatchlistEntry handles DELETE /api/watchlist/{id}
+func (h *WatchlistHandler) DeleteWatchlistEntry(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid watchlist entry ID")
+		return
+	}
+
+	if _, err := h.repo.GetByID(id); err != nil {
+		respondWithError(w, http.StatusNotFound, "Watchlist entry not found")
+		return
+	}
+
+	if err := h.repo.Delete(id); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete watchlist entry")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// apply validates req and copies it onto entry, returning the status to respond with on error
+func (h *WatchlistHandler) apply(entry *models.WatchlistEntry, req models.SaveWatchlistRequest) (int, error) {
+	req.IDCard = strings.TrimSpace(req.IDCard)
+	req.Reason = strings.TrimSpace(req.Reason)
+	req.AddedBy = strings.TrimSpace(req.AddedBy)
+	if len(req.IDCard) != 13 {
+		return http.StatusBadRequest, fmt.Errorf("ID card must be 13 digits")
+	}
+	if req.Reason == "" || req.AddedBy == "" {
+		return http.StatusBadRequest, fmt.Errorf("reason and addedBy are required")
+	}
+	if other, err := h.repo.GetByIDCard(req.IDCard); err == nil && other.ID != entry.ID {
+		return http.StatusConflict, fmt.Errorf("ID card is already on the watchlist")
+	}
+
+	entry.IDCard = req.IDCard
+	entry.Name = strings.TrimSpace(req.Name)
+	entry.Reason = req.Reason
+	entry.AddedBy = req.AddedBy
+	if req.Active != nil {
+		entry.Active = *req.Active
+	}
+	return 0, nil
+}
+
+// screenVisitor alerts every guard station when a newly registered visitor is on the watchlist.
+// ไม่ขัดการลงทะเบียน ตรวจไม่ได้ก็แค่บันทึก log
+func screenVisitor(watchlist repository.WatchlistStore, bus *events.Bus, visitor *models.Visitor) {
+	entry, err := watchlist.Match(visitor.IDCard)
+	if err != nil {
+		log.Printf("Failed to screen visitor %d: %v", visitor.ID, err)
+		return
+	}
+	if entry == nil {
+		return
+	}
+	bus.Publish(events.TypeWatchlistHit, "", events.WatchlistHit{
+		Visitor: events.VisitorOf(visitor),
+		EntryID: entry.ID,
+		Reason:  entry.Reason,
+	})
+}
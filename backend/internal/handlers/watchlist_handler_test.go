@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"backend/internal/events"
+	"backend/internal/models"
+)
+
+func TestWatchlist(t *testing.T) {
+	ts := newTestServer(t)
+	watched := newVisitorRequest(1)
+
+	rec := ts.do("POST", "/api/watchlist", models.SaveWatchlistRequest{IDCard: watched.IDCard, Name: "สมชาย1 ใจดี", Reason: " ศาลสั่งห้ามเข้าใกล้นักเรียน ", AddedBy: "ผอ."})
+	expectStatus(t, rec, http.StatusCreated)
+	var entry models.WatchlistEntry
+	decode(t, rec, &entry)
+	if entry.ID == 0 || !entry.Active || entry.Reason != "ศาลสั่งห้ามเข้าใกล้นักเรียน" {
+		t.Fatalf("created entry = %+v", entry)
+	}
+	path := "/api/watchlist/" + strconv.Itoa(entry.ID)
+
+	tests := []struct {
+		name string
+		body models.SaveWatchlistRequest
+		want int
+	}{
+		{"short id card", models.SaveWatchlistRequest{IDCard: "123", Reason: "x", AddedBy: "ผอ."}, http.StatusBadRequest},
+		{"missing reason", models.SaveWatchlistRequest{IDCard: "1101234599999", AddedBy: "ผอ."}, http.StatusBadRequest},
+		{"listed id card", models.SaveWatchlistRequest{IDCard: watched.IDCard, Reason: "x", AddedBy: "ผอ."}, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expectStatus(t, ts.do("POST", "/api/watchlist", tt.body), tt.want)
+		})
+	}
+
+	// ลงทะเบียนได้ตามปกติ แต่ป้อมทุกจุดได้รับแจ้ง ไม่ว่าจะกรองส่วนงานใด
+	sub, _, _ := ts.bus.Subscribe(events.Filter{Departments: []string{"ฝ่ายบุคคล"}}, 0)
+	defer sub.Close()
+	v := ts.createVisitor(watched)
+	ts.createVisitor(newVisitorRequest(2))
+
+	var hits []events.Event
+	for len(sub.C) > 0 {
+		if e := <-sub.C; e.Type == events.TypeWatchlistHit {
+			hits = append(hits, e)
+		}
+	}
+	if len(hits) != 1 {
+		t.Fatalf("watchlist hits = %+v", hits)
+	}
+	hit, ok := hits[0].Data.(events.WatchlistHit)
+	if !ok || hit.Visitor.ID != v.ID || hit.EntryID != entry.ID || hit.Reason != entry.Reason {
+		t.Errorf("watchlist hit = %+v", hits[0].Data)
+	}
+
+	// ปิดแล้วไม่แจ้ง และไม่อยู่ในรายการปกติ
+	inactive := false
+	rec = ts.do("PUT", path, models.SaveWatchlistRequest{IDCard: watched.IDCard, Reason: entry.Reason, AddedBy: "ผอ.", Active: &inactive})
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &entry)
+	if entry.Active {
+		t.Errorf("updated entry = %+v", entry)
+	}
+	if match, err := ts.watchlist.Match(watched.IDCard); err != nil || match != nil {
+		t.Errorf("Match() of an inactive entry = %+v, %v", match, err)
+	}
+
+	var list []models.WatchlistEntry
+	rec = ts.do("GET", "/api/watchlist", nil)
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &list)
+	if len(list) != 0 {
+		t.Errorf("active watchlist = %+v", list)
+	}
+	rec = ts.do("GET", "/api/watchlist?all=true", nil)
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &list)
+	if len(list) != 1 {
+		t.Errorf("whole watchlist = %+v", list)
+	}
+
+	expectStatus(t, ts.do("PUT", "/api/watchlist/x", models.SaveWatchlistRequest{}), http.StatusBadRequest)
+	expectStatus(t, ts.do("PUT", "/api/watchlist/99", models.SaveWatchlistRequest{}), http.StatusNotFound)
+	expectStatus(t, ts.do("DELETE", "/api/watchlist/x", nil), http.StatusBadRequest)
+	expectStatus(t, ts.do("DELETE", path, nil), http.StatusNoContent)
+	expectStatus(t, ts.do("DELETE", path, nil), http.StatusNotFound)
+}
@@ -0,0 +1,385 @@
+// Package migrate applies the versioned SQL migrations and records them in schema_migrations.
+//
+// แต่ละ migration ถูกบันทึกพร้อม checksum ของไฟล์ ถ้าไฟล์ที่ apply แล้วถูกแก้ จะไม่ยอม migrate ต่อ
+// ระหว่าง migrate จะถือ lock ของฐานข้อมูล server หลายเครื่องที่เปิดพร้อมกันจึงไม่ migrate ซ้อนกัน
+// (MySQL ใช้ GET_LOCK, PostgreSQL ใช้ advisory lock ส่วน SQLite เป็นไฟล์ของ server เครื่องเดียว ไม่ต้องใช้ lock)
+//
+// ฐานข้อมูลเดิมที่รัน SQL เองมาก่อน ให้ใช้ Baseline บันทึกว่า apply ถึงเลขไหนแล้ว (ไม่รัน SQL)
+// หมายเลขไฟล์ถูกเรียงใหม่ตอนเพิ่มระบบนี้ (002_create_return_card_logs เดิมเป็น 003 ไฟล์ถัดไปเลื่อนขึ้นหนึ่ง)
+// ฐานข้อมูลที่รันไฟล์เดิมครบถึง 012_create_watchlist จึงตรงกับ baseline 13
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"backend/internal/database"
+)
+
+// lockTimeout คือเวลาที่รอ server อื่นที่กำลัง migrate อยู่
+const lockTimeout = 5 * time.Minute
+
+var fileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+?)(\.down)?\.sql$`)
+
+// Migration is one version with its up and optional down SQL
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string // ว่าง = ย้อนกลับไม่ได้
+	Checksum string // SHA-256 ของไฟล์ขาขึ้น
+}
+
+// Status describes a migration and whether it has been applied
+type Status struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+	Dirty     bool // เริ่มรันแล้วแต่ไม่สำเร็จ ต้องแก้ฐานข้อมูลเองแล้วใช้ Baseline
+	Changed   bool // ไฟล์ถูกแก้หลังจาก apply
+	Missing   bool // มีในฐานข้อมูลแต่ไม่มีไฟล์ (เช่นรันโปรแกรมรุ่นเก่ากับฐานข้อมูลใหม่)
+}
+
+// Load reads NNN_name.sql and NNN_name.down.sql files from fsys, ordered by version
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		m := fileName.FindStringSubmatch(entry.Name())
+		if m == nil {
+			continue
+		}
+		version, _ := strconv.Atoi(m[1])
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		mig := byVersion[version]
+		if mig == nil {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		}
+		if mig.Name != m[2] {
+			return nil, fmt.Errorf("migration version %d is used by both %s and %s", version, mig.Name, m[2])
+		}
+
+		if m[3] != "" {
+			mig.Down = string(data)
+			continue
+		}
+		if mig.Up != "" {
+			return nil, fmt.Errorf("migration version %d is defined twice", version)
+		}
+		mig.Up = string(data)
+		sum := sha256.Sum256(data)
+		mig.Checksum = hex.EncodeToString(sum[:])
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" {
+			return nil, fmt.Errorf("migration %03d_%s has a down file but no up file", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+type record struct {
+	name      string
+	checksum  string
+	appliedAt time.Time
+	dirty     bool
+}
+
+type Runner struct {
+	db         *sql.DB
+	dialect    database.Dialect
+	migrations []Migration
+	now        func() time.Time
+}
+
+func NewRunner(db *sql.DB, fsys fs.FS) (*Runner, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Runner{db: db, dialect: database.DialectOf(db), migrations: migrations, now: time.Now}, nil
+}
+
+// Latest returns the highest migration version, 0 if there are none
+func (r *Runner) Latest() int {
+	if len(r.migrations) == 0 {
+		return 0
+	}
+	return r.migrations[len(r.migrations)-1].Version
+}
+
+// Up applies pending migrations up to and including target (0 = all) and returns how many ran
+func (r *Runner) Up(ctx context.Context, target int) (int, error) {
+	applied := 0
+	err := r.locked(ctx, func(conn *sql.Conn, records map[int]record) error {
+		if err := r.verify(records); err != nil {
+			return err
+		}
+		for _, m := range r.migrations {
+			if target > 0 && m.Version > target {
+				break
+			}
+			if _, ok := records[m.Version]; ok {
+				continue
+			}
+
+			log.Printf("Applying migration %03d_%s", m.Version, m.Name)
+			if _, err := conn.ExecContext(ctx,
+				`INSERT INTO schema_migrations (version, name, checksum, applied_at, dirty) VALUES (?, ?, ?, ?, TRUE)`,
+				m.Version, m.Name, m.Checksum, r.now().UTC()); err != nil {
+				return fmt.Errorf("failed to record migration %d: %w", m.Version, err)
+			}
+			if err := execAll(ctx, conn, m.Up); err != nil {
+				return fmt.Errorf("migration %03d_%s failed, the database may be partly migrated: %w", m.Version, m.Name, err)
+			}
+			if _, err := conn.ExecContext(ctx,
+				`UPDATE schema_migrations SET dirty = FALSE, applied_at = ? WHERE version = ?`,
+				r.now().UTC(), m.Version); err != nil {
+				return fmt.Errorf("failed to record migration %d: %w", m.Version, err)
+			}
+			applied++
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down rolls back the last steps applied migrations and returns how many were rolled back
+func (r *Runner) Down(ctx context.Context, steps int) (int, error) {
+	rolledBack := 0
+	err := r.locked(ctx, func(conn *sql.Conn, records map[int]record) error {
+		if err := r.verify(records); err != nil {
+			return err
+		}
+		for i := len(r.migrations) - 1; i >= 0 && rolledBack < steps; i-- {
+			m := r.migrations[i]
+			if _, ok := records[m.Version]; !ok {
+				continue
+			}
+			if m.Down == "" {
+				return fmt.Errorf("migration %03d_%s cannot be rolled back, it has no down file", m.Version, m.Name)
+			}
+
+			log.Printf("Rolling back migration %03d_%s", m.Version, m.Name)
+			if _, err := conn.ExecContext(ctx, `UPDATE schema_migrations SET dirty = TRUE WHERE version = ?`, m.Version); err != nil {
+				return fmt.Errorf("failed to record migration %d: %w", m.Version, err)
+			}
+			if err := execAll(ctx, conn, m.Down); err != nil {
+				return fmt.Errorf("rolling back %03d_%s failed, the database may be partly rolled back: %w", m.Version, m.Name, err)
+			}
+			if _, err := conn.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = ?`, m.Version); err != nil {
+				return fmt.Errorf("failed to record migration %d: %w", m.Version, err)
+			}
+			rolledBack++
+		}
+		return nil
+	})
+	return rolledBack, err
+}
+
+// Baseline records every migration up to version as applied without running it,
+// and forgets the ones after it. ใช้กับฐานข้อมูลที่รัน SQL เองมาก่อน
+// หรือหลัง migration ล้มเหลวแล้วแก้ฐานข้อมูลเอง (ทำต่อจนเสร็จ = baseline เลขนั้น, ย้อนกลับ = baseline เลขก่อนหน้า)
+func (r *Runner) Baseline(ctx context.Context, version int) error {
+	if version < 0 || version > r.Latest() {
+		return fmt.Errorf("version must be between 0 and %d", r.Latest())
+	}
+	return r.locked(ctx, func(conn *sql.Conn, records map[int]record) error {
+		if _, err := conn.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version > ?`, version); err != nil {
+			return fmt.Errorf("failed to clear migrations after %d: %w", version, err)
+		}
+		for _, m := range r.migrations {
+			if m.Version > version {
+				break
+			}
+			// REPLACE INTO มีแค่ MySQL/SQLite
+			if _, err := conn.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = ?`, m.Version); err != nil {
+				return fmt.Errorf("failed to record migration %d: %w", m.Version, err)
+			}
+			if _, err := conn.ExecContext(ctx,
+				`INSERT INTO schema_migrations (version, name, checksum, applied_at, dirty) VALUES (?, ?, ?, ?, FALSE)`,
+				m.Version, m.Name, m.Checksum, r.now().UTC()); err != nil {
+				return fmt.Errorf("failed to record migration %d: %w", m.Version, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Status lists every known migration and the applied ones that have no file
+func (r *Runner) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+	err := r.locked(ctx, func(conn *sql.Conn, records map[int]record) error {
+		known := map[int]bool{}
+		for _, m := range r.migrations {
+			known[m.Version] = true
+			s := Status{Version: m.Version, Name: m.Name}
+			if rec, ok := records[m.Version]; ok {
+				appliedAt := rec.appliedAt
+				s.Applied = true
+				s.AppliedAt = &appliedAt
+				s.Dirty = rec.dirty
+				s.Changed = rec.checksum != m.Checksum
+			}
+			statuses = append(statuses, s)
+		}
+		for version, rec := range records {
+			if !known[version] {
+				appliedAt := rec.appliedAt
+				statuses = append(statuses, Status{Version: version, Name: rec.name, Applied: true, AppliedAt: &appliedAt, Dirty: rec.dirty, Missing: true})
+			}
+		}
+		return nil
+	})
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, err
+}
+
+// verify refuses to continue when a migration failed halfway or an applied file was edited
+func (r *Runner) verify(records map[int]record) error {
+	for _, m := range r.migrations {
+		rec, ok := records[m.Version]
+		if !ok {
+			continue
+		}
+		if rec.dirty {
+			return fmt.Errorf("migration %03d_%s did not finish, fix the database by hand then run baseline", m.Version, m.Name)
+		}
+		if rec.checksum != m.Checksum {
+			return fmt.Errorf("migration %03d_%s was changed after it was applied, add a new migration instead", m.Version, m.Name)
+		}
+	}
+	return nil
+}
+
+// locked runs fn on one connection while holding the migration lock.
+// GET_LOCK และ advisory lock ของ PostgreSQL ผูกกับ session จึงต้องใช้ connection เดียวตลอด (PRAGMA ของ SQLite ก็เช่นกัน)
+func (r *Runner) locked(ctx context.Context, fn func(conn *sql.Conn, records map[int]record) error) error {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	switch r.dialect {
+	case database.MySQL:
+		var got sql.NullInt64
+		if err := conn.QueryRowContext(ctx,
+			`SELECT GET_LOCK(CONCAT('schema_migrations.', DATABASE()), ?)`, int(lockTimeout.Seconds())).Scan(&got); err != nil {
+			return fmt.Errorf("failed to get migration lock: %w", err)
+		}
+		if got.Int64 != 1 {
+			return fmt.Errorf("another server is migrating the database, gave up after %s", lockTimeout)
+		}
+		defer conn.ExecContext(context.Background(), `SELECT RELEASE_LOCK(CONCAT('schema_migrations.', DATABASE()))`)
+	case database.Postgres:
+		if err := postgresLock(ctx, conn); err != nil {
+			return err
+		}
+		defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock(hashtext('schema_migrations.' || current_database()))`)
+	}
+
+	appliedAt := `DATETIME`
+	if r.dialect == database.Postgres {
+		appliedAt = `TIMESTAMPTZ`
+	}
+	ddl := `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			checksum CHAR(64) NOT NULL,
+			applied_at ` + appliedAt + ` NOT NULL,
+			dirty BOOLEAN NOT NULL DEFAULT FALSE
+		)`
+	if r.dialect == database.MySQL {
+		ddl += ` ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`
+	}
+	if _, err := conn.ExecContext(ctx, ddl); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	records, err := readRecords(ctx, conn)
+	if err != nil {
+		return err
+	}
+	return fn(conn, records)
+}
+
+// postgresLock waits up to lockTimeout for the advisory lock of the database, like GET_LOCK
+func postgresLock(ctx context.Context, conn *sql.Conn) error {
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		var got bool
+		if err := conn.QueryRowContext(ctx,
+			`SELECT pg_try_advisory_lock(hashtext('schema_migrations.' || current_database()))`).Scan(&got); err != nil {
+			return fmt.Errorf("failed to get migration lock: %w", err)
+		}
+		if got {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("another server is migrating the database, gave up after %s", lockTimeout)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+func readRecords(ctx context.Context, conn *sql.Conn) (map[int]record, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version, name, checksum, applied_at, dirty FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	records := map[int]record{}
+	for rows.Next() {
+		var version int
+		var rec record
+		if err := rows.Scan(&version, &rec.name, &rec.checksum, &rec.appliedAt, &rec.dirty); err != nil {
+			return nil, fmt.Errorf("failed to scan schema_migrations: %w", err)
+		}
+		records[version] = rec
+	}
+	return records, rows.Err()
+}
+
+// execAll runs the statements of a file one by one on the same session (SET @var ใช้ข้ามคำสั่งได้)
+func execAll(ctx context.Context, conn *sql.Conn, src string) error {
+	for i, stmt := range splitStatements(src) {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("statement %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,282 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"backend/internal/database"
+	"backend/internal/database/dbtest"
+	"backend/migrations"
+)
+
+func TestSplitStatements(t *testing.T) {
+	src := `-- migrations/010_example.sql
+-- คำอธิบาย; มี semicolon ใน comment
+SET @old_offset = '+07:00';
+
+# MySQL comment
+INSERT INTO t (a, b) VALUES ('x;y', "it''s"), ('a\'b;', 'c');
+SET @sql = IF(@x = 'datetime',
+    'UPDATE v SET e = CONVERT_TZ(e, @old_offset, ''+00:00'')',
+    'DO 0');
+/* block; comment */ SELECT ` + "`odd;name`" + ` FROM t;
+SELECT 1 - -1;
+`
+	want := []string{
+		"SET @old_offset = '+07:00'",
+		`INSERT INTO t (a, b) VALUES ('x;y', "it''s"), ('a\'b;', 'c')`,
+		"SET @sql = IF(@x = 'datetime',\n    'UPDATE v SET e = CONVERT_TZ(e, @old_offset, ''+00:00'')',\n    'DO 0')",
+		"SELECT `odd;name` FROM t",
+		"SELECT 1 - -1",
+	}
+
+	if got := splitStatements(src); !reflect.DeepEqual(got, want) {
+		t.Errorf("splitStatements() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+// ; ภายใน BEGIN ... END ของ trigger ไม่ใช่จุดจบคำสั่ง
+func TestSplitStatementsKeepsTriggerBody(t *testing.T) {
+	src := `CREATE TRIGGER trg AFTER UPDATE ON t FOR EACH ROW
+BEGIN
+    UPDATE t SET a = 1 WHERE id = NEW.id;
+    UPDATE t SET b = 'end;' WHERE id = NEW.id;
+END;
+DROP INDEX idx;`
+	want := []string{
+		"CREATE TRIGGER trg AFTER UPDATE ON t FOR EACH ROW\nBEGIN\n    UPDATE t SET a = 1 WHERE id = NEW.id;\n    UPDATE t SET b = 'end;' WHERE id = NEW.id;\nEND",
+		"DROP INDEX idx",
+	}
+
+	if got := splitStatements(src); !reflect.DeepEqual(got, want) {
+		t.Errorf("splitStatements() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestSplitStatementsKeepsFunctionBody(t *testing.T) {
+	src := `CREATE FUNCTION touch() RETURNS trigger AS $$
+BEGIN
+    NEW.updated_at := CURRENT_TIMESTAMP;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+CREATE TRIGGER trg BEFORE UPDATE ON t FOR EACH ROW EXECUTE FUNCTION touch();
+SELECT price$ FROM t WHERE a = $body$;$body$;`
+	want := []string{
+		"CREATE FUNCTION touch() RETURNS trigger AS $$\nBEGIN\n    NEW.updated_at := CURRENT_TIMESTAMP;\n    RETURN NEW;\nEND;\n$$ LANGUAGE plpgsql",
+		"CREATE TRIGGER trg BEFORE UPDATE ON t FOR EACH ROW EXECUTE FUNCTION touch()",
+		"SELECT price$ FROM t WHERE a = $body$;$body$",
+	}
+
+	if got := splitStatements(src); !reflect.DeepEqual(got, want) {
+		t.Errorf("splitStatements() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"002_add_b.sql":         {Data: []byte("ALTER TABLE a ADD b INT;")},
+		"001_create_a.sql":      {Data: []byte("CREATE TABLE a (id INT);")},
+		"001_create_a.down.sql": {Data: []byte("DROP TABLE a;")},
+		"README.md":             {Data: []byte("not a migration")},
+	}
+
+	got, err := Load(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Version != 1 || got[1].Version != 2 {
+		t.Fatalf("Load() = %+v, want versions 1 and 2 in order", got)
+	}
+	if got[0].Name != "create_a" || got[0].Down != "DROP TABLE a;" || got[1].Down != "" {
+		t.Errorf("Load() did not pair the down files: %+v", got)
+	}
+	if len(got[0].Checksum) != 64 || got[0].Checksum == got[1].Checksum {
+		t.Errorf("Load() checksums = %q, %q", got[0].Checksum, got[1].Checksum)
+	}
+}
+
+func TestLoadRejectsDuplicateVersions(t *testing.T) {
+	fsys := fstest.MapFS{
+		"002_create_export_history_table.sql": {Data: []byte("SELECT 1;")},
+		"002_create_return_card_logs.sql":     {Data: []byte("SELECT 2;")},
+	}
+	if _, err := Load(fsys); err == nil || !strings.Contains(err.Error(), "version 2") {
+		t.Errorf("Load() error = %v, want duplicate version error", err)
+	}
+}
+
+// ไฟล์ที่ฝังในโปรแกรมต้องโหลดได้ เลขต่อเนื่อง ย้อนกลับได้ทุกตัว และทุก driver มีชุดเลขและชื่อเดียวกัน
+func TestEmbeddedMigrations(t *testing.T) {
+	var names []string
+	for _, driver := range migrations.Drivers {
+		fsys, err := migrations.For(driver)
+		if err != nil {
+			t.Fatal(err)
+		}
+		all, err := Load(fsys)
+		if err != nil {
+			t.Fatalf("%s: %v", driver, err)
+		}
+		if len(all) == 0 {
+			t.Fatalf("%s: no embedded migrations", driver)
+		}
+
+		var driverNames []string
+		for i, m := range all {
+			driverNames = append(driverNames, fmt.Sprintf("%03d_%s", m.Version, m.Name))
+			if m.Version != i+1 {
+				t.Errorf("%s: migration %03d_%s: want version %d", driver, m.Version, m.Name, i+1)
+			}
+			if m.Down == "" {
+				t.Errorf("%s: migration %03d_%s has no down file", driver, m.Version, m.Name)
+			}
+			for _, stmt := range splitStatements(m.Up) {
+				if strings.HasPrefix(strings.ToUpper(stmt), "USE ") || strings.HasPrefix(strings.ToUpper(stmt), "CREATE DATABASE") {
+					t.Errorf("%s: migration %03d_%s must run against the configured database: %s", driver, m.Version, m.Name, stmt)
+				}
+			}
+		}
+		if names == nil {
+			names = driverNames
+		} else if !reflect.DeepEqual(driverNames, names) {
+			t.Errorf("%s migrations = %v, want the same as %s: %v", driver, driverNames, migrations.Drivers[0], names)
+		}
+	}
+	if _, err := migrations.For("oracle"); err == nil {
+		t.Error("For() should reject an unknown driver")
+	}
+}
+
+// ทุก migration ต้องรันขึ้น ย้อนกลับจนหมด แล้วรันขึ้นใหม่ได้บนฐานข้อมูลจริงของทุก driver
+func TestRunnerUpDown(t *testing.T) {
+	dbtest.Run(t, func(t *testing.T, db *sql.DB) {
+		fsys, err := migrations.For(string(database.DialectOf(db)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		runner, err := NewRunner(db, fsys)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx := context.Background()
+
+		if n, err := runner.Up(ctx, 3); err != nil || n != 3 {
+			t.Fatalf("Up(3) = %d, %v", n, err)
+		}
+		if n, err := runner.Up(ctx, 0); err != nil || n != runner.Latest()-3 {
+			t.Fatalf("Up(0) = %d, %v", n, err)
+		}
+		statuses, err := runner.Status(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range statuses {
+			if !s.Applied || s.Dirty || s.Changed || s.Missing {
+				t.Errorf("status after up = %+v", s)
+			}
+		}
+
+		if n, err := runner.Down(ctx, runner.Latest()); err != nil || n != runner.Latest() {
+			t.Fatalf("Down(all) = %d, %v", n, err)
+		}
+		var tables int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM visitors`).Scan(&tables); err == nil {
+			t.Error("visitors still exists after rolling back every migration")
+		}
+
+		if n, err := runner.Up(ctx, 0); err != nil || n != runner.Latest() {
+			t.Fatalf("Up(0) after down = %d, %v", n, err)
+		}
+
+		// baseline ไม่รัน SQL แค่บันทึก
+		if err := runner.Baseline(ctx, 5); err != nil {
+			t.Fatal(err)
+		}
+		statuses, _ = runner.Status(ctx)
+		if len(statuses) != runner.Latest() || !statuses[4].Applied || statuses[5].Applied {
+			t.Errorf("status after baseline 5 = %+v", statuses)
+		}
+	})
+}
+
+// 016 แปลงส่วนงานที่เป็นข้อความอิสระเป็นตาราง departments และผูก visitors.department_id
+func TestDepartmentMigration(t *testing.T) {
+	dbtest.Run(t, func(t *testing.T, db *sql.DB) {
+		fsys, err := migrations.For(string(database.DialectOf(db)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		runner, err := NewRunner(db, fsys)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx := context.Background()
+		if _, err := runner.Up(ctx, 15); err != nil {
+			t.Fatal(err)
+		}
+
+		exec := func(query string, args ...interface{}) {
+			t.Helper()
+			if _, err := db.Exec(query, args...); err != nil {
+				t.Fatalf("%s: %v", query, err)
+			}
+		}
+		exec(`INSERT INTO schools (id, code, name, departments) VALUES (2, 'school-b', 'โรงเรียนบ้านเหนือ', ?)`, `["ฝ่ายวิชาการ", "ฝ่ายบุคคล"]`)
+		for i, v := range []struct {
+			school     int
+			department string
+		}{{1, "กลุ่มบริหารวิชาการ"}, {1, " ห้องพยาบาล "}, {2, "ฝ่ายบุคคล"}, {2, ""}} {
+			exec(`INSERT INTO visitors (school_id, id_card, first_name, last_name, phone, department) VALUES (?, ?, 'ก', 'ข', '0800000000', ?)`,
+				v.school, fmt.Sprintf("11012345%05d", i), v.department)
+		}
+
+		if _, err := runner.Up(ctx, 16); err != nil {
+			t.Fatal(err)
+		}
+
+		rows, err := db.Query(`SELECT school_id, name_th, active, display_order FROM departments ORDER BY school_id, display_order, id`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for rows.Next() {
+			var school, order int
+			var name string
+			var active bool
+			if err := rows.Scan(&school, &name, &active, &order); err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, fmt.Sprintf("%d %s %v %d", school, name, active, order))
+		}
+		rows.Close()
+		want := []string{
+			"1 สำนักงานผู้อำนวยการ true 1", "1 กลุ่มบริหารงานบุคคล true 2", "1 กลุ่มบริหารวิชาการ true 3",
+			"1 กลุ่มบริหารทั่วไป true 4", "1 กลุ่มบริหารงบประมาน true 5", "1 ติดต่อ ข้าราชการครู true 6",
+			"1 นักพัฒนา true 7", "1 พ่อค้า และ แม่ค้า true 8", "1 ร้านค้าสหกรณ์โรงเรียน true 9",
+			"1 ห้องพยาบาล false 1000",
+			"2 ฝ่ายวิชาการ true 1", "2 ฝ่ายบุคคล true 2",
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("departments =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+		}
+
+		var unmapped int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM visitors WHERE department_id IS NULL AND department <> ''`).Scan(&unmapped); err != nil || unmapped != 0 {
+			t.Errorf("visitors without department_id = %d, %v", unmapped, err)
+		}
+		var mismatched int
+		err = db.QueryRow(`
+			SELECT COUNT(*) FROM visitors v JOIN departments d ON d.id = v.department_id
+			WHERE d.school_id <> v.school_id OR d.name_th <> TRIM(v.department)
+		`).Scan(&mismatched)
+		if err != nil || mismatched != 0 {
+			t.Errorf("visitors mapped to the wrong department = %d, %v", mismatched, err)
+		}
+	})
+}
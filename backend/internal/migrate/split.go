@@ -0,0 +1,134 @@
+package migrate
+
+import "strings"
+
+// splitStatements splits a SQL file into statements on ; outside of strings and comments.
+// driver ของ MySQL รันได้ครั้งละหนึ่งคำสั่ง (ไม่เปิด multiStatements) จึงต้องแยกเอง
+// comment ถูกตัดทิ้ง คำสั่งที่เหลือแต่ช่องว่างไม่ถูกนับ
+func splitStatements(src string) []string {
+	var (
+		statements []string
+		current    strings.Builder
+	)
+	flush := func() {
+		if s := strings.TrimSpace(current.String()); s != "" {
+			statements = append(statements, s)
+		}
+		current.Reset()
+	}
+
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end := skipQuoted(src, i)
+			current.WriteString(src[i:end])
+			i = end - 1
+
+		case c == '$' && dollarQuoteEnd(src, i) > i:
+			// ตัวฟังก์ชันของ PostgreSQL ($$ ... $$) มี ; ข้างใน
+			end := dollarQuoteEnd(src, i)
+			current.WriteString(src[i:end])
+			i = end - 1
+
+		case c == '#' || (c == '-' && strings.HasPrefix(src[i:], "--") && (i+2 == len(src) || isSpace(src[i+2]))):
+			// comment ถึงท้ายบรรทัด (MySQL ต้องมีช่องว่างหลัง --)
+			end := strings.IndexByte(src[i:], '\n')
+			if end < 0 {
+				i = len(src)
+			} else {
+				i += end - 1
+			}
+
+		case c == '/' && strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				i = len(src)
+			} else {
+				i += end + 3
+			}
+			current.WriteByte(' ')
+
+		case c == ';':
+			// ตัวของ trigger มี ; คั่นคำสั่งข้างใน คำสั่ง CREATE TRIGGER จบที่ END;
+			if inTriggerBody(current.String()) {
+				current.WriteByte(c)
+				continue
+			}
+			flush()
+
+		default:
+			current.WriteByte(c)
+		}
+	}
+	flush()
+
+	return statements
+}
+
+// inTriggerBody reports whether stmt is a CREATE [TEMP] TRIGGER whose BEGIN ... END is still open.
+// trigger ของ PostgreSQL (EXECUTE FUNCTION) ไม่มี BEGIN จบที่ ; แรก
+func inTriggerBody(stmt string) bool {
+	words := strings.Fields(strings.ToUpper(stmt))
+	if len(words) < 3 || words[0] != "CREATE" || (words[1] != "TRIGGER" && words[2] != "TRIGGER") {
+		return false
+	}
+	for _, word := range words {
+		if word == "BEGIN" {
+			return words[len(words)-1] != "END"
+		}
+	}
+	return false
+}
+
+// skipQuoted returns the index just after the string or identifier starting at src[start]
+func skipQuoted(src string, start int) int {
+	quote := src[start]
+	for i := start + 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case quote:
+			// '' ภายในสตริงคือ ' หนึ่งตัว
+			if i+1 < len(src) && src[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(src)
+}
+
+// dollarQuoteEnd returns the index just after the dollar-quoted string ($$ or $tag$) starting at src[start],
+// or start when there is none. $ ที่เป็นส่วนของชื่อหรือ $1 ไม่ใช่
+func dollarQuoteEnd(src string, start int) int {
+	if start > 0 && isIdentByte(src[start-1]) {
+		return start
+	}
+	for i := start + 1; i < len(src); i++ {
+		c := src[i]
+		if c == '$' {
+			tag := src[start : i+1]
+			end := strings.Index(src[i+1:], tag)
+			if end < 0 {
+				return len(src)
+			}
+			return i + 1 + end + len(tag)
+		}
+		if !isIdentByte(c) || (i == start+1 && c >= '0' && c <= '9') {
+			return start
+		}
+	}
+	return start
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// Appointment statuses
+const (
+	AppointmentPending   = "pending"
+	AppointmentArrived   = "arrived"
+	AppointmentCancelled = "cancelled"
+)
+
+// Appointment represents a pre-registered, expected visit
+type Appointment struct {
+	ID            int        `json:"id" db:"id"`
+	IDCard        string     `json:"idCard" db:"id_card"`
+	FirstName     string     `json:"firstName" db:"first_name"`
+	LastName      string     `json:"lastName" db:"last_name"`
+	Phone         string     `json:"phone" db:"phone"`
+	LicensePlate  string     `json:"licensePlate" db:"license_plate"`
+	HostName      string     `json:"hostName" db:"host_name"`
+	HostContact   string     `json:"hostContact" db:"host_contact"`
+	Department    string     `json:"department" db:"department"`
+	Purpose       string     `json:"purpose" db:"purpose"`
+	ExpectedFrom  time.Time  `json:"expectedFrom" db:"expected_from"`
+	ExpectedUntil time.Time  `json:"expectedUntil" db:"expected_until"`
+	Status        string     `json:"status" db:"status"`
+	VisitorID     *int       `json:"visitorId" db:"visitor_id"`
+	CreatedBy     string     `json:"createdBy" db:"created_by"`
+	ArrivedAt     *time.Time `json:"arrivedAt" db:"arrived_at"`
+	CreatedAt     time.Time  `json:"createdAt" db:"created_at"`
+}
+
+// CreateAppointmentRequest represents the request body for pre-registering a visitor
+type CreateAppointmentRequest struct {
+	IDCard        string `json:"idCard"`
+	FirstName     string `json:"firstName"`
+	LastName      string `json:"lastName"`
+	Phone         string `json:"phone"`
+	LicensePlate  string `json:"licensePlate"`
+	HostName      string `json:"hostName"`
+	HostContact   string `json:"hostContact"`
+	Department    string `json:"department"`
+	Purpose       string `json:"purpose"`
+	ExpectedFrom  string `json:"expectedFrom"`  // RFC3339
+	ExpectedUntil string `json:"expectedUntil"` // RFC3339
+	CreatedBy     string `json:"createdBy"`
+}
+
+// AppointmentInvite is returned after creating an appointment
+type AppointmentInvite struct {
+	Appointment *Appointment `json:"appointment"`
+	Token       string       `json:"token"`
+	QRCodeURL   string       `json:"qrCodeUrl"`
+}
+
+// ScanAppointmentRequest represents the token scanned at the gate
+type ScanAppointmentRequest struct {
+	Token string `json:"token"`
+}
+
+// ScanAppointmentResponse pre-fills the registration form from an appointment
+type ScanAppointmentResponse struct {
+	Appointment *Appointment         `json:"appointment"`
+	Visitor     CreateVisitorRequest `json:"visitor"`
+}
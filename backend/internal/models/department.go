@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Department is one entry of a school's list of departments a visitor can come to see (ส่วนงาน)
+type Department struct {
+	ID           int       `json:"id" db:"id"`
+	NameTh       string    `json:"nameTh" db:"name_th"`
+	NameEn       string    `json:"nameEn" db:"name_en"`
+	Active       bool      `json:"active" db:"active"` // ปิดแล้วเลือกตอนลงทะเบียนไม่ได้ แต่ยังใช้กรองข้อมูลย้อนหลังได้
+	DisplayOrder int       `json:"displayOrder" db:"display_order"`
+	CreatedAt    time.Time `json:"createdAt" db:"created_at"`
+}
+
+// SaveDepartmentRequest represents the request body for creating or updating a department
+type SaveDepartmentRequest struct {
+	NameTh       string `json:"nameTh"`
+	NameEn       string `json:"nameEn"`
+	Active       *bool  `json:"active"` // ว่าง = ใช้งาน (ตอนสร้าง) หรือคงเดิม (ตอนแก้)
+	DisplayOrder int    `json:"displayOrder"`
+}
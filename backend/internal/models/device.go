@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// Device roles decide what a card tap on the reader does
+const (
+	DeviceRoleCheckIn  = "check_in"  // ประตูเข้า: ตรวจว่าบัตรใช้ผ่านได้
+	DeviceRoleCheckOut = "check_out" // ประตูออก: คืนบัตร
+	DeviceRoleRollCall = "roll_call" // โต๊ะประชาสัมพันธ์/จุดรวมพล: ตรวจว่ายังอยู่ในพื้นที่
+	DeviceRoleEdge     = "edge"      // server ที่ประตู (โหมด edge): ส่งรายการที่บันทึกไว้ขึ้นมา sync ไม่ได้แตะบัตร
+)
+
+// Tap results
+const (
+	TapAccepted    = "accepted"
+	TapRejected    = "rejected"
+	TapUnknownCard = "unknown_card"
+	TapRepeat      = "repeat" // แตะซ้ำติดกัน ไม่ได้ทำอะไรเพิ่ม
+)
+
+// Device is a registered RFID reader
+type Device struct {
+	ID         int        `json:"id" db:"id"`
+	Name       string     `json:"name" db:"name"`
+	Location   string     `json:"location" db:"location"`
+	Role       string     `json:"role" db:"role"`
+	Active     bool       `json:"active" db:"active"`
+	KeyHash    string     `json:"-" db:"key_hash"`
+	KeyHint    string     `json:"keyHint" db:"key_hint"`
+	LastSeenAt *time.Time `json:"lastSeenAt" db:"last_seen_at"`
+	CreatedAt  time.Time  `json:"createdAt" db:"created_at"`
+}
+
+// DeviceTap is one card tap reported by a reader and what it was routed to
+type DeviceTap struct {
+	ID          int       `json:"id" db:"id"`
+	DeviceID    int       `json:"deviceId" db:"device_id"`
+	CardUID     string    `json:"cardUid" db:"card_uid"`
+	Role        string    `json:"role" db:"role"`
+	Result      string    `json:"result" db:"result"`
+	VisitorID   *int      `json:"visitorId" db:"visitor_id"`
+	VisitorName string    `json:"visitorName" db:"-"`
+	Message     string    `json:"message" db:"message"` // ข้อความสั้นให้เครื่องอ่านแสดงบนจอ
+	TappedAt    time.Time `json:"tappedAt" db:"tapped_at"`
+}
+
+// SaveDeviceRequest represents the request body for registering or updating a reader
+type SaveDeviceRequest struct {
+	Name     string `json:"name"`
+	Location string `json:"location"`
+	Role     string `json:"role"`
+	Active   *bool  `json:"active"` // ว่าง = ใช้งาน
+}
+
+// DeviceKeyResponse returns the API key; the key is shown only once
+type DeviceKeyResponse struct {
+	Device *Device `json:"device"`
+	APIKey string  `json:"apiKey"`
+}
+
+// TapRequest represents a card tap reported by a reader
+type TapRequest struct {
+	CardUID string `json:"cardUid"`
+}
@@ -0,0 +1,11 @@
+package models
+
+// VisitPurpose is one entry of the configurable list of visit purposes
+type VisitPurpose struct {
+	Code             string `json:"code" db:"code"`
+	NameTh           string `json:"nameTh" db:"name_th"`
+	NameEn           string `json:"nameEn" db:"name_en"`
+	RequiresApproval bool   `json:"requiresApproval" db:"requires_approval"`
+	Active           bool   `json:"active" db:"active"`
+	DisplayOrder     int    `json:"displayOrder" db:"display_order"`
+}
@@ -5,6 +5,7 @@ import "time"
 // ReturnCardLog represents a return card history record
 type ReturnCardLog struct {
 	ID         int       `json:"id" db:"id"`
+	ClientID   string    `json:"clientId" db:"client_id"`
 	VisitorID  int       `json:"visitorId" db:"visitor_id"`
 	CardID     string    `json:"cardId" db:"card_id"`
 	Name       string    `json:"name" db:"name"`
@@ -13,15 +14,31 @@ type ReturnCardLog struct {
 	ReturnDate time.Time `json:"returnDate" db:"return_date"`
 	Status     string    `json:"status" db:"status"`
 	CreatedAt  time.Time `json:"createdAt" db:"created_at"`
+
+	// VoidedAt is set when the return was recorded by mistake and voided (ไม่นับเป็นการคืนบัตร)
+	VoidedAt   *time.Time `json:"voidedAt,omitempty" db:"voided_at"`
+	VoidedBy   string     `json:"voidedBy,omitempty" db:"voided_by"`
+	VoidReason string     `json:"voidReason,omitempty" db:"void_reason"`
+
+	// ReturnedAt is when the card came back, stored as the visitor's exit time (ว่าง = ตอนบันทึก)
+	ReturnedAt time.Time `json:"-" db:"-"`
 }
 
 // ReturnCardRequest represents the request body for returning a card
 type ReturnCardRequest struct {
 	CheckOut string `json:"checkOut" validate:"required"`
+	ClientID string `json:"clientId,omitempty"` // UUID ที่หน้าจอสร้างเอง (ว่าง = server สร้างให้)
+}
+
+// VoidReturnRequest represents the request body for voiding a mistaken return
+type VoidReturnRequest struct {
+	VoidedBy string `json:"voidedBy"`
+	Reason   string `json:"reason"`
 }
 
 // ReturnCardHistoryResponse represents the response for return card history
 type ReturnCardHistoryResponse struct {
+	ID      int    `json:"id"` // ใช้ยกเลิกการคืนบัตร
 	No      int    `json:"no"`
 	CardID  string `json:"cardId"`
 	Name    string `json:"name"`
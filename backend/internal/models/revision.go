@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// Revision actions
+const (
+	RevisionUpdate = "update"
+	RevisionDelete = "delete"
+	// RevisionVoidReturn: ยกเลิกการคืนบัตรที่บันทึกผิด ผู้มาติดต่อกลับเป็นยังอยู่ในโรงเรียน
+	RevisionVoidReturn = "void_return"
+)
+
+// UpdateVisitorRequest is the body of PATCH /api/visitors/{id}. ช่องที่ไม่ส่งมา (null) คงค่าเดิม
+// เลขบัตร RFID วัตถุประสงค์ และรถ แก้ผ่านขั้นตอนของมันเอง (ออกบัตร อนุมัติ) ไม่ได้แก้ที่นี่
+type UpdateVisitorRequest struct {
+	IDCard       *string `json:"idCard"`
+	FirstName    *string `json:"firstName"`
+	LastName     *string `json:"lastName"`
+	BirthDate    *string `json:"birthDate"` // "" = ลบวันเกิด
+	Phone        *string `json:"phone"`
+	HouseNumber  *string `json:"houseNumber"`
+	Moo          *string `json:"moo"`
+	Soi          *string `json:"soi"`
+	Road         *string `json:"road"`
+	SubDistrict  *string `json:"subDistrict"`
+	District     *string `json:"district"`
+	Province     *string `json:"province"`
+	PostalCode   *string `json:"postalCode"`
+	DepartmentID *int    `json:"departmentId"`
+	Department   *string `json:"department"` // ชื่อส่วนงาน ถ้าไม่ได้ส่ง departmentId
+	OfficerName  *string `json:"officerName"`
+	HostName     *string `json:"hostName"`
+	HostContact  *string `json:"hostContact"`
+	PurposeNote  *string `json:"purposeNote"`
+
+	ChangedBy string `json:"changedBy"` // ผู้แก้ไข (บังคับ)
+	Reason    string `json:"reason"`
+}
+
+// DeleteVisitorRequest is the body of DELETE /api/visitors/{id}
+type DeleteVisitorRequest struct {
+	DeletedBy string `json:"deletedBy"`
+	Reason    string `json:"reason"` // บังคับ
+}
+
+// VisitorChange is one field changed by a revision
+type VisitorChange struct {
+	Field string `json:"field"` // ชื่อ field แบบ JSON ของ Visitor เช่น firstName
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// VisitorRevision is one entry of a visitor's change history
+type VisitorRevision struct {
+	ID        int             `json:"id" db:"id"`
+	VisitorID int             `json:"visitorId" db:"visitor_id"`
+	Version   int             `json:"version" db:"version"` // version ของรายการหลังแก้
+	Action    string          `json:"action" db:"action"`
+	Changes   []VisitorChange `json:"changes" db:"changes"`
+	ChangedBy string          `json:"changedBy" db:"changed_by"`
+	Reason    string          `json:"reason" db:"reason"`
+	CreatedAt time.Time       `json:"createdAt" db:"created_at"`
+}
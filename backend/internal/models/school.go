@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// DefaultSchoolID is the school created by migration 015 that owns the data of a single-school deployment
+const DefaultSchoolID = 1
+
+// School is one tenant of a district deployment with its own configuration
+type School struct {
+	ID            int       `json:"id" db:"id"`
+	Code          string    `json:"code" db:"code"` // ใช้เป็น subdomain และ header X-School
+	Name          string    `json:"name" db:"name"`
+	Timezone      string    `json:"timezone" db:"timezone"`            // ว่าง = ตาม TIMEZONE ของ server
+	RetentionDays int       `json:"retentionDays" db:"retention_days"` // 0 = เก็บตลอด
+	Active        bool      `json:"active" db:"active"`
+	CreatedAt     time.Time `json:"createdAt" db:"created_at"`
+}
+
+// SaveSchoolRequest represents the request body for creating or updating a school
+type SaveSchoolRequest struct {
+	Code          string `json:"code"`
+	Name          string `json:"name"`
+	Timezone      string `json:"timezone"` // ว่าง = ตาม TIMEZONE ของ server
+	RetentionDays int    `json:"retentionDays"`
+	Active        *bool  `json:"active"` // ว่าง = ใช้งาน
+}
+
+// DistrictSchoolStats is the activity of one school over the report range (รายงานระดับเขต)
+type DistrictSchoolStats struct {
+	SchoolID int         `json:"schoolId"`
+	Code     string      `json:"code"`
+	Name     string      `json:"name"`
+	Totals   StatsTotals `json:"totals"`
+}
+
+// DistrictStatsResponse is the cross-school report of a date range, each school in its own timezone
+type DistrictStatsResponse struct {
+	StartDate string                `json:"startDate"`
+	EndDate   string                `json:"endDate"`
+	Schools   []DistrictSchoolStats `json:"schools"`
+	Totals    StatsTotals           `json:"totals"` // เวลาเฉลี่ยถ่วงด้วยจำนวนคนที่คืนบัตรของแต่ละโรงเรียน
+}
@@ -0,0 +1,36 @@
+package models
+
+// VisitorSearchHit represents a visitor matched by the search endpoint
+type VisitorSearchHit struct {
+	ID           int     `json:"id"`
+	IDCard       string  `json:"idCard"`
+	Name         string  `json:"name"`
+	Phone        string  `json:"phone"`
+	LicensePlate string  `json:"licensePlate"`
+	RFID         string  `json:"rfid"`
+	Department   string  `json:"department"`
+	RegisteredAt string  `json:"registeredAt"`
+	ExitTime     string  `json:"exitTime"`
+	Score        float64 `json:"score"`
+}
+
+// ReturnLogSearchHit represents a return card log matched by the search endpoint
+type ReturnLogSearchHit struct {
+	ID        int     `json:"id"`
+	VisitorID int     `json:"visitorId"`
+	CardID    string  `json:"cardId"`
+	Name      string  `json:"name"`
+	TimeIn    string  `json:"timeIn"`
+	TimeOut   string  `json:"timeOut"`
+	Date      string  `json:"date"`
+	Status    string  `json:"status"`
+	Score     float64 `json:"score"`
+}
+
+// SearchResponse represents the response for GET /api/search
+type SearchResponse struct {
+	Query      string               `json:"query"`
+	Visitors   []VisitorSearchHit   `json:"visitors"`
+	ReturnLogs []ReturnLogSearchHit `json:"returnLogs"`
+	Total      int                  `json:"total"`
+}
@@ -0,0 +1,58 @@
+package models
+
+// Stats bucket sizes
+const (
+	StatsByDay   = "day"
+	StatsByWeek  = "week" // สัปดาห์เริ่มวันจันทร์
+	StatsByMonth = "month"
+)
+
+// StatsParams are the filters of GET /api/stats
+type StatsParams struct {
+	StartDate    string // YYYY-MM-DD ตามเวลาของโรงเรียน
+	EndDate      string // YYYY-MM-DD (รวมวันนี้)
+	GroupBy      string
+	DepartmentID int // 0 = ทุกส่วนงาน
+}
+
+// StatsResponse is the dashboard and monthly report summary
+type StatsResponse struct {
+	StartDate   string        `json:"startDate"`
+	EndDate     string        `json:"endDate"`
+	GroupBy     string        `json:"groupBy"`
+	Department  string        `json:"department"` // ชื่อส่วนงานที่เลือก (ว่าง = ทุกส่วนงาน)
+	Totals      StatsTotals   `json:"totals"`
+	Series      []StatsBucket `json:"series"`
+	Departments []StatsCount  `json:"departments"`
+	Provinces   []StatsCount  `json:"provinces"`
+	Hours       []StatsHour   `json:"hours"`    // 24 ช่อง ตามชั่วโมงที่เข้า (เวลาโรงเรียน)
+	PeakHour    *int          `json:"peakHour"` // nil = ไม่มีผู้มาติดต่อในช่วงนี้
+}
+
+// StatsTotals are the totals over the whole range
+type StatsTotals struct {
+	Visits                 int     `json:"visits"`
+	CardsReturned          int     `json:"cardsReturned"`
+	CardsUnreturned        int     `json:"cardsUnreturned"` // ออกบัตรแล้วแต่ยังไม่คืน
+	AverageDurationMinutes float64 `json:"averageDurationMinutes"`
+}
+
+// StatsBucket is one day, week or month of the series
+type StatsBucket struct {
+	Start         string `json:"start"` // YYYY-MM-DD (ค.ศ.) วันแรกของช่วง
+	Label         string `json:"label"` // ตามรูปแบบวันที่ที่ขอ เช่น 01/06/2568
+	Visits        int    `json:"visits"`
+	CardsReturned int    `json:"cardsReturned"`
+}
+
+// StatsCount is the number of visits for one department or province
+type StatsCount struct {
+	Name   string `json:"name"`
+	Visits int    `json:"visits"`
+}
+
+// StatsHour is the number of arrivals in one hour of the day
+type StatsHour struct {
+	Hour   int `json:"hour"`
+	Visits int `json:"visits"`
+}
@@ -0,0 +1,85 @@
+package models
+
+import "time"
+
+// Student represents a student who may be collected by an authorised guardian
+type Student struct {
+	ID          int       `json:"id" db:"id"`
+	StudentCode string    `json:"studentCode" db:"student_code"`
+	FirstName   string    `json:"firstName" db:"first_name"`
+	LastName    string    `json:"lastName" db:"last_name"`
+	ClassRoom   string    `json:"classRoom" db:"class_room"`
+	Active      bool      `json:"active" db:"active"`
+	CreatedAt   time.Time `json:"createdAt" db:"created_at"`
+}
+
+// GuardianAuthorisation links an ID card number to a student it may collect
+type GuardianAuthorisation struct {
+	ID           int        `json:"id" db:"id"`
+	StudentID    int        `json:"studentId" db:"student_id"`
+	IDCard       string     `json:"idCard" db:"id_card"`
+	GuardianName string     `json:"guardianName" db:"guardian_name"`
+	Relationship string     `json:"relationship" db:"relationship"`
+	ValidFrom    time.Time  `json:"validFrom" db:"valid_from"`
+	ValidUntil   *time.Time `json:"validUntil" db:"valid_until"`
+	RevokedAt    *time.Time `json:"revokedAt" db:"revoked_at"`
+	CreatedAt    time.Time  `json:"createdAt" db:"created_at"`
+}
+
+// StudentPickup records a student leaving with a registered visitor
+type StudentPickup struct {
+	ID              int       `json:"id" db:"id"`
+	StudentID       int       `json:"studentId" db:"student_id"`
+	StudentName     string    `json:"studentName" db:"-"`
+	VisitorID       int       `json:"visitorId" db:"visitor_id"`
+	VisitorName     string    `json:"visitorName" db:"-"`
+	AuthorisationID int       `json:"authorisationId" db:"authorisation_id"`
+	Relationship    string    `json:"relationship" db:"-"`
+	OfficerName     string    `json:"officerName" db:"officer_name"`
+	PickedUpAt      time.Time `json:"pickedUpAt" db:"picked_up_at"`
+}
+
+// PickupAlert records a refused pickup attempt
+type PickupAlert struct {
+	ID             int        `json:"id" db:"id"`
+	StudentID      int        `json:"studentId" db:"student_id"`
+	StudentName    string     `json:"studentName" db:"-"`
+	VisitorID      *int       `json:"visitorId" db:"visitor_id"`
+	IDCard         string     `json:"idCard" db:"id_card"`
+	VisitorName    string     `json:"visitorName" db:"visitor_name"`
+	Reason         string     `json:"reason" db:"reason"`
+	OfficerName    string     `json:"officerName" db:"officer_name"`
+	CreatedAt      time.Time  `json:"createdAt" db:"created_at"`
+	AcknowledgedAt *time.Time `json:"acknowledgedAt" db:"acknowledged_at"`
+}
+
+// CreateStudentRequest represents the request body for adding a student
+type CreateStudentRequest struct {
+	StudentCode string `json:"studentCode"`
+	FirstName   string `json:"firstName"`
+	LastName    string `json:"lastName"`
+	ClassRoom   string `json:"classRoom"`
+}
+
+// AuthoriseGuardianRequest represents the request body for authorising a guardian
+type AuthoriseGuardianRequest struct {
+	IDCard       string  `json:"idCard"`
+	GuardianName string  `json:"guardianName"`
+	Relationship string  `json:"relationship"`
+	ValidFrom    string  `json:"validFrom"`  // YYYY-MM-DD (ว่าง = วันนี้)
+	ValidUntil   *string `json:"validUntil"` // YYYY-MM-DD (ว่าง = ไม่มีกำหนด)
+}
+
+// PickupRequest represents students leaving with a registered visitor
+type PickupRequest struct {
+	VisitorID   int    `json:"visitorId"`
+	StudentIDs  []int  `json:"studentIds"`
+	OfficerName string `json:"officerName"`
+}
+
+// AuthorisedStudent is a student the visitor may collect today
+type AuthorisedStudent struct {
+	Student         Student `json:"student"`
+	AuthorisationID int     `json:"authorisationId"`
+	Relationship    string  `json:"relationship"`
+}
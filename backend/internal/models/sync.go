@@ -0,0 +1,79 @@
+package models
+
+import "time"
+
+// Kinds of records queued at an edge gate
+const (
+	SyncKindVisitor = "visitor" // การลงทะเบียน
+	SyncKindReturn  = "return"  // การคืนบัตร
+	SyncKindVoid    = "void"    // การยกเลิกการคืนบัตรที่ส่งขึ้นไปแล้ว
+)
+
+// Sync queue statuses at the edge gate
+const (
+	SyncPending  = "pending"
+	SyncSynced   = "synced"
+	SyncConflict = "conflict" // server กลางไม่รับ ต้องให้เจ้าหน้าที่ตรวจ ไม่ส่งซ้ำอีก
+)
+
+// Results of one synced item at the central server (ขัดแย้งใช้ SyncConflict)
+const (
+	SyncApplied   = "applied"
+	SyncDuplicate = "duplicate" // เคยรับรายการนี้แล้ว (ส่งซ้ำหลังเน็ตหลุดกลางทาง)
+	SyncFailed    = "error"     // server กลางบันทึกไม่สำเร็จ ให้ส่งใหม่รอบหน้า
+)
+
+// SyncQueueEntry is a registration or card return made at an edge gate, waiting to reach the central server
+type SyncQueueEntry struct {
+	ID        int        `json:"id" db:"id"`
+	ClientID  string     `json:"clientId" db:"client_id"`
+	Kind      string     `json:"kind" db:"kind"`
+	RecordID  int        `json:"recordId" db:"record_id"`
+	Status    string     `json:"status" db:"status"`
+	Attempts  int        `json:"attempts" db:"attempts"`
+	Message   string     `json:"message" db:"message"`
+	CreatedAt time.Time  `json:"createdAt" db:"created_at"`
+	SyncedAt  *time.Time `json:"syncedAt" db:"synced_at"`
+}
+
+// SyncItem is one queued record sent to the central server, with Visitor, Return or Void set by Kind
+type SyncItem struct {
+	ClientID string      `json:"clientId"`
+	Kind     string      `json:"kind"`
+	Visitor  *Visitor    `json:"visitor,omitempty"`
+	Return   *SyncReturn `json:"return,omitempty"`
+	Void     *SyncVoid   `json:"void,omitempty"`
+}
+
+// SyncReturn is a card return made at an edge gate
+type SyncReturn struct {
+	VisitorClientID string    `json:"visitorClientId"` // client_id ของการลงทะเบียนที่คืนบัตร
+	CardID          string    `json:"cardId"`
+	CheckOut        string    `json:"checkOut"`
+	ReturnedAt      time.Time `json:"returnedAt"`
+}
+
+// SyncVoid is a card return voided at an edge gate after it was sent to the central server
+type SyncVoid struct {
+	ReturnClientID string `json:"returnClientId"` // client_id ของการคืนบัตรที่ยกเลิก
+	VoidedBy       string `json:"voidedBy"`
+	Reason         string `json:"reason"`
+}
+
+// SyncRequest represents the request body of a sync from an edge gate (เรียงตามลำดับที่บันทึก)
+type SyncRequest struct {
+	Items []SyncItem `json:"items"`
+}
+
+// SyncItemResult tells the edge gate what happened to one item
+type SyncItemResult struct {
+	ClientID string `json:"clientId"`
+	Status   string `json:"status"`
+	Message  string `json:"message,omitempty"`
+	ID       int    `json:"id,omitempty"` // id ของรายการที่ server กลาง
+}
+
+// SyncResponse represents the response of a sync, one result per item in the same order
+type SyncResponse struct {
+	Results []SyncItemResult `json:"results"`
+}
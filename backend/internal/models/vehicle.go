@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// Vehicle represents a visitor vehicle identified by plate and province of registration
+type Vehicle struct {
+	ID           int       `json:"id" db:"id"`
+	PlateNumber  string    `json:"plateNumber" db:"plate_number"` // ทะเบียนที่ normalize แล้ว ใช้ค้นหา
+	PlateDisplay string    `json:"plateDisplay" db:"plate_display"`
+	Province     string    `json:"province" db:"province"`
+	VehicleType  string    `json:"vehicleType" db:"vehicle_type"`
+	Color        string    `json:"color" db:"color"`
+	CreatedAt    time.Time `json:"createdAt" db:"created_at"`
+}
+
+// VehicleRequest represents the vehicle part of CreateVisitorRequest
+type VehicleRequest struct {
+	PlateNumber string `json:"plateNumber"`
+	Province    string `json:"province"`
+	VehicleType string `json:"vehicleType"`
+	Color       string `json:"color"`
+}
+
+// VehicleVisitResponse represents one visit made with a given vehicle
+type VehicleVisitResponse struct {
+	VisitorID    int    `json:"visitorId"`
+	Name         string `json:"name"`
+	IDCard       string `json:"idCard"`
+	Phone        string `json:"phone"`
+	Department   string `json:"department"`
+	PlateNumber  string `json:"plateNumber"`
+	Province     string `json:"province"`
+	VehicleType  string `json:"vehicleType"`
+	Color        string `json:"color"`
+	RegisteredAt string `json:"registeredAt"`
+	ExitTime     string `json:"exitTime"`
+}
+
+// ParkingStatusResponse represents the live count of visitor vehicles in the car park
+type ParkingStatusResponse struct {
+	Total    int                    `json:"total"`
+	ByType   map[string]int         `json:"byType"`
+	Vehicles []VehicleVisitResponse `json:"vehicles"`
+}
@@ -7,12 +7,14 @@ import (
 // Visitor represents a visitor in the system
 type Visitor struct {
 	ID           int        `json:"id" db:"id"`
+	ClientID     string     `json:"clientId" db:"client_id"` // UUID ที่สร้างตอนลงทะเบียน (ใช้ sync ระหว่างเครื่อง edge กับ server กลาง)
 	IDCard       string     `json:"idCard" db:"id_card"`
 	FirstName    string     `json:"firstName" db:"first_name"`
 	LastName     string     `json:"lastName" db:"last_name"`
 	BirthDate    *time.Time `json:"birthDate" db:"birth_date"`
 	Phone        string     `json:"phone" db:"phone"`
 	LicensePlate string     `json:"licensePlate" db:"license_plate"`
+	VehicleID    *int       `json:"vehicleId" db:"vehicle_id"`
 	HouseNumber  string     `json:"houseNumber" db:"house_number"`
 	Moo          string     `json:"moo" db:"moo"`
 	Soi          string     `json:"soi" db:"soi"`
@@ -20,63 +22,156 @@ type Visitor struct {
 	SubDistrict  string     `json:"subDistrict" db:"sub_district"`
 	District     string     `json:"district" db:"district"`
 	Province     string     `json:"province" db:"province"`
+	PostalCode   string     `json:"postalCode" db:"postal_code"`
 	RFID         string     `json:"rfid" db:"rfid"`
-	Department   string     `json:"department" db:"department"`
+	Department   string     `json:"department" db:"department"` // ชื่อส่วนงาน ณ วันที่มาติดต่อ
+	DepartmentID *int       `json:"departmentId" db:"department_id"`
 	OfficerName  string     `json:"officerName" db:"officer_name"`
 	IDCardImage  string     `json:"idCardImage" db:"id_card_image"`
+
+	PhotoKey          string     `json:"-" db:"photo_key"`
+	PhotoThumbnailKey string     `json:"-" db:"photo_thumbnail_key"`
+	PhotoTakenAt      *time.Time `json:"photoTakenAt" db:"photo_taken_at"`
+
+	HostName       string     `json:"hostName" db:"host_name"`
+	HostContact    string     `json:"hostContact" db:"host_contact"`
+	Purpose        string     `json:"purpose" db:"purpose"`
+	PurposeNote    string     `json:"purposeNote" db:"purpose_note"`
+	ApprovalStatus string     `json:"approvalStatus" db:"approval_status"`
+	ApprovedBy     string     `json:"approvedBy" db:"approved_by"`
+	ApprovedAt     *time.Time `json:"approvedAt" db:"approved_at"`
+	ApprovalNote   string     `json:"approvalNote" db:"approval_note"`
+
 	RegisteredAt time.Time  `json:"registeredAt" db:"registered_at"`
 	ExitTime     *time.Time `json:"exitTime" db:"exit_time"`
 	UpdatedAt    time.Time  `json:"updatedAt" db:"updated_at"`
+	Version      int        `json:"version" db:"version"`                // เพิ่มทุกครั้งที่รายการเปลี่ยน ใช้เป็น ETag
+	DeletedAt    *time.Time `json:"deletedAt,omitempty" db:"deleted_at"` // soft delete
 
 	// ⭐ เพิ่ม 2 field นี้สำหรับ Export (ไม่ได้มาจาก DB)
 	Name    string `json:"name" db:"-"`
 	Address string `json:"address" db:"-"`
+
+	Vehicle       *Vehicle `json:"vehicle,omitempty" db:"-"`
+	AppointmentID *int     `json:"appointmentId,omitempty" db:"-"`
+
+	PhotoURL     string `json:"photoUrl,omitempty" db:"-"`
+	ThumbnailURL string `json:"thumbnailUrl,omitempty" db:"-"`
 }
 
 // CreateVisitorRequest represents the request body for creating a visitor
 type CreateVisitorRequest struct {
-	IDCard       string  `json:"idCard" validate:"required,len=13"`
-	FirstName    string  `json:"firstName" validate:"required"`
-	LastName     string  `json:"lastName" validate:"required"`
-	BirthDate    *string `json:"birthDate"`
-	Phone        string  `json:"phone" validate:"required"`
-	LicensePlate string  `json:"licensePlate"`
-	HouseNumber  string  `json:"houseNumber"`
-	Moo          string  `json:"moo"`
-	Soi          string  `json:"soi"`
-	Road         string  `json:"road"`
-	SubDistrict  string  `json:"subDistrict"`
-	District     string  `json:"district"`
-	Province     string  `json:"province"`
-	RFID         string  `json:"rfid"`
-	Department   string  `json:"department"`
-	OfficerName  string  `json:"officerName"`
-	IDCardImage  string  `json:"idCardImage"`
+	IDCard       string          `json:"idCard" validate:"required,len=13"`
+	FirstName    string          `json:"firstName" validate:"required"`
+	LastName     string          `json:"lastName" validate:"required"`
+	BirthDate    *string         `json:"birthDate"`
+	Phone        string          `json:"phone" validate:"required"`
+	LicensePlate string          `json:"licensePlate"`
+	Vehicle      *VehicleRequest `json:"vehicle"`
+	HouseNumber  string          `json:"houseNumber"`
+	Moo          string          `json:"moo"`
+	Soi          string          `json:"soi"`
+	Road         string          `json:"road"`
+	SubDistrict  string          `json:"subDistrict"`
+	District     string          `json:"district"`
+	Province     string          `json:"province"`
+	PostalCode   string          `json:"postalCode"`
+	RFID         string          `json:"rfid"`
+	Department   string          `json:"department"`   // ชื่อส่วนงาน (ไทยหรืออังกฤษ) ถ้าไม่ได้ส่ง departmentId
+	DepartmentID int             `json:"departmentId"` // id ในรายการส่วนงานของโรงเรียน
+	OfficerName  string          `json:"officerName"`
+	IDCardImage  string          `json:"idCardImage"`
+	HostName     string          `json:"hostName"`
+	HostContact  string          `json:"hostContact"`
+	Purpose      string          `json:"purpose"`
+	PurposeNote  string          `json:"purposeNote"`
+
+	// AppointmentToken คือ token จาก QR นัดหมาย (ถ้ามี) ใช้ผูกการเข้าเยี่ยมกับนัดหมาย
+	AppointmentToken string `json:"appointmentToken,omitempty"`
+
+	// ClientID คือ UUID ที่หน้าจอลงทะเบียนสร้างเอง (ว่าง = server สร้างให้)
+	ClientID string `json:"clientId,omitempty"`
 }
 
 // VisitorListResponse represents the response for visitor list
 type VisitorListResponse struct {
-	ID           int    `json:"id"`
-	IDCard       string `json:"idCard"`
-	Name         string `json:"name"`
-	BirthDate    string `json:"birthDate"`
-	Phone        string `json:"phone"`
-	LicensePlate string `json:"licensePlate"`
-	Address      string `json:"address"`
-	RFID         string `json:"rfid"`
-	Department   string `json:"department"`
-	OfficerName  string `json:"officerName"`
-	RegisteredAt string `json:"registeredAt"`
-	ExitTime     string `json:"exitTime"`
+	ID             int    `json:"id"`
+	IDCard         string `json:"idCard"`
+	Name           string `json:"name"`
+	BirthDate      string `json:"birthDate"`
+	Phone          string `json:"phone"`
+	LicensePlate   string `json:"licensePlate"`
+	Address        string `json:"address"`
+	RFID           string `json:"rfid"`
+	Department     string `json:"department"`
+	OfficerName    string `json:"officerName"`
+	HostName       string `json:"hostName"`
+	Purpose        string `json:"purpose"`
+	ApprovalStatus string `json:"approvalStatus"`
+	ThumbnailURL   string `json:"thumbnailUrl"`
+	RegisteredAt   string `json:"registeredAt"`
+	ExitTime       string `json:"exitTime"`
+}
+
+// Approval statuses of a visit
+const (
+	ApprovalNotRequired = "not_required"
+	ApprovalPending     = "pending"
+	ApprovalApproved    = "approved"
+	ApprovalRejected    = "rejected"
+)
+
+// ApprovalRequest represents the host's decision on a pending visit
+type ApprovalRequest struct {
+	ApprovedBy string `json:"approvedBy"`
+	Note       string `json:"note"`
 }
 
+// IssueCardRequest represents the RFID card handed out after approval
+type IssueCardRequest struct {
+	RFID string `json:"rfid"`
+}
+
+// Visitor status filters
+const (
+	VisitorStatusOnSite     = "onsite"
+	VisitorStatusCheckedOut = "checkedout"
+)
+
+// Visitor sort fields
+const (
+	SortByRegisteredAt = "registeredAt"
+	SortByName         = "name"
+	SortByExitTime     = "exitTime"
+)
+
 // QueryParams represents query parameters for filtering
 type QueryParams struct {
-	Search     string `json:"search"`
-	StartDate  string `json:"startDate"`
-	EndDate    string `json:"endDate"`
-	SortOrder  string `json:"sortOrder"`
-	Department string `json:"department"`
-	Page       int    `json:"page"`
-	Limit      int    `json:"limit"`
+	Search        string `json:"search"`
+	StartDate     string `json:"startDate"`
+	EndDate       string `json:"endDate"`
+	SortBy        string `json:"sortBy"`
+	SortOrder     string `json:"sortOrder"`
+	Department    string `json:"department"`    // ชื่อส่วนงาน handler แปลงเป็น DepartmentIDs
+	DepartmentIDs []int  `json:"departmentIds"` // ว่าง = ทุกส่วนงาน
+	Province      string `json:"province"`
+	LicensePlate  string `json:"licensePlate"`
+	OfficerName   string `json:"officer"`
+	Status        string `json:"status"`
+	MinDuration   *int   `json:"minDuration"` // นาที
+	MaxDuration   *int   `json:"maxDuration"` // นาที
+	HasRFID       *bool  `json:"hasRfid"`
+	Page          int    `json:"page"`
+	Limit         int    `json:"limit"`
+}
+
+// ScanBadgeRequest represents the badge QR scanned by the guard
+type ScanBadgeRequest struct {
+	Token string `json:"token"`
+}
+
+// ScanBadgeResponse is the visit a valid badge belongs to
+type ScanBadgeResponse struct {
+	Visitor   *Visitor  `json:"visitor"`
+	ExpiresAt time.Time `json:"expiresAt"`
 }
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// WatchlistEntry is a person the school wants the guards alerted about (รายชื่อเฝ้าระวัง).
+// ตรวจด้วยเลขบัตรประชาชนตอนลงทะเบียน ไม่ห้ามลงทะเบียน
+type WatchlistEntry struct {
+	ID        int       `json:"id" db:"id"`
+	IDCard    string    `json:"idCard" db:"id_card"`
+	Name      string    `json:"name" db:"name"`
+	Reason    string    `json:"reason" db:"reason"`
+	AddedBy   string    `json:"addedBy" db:"added_by"`
+	Active    bool      `json:"active" db:"active"` // ปิดแล้วไม่แจ้งเตือน แต่ยังเก็บไว้ดูย้อนหลัง
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+}
+
+// SaveWatchlistRequest represents the request body for adding or updating a watchlist entry
+type SaveWatchlistRequest struct {
+	IDCard  string `json:"idCard"`
+	Name    string `json:"name"`
+	Reason  string `json:"reason"`
+	AddedBy string `json:"addedBy"`
+	Active  *bool  `json:"active"` // ว่าง = ใช้งาน (ตอนเพิ่ม) หรือคงเดิม (ตอนแก้)
+}
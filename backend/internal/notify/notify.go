@@ -0,0 +1,73 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Message is a notification sent to a staff member (host, guard, admin)
+type Message struct {
+	Type      string      `json:"type"`
+	Recipient string      `json:"recipient"`
+	Title     string      `json:"title"`
+	Body      string      `json:"body"`
+	Data      interface{} `json:"data,omitempty"`
+	SentAt    time.Time   `json:"sentAt"`
+}
+
+// Notifier delivers messages to staff
+type Notifier interface {
+	Notify(msg Message) error
+}
+
+// New returns a webhook notifier when url is set, otherwise a notifier that only logs
+func New(url string) Notifier {
+	if url == "" {
+		return LogNotifier{}
+	}
+	return &WebhookNotifier{
+		url:    url,
+		client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// LogNotifier writes messages to the server log
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(msg Message) error {
+	log.Printf("[notify] %s -> %s: %s - %s", msg.Type, msg.Recipient, msg.Title, msg.Body)
+	return nil
+}
+
+// WebhookNotifier POSTs messages as JSON to a configured URL
+// (เช่น ระบบแจ้งเตือนของโรงเรียน หรือ LINE bot)
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func (n *WebhookNotifier) Notify(msg Message) error {
+	if msg.SentAt.IsZero() {
+		msg.SentAt = time.Now()
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	resp, err := n.client.Post(n.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("notification webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+// Package overstay watches for visitors who stay longer than allowed.
+//
+// ตรวจทุกนาทีว่ามีผู้มาติดต่อของวันนี้ที่ยังไม่คืนบัตรเกินเวลาที่กำหนดหรือไม่
+// แล้ว publish เหตุการณ์ visitor.overstay ครั้งเดียวต่อคน ให้จอของป้อมยามแจ้งเตือน
+package overstay
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"backend/internal/clock"
+	"backend/internal/events"
+	"backend/internal/repository"
+)
+
+// checkInterval is how often the watcher looks for overstaying visitors
+const checkInterval = time.Minute
+
+// Watcher publishes an overstay event for each visitor on site longer than the limit
+type Watcher struct {
+	repo     repository.VisitorStore
+	bus      *events.Bus
+	clock    *clock.Clock
+	limit    time.Duration
+	notified map[int]bool
+}
+
+func NewWatcher(repo repository.VisitorStore, bus *events.Bus, clk *clock.Clock, limit time.Duration) *Watcher {
+	return &Watcher{repo: repo, bus: bus, clock: clk, limit: limit, notified: make(map[int]bool)}
+}
+
+// Run checks every minute until ctx is cancelled
+func (w *Watcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.Check(); err != nil {
+			log.Printf("Failed to check overstaying visitors: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check publishes events for visitors who became overstaying since the last check.
+// จำคนที่แจ้งไปแล้วในหน่วยความจำ คนที่คืนบัตรแล้วหรือข้ามวันจะถูกลบออกเอง
+func (w *Watcher) Check() error {
+	now := w.clock.Now()
+	today, _, err := w.clock.DayRange(w.clock.Today())
+	if err != nil {
+		return err
+	}
+
+	visitors, err := w.repo.ListOnSite(today, now.Add(-w.limit))
+	if err != nil {
+		return err
+	}
+
+	overstaying := make(map[int]bool, len(visitors))
+	for i := range visitors {
+		v := &visitors[i]
+		overstaying[v.ID] = true
+		if w.notified[v.ID] {
+			continue
+		}
+		w.bus.Publish(events.TypeOverstay, v.Department, events.Overstay{
+			Visitor:      events.VisitorOf(v),
+			RegisteredAt: v.RegisteredAt,
+			Minutes:      int(now.Sub(v.RegisteredAt).Minutes()),
+		})
+	}
+	w.notified = overstaying
+
+	return nil
+}
@@ -0,0 +1,125 @@
+// Package photo ตรวจสอบรูปถ่ายผู้มาติดต่อจากกล้องหน้าป้อมและสร้างรูปย่อ
+package photo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"golang.org/x/image/draw"
+)
+
+const (
+	ContentTypeJPEG = "image/jpeg"
+	ContentTypePNG  = "image/png"
+
+	// DefaultMaxBytes คือขนาดไฟล์สูงสุดที่รับ (5 MB)
+	DefaultMaxBytes = 5 << 20
+	// ThumbnailSize คือความยาวด้านที่ยาวที่สุดของรูปย่อ (px)
+	ThumbnailSize = 160
+	// maxPixels กันรูปที่ประกาศขนาดใหญ่ผิดปกติ (decompression bomb)
+	maxPixels = 40_000_000
+)
+
+var (
+	ErrUnsupportedType = errors.New("รองรับเฉพาะไฟล์ JPEG หรือ PNG")
+	ErrTooLarge        = errors.New("ไฟล์รูปมีขนาดใหญ่เกินไป")
+	ErrInvalidImage    = errors.New("ไฟล์รูปเสียหรืออ่านไม่ได้")
+)
+
+// Photo is a validated upload with its thumbnail
+type Photo struct {
+	Data        []byte
+	ContentType string
+	Width       int
+	Height      int
+	Thumbnail   []byte // JPEG เสมอ
+}
+
+// Ext returns the file extension matching the content type
+func (p *Photo) Ext() string {
+	if p.ContentType == ContentTypePNG {
+		return ".png"
+	}
+	return ".jpg"
+}
+
+// Process validates the uploaded bytes and renders the thumbnail.
+// ชนิดไฟล์ดูจากเนื้อไฟล์จริง ไม่เชื่อ Content-Type ที่ browser ส่งมา
+func Process(data []byte, maxBytes int) (*Photo, error) {
+	if maxBytes > 0 && len(data) > maxBytes {
+		return nil, ErrTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	if contentType != ContentTypeJPEG && contentType != ContentTypePNG {
+		return nil, ErrUnsupportedType
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return nil, ErrTooLarge
+	}
+
+	var img image.Image
+	if contentType == ContentTypePNG {
+		img, err = png.Decode(bytes.NewReader(data))
+	} else {
+		img, err = jpeg.Decode(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	thumb, err := Thumbnail(img, ThumbnailSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Photo{
+		Data:        data,
+		ContentType: contentType,
+		Width:       cfg.Width,
+		Height:      cfg.Height,
+		Thumbnail:   thumb,
+	}, nil
+}
+
+// Thumbnail scales img so its longest side is at most size and encodes it as JPEG
+func Thumbnail(img image.Image, size int) ([]byte, error) {
+	dst := image.NewRGBA(fitRect(img.Bounds(), size))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, img.Bounds(), draw.Src, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 80}); err != nil {
+		return nil, fmt.Errorf("failed to encode thumbnail: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Decode reads a stored photo back into an image (ใช้ตอนพิมพ์บัตร)
+func Decode(data []byte) (image.Image, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	return img, nil
+}
+
+func fitRect(src image.Rectangle, size int) image.Rectangle {
+	w, h := src.Dx(), src.Dy()
+	if w <= size && h <= size {
+		return image.Rect(0, 0, w, h)
+	}
+	if w >= h {
+		return image.Rect(0, 0, size, max(1, h*size/w))
+	}
+	return image.Rect(0, 0, max(1, w*size/h), size)
+}
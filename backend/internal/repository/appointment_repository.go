@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/internal/clock"
+	"backend/internal/database"
+	"backend/internal/models"
+)
+
+// ErrAppointmentNotPending is returned when an appointment was already used or cancelled
+var ErrAppointmentNotPending = errors.New("appointment is not pending")
+
+type AppointmentRepository struct {
+	db      *sql.DB
+	dialect database.Dialect
+	clock   *clock.Clock
+	school  int
+}
+
+func NewAppointmentRepository(db *sql.DB, clk *clock.Clock) *AppointmentRepository {
+	return &AppointmentRepository{db: db, dialect: database.DialectOf(db), clock: clk, school: models.DefaultSchoolID}
+}
+
+// ForSchool returns a copy of the repository that reads and writes the appointments of school id
+func (r *AppointmentRepository) ForSchool(id int) *AppointmentRepository {
+	scoped := *r
+	scoped.school = id
+	return &scoped
+}
+
+const appointmentColumns = `
+	id, id_card, first_name, last_name, phone, license_plate, host_name, host_contact,
+	department, purpose, expected_from, expected_until, status, visitor_id, created_by,
+	arrived_at, created_at
+`
+
+// Create inserts a new appointment
+func (r *AppointmentRepository) Create(appt *models.Appointment) error {
+	query := `
+		INSERT INTO appointments (
+			school_id, id_card, first_name, last_name, phone, license_plate, host_name, host_contact,
+			department, purpose, expected_from, expected_until, status, created_by
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	id, err := insertID(r.db, r.dialect, query,
+		r.school,
+		appt.IDCard,
+		appt.FirstName,
+		appt.LastName,
+		appt.Phone,
+		appt.LicensePlate,
+		appt.HostName,
+		appt.HostContact,
+		appt.Department,
+		appt.Purpose,
+		appt.ExpectedFrom,
+		appt.ExpectedUntil,
+		appt.Status,
+		appt.CreatedBy,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create appointment: %w", err)
+	}
+
+	appt.ID = id
+	return nil
+}
+
+// GetByID retrieves an appointment by ID
+func (r *AppointmentRepository) GetByID(id int) (*models.Appointment, error) {
+	query := `SELECT ` + appointmentColumns + ` FROM appointments WHERE id = ? AND school_id = ?`
+
+	appt, err := scanAppointment(r.db.QueryRow(query, id, r.school))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("appointment not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get appointment: %w", err)
+	}
+
+	return appt, nil
+}
+
+// List retrieves appointments expected on the given date (YYYY-MM-DD), optionally by status
+func (r *AppointmentRepository) List(date, status string) ([]models.Appointment, error) {
+	query := `SELECT ` + appointmentColumns + ` FROM appointments WHERE school_id = ?`
+	args := []interface{}{r.school}
+
+	if date != "" {
+		// นัดที่ช่วงเวลาคาบเกี่ยวกับวันนั้นตามเวลาของโรงเรียน
+		start, end, err := r.clock.DayRange(date)
+		if err != nil {
+			return nil, err
+		}
+		query += ` AND expected_from < ? AND expected_until >= ?`
+		args = append(args, end, start)
+	}
+	if status != "" {
+		query += ` AND status = ?`
+		args = append(args, status)
+	}
+
+	query += ` ORDER BY expected_from ASC`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list appointments: %w", err)
+	}
+	defer rows.Close()
+
+	appointments := []models.Appointment{}
+	for rows.Next() {
+		appt, err := scanAppointment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan appointment: %w", err)
+		}
+		appointments = append(appointments, *appt)
+	}
+
+	return appointments, nil
+}
+
+// Cancel marks a pending appointment as cancelled
+func (r *AppointmentRepository) Cancel(id int) error {
+	result, err := r.db.Exec(
+		`UPDATE appointments SET status = ? WHERE id = ? AND school_id = ? AND status = ?`,
+		models.AppointmentCancelled, id, r.school, models.AppointmentPending,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to cancel appointment: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to cancel appointment: %w", err)
+	}
+	if affected == 0 {
+		return ErrAppointmentNotPending
+	}
+
+	return nil
+}
+
+// markAppointmentArrived links the visit to the appointment inside the visitor's transaction.
+// เงื่อนไข status = pending กันไม่ให้ใช้ QR เดียวกันลงทะเบียนซ้ำ (และนัดของโรงเรียนอื่น)
+func markAppointmentArrived(db execer, schoolID, appointmentID, visitorID int, arrivedAt time.Time) error {
+	result, err := db.Exec(`
+		UPDATE appointments
+		SET status = ?, visitor_id = ?, arrived_at = ?
+		WHERE id = ? AND school_id = ? AND status = ?
+	`, models.AppointmentArrived, visitorID, arrivedAt, appointmentID, schoolID, models.AppointmentPending)
+	if err != nil {
+		return fmt.Errorf("failed to link appointment: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to link appointment: %w", err)
+	}
+	if affected == 0 {
+		return ErrAppointmentNotPending
+	}
+
+	return nil
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAppointment(row rowScanner) (*models.Appointment, error) {
+	appt := &models.Appointment{}
+	var visitorID sql.NullInt64
+	var arrivedAt sql.NullTime
+
+	err := row.Scan(
+		&appt.ID,
+		&appt.IDCard,
+		&appt.FirstName,
+		&appt.LastName,
+		&appt.Phone,
+		&appt.LicensePlate,
+		&appt.HostName,
+		&appt.HostContact,
+		&appt.Department,
+		&appt.Purpose,
+		&appt.ExpectedFrom,
+		&appt.ExpectedUntil,
+		&appt.Status,
+		&visitorID,
+		&appt.CreatedBy,
+		&arrivedAt,
+		&appt.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if visitorID.Valid {
+		id := int(visitorID.Int64)
+		appt.VisitorID = &id
+	}
+	if arrivedAt.Valid {
+		appt.ArrivedAt = &arrivedAt.Time
+	}
+
+	return appt, nil
+}
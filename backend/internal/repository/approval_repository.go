@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"backend/internal/models"
+)
+
+var (
+	// ErrNotPendingApproval is returned when deciding on a visit that is not waiting for approval
+	ErrNotPendingApproval = errors.New("visit is not pending approval")
+	// ErrCardNotIssuable is returned when a card cannot be issued for the visit yet
+	ErrCardNotIssuable = errors.New("card cannot be issued for this visit")
+)
+
+// ListPendingApprovals retrieves visits waiting for approval, optionally for one host
+func (r *VisitorRepository) ListPendingApprovals(hostName string) ([]models.Visitor, error) {
+	query := `SELECT ` + visitorColumns + ` FROM visitors WHERE school_id = ? AND approval_status = ? AND deleted_at IS NULL`
+	args := []interface{}{r.school, models.ApprovalPending}
+
+	if hostName != "" {
+		query += ` AND host_name = ?`
+		args = append(args, hostName)
+	}
+	query += ` ORDER BY registered_at ASC`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending approvals: %w", err)
+	}
+	defer rows.Close()
+
+	visitors := []models.Visitor{}
+	for rows.Next() {
+		v, err := scanVisitor(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan visitor: %w", err)
+		}
+		visitors = append(visitors, *v)
+	}
+
+	return visitors, nil
+}
+
+// SetApproval records the host's decision on a pending visit
+func (r *VisitorRepository) SetApproval(id int, status, approvedBy, note string) error {
+	result, err := r.db.Exec(`
+		UPDATE visitors
+		SET approval_status = ?, approved_by = ?, approval_note = ?, approved_at = ?, version = version + 1
+		WHERE id = ? AND school_id = ? AND approval_status = ? AND deleted_at IS NULL
+	`, status, approvedBy, note, r.clock.Now(), id, r.school, models.ApprovalPending)
+	if err != nil {
+		return fmt.Errorf("failed to set approval: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to set approval: %w", err)
+	}
+	if affected == 0 {
+		return ErrNotPendingApproval
+	}
+
+	return nil
+}
+
+// IssueCard assigns an RFID card to a visit that is approved (or needs no approval) and has no card yet
+func (r *VisitorRepository) IssueCard(id int, rfid string) error {
+	result, err := r.db.Exec(`
+		UPDATE visitors
+		SET rfid = ?, version = version + 1
+		WHERE id = ? AND school_id = ? AND rfid IS NULL AND approval_status IN (?, ?) AND deleted_at IS NULL
+	`, rfid, id, r.school, models.ApprovalNotRequired, models.ApprovalApproved)
+	if err != nil {
+		return fmt.Errorf("failed to issue card: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to issue card: %w", err)
+	}
+	if affected == 0 {
+		return ErrCardNotIssuable
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"backend/internal/database"
+	"backend/internal/models"
+)
+
+// ErrDepartmentInUse is returned when deleting a department that visits refer to (ให้ปิดใช้งานแทน)
+var ErrDepartmentInUse = errors.New("department is used by visits")
+
+// DepartmentRepository stores the departments of a school
+type DepartmentRepository struct {
+	db      *sql.DB
+	dialect database.Dialect
+	school  int
+}
+
+func NewDepartmentRepository(db *sql.DB) *DepartmentRepository {
+	return &DepartmentRepository{db: db, dialect: database.DialectOf(db), school: models.DefaultSchoolID}
+}
+
+// ForSchool returns a copy of the repository that works on the departments of school id
+func (r *DepartmentRepository) ForSchool(id int) *DepartmentRepository {
+	scoped := *r
+	scoped.school = id
+	return &scoped
+}
+
+const departmentColumns = `id, name_th, name_en, active, display_order, created_at`
+
+func scanDepartment(row rowScanner) (*models.Department, error) {
+	var d models.Department
+	if err := row.Scan(&d.ID, &d.NameTh, &d.NameEn, &d.Active, &d.DisplayOrder, &d.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// List retrieves the departments ordered for display
+func (r *DepartmentRepository) List(activeOnly bool) ([]models.Department, error) {
+	query := `SELECT ` + departmentColumns + ` FROM departments WHERE school_id = ?`
+	if activeOnly {
+		query += ` AND active = TRUE`
+	}
+	query += ` ORDER BY display_order ASC, id ASC`
+
+	rows, err := r.db.Query(query, r.school)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list departments: %w", err)
+	}
+	defer rows.Close()
+
+	departments := []models.Department{}
+	for rows.Next() {
+		d, err := scanDepartment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan department: %w", err)
+		}
+		departments = append(departments, *d)
+	}
+
+	return departments, nil
+}
+
+// GetByID retrieves a department by ID
+func (r *DepartmentRepository) GetByID(id int) (*models.Department, error) {
+	return r.get(`id = ?`, id)
+}
+
+// GetByName retrieves a department by its Thai or English name (ไม่สนตัวพิมพ์และช่องว่างหัวท้าย)
+func (r *DepartmentRepository) GetByName(name string) (*models.Department, error) {
+	name = strings.TrimSpace(name)
+	return r.get(`(name_th = ? OR LOWER(name_en) = LOWER(?))`, name, name)
+}
+
+func (r *DepartmentRepository) get(where string, args ...interface{}) (*models.Department, error) {
+	query := `SELECT ` + departmentColumns + ` FROM departments WHERE school_id = ? AND ` + where + ` ORDER BY id LIMIT 1`
+	d, err := scanDepartment(r.db.QueryRow(query, append([]interface{}{r.school}, args...)...))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("department not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get department: %w", err)
+	}
+	return d, nil
+}
+
+// Create inserts a new department
+func (r *DepartmentRepository) Create(d *models.Department) error {
+	id, err := insertID(r.db, r.dialect, `
+		INSERT INTO departments (school_id, name_th, name_en, active, display_order)
+		VALUES (?, ?, ?, ?, ?)
+	`, r.school, d.NameTh, d.NameEn, d.Active, d.DisplayOrder)
+	if err != nil {
+		return fmt.Errorf("failed to create department: %w", err)
+	}
+
+	d.ID = id
+	return nil
+}
+
+// Update changes a department. ชื่อใน visitors.department ของการมาติดต่อเดิมไม่เปลี่ยนตาม
+func (r *DepartmentRepository) Update(d *models.Department) error {
+	_, err := r.db.Exec(`
+		UPDATE departments SET name_th = ?, name_en = ?, active = ?, display_order = ?
+		WHERE id = ? AND school_id = ?
+	`, d.NameTh, d.NameEn, d.Active, d.DisplayOrder, d.ID, r.school)
+	if err != nil {
+		return fmt.Errorf("failed to update department: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a department no visit refers to, otherwise returns ErrDepartmentInUse
+func (r *DepartmentRepository) Delete(id int) error {
+	var visits int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM visitors WHERE department_id = ?`, id).Scan(&visits); err != nil {
+		return fmt.Errorf("failed to check department usage: %w", err)
+	}
+	if visits > 0 {
+		return ErrDepartmentInUse
+	}
+
+	if _, err := r.db.Exec(`DELETE FROM departments WHERE id = ? AND school_id = ?`, id, r.school); err != nil {
+		return fmt.Errorf("failed to delete department: %w", err)
+	}
+	return nil
+}
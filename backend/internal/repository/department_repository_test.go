@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"backend/internal/models"
+)
+
+func TestDepartmentRepository(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *sql.DB) {
+		now := testNow
+		repo := NewDepartmentRepository(db)
+
+		// migration ใส่ส่วนงานเริ่มต้นให้โรงเรียนหลัก
+		seeded, err := repo.List(true)
+		if err != nil || len(seeded) == 0 || seeded[0].DisplayOrder != 1 {
+			t.Fatalf("seeded departments = %+v, %v", seeded, err)
+		}
+
+		d := &models.Department{NameTh: "งานพัสดุ", NameEn: "Supplies", Active: true, DisplayOrder: 20}
+		if err := repo.Create(d); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.Create(&models.Department{NameTh: "งานพัสดุ"}); err == nil {
+			t.Error("duplicate Thai name should fail")
+		}
+		if got, err := repo.GetByName(" supplies "); err != nil || got.ID != d.ID {
+			t.Errorf("GetByName(English) = %+v, %v", got, err)
+		}
+
+		// ส่วนงานของโรงเรียนอื่นมองไม่เห็น
+		other := repo.ForSchool(2)
+		if _, err := other.GetByID(d.ID); err == nil {
+			t.Error("school 2 can read school 1's department")
+		}
+		if list, err := other.List(false); err != nil || len(list) != 0 {
+			t.Errorf("school 2 departments = %+v, %v", list, err)
+		}
+
+		d.Active = false
+		if err := repo.Update(d); err != nil {
+			t.Fatal(err)
+		}
+		if active, err := repo.List(true); err != nil || len(active) != len(seeded) {
+			t.Errorf("active departments = %d, want %d", len(active), len(seeded))
+		}
+
+		v := testVisitor(1)
+		v.DepartmentID = &d.ID
+		if err := NewVisitorRepository(db, testClock(t, &now)).Create(v); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.Delete(d.ID); !errors.Is(err, ErrDepartmentInUse) {
+			t.Errorf("Delete() of a used department = %v", err)
+		}
+		if err := repo.Delete(seeded[0].ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := repo.GetByID(seeded[0].ID); err == nil {
+			t.Error("deleted department still found")
+		}
+	})
+}
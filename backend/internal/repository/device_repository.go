@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"backend/internal/clock"
+	"backend/internal/database"
+	"backend/internal/models"
+)
+
+type DeviceRepository struct {
+	db      *sql.DB
+	dialect database.Dialect
+	clock   *clock.Clock
+	school  int
+}
+
+func NewDeviceRepository(db *sql.DB, clk *clock.Clock) *DeviceRepository {
+	return &DeviceRepository{db: db, dialect: database.DialectOf(db), clock: clk, school: models.DefaultSchoolID}
+}
+
+// ForSchool returns a copy of the repository that reads and writes the readers of school id.
+// การแตะบัตรไม่มี school_id ของตัวเอง กรองผ่านเครื่องอ่าน
+func (r *DeviceRepository) ForSchool(id int) *DeviceRepository {
+	scoped := *r
+	scoped.school = id
+	return &scoped
+}
+
+const deviceColumns = `id, name, location, role, active, key_hash, key_hint, last_seen_at, created_at`
+
+func scanDevice(row rowScanner) (*models.Device, error) {
+	var d models.Device
+	err := row.Scan(&d.ID, &d.Name, &d.Location, &d.Role, &d.Active, &d.KeyHash, &d.KeyHint, &d.LastSeenAt, &d.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// Create registers a reader with the hash of its API key
+func (r *DeviceRepository) Create(d *models.Device) error {
+	id, err := insertID(r.db, r.dialect, `
+		INSERT INTO devices (school_id, name, location, role, active, key_hash, key_hint)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, r.school, d.Name, d.Location, d.Role, d.Active, d.KeyHash, d.KeyHint)
+	if err != nil {
+		return fmt.Errorf("failed to create device: %w", err)
+	}
+
+	d.ID = id
+	return nil
+}
+
+// GetByID retrieves a reader, including its key hash
+func (r *DeviceRepository) GetByID(id int) (*models.Device, error) {
+	d, err := scanDevice(r.db.QueryRow(`SELECT `+deviceColumns+` FROM devices WHERE id = ? AND school_id = ?`, id, r.school))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("device not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get device: %w", err)
+	}
+	return d, nil
+}
+
+// List retrieves every registered reader
+func (r *DeviceRepository) List() ([]models.Device, error) {
+	rows, err := r.db.Query(`SELECT `+deviceColumns+` FROM devices WHERE school_id = ? ORDER BY name ASC`, r.school)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list devices: %w", err)
+	}
+	defer rows.Close()
+
+	devices := []models.Device{}
+	for rows.Next() {
+		d, err := scanDevice(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan device: %w", err)
+		}
+		devices = append(devices, *d)
+	}
+
+	return devices, nil
+}
+
+// Update changes the name, location, role and active flag of a reader
+func (r *DeviceRepository) Update(d *models.Device) error {
+	_, err := r.db.Exec(`
+		UPDATE devices SET name = ?, location = ?, role = ?, active = ?
+		WHERE id = ? AND school_id = ?
+	`, d.Name, d.Location, d.Role, d.Active, d.ID, r.school)
+	if err != nil {
+		return fmt.Errorf("failed to update device: %w", err)
+	}
+	return nil
+}
+
+// SetKey replaces the API key of a reader; the old key stops working immediately
+func (r *DeviceRepository) SetKey(id int, keyHash, keyHint string) error {
+	_, err := r.db.Exec(`UPDATE devices SET key_hash = ?, key_hint = ? WHERE id = ? AND school_id = ?`, keyHash, keyHint, id, r.school)
+	if err != nil {
+		return fmt.Errorf("failed to set device key: %w", err)
+	}
+	return nil
+}
+
+// HasKeyHash reports whether an active reader of the school has the key with this hash
+func (r *DeviceRepository) HasKeyHash(keyHash string) (bool, error) {
+	var n int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM devices WHERE key_hash = ? AND active = ? AND school_id = ?`, keyHash, true, r.school).Scan(&n)
+	if err != nil {
+		return false, fmt.Errorf("failed to check device key: %w", err)
+	}
+	return n > 0, nil
+}
+
+// Touch marks the device as seen now (เครื่อง edge ที่ sync เข้ามา)
+func (r *DeviceRepository) Touch(id int) error {
+	if _, err := r.db.Exec(`UPDATE devices SET last_seen_at = ? WHERE id = ? AND school_id = ?`, r.clock.Now(), id, r.school); err != nil {
+		return fmt.Errorf("failed to update device last seen: %w", err)
+	}
+	return nil
+}
+
+// RecordTap saves a tap in the log and marks the reader as seen
+func (r *DeviceRepository) RecordTap(tap *models.DeviceTap) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	id, err := insertID(tx, r.dialect, `
+		INSERT INTO device_taps (device_id, card_uid, role, result, visitor_id, message, tapped_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, tap.DeviceID, tap.CardUID, tap.Role, tap.Result, tap.VisitorID, tap.Message, tap.TappedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record tap: %w", err)
+	}
+	tap.ID = id
+
+	if _, err := tx.Exec(`UPDATE devices SET last_seen_at = ? WHERE id = ?`, tap.TappedAt, tap.DeviceID); err != nil {
+		return fmt.Errorf("failed to update device last seen: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// HasRecentTap reports whether the card was tapped on the reader within the window.
+// เครื่องอ่านบางรุ่นส่ง UID ซ้ำหลายครั้งเมื่อถือบัตรค้างไว้
+func (r *DeviceRepository) HasRecentTap(deviceID int, cardUID string, window time.Duration) (bool, error) {
+	var count int
+	err := r.db.QueryRow(`
+		SELECT COUNT(*) FROM device_taps
+		WHERE device_id = ? AND card_uid = ? AND tapped_at >= ? AND result <> ?
+	`, deviceID, cardUID, r.clock.Now().Add(-window), models.TapRepeat).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to check recent taps: %w", err)
+	}
+	return count > 0, nil
+}
+
+// ListTaps retrieves the latest taps of a reader, newest first
+func (r *DeviceRepository) ListTaps(deviceID, limit int) ([]models.DeviceTap, error) {
+	// CONCAT ของ SQLite ไม่คืน NULL เมื่อ LEFT JOIN ไม่เจอผู้มาติดต่อ จึงเช็ค v.id เอง
+	rows, err := r.db.Query(`
+		SELECT t.id, t.device_id, t.card_uid, t.role, t.result, t.visitor_id,
+			CASE WHEN v.id IS NULL THEN '' ELSE CONCAT(v.first_name, ' ', v.last_name) END, t.message, t.tapped_at
+		FROM device_taps t
+		JOIN devices d ON d.id = t.device_id
+		LEFT JOIN visitors v ON v.id = t.visitor_id
+		WHERE t.device_id = ? AND d.school_id = ?
+		ORDER BY t.tapped_at DESC, t.id DESC
+		LIMIT ?
+	`, deviceID, r.school, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list taps: %w", err)
+	}
+	defer rows.Close()
+
+	taps := []models.DeviceTap{}
+	for rows.Next() {
+		var t models.DeviceTap
+		err := rows.Scan(&t.ID, &t.DeviceID, &t.CardUID, &t.Role, &t.Result, &t.VisitorID,
+			&t.VisitorName, &t.Message, &t.TappedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tap: %w", err)
+		}
+		taps = append(taps, t)
+	}
+
+	return taps, nil
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+)
+
+func TestDeviceRepository(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *sql.DB) {
+		now := testNow
+		clk := testClock(t, &now)
+		repo := NewDeviceRepository(db, clk)
+
+		device := &models.Device{Name: "ประตูหน้า", Location: "ป้อมยาม", Role: models.DeviceRoleCheckIn, Active: true, KeyHash: "hash-1", KeyHint: "ab12"}
+		if err := repo.Create(device); err != nil {
+			t.Fatal(err)
+		}
+		device.Role = models.DeviceRoleCheckOut
+		device.Active = false
+		if err := repo.Update(device); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.SetKey(device.ID, "hash-2", "cd34"); err != nil {
+			t.Fatal(err)
+		}
+		got, err := repo.GetByID(device.ID)
+		if err != nil || got.Role != models.DeviceRoleCheckOut || got.Active || got.KeyHash != "hash-2" || got.LastSeenAt != nil {
+			t.Fatalf("GetByID() = %+v, %v", got, err)
+		}
+		if _, err := repo.GetByID(99); err == nil {
+			t.Error("GetByID() of a missing device should fail")
+		}
+
+		// เครื่องที่ปิดไว้ key เก่า หรือเครื่องของโรงเรียนอื่น ใช้ยืนยันโรงเรียนไม่ได้
+		if ok, err := repo.HasKeyHash("hash-2"); err != nil || ok {
+			t.Errorf("HasKeyHash() of an inactive reader = %v, %v", ok, err)
+		}
+		got.Active = true
+		if err := repo.Update(got); err != nil {
+			t.Fatal(err)
+		}
+		if ok, err := repo.HasKeyHash("hash-2"); err != nil || !ok {
+			t.Errorf("HasKeyHash() of an active reader = %v, %v", ok, err)
+		}
+		if ok, err := repo.HasKeyHash("hash-1"); err != nil || ok {
+			t.Errorf("HasKeyHash() of a replaced key = %v, %v", ok, err)
+		}
+		if ok, err := repo.ForSchool(2).HasKeyHash("hash-2"); err != nil || ok {
+			t.Errorf("HasKeyHash() of another school = %v, %v", ok, err)
+		}
+		got.Active = false
+		if err := repo.Update(got); err != nil {
+			t.Fatal(err)
+		}
+
+		visitor := testVisitor(1)
+		if err := NewVisitorRepository(db, clk).Create(visitor); err != nil {
+			t.Fatal(err)
+		}
+		taps := []*models.DeviceTap{
+			{DeviceID: device.ID, CardUID: "RF0001", Role: device.Role, Result: models.TapAccepted, VisitorID: &visitor.ID, Message: "คืนบัตรแล้ว", TappedAt: testNow.Add(-time.Minute)},
+			{DeviceID: device.ID, CardUID: "ZZ9999", Role: device.Role, Result: models.TapRejected, Message: "ไม่พบบัตร", TappedAt: testNow},
+		}
+		for _, tap := range taps {
+			if err := repo.RecordTap(tap); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		list, err := repo.ListTaps(device.ID, 10)
+		if err != nil || len(list) != 2 {
+			t.Fatalf("ListTaps() = %+v, %v", list, err)
+		}
+		if list[0].ID != taps[1].ID || list[0].VisitorName != "" || list[0].VisitorID != nil {
+			t.Errorf("tap without a visitor = %+v", list[0])
+		}
+		if list[1].VisitorName != "ผู้มาติดต่อ คนที่1" || !list[1].TappedAt.Equal(taps[0].TappedAt) {
+			t.Errorf("tap of a visitor = %+v", list[1])
+		}
+		if got, _ := repo.GetByID(device.ID); got.LastSeenAt == nil || !got.LastSeenAt.Equal(testNow) {
+			t.Errorf("last seen at = %v, want %v", got.LastSeenAt, testNow)
+		}
+
+		// UID ของบัตรเทียบแบบไม่สนตัวพิมพ์ เหมือนตอนค้นหาผู้มาติดต่อ
+		if recent, err := repo.HasRecentTap(device.ID, "rf0001", 5*time.Minute); err != nil || !recent {
+			t.Errorf("HasRecentTap(rf0001) = %v, %v", recent, err)
+		}
+		if recent, _ := repo.HasRecentTap(device.ID, "RF0001", 30*time.Second); recent {
+			t.Error("a tap a minute ago should be outside a 30 second window")
+		}
+	})
+}
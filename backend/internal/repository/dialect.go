@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"backend/internal/database"
+)
+
+// querier is implemented by both *sql.DB and *sql.Tx
+type querier interface {
+	execer
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// insertID runs an INSERT and returns the id of the row it wrote.
+// MySQL อ่านจาก LastInsertId ส่วน SQLite ใช้ RETURNING id (ได้ id ของแถวเดิมเมื่อ upsert ด้วย)
+func insertID(db querier, dialect database.Dialect, query string, args ...interface{}) (int, error) {
+	if dialect.Returning() {
+		var id int
+		err := db.QueryRow(query+` RETURNING id`, args...).Scan(&id)
+		return id, err
+	}
+
+	result, err := db.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return int(id), nil
+}
+
+// sqlDate is the value for a DATE column. ส่งเป็นข้อความ YYYY-MM-DD เพราะ SQLite เก็บตามที่ส่งไป
+// และต้องเทียบกับ clock.Today() ได้ตรงตัว
+func sqlDate(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// nullSQLDate is sqlDate for a nullable DATE column
+func nullSQLDate(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return sqlDate(*t)
+}
@@ -1,16 +1,26 @@
 package repository
 
 import (
+	"backend/internal/database"
 	"backend/internal/models"
 	"database/sql"
 )
 
 type ExportRepository struct {
-	db *sql.DB
+	db      *sql.DB
+	dialect database.Dialect
+	school  int
 }
 
 func NewExportRepository(db *sql.DB) *ExportRepository {
-	return &ExportRepository{db: db}
+	return &ExportRepository{db: db, dialect: database.DialectOf(db), school: models.DefaultSchoolID}
+}
+
+// ForSchool returns a copy of the repository that reads and writes the export history of school id
+func (r *ExportRepository) ForSchool(id int) *ExportRepository {
+	scoped := *r
+	scoped.school = id
+	return &scoped
 }
 
 // GetHistory retrieves all export history records
@@ -18,10 +28,11 @@ func (r *ExportRepository) GetHistory() ([]models.ExportRecord, error) {
 	query := `
 		SELECT id, export_date, department, date_range, format, status, record_count, created_at
 		FROM export_history
+		WHERE school_id = ?
 		ORDER BY export_date DESC
 	`
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.Query(query, r.school)
 	if err != nil {
 		return nil, err
 	}
@@ -61,12 +72,13 @@ func (r *ExportRepository) GetHistory() ([]models.ExportRecord, error) {
 // Create inserts a new export history record
 func (r *ExportRepository) Create(record *models.ExportRecord) (*models.ExportRecord, error) {
 	query := `
-		INSERT INTO export_history (export_date, department, date_range, format, status, record_count)
-		VALUES (?, ?, ?, ?, ?, ?)
+		INSERT INTO export_history (school_id, export_date, department, date_range, format, status, record_count)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
 	`
 
-	result, err := r.db.Exec(
+	id, err := insertID(r.db, r.dialect,
 		query,
+		r.school,
 		record.ExportDate,
 		record.Department,
 		record.DateRange,
@@ -78,12 +90,7 @@ func (r *ExportRepository) Create(record *models.ExportRecord) (*models.ExportRe
 		return nil, err
 	}
 
-	id, err := result.LastInsertId()
-	if err != nil {
-		return nil, err
-	}
-
-	record.ID = int(id)
+	record.ID = id
 	return record, nil
 }
 
@@ -92,14 +99,14 @@ func (r *ExportRepository) GetByID(id int) (*models.ExportRecord, error) {
 	query := `
 		SELECT id, export_date, department, date_range, format, status, record_count, created_at
 		FROM export_history
-		WHERE id = ?
+		WHERE id = ? AND school_id = ?
 	`
 
 	var record models.ExportRecord
 	var recordCount sql.NullInt64
 	var createdAt sql.NullTime
 
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRow(query, id, r.school).Scan(
 		&record.ID,
 		&record.ExportDate,
 		&record.Department,
@@ -0,0 +1,126 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"backend/internal/models"
+	"backend/internal/repository"
+)
+
+// DepartmentStore keeps the departments of one school. ใช้ visitors ตรวจว่าส่วนงานถูกใช้แล้วหรือยัง
+type DepartmentStore struct {
+	mu          sync.Mutex
+	visitors    *Store
+	departments []models.Department
+	nextID      int
+}
+
+func NewDepartmentStore(visitors *Store) *DepartmentStore {
+	return &DepartmentStore{visitors: visitors}
+}
+
+var _ repository.DepartmentStore = (*DepartmentStore)(nil)
+
+// List retrieves the departments ordered by display order
+func (s *DepartmentStore) List(activeOnly bool) ([]models.Department, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	departments := []models.Department{}
+	for _, d := range s.departments {
+		if !activeOnly || d.Active {
+			departments = append(departments, d)
+		}
+	}
+	sort.SliceStable(departments, func(i, j int) bool {
+		if departments[i].DisplayOrder != departments[j].DisplayOrder {
+			return departments[i].DisplayOrder < departments[j].DisplayOrder
+		}
+		return departments[i].ID < departments[j].ID
+	})
+	return departments, nil
+}
+
+// GetByID retrieves a department by ID
+func (s *DepartmentStore) GetByID(id int) (*models.Department, error) {
+	return s.find(func(d *models.Department) bool { return d.ID == id })
+}
+
+// GetByName retrieves a department by its Thai name or its English name, ignoring case
+func (s *DepartmentStore) GetByName(name string) (*models.Department, error) {
+	name = strings.TrimSpace(name)
+	return s.find(func(d *models.Department) bool {
+		return d.NameTh == name || (d.NameEn != "" && strings.EqualFold(d.NameEn, name))
+	})
+}
+
+func (s *DepartmentStore) find(match func(d *models.Department) bool) (*models.Department, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.departments {
+		if match(&s.departments[i]) {
+			out := s.departments[i]
+			return &out, nil
+		}
+	}
+	return nil, fmt.Errorf("department not found")
+}
+
+// Create inserts a department; the Thai name is unique like uq_departments_school_name
+func (s *DepartmentStore) Create(department *models.Department) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, d := range s.departments {
+		if d.NameTh == department.NameTh {
+			return fmt.Errorf("failed to create department: %w for name_th %s", ErrDuplicate, department.NameTh)
+		}
+	}
+
+	s.nextID++
+	department.ID = s.nextID
+	department.CreatedAt = s.visitors.clock.Now()
+	s.departments = append(s.departments, *department)
+	return nil
+}
+
+// Update changes a department
+func (s *DepartmentStore) Update(department *models.Department) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, d := range s.departments {
+		if d.ID != department.ID && d.NameTh == department.NameTh {
+			return fmt.Errorf("failed to update department: %w for name_th %s", ErrDuplicate, department.NameTh)
+		}
+	}
+	for i := range s.departments {
+		if s.departments[i].ID == department.ID {
+			department.CreatedAt = s.departments[i].CreatedAt
+			s.departments[i] = *department
+		}
+	}
+	return nil
+}
+
+// Delete removes a department no visit refers to
+func (s *DepartmentStore) Delete(id int) error {
+	if s.visitors.usesDepartment(id) {
+		return repository.ErrDepartmentInUse
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.departments {
+		if s.departments[i].ID == id {
+			s.departments = append(s.departments[:i], s.departments[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"database/sql"
+	"sort"
+	"sync"
+
+	"backend/internal/models"
+	"backend/internal/repository"
+)
+
+type ExportStore struct {
+	mu      sync.Mutex
+	records []models.ExportRecord
+	nextID  int
+}
+
+func NewExportStore() *ExportStore {
+	return &ExportStore{}
+}
+
+var _ repository.ExportStore = (*ExportStore)(nil)
+
+// GetHistory retrieves all export history records, newest export date first
+func (s *ExportStore) GetHistory() ([]models.ExportRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// เหมือนตัวจริงที่คืน nil เมื่อยังไม่มีประวัติ
+	var history []models.ExportRecord
+	history = append(history, s.records...)
+	sort.SliceStable(history, func(i, j int) bool { return history[i].ExportDate > history[j].ExportDate })
+	return history, nil
+}
+
+// Create inserts a new export history record
+func (s *ExportStore) Create(record *models.ExportRecord) (*models.ExportRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	record.ID = s.nextID
+	s.records = append(s.records, *record)
+	return record, nil
+}
+
+// GetByID retrieves a single export history record by ID
+func (s *ExportStore) GetByID(id int) (*models.ExportRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, record := range s.records {
+		if record.ID == id {
+			out := record
+			return &out, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
@@ -0,0 +1,640 @@
+// Package memory is an in-memory implementation of the visitor, return-log, export and department stores.
+// Store เก็บผู้มาติดต่อและการคืนบัตร ExportStore เก็บประวัติการส่งออก DepartmentStore เก็บรายการส่วนงาน
+//
+// ทำงานเหมือน repository บน MySQL (เช็คค่าซ้ำ การทำหลายขั้นตอนพร้อมกัน และลำดับผลลัพธ์)
+// ใช้ทดสอบ handler โดยไม่ต้องมีฐานข้อมูล ข้อมูลหายเมื่อปิดโปรแกรม
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"backend/internal/address"
+	"backend/internal/clock"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/search"
+	"backend/internal/vehicle"
+)
+
+// ErrDuplicate is returned where MySQL would fail on a UNIQUE key
+var ErrDuplicate = errors.New("duplicate entry")
+
+type Store struct {
+	mu    sync.Mutex
+	clock *clock.Clock
+
+	visitors      map[int]*models.Visitor
+	nextVisitorID int
+
+	vehicles      map[string]int // plate_number|province -> id
+	nextVehicleID int
+	appointments  map[int]int // appointment id -> visitor id ที่มาตามนัดแล้ว
+
+	returnLogs []models.ReturnCardLog
+	nextLogID  int
+
+	revisions      []models.VisitorRevision
+	nextRevisionID int
+}
+
+func NewStore(clk *clock.Clock) *Store {
+	return &Store{
+		clock:        clk,
+		visitors:     map[int]*models.Visitor{},
+		vehicles:     map[string]int{},
+		appointments: map[int]int{},
+	}
+}
+
+var (
+	_ repository.VisitorStore   = (*Store)(nil)
+	_ repository.ReturnLogStore = (*Store)(nil)
+)
+
+// Create inserts a new visitor, with its vehicle and appointment, all or nothing
+func (s *Store) Create(visitor *models.Visitor) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, v := range s.visitors {
+		if v.DeletedAt == nil && v.IDCard == visitor.IDCard {
+			return fmt.Errorf("failed to create visitor: %w for id_card %s", ErrDuplicate, visitor.IDCard)
+		}
+		if visitor.RFID != "" && v.RFID == visitor.RFID {
+			return fmt.Errorf("failed to create visitor: %w for rfid %s", ErrDuplicate, visitor.RFID)
+		}
+		if visitor.ClientID != "" && v.ClientID == visitor.ClientID {
+			return fmt.Errorf("failed to create visitor: %w for client_id %s", ErrDuplicate, visitor.ClientID)
+		}
+	}
+	if visitor.AppointmentID != nil {
+		if _, used := s.appointments[*visitor.AppointmentID]; used {
+			return repository.ErrAppointmentNotPending
+		}
+	}
+
+	// ตรวจครบแล้วจึงเขียน เหมือน commit ของ transaction
+	if visitor.Vehicle != nil {
+		key := visitor.Vehicle.PlateNumber + "|" + visitor.Vehicle.Province
+		id, ok := s.vehicles[key]
+		if !ok {
+			s.nextVehicleID++
+			id = s.nextVehicleID
+			s.vehicles[key] = id
+		}
+		visitor.Vehicle.ID = id
+		visitor.VehicleID = &visitor.Vehicle.ID
+	}
+
+	s.nextVisitorID++
+	visitor.ID = s.nextVisitorID
+	if visitor.AppointmentID != nil {
+		s.appointments[*visitor.AppointmentID] = visitor.ID
+	}
+
+	stored := *visitor
+	stored.Vehicle = nil
+	stored.AppointmentID = nil
+	if stored.ApprovalStatus == "" {
+		stored.ApprovalStatus = models.ApprovalNotRequired
+	}
+	now := s.clock.Now()
+	if stored.RegisteredAt.IsZero() {
+		stored.RegisteredAt = now
+	}
+	stored.UpdatedAt = now
+	stored.Version = 1
+	s.visitors[stored.ID] = &stored
+
+	return nil
+}
+
+// GetByID retrieves a visitor by ID
+func (s *Store) GetByID(id int) (*models.Visitor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v, ok := s.visitors[id]
+	if !ok || v.DeletedAt != nil {
+		return nil, fmt.Errorf("visitor not found")
+	}
+	out := *v
+	return &out, nil
+}
+
+// GetByRFID retrieves a visitor by RFID card
+func (s *Store) GetByRFID(rfid string) (*models.Visitor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, v := range s.visitors {
+		if rfid != "" && v.RFID == rfid {
+			out := *v
+			return &out, nil
+		}
+	}
+	return nil, fmt.Errorf("visitor not found")
+}
+
+// GetByClientID retrieves a visitor by the UUID given when it was registered
+func (s *Store) GetByClientID(clientID string) (*models.Visitor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, v := range s.visitors {
+		if clientID != "" && v.ClientID == clientID {
+			out := *v
+			return &out, nil
+		}
+	}
+	return nil, fmt.Errorf("visitor not found")
+}
+
+// List retrieves visitors with the same filters, order and paging as VisitorRepository.List
+func (s *Store) List(params models.QueryParams) ([]models.Visitor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.list(params), nil
+}
+
+// GetVisitorsForExport is List with the full name and address filled in
+func (s *Store) GetVisitorsForExport(params models.QueryParams) ([]models.Visitor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	visitors := s.list(params)
+	for i := range visitors {
+		v := &visitors[i]
+		v.Name = v.FirstName + " " + v.LastName
+		v.Address = address.Format(address.FromVisitor(v), address.Full)
+	}
+	return visitors, nil
+}
+
+// ListOnSite retrieves visitors registered in [from, before) who have not returned their card
+func (s *Store) ListOnSite(from, before time.Time) ([]models.Visitor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	visitors := s.filter(func(v *models.Visitor) bool {
+		return v.DeletedAt == nil && v.ExitTime == nil && v.ApprovalStatus != models.ApprovalRejected &&
+			!v.RegisteredAt.Before(from) && v.RegisteredAt.Before(before)
+	})
+	sortVisitors(visitors, func(a, b *models.Visitor) int { return compareTime(a.RegisteredAt, b.RegisteredAt) })
+	return visitors, nil
+}
+
+// CheckIDCardExists checks if ID card already exists (ไม่นับรายการที่ถูกลบ เหมือน UNIQUE ของฐานข้อมูล)
+func (s *Store) CheckIDCardExists(idCard string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.filter(func(v *models.Visitor) bool { return v.DeletedAt == nil && v.IDCard == idCard })) > 0, nil
+}
+
+// CheckRFIDExists checks if RFID already exists
+func (s *Store) CheckRFIDExists(rfid string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.filter(func(v *models.Visitor) bool { return v.RFID != "" && v.RFID == rfid })) > 0, nil
+}
+
+// ListPendingApprovals retrieves visits waiting for approval, optionally for one host
+func (s *Store) ListPendingApprovals(hostName string) ([]models.Visitor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	visitors := s.filter(func(v *models.Visitor) bool {
+		return v.DeletedAt == nil && v.ApprovalStatus == models.ApprovalPending && (hostName == "" || v.HostName == hostName)
+	})
+	sortVisitors(visitors, func(a, b *models.Visitor) int { return compareTime(a.RegisteredAt, b.RegisteredAt) })
+	return visitors, nil
+}
+
+// SetApproval records the host's decision on a pending visit
+func (s *Store) SetApproval(id int, status, approvedBy, note string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v, ok := s.visitors[id]
+	if !ok || v.DeletedAt != nil || v.ApprovalStatus != models.ApprovalPending {
+		return repository.ErrNotPendingApproval
+	}
+	now := s.clock.Now()
+	v.ApprovalStatus = status
+	v.ApprovedBy = approvedBy
+	v.ApprovalNote = note
+	v.ApprovedAt = &now
+	v.UpdatedAt = now
+	v.Version++
+	return nil
+}
+
+// IssueCard assigns an RFID card to a visit that is approved (or needs no approval) and has no card yet
+func (s *Store) IssueCard(id int, rfid string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v, ok := s.visitors[id]
+	if !ok || v.DeletedAt != nil || v.RFID != "" ||
+		(v.ApprovalStatus != models.ApprovalNotRequired && v.ApprovalStatus != models.ApprovalApproved) {
+		return repository.ErrCardNotIssuable
+	}
+	for _, other := range s.visitors {
+		if other.RFID == rfid {
+			return fmt.Errorf("failed to issue card: %w for rfid %s", ErrDuplicate, rfid)
+		}
+	}
+	v.RFID = rfid
+	v.UpdatedAt = s.clock.Now()
+	v.Version++
+	return nil
+}
+
+// SetPhoto stores the keys of the visitor's photo and returns the keys it replaced
+func (s *Store) SetPhoto(id int, photoKey, thumbnailKey string) (oldPhotoKey, oldThumbnailKey string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v, ok := s.visitors[id]
+	if !ok || v.DeletedAt != nil {
+		return "", "", fmt.Errorf("visitor not found")
+	}
+	oldPhotoKey, oldThumbnailKey = v.PhotoKey, v.PhotoThumbnailKey
+	now := s.clock.Now()
+	v.PhotoKey = photoKey
+	v.PhotoThumbnailKey = thumbnailKey
+	v.PhotoTakenAt = &now
+	v.Version++
+	return oldPhotoKey, oldThumbnailKey, nil
+}
+
+// CheckDuplicateReturn เช็คว่าบัตรถูกคืนวันนี้แล้วหรือยัง (วันนี้ตามเวลาของโรงเรียน ไม่นับที่ถูกยกเลิก)
+func (s *Store) CheckDuplicateReturn(cardID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	today := s.clock.TodayDate()
+	for _, log := range s.returnLogs {
+		if log.CardID == cardID && log.ReturnDate.Equal(today) && log.VoidedAt == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CreateReturnLog creates a new return card log and sets the visitor's exit time together,
+// or returns repository.ErrDuplicateReturn when the card was already returned that day
+func (s *Store) CreateReturnLog(log *models.ReturnCardLog) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// FOREIGN KEY ของ visitor_id
+	v, ok := s.visitors[log.VisitorID]
+	if !ok {
+		return fmt.Errorf("failed to create return log: visitor %d not found", log.VisitorID)
+	}
+	for _, l := range s.returnLogs {
+		if l.CardID == log.CardID && l.ReturnDate.Equal(log.ReturnDate) && l.VoidedAt == nil {
+			return repository.ErrDuplicateReturn
+		}
+	}
+
+	if log.ClientID != "" {
+		for _, l := range s.returnLogs {
+			if l.ClientID == log.ClientID {
+				return fmt.Errorf("failed to create return log: %w for client_id %s", ErrDuplicate, log.ClientID)
+			}
+		}
+	}
+
+	now := s.clock.Now()
+	s.nextLogID++
+	log.ID = s.nextLogID
+	log.CreatedAt = now
+	s.returnLogs = append(s.returnLogs, *log)
+
+	exitTime := log.ReturnedAt
+	if exitTime.IsZero() {
+		exitTime = now
+	}
+	v.ExitTime = &exitTime
+	v.UpdatedAt = now
+	v.Version++
+	return nil
+}
+
+// VoidReturnLog marks a mistaken return as voided and reopens the visit when no other return is left
+func (s *Store) VoidReturnLog(id int, revision *models.VisitorRevision) (*models.ReturnCardLog, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var log *models.ReturnCardLog
+	for i := range s.returnLogs {
+		if s.returnLogs[i].ID == id {
+			log = &s.returnLogs[i]
+		}
+	}
+	if log == nil {
+		return nil, repository.ErrReturnLogNotFound
+	}
+	if log.VoidedAt != nil {
+		return nil, repository.ErrReturnAlreadyVoided
+	}
+	v, ok := s.visitors[log.VisitorID]
+	if !ok || v.DeletedAt != nil {
+		return nil, repository.ErrReturnLogNotFound
+	}
+
+	now := s.clock.Now()
+	log.VoidedAt = &now
+	log.VoidedBy = revision.ChangedBy
+	log.VoidReason = revision.Reason
+
+	remaining := 0
+	for _, l := range s.returnLogs {
+		if l.VisitorID == v.ID && l.VoidedAt == nil {
+			remaining++
+		}
+	}
+	revision.Changes = nil
+	if remaining == 0 && v.ExitTime != nil {
+		revision.Changes = append(revision.Changes, models.VisitorChange{Field: "exitTime", Old: s.clock.In(*v.ExitTime).Format(time.RFC3339), New: ""})
+		v.ExitTime = nil
+	}
+	s.recordRevision(v, revision)
+
+	voided := *log
+	return &voided, nil
+}
+
+// GetReturnLogByClientID retrieves the return recorded with this client ID, voided or not
+func (s *Store) GetReturnLogByClientID(clientID string) (*models.ReturnCardLog, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, log := range s.returnLogs {
+		if clientID != "" && log.ClientID == clientID {
+			found := log
+			return &found, nil
+		}
+	}
+	return nil, repository.ErrReturnLogNotFound
+}
+
+// CheckReturnLogExists checks if a return with this client ID was already recorded
+func (s *Store) CheckReturnLogExists(clientID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, log := range s.returnLogs {
+		if clientID != "" && log.ClientID == clientID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetReturnLogs retrieves return card logs with filters (ไม่รวมรายการที่ถูกยกเลิก)
+func (s *Store) GetReturnLogs(searchTerm, startDate, endDate, sortOrder string) ([]models.ReturnCardLog, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	logs := []models.ReturnCardLog{}
+	for _, log := range s.returnLogs {
+		if log.VoidedAt != nil {
+			continue
+		}
+		if searchTerm != "" && !like(log.CardID, searchTerm) && !like(log.Name, searchTerm) {
+			continue
+		}
+		day := log.ReturnDate.Format("2006-01-02")
+		if startDate != "" && day < startDate {
+			continue
+		}
+		if endDate != "" && day > endDate {
+			continue
+		}
+		logs = append(logs, log)
+	}
+
+	sort.SliceStable(logs, func(i, j int) bool {
+		switch sortOrder {
+		case "asc":
+			return logs[i].CheckIn < logs[j].CheckIn
+		case "desc":
+			return logs[i].CheckIn > logs[j].CheckIn
+		}
+		if !logs[i].CreatedAt.Equal(logs[j].CreatedAt) {
+			return logs[i].CreatedAt.After(logs[j].CreatedAt)
+		}
+		return logs[i].ID > logs[j].ID
+	})
+	return logs, nil
+}
+
+// list must be called with s.mu held
+func (s *Store) list(params models.QueryParams) []models.Visitor {
+	visitors := s.filter(s.matcher(params))
+	sortVisitors(visitors, visitorOrder(params))
+
+	if params.Limit > 0 {
+		offset := 0
+		if params.Page > 0 {
+			offset = (params.Page - 1) * params.Limit
+		}
+		if offset > len(visitors) {
+			offset = len(visitors)
+		}
+		end := offset + params.Limit
+		if end > len(visitors) {
+			end = len(visitors)
+		}
+		visitors = visitors[offset:end]
+	}
+	return visitors
+}
+
+// filter returns copies of the visitors that match, must be called with s.mu held
+func (s *Store) filter(match func(v *models.Visitor) bool) []models.Visitor {
+	visitors := []models.Visitor{}
+	for _, v := range s.visitors {
+		if match(v) {
+			visitors = append(visitors, *v)
+		}
+	}
+	// เรียงตาม ID ไว้ก่อน ลำดับที่เท่ากันจะได้แน่นอน
+	sort.Slice(visitors, func(i, j int) bool { return visitors[i].ID < visitors[j].ID })
+	return visitors
+}
+
+// usesDepartment reports whether a visit refers to department id
+func (s *Store) usesDepartment(id int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, v := range s.visitors {
+		if v.DepartmentID != nil && *v.DepartmentID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// matcher is the Go version of the WHERE clause built by visitorFilters
+func (s *Store) matcher(params models.QueryParams) func(v *models.Visitor) bool {
+	q := search.Parse(params.Search)
+	start, startErr := s.clock.StartOfDay(params.StartDate)
+	end, endErr := s.clock.EndOfDay(params.EndDate)
+	plate := vehicle.NormalizePlate(params.LicensePlate)
+	stripSeparators := strings.NewReplacer(" ", "", "-", "", ".", "")
+	now := s.clock.Now()
+
+	return func(v *models.Visitor) bool {
+		if v.DeletedAt != nil {
+			return false
+		}
+		phone := stripSeparators.Replace(v.Phone)
+		for _, term := range q.Terms {
+			if !like(v.FirstName, term) && !like(v.LastName, term) && !like(v.IDCard, term) &&
+				!like(phone, term) && !like(v.LicensePlate, term) && !like(v.RFID, term) {
+				return false
+			}
+		}
+
+		if len(params.DepartmentIDs) > 0 && (v.DepartmentID == nil || !containsInt(params.DepartmentIDs, *v.DepartmentID)) {
+			return false
+		}
+		if params.Province != "" && !strings.EqualFold(v.Province, params.Province) {
+			return false
+		}
+		if params.LicensePlate != "" {
+			stripped := stripSeparators.Replace(v.LicensePlate)
+			if !like(stripped, plate) {
+				return false
+			}
+		}
+		if params.OfficerName != "" && !like(v.OfficerName, params.OfficerName) {
+			return false
+		}
+
+		if startErr == nil && v.RegisteredAt.Before(start) {
+			return false
+		}
+		if endErr == nil && !v.RegisteredAt.Before(end) {
+			return false
+		}
+
+		switch params.Status {
+		case models.VisitorStatusOnSite:
+			if v.ExitTime != nil {
+				return false
+			}
+		case models.VisitorStatusCheckedOut:
+			if v.ExitTime == nil {
+				return false
+			}
+		}
+
+		if params.MinDuration != nil || params.MaxDuration != nil {
+			until := now
+			if v.ExitTime != nil {
+				until = *v.ExitTime
+			}
+			minutes := int(until.Sub(v.RegisteredAt).Minutes()) // TIMESTAMPDIFF ปัดทิ้ง
+			if params.MinDuration != nil && minutes < *params.MinDuration {
+				return false
+			}
+			if params.MaxDuration != nil && minutes > *params.MaxDuration {
+				return false
+			}
+		}
+
+		if params.HasRFID != nil && *params.HasRFID != (v.RFID != "") {
+			return false
+		}
+		return true
+	}
+}
+
+// visitorOrder mirrors visitorOrderBy: registered_at (default), name or exit time; id breaks ties
+func visitorOrder(params models.QueryParams) func(a, b *models.Visitor) int {
+	desc := params.SortBy != models.SortByName
+	switch params.SortOrder {
+	case "oldest", "asc":
+		desc = false
+	case "latest", "desc":
+		desc = true
+	}
+	direction := func(c int) int {
+		if desc {
+			return -c
+		}
+		return c
+	}
+
+	return func(a, b *models.Visitor) int {
+		switch params.SortBy {
+		case models.SortByName:
+			if c := strings.Compare(a.FirstName, b.FirstName); c != 0 {
+				return direction(c)
+			}
+			if c := strings.Compare(a.LastName, b.LastName); c != 0 {
+				return direction(c)
+			}
+		case models.SortByExitTime:
+			// คนที่ยังไม่ออกอยู่ท้ายเสมอ
+			if (a.ExitTime == nil) != (b.ExitTime == nil) {
+				if a.ExitTime == nil {
+					return 1
+				}
+				return -1
+			}
+			if a.ExitTime != nil {
+				if c := compareTime(*a.ExitTime, *b.ExitTime); c != 0 {
+					return direction(c)
+				}
+			}
+		default:
+			if c := compareTime(a.RegisteredAt, b.RegisteredAt); c != 0 {
+				return direction(c)
+			}
+		}
+		return direction(a.ID - b.ID)
+	}
+}
+
+func sortVisitors(visitors []models.Visitor, cmp func(a, b *models.Visitor) int) {
+	sort.SliceStable(visitors, func(i, j int) bool { return cmp(&visitors[i], &visitors[j]) < 0 })
+}
+
+func compareTime(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	}
+	return 0
+}
+
+func containsInt(values []int, n int) bool {
+	for _, v := range values {
+		if v == n {
+			return true
+		}
+	}
+	return false
+}
+
+// like is LIKE '%term%' with the case-insensitive collation of the database
+func like(value, term string) bool {
+	return strings.Contains(strings.ToLower(value), strings.ToLower(term))
+}
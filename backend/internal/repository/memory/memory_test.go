@@ -0,0 +1,164 @@
+package memory
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"backend/internal/clock"
+	"backend/internal/models"
+	"backend/internal/repository"
+)
+
+func newTestStore(t *testing.T, now *time.Time) *Store {
+	t.Helper()
+	bangkok, err := clock.Load("Asia/Bangkok")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewStore(bangkok.WithNow(func() time.Time { return *now }))
+}
+
+func TestCreateRejectsDuplicates(t *testing.T) {
+	now := time.Date(2025, 6, 2, 3, 0, 0, 0, time.UTC)
+	s := newTestStore(t, &now)
+
+	first := &models.Visitor{IDCard: "1101234500001", RFID: "RF1", Vehicle: &models.Vehicle{PlateNumber: "กข1234", Province: "กรุงเทพมหานคร"}}
+	if err := s.Create(first); err != nil {
+		t.Fatal(err)
+	}
+	if first.ID != 1 || first.VehicleID == nil {
+		t.Errorf("created visitor = %+v", first)
+	}
+	// ค่าเริ่มต้นของคอลัมน์เห็นเมื่ออ่านกลับ เหมือน MySQL
+	if stored, _ := s.GetByID(first.ID); stored.ApprovalStatus != models.ApprovalNotRequired || !stored.RegisteredAt.Equal(now) {
+		t.Errorf("stored visitor = %+v", stored)
+	}
+
+	// รถคันเดิมใช้ vehicle id เดิม
+	second := &models.Visitor{IDCard: "1101234500002", Vehicle: &models.Vehicle{PlateNumber: "กข1234", Province: "กรุงเทพมหานคร"}}
+	if err := s.Create(second); err != nil {
+		t.Fatal(err)
+	}
+	if second.VehicleID == nil || *second.VehicleID != *first.VehicleID {
+		t.Errorf("vehicle ids = %v, %v", first.VehicleID, second.VehicleID)
+	}
+
+	if err := s.Create(&models.Visitor{IDCard: "1101234500001"}); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("duplicate ID card error = %v", err)
+	}
+	if err := s.Create(&models.Visitor{IDCard: "1101234500003", RFID: "RF1"}); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("duplicate RFID error = %v", err)
+	}
+
+	appt := 7
+	if err := s.Create(&models.Visitor{IDCard: "1101234500004", AppointmentID: &appt}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Create(&models.Visitor{IDCard: "1101234500005", AppointmentID: &appt}); !errors.Is(err, repository.ErrAppointmentNotPending) {
+		t.Errorf("reused appointment error = %v", err)
+	}
+}
+
+func TestListFiltersAndOrder(t *testing.T) {
+	now := time.Date(2025, 6, 1, 17, 30, 0, 0, time.UTC) // 00:30 ของวันที่ 2 มิ.ย. เวลาโรงเรียน
+	s := newTestStore(t, &now)
+
+	academic, personnel := 1, 2
+	for _, v := range []*models.Visitor{
+		{IDCard: "1", FirstName: "ข", Department: "ฝ่ายวิชาการ", DepartmentID: &academic},
+		{IDCard: "2", FirstName: "ก", Department: "ฝ่ายบุคคล", DepartmentID: &personnel, RFID: "RF2"},
+		{IDCard: "3", FirstName: "ค", Department: "ฝ่ายวิชาการ", DepartmentID: &academic, RFID: "RF3"},
+	} {
+		if err := s.Create(v); err != nil {
+			t.Fatal(err)
+		}
+		now = now.Add(time.Hour)
+	}
+
+	ids := func(visitors []models.Visitor) []int {
+		out := make([]int, len(visitors))
+		for i, v := range visitors {
+			out[i] = v.ID
+		}
+		return out
+	}
+	hasRFID := true
+
+	tests := []struct {
+		name   string
+		params models.QueryParams
+		want   []int
+	}{
+		{"latest first", models.QueryParams{SortOrder: "latest"}, []int{3, 2, 1}},
+		{"by name", models.QueryParams{SortBy: models.SortByName, SortOrder: "asc"}, []int{2, 1, 3}},
+		{"department", models.QueryParams{DepartmentIDs: []int{academic}, SortOrder: "oldest"}, []int{1, 3}},
+		{"several departments", models.QueryParams{DepartmentIDs: []int{academic, personnel}, SortOrder: "oldest"}, []int{1, 2, 3}},
+		{"unknown department", models.QueryParams{DepartmentIDs: []int{99}}, []int{}},
+		{"has RFID", models.QueryParams{HasRFID: &hasRFID, SortOrder: "oldest"}, []int{2, 3}},
+		{"school-local day", models.QueryParams{StartDate: "2025-06-02", EndDate: "2025-06-02", SortOrder: "oldest"}, []int{1, 2, 3}},
+		{"previous day", models.QueryParams{StartDate: "2025-06-01", EndDate: "2025-06-01"}, []int{}},
+		{"paging", models.QueryParams{SortOrder: "oldest", Page: 2, Limit: 2}, []int{3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.List(tt.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if g := ids(got); !equalInts(g, tt.want) {
+				t.Errorf("List() ids = %v, want %v", g, tt.want)
+			}
+		})
+	}
+}
+
+func TestReturnLogs(t *testing.T) {
+	now := time.Date(2025, 6, 2, 3, 0, 0, 0, time.UTC)
+	s := newTestStore(t, &now)
+
+	v := &models.Visitor{IDCard: "1", FirstName: "สมชาย", RFID: "RF1"}
+	if err := s.Create(v); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CreateReturnLog(&models.ReturnCardLog{VisitorID: 99, CardID: "RF9"}); err == nil {
+		t.Error("return log for a missing visitor should fail")
+	}
+
+	log := &models.ReturnCardLog{VisitorID: v.ID, CardID: "RF1", Name: "สมชาย", ReturnDate: s.clock.TodayDate()}
+	if err := s.CreateReturnLog(log); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := s.GetByID(v.ID)
+	if got.ExitTime == nil || !got.ExitTime.Equal(now) {
+		t.Errorf("exit time = %v, want %v", got.ExitTime, now)
+	}
+
+	if dup, _ := s.CheckDuplicateReturn("RF1"); !dup {
+		t.Error("same-day return should be a duplicate")
+	}
+	now = now.Add(24 * time.Hour)
+	if dup, _ := s.CheckDuplicateReturn("RF1"); dup {
+		t.Error("next-day return should not be a duplicate")
+	}
+
+	logs, err := s.GetReturnLogs("rf1", "2025-06-02", "2025-06-02", "")
+	if err != nil || len(logs) != 1 {
+		t.Errorf("GetReturnLogs() = %+v, %v", logs, err)
+	}
+	if logs, _ := s.GetReturnLogs("", "2025-06-03", "", ""); len(logs) != 0 {
+		t.Errorf("GetReturnLogs() after the range = %+v", logs)
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
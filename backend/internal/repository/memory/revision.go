@@ -0,0 +1,100 @@
+package memory
+
+import (
+	"fmt"
+
+	"backend/internal/models"
+	"backend/internal/repository"
+)
+
+// Update saves the edited fields of visitor if it is still at visitor.Version, with the revision
+func (s *Store) Update(visitor *models.Visitor, revision *models.VisitorRevision) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v, ok := s.visitors[visitor.ID]
+	if !ok || v.DeletedAt != nil || v.Version != visitor.Version {
+		return repository.ErrVersionConflict
+	}
+	for _, other := range s.visitors {
+		if other.ID != v.ID && other.DeletedAt == nil && other.IDCard == visitor.IDCard {
+			return fmt.Errorf("failed to update visitor: %w for id_card %s", ErrDuplicate, visitor.IDCard)
+		}
+	}
+
+	v.IDCard = visitor.IDCard
+	v.FirstName = visitor.FirstName
+	v.LastName = visitor.LastName
+	v.BirthDate = visitor.BirthDate
+	v.Phone = visitor.Phone
+	v.HouseNumber = visitor.HouseNumber
+	v.Moo = visitor.Moo
+	v.Soi = visitor.Soi
+	v.Road = visitor.Road
+	v.SubDistrict = visitor.SubDistrict
+	v.District = visitor.District
+	v.Province = visitor.Province
+	v.PostalCode = visitor.PostalCode
+	v.Department = visitor.Department
+	v.DepartmentID = visitor.DepartmentID
+	v.OfficerName = visitor.OfficerName
+	v.HostName = visitor.HostName
+	v.HostContact = visitor.HostContact
+	v.PurposeNote = visitor.PurposeNote
+	s.recordRevision(v, revision)
+
+	visitor.Version = v.Version
+	visitor.UpdatedAt = v.UpdatedAt
+	return nil
+}
+
+// Delete soft-deletes visitor if it is still at visitor.Version and releases its RFID card
+func (s *Store) Delete(visitor *models.Visitor, revision *models.VisitorRevision) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v, ok := s.visitors[visitor.ID]
+	if !ok || v.DeletedAt != nil || v.Version != visitor.Version {
+		return repository.ErrVersionConflict
+	}
+
+	now := s.clock.Now()
+	v.DeletedAt = &now
+	v.RFID = ""
+	s.recordRevision(v, revision)
+
+	visitor.Version = v.Version
+	visitor.DeletedAt = v.DeletedAt
+	visitor.RFID = ""
+	return nil
+}
+
+// recordRevision bumps the version of v and stores revision
+func (s *Store) recordRevision(v *models.Visitor, revision *models.VisitorRevision) {
+	now := s.clock.Now()
+	v.Version++
+	v.UpdatedAt = now
+
+	s.nextRevisionID++
+	revision.ID = s.nextRevisionID
+	revision.VisitorID = v.ID
+	revision.Version = v.Version
+	revision.CreatedAt = now
+	stored := *revision
+	stored.Changes = append([]models.VisitorChange{}, revision.Changes...)
+	s.revisions = append(s.revisions, stored)
+}
+
+// ListRevisions retrieves the change history of a visit, oldest first
+func (s *Store) ListRevisions(visitorID int) ([]models.VisitorRevision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	revisions := []models.VisitorRevision{}
+	for _, rev := range s.revisions {
+		if rev.VisitorID == visitorID {
+			revisions = append(revisions, rev)
+		}
+	}
+	return revisions, nil
+}
@@ -0,0 +1,123 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/repository"
+)
+
+// WatchlistStore keeps the watchlist of one school
+type WatchlistStore struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	entries []models.WatchlistEntry
+	nextID  int
+}
+
+func NewWatchlistStore(now func() time.Time) *WatchlistStore {
+	return &WatchlistStore{now: now}
+}
+
+var _ repository.WatchlistStore = (*WatchlistStore)(nil)
+
+// List retrieves the watchlist, newest first
+func (s *WatchlistStore) List(activeOnly bool) ([]models.WatchlistEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := []models.WatchlistEntry{}
+	for _, e := range s.entries {
+		if !activeOnly || e.Active {
+			entries = append(entries, e)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID > entries[j].ID })
+	return entries, nil
+}
+
+// GetByID retrieves a watchlist entry by ID
+func (s *WatchlistStore) GetByID(id int) (*models.WatchlistEntry, error) {
+	return s.find(func(e *models.WatchlistEntry) bool { return e.ID == id })
+}
+
+// GetByIDCard retrieves the entry of an ID card, active or not
+func (s *WatchlistStore) GetByIDCard(idCard string) (*models.WatchlistEntry, error) {
+	return s.find(func(e *models.WatchlistEntry) bool { return e.IDCard == idCard })
+}
+
+// Match returns the active entry of idCard, or nil when the person is not watched
+func (s *WatchlistStore) Match(idCard string) (*models.WatchlistEntry, error) {
+	e, err := s.find(func(e *models.WatchlistEntry) bool { return e.Active && e.IDCard == idCard })
+	if err != nil {
+		return nil, nil
+	}
+	return e, nil
+}
+
+func (s *WatchlistStore) find(match func(e *models.WatchlistEntry) bool) (*models.WatchlistEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.entries {
+		if match(&s.entries[i]) {
+			out := s.entries[i]
+			return &out, nil
+		}
+	}
+	return nil, fmt.Errorf("watchlist entry not found")
+}
+
+// Create adds an entry; the ID card is unique like uq_watchlist_school_id_card
+func (s *WatchlistStore) Create(entry *models.WatchlistEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range s.entries {
+		if e.IDCard == entry.IDCard {
+			return fmt.Errorf("failed to create watchlist entry: %w for id_card %s", ErrDuplicate, entry.IDCard)
+		}
+	}
+
+	s.nextID++
+	entry.ID = s.nextID
+	entry.CreatedAt = s.now()
+	s.entries = append(s.entries, *entry)
+	return nil
+}
+
+// Update changes a watchlist entry
+func (s *WatchlistStore) Update(entry *models.WatchlistEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range s.entries {
+		if e.ID != entry.ID && e.IDCard == entry.IDCard {
+			return fmt.Errorf("failed to update watchlist entry: %w for id_card %s", ErrDuplicate, entry.IDCard)
+		}
+	}
+	for i := range s.entries {
+		if s.entries[i].ID == entry.ID {
+			entry.CreatedAt = s.entries[i].CreatedAt
+			s.entries[i] = *entry
+		}
+	}
+	return nil
+}
+
+// Delete removes a watchlist entry
+func (s *WatchlistStore) Delete(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.entries {
+		if s.entries[i].ID == id {
+			s.entries = append(s.entries[:i], s.entries[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"backend/internal/clock"
+	"backend/internal/database"
+	"backend/internal/models"
+)
+
+type PickupRepository struct {
+	db      *sql.DB
+	dialect database.Dialect
+	clock   *clock.Clock
+	school  int
+	visits  *VisitorRepository // ปิดการเข้าพบของผู้มารับใน transaction เดียวกับการรับนักเรียน
+}
+
+func NewPickupRepository(db *sql.DB, clk *clock.Clock) *PickupRepository {
+	return &PickupRepository{db: db, dialect: database.DialectOf(db), clock: clk, school: models.DefaultSchoolID, visits: NewVisitorRepository(db, clk)}
+}
+
+// ForSchool returns a copy of the repository that reads and writes the pickups of school id
+// (การรับนักเรียนกรองผ่านนักเรียน ส่วนการแจ้งเตือนมี school_id ของตัวเอง)
+func (r *PickupRepository) ForSchool(id int) *PickupRepository {
+	scoped := *r
+	scoped.school = id
+	scoped.visits = r.visits.ForSchool(id)
+	return &scoped
+}
+
+// QueueForSync makes the card returned with a pickup also queue for the central server (โหมด edge)
+func (r *PickupRepository) QueueForSync() *PickupRepository {
+	r.visits = r.visits.ForSchool(r.school).QueueForSync()
+	return r
+}
+
+// CreatePickups records every student leaving with the visitor and closes the visit, in one transaction.
+// returned คือการคืนบัตรของผู้มารับ (บันทึกเวลาออก และบันทึกคืนบัตรเมื่อมีบัตร)
+// ถ้าการเข้าพบถูกปิดไปแล้ว (คืนบัตรก่อนมารับ) บันทึกเฉพาะการรับนักเรียน
+func (r *PickupRepository) CreatePickups(pickups []models.StudentPickup, returned *models.ReturnCardLog) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for i := range pickups {
+		p := &pickups[i]
+		if p.PickedUpAt.IsZero() {
+			p.PickedUpAt = r.clock.Now()
+		}
+		id, err := insertID(tx, r.dialect, `
+			INSERT INTO student_pickups (student_id, visitor_id, authorisation_id, officer_name, picked_up_at)
+			VALUES (?, ?, ?, ?, ?)
+		`, p.StudentID, p.VisitorID, p.AuthorisationID, p.OfficerName, p.PickedUpAt.UTC())
+		if err != nil {
+			return fmt.Errorf("failed to record pickup: %w", err)
+		}
+		p.ID = id
+	}
+
+	if returned != nil {
+		if err := r.closeVisit(tx, returned); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// closeVisit sets the exit time of the visitor who picked the students up, unless it is already set.
+// ผู้มาติดต่อที่ถือบัตรได้บันทึกคืนบัตรแบบเดียวกับ CreateReturnLog
+func (r *PickupRepository) closeVisit(tx *sql.Tx, returned *models.ReturnCardLog) error {
+	var exitTime sql.NullTime
+	err := tx.QueryRow(`SELECT exit_time FROM visitors WHERE id = ? AND school_id = ?`+r.dialect.ForUpdate(), returned.VisitorID, r.school).Scan(&exitTime)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("failed to close visit: visitor %d not found", returned.VisitorID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock visitor: %w", err)
+	}
+	if exitTime.Valid {
+		return nil
+	}
+
+	if returned.CardID != "" {
+		return r.visits.recordReturn(tx, returned)
+	}
+	if returned.ReturnedAt.IsZero() {
+		returned.ReturnedAt = r.clock.Now()
+	}
+	_, err = tx.Exec(`UPDATE visitors SET exit_time = ?, version = version + 1 WHERE id = ? AND school_id = ?`,
+		returned.ReturnedAt.UTC(), returned.VisitorID, r.school)
+	if err != nil {
+		return fmt.Errorf("failed to update exit_time: %w", err)
+	}
+	return nil
+}
+
+// ListPickups retrieves pickups on the given date (YYYY-MM-DD), newest first
+func (r *PickupRepository) ListPickups(date string) ([]models.StudentPickup, error) {
+	start, end, err := r.clock.DayRange(date)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(`
+		SELECT p.id, p.student_id, CONCAT(s.first_name, ' ', s.last_name),
+			p.visitor_id, CONCAT(v.first_name, ' ', v.last_name),
+			p.authorisation_id, ga.relationship, p.officer_name, p.picked_up_at
+		FROM student_pickups p
+		JOIN students s ON s.id = p.student_id
+		JOIN visitors v ON v.id = p.visitor_id
+		JOIN guardian_authorisations ga ON ga.id = p.authorisation_id
+		WHERE s.school_id = ? AND p.picked_up_at >= ? AND p.picked_up_at < ?
+		ORDER BY p.picked_up_at DESC
+	`, r.school, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pickups: %w", err)
+	}
+	defer rows.Close()
+
+	pickups := []models.StudentPickup{}
+	for rows.Next() {
+		var p models.StudentPickup
+		err := rows.Scan(&p.ID, &p.StudentID, &p.StudentName, &p.VisitorID, &p.VisitorName,
+			&p.AuthorisationID, &p.Relationship, &p.OfficerName, &p.PickedUpAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan pickup: %w", err)
+		}
+		pickups = append(pickups, p)
+	}
+
+	return pickups, nil
+}
+
+// CreateAlert records a refused pickup attempt
+func (r *PickupRepository) CreateAlert(alert *models.PickupAlert) error {
+	if alert.CreatedAt.IsZero() {
+		alert.CreatedAt = r.clock.Now()
+	}
+	id, err := insertID(r.db, r.dialect, `
+		INSERT INTO pickup_alerts (school_id, student_id, visitor_id, id_card, visitor_name, reason, officer_name, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, r.school, alert.StudentID, alert.VisitorID, alert.IDCard, alert.VisitorName, alert.Reason, alert.OfficerName, alert.CreatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to create pickup alert: %w", err)
+	}
+
+	alert.ID = id
+	return nil
+}
+
+// ListAlerts retrieves pickup alerts, newest first
+func (r *PickupRepository) ListAlerts(unacknowledgedOnly bool) ([]models.PickupAlert, error) {
+	query := `
+		SELECT a.id, a.student_id, CONCAT(s.first_name, ' ', s.last_name), a.visitor_id,
+			a.id_card, a.visitor_name, a.reason, a.officer_name, a.created_at, a.acknowledged_at
+		FROM pickup_alerts a
+		JOIN students s ON s.id = a.student_id
+		WHERE a.school_id = ?
+	`
+	if unacknowledgedOnly {
+		query += ` AND a.acknowledged_at IS NULL`
+	}
+	query += ` ORDER BY a.created_at DESC`
+
+	rows, err := r.db.Query(query, r.school)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pickup alerts: %w", err)
+	}
+	defer rows.Close()
+
+	alerts := []models.PickupAlert{}
+	for rows.Next() {
+		var a models.PickupAlert
+		var visitorID sql.NullInt64
+		err := rows.Scan(&a.ID, &a.StudentID, &a.StudentName, &visitorID, &a.IDCard,
+			&a.VisitorName, &a.Reason, &a.OfficerName, &a.CreatedAt, &a.AcknowledgedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan pickup alert: %w", err)
+		}
+		if visitorID.Valid {
+			id := int(visitorID.Int64)
+			a.VisitorID = &id
+		}
+		alerts = append(alerts, a)
+	}
+
+	return alerts, nil
+}
+
+// AcknowledgeAlert marks an alert as handled
+func (r *PickupRepository) AcknowledgeAlert(id int) error {
+	result, err := r.db.Exec(`
+		UPDATE pickup_alerts SET acknowledged_at = ?
+		WHERE id = ? AND school_id = ? AND acknowledged_at IS NULL
+	`, r.clock.Now(), id, r.school)
+	if err != nil {
+		return fmt.Errorf("failed to acknowledge alert: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to acknowledge alert: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("alert not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"backend/internal/database"
+	"backend/internal/models"
+)
+
+type PurposeRepository struct {
+	db      *sql.DB
+	dialect database.Dialect
+}
+
+func NewPurposeRepository(db *sql.DB) *PurposeRepository {
+	return &PurposeRepository{db: db, dialect: database.DialectOf(db)}
+}
+
+// List retrieves visit purposes ordered for display
+func (r *PurposeRepository) List(activeOnly bool) ([]models.VisitPurpose, error) {
+	query := `
+		SELECT code, name_th, name_en, requires_approval, active, display_order
+		FROM visit_purposes
+	`
+	if activeOnly {
+		query += ` WHERE active = TRUE`
+	}
+	query += ` ORDER BY display_order ASC, code ASC`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list visit purposes: %w", err)
+	}
+	defer rows.Close()
+
+	purposes := []models.VisitPurpose{}
+	for rows.Next() {
+		var p models.VisitPurpose
+		if err := rows.Scan(&p.Code, &p.NameTh, &p.NameEn, &p.RequiresApproval, &p.Active, &p.DisplayOrder); err != nil {
+			return nil, fmt.Errorf("failed to scan visit purpose: %w", err)
+		}
+		purposes = append(purposes, p)
+	}
+
+	return purposes, nil
+}
+
+// GetByCode retrieves a visit purpose by code
+func (r *PurposeRepository) GetByCode(code string) (*models.VisitPurpose, error) {
+	query := `
+		SELECT code, name_th, name_en, requires_approval, active, display_order
+		FROM visit_purposes WHERE code = ?
+	`
+
+	var p models.VisitPurpose
+	err := r.db.QueryRow(query, code).Scan(&p.Code, &p.NameTh, &p.NameEn, &p.RequiresApproval, &p.Active, &p.DisplayOrder)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("visit purpose not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get visit purpose: %w", err)
+	}
+
+	return &p, nil
+}
+
+// Save inserts the purpose or updates the existing one with the same code
+func (r *PurposeRepository) Save(p *models.VisitPurpose) error {
+	query := `
+		INSERT INTO visit_purposes (code, name_th, name_en, requires_approval, active, display_order)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	var assignments []string
+	for _, column := range []string{"name_th", "name_en", "requires_approval", "active", "display_order"} {
+		assignments = append(assignments, column+` = `+r.dialect.Excluded(column))
+	}
+	query += r.dialect.Upsert([]string{"code"}, assignments...)
+
+	_, err := r.db.Exec(query, p.Code, p.NameTh, p.NameEn, p.RequiresApproval, p.Active, p.DisplayOrder)
+	if err != nil {
+		return fmt.Errorf("failed to save visit purpose: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"database/sql"
+	"testing"
+
+	"backend/internal/models"
+)
+
+func TestPurposeRepository(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *sql.DB) {
+		repo := NewPurposeRepository(db)
+
+		seeded, err := repo.List(false)
+		if err != nil || len(seeded) != 6 || seeded[0].Code != "pickup_student" || seeded[5].Code != "other" {
+			t.Fatalf("seeded purposes = %+v, %v", seeded, err)
+		}
+
+		// code เดิมแก้ไข code ใหม่เพิ่ม
+		updated := models.VisitPurpose{Code: "meeting", NameTh: "ประชุมผู้ปกครอง", NameEn: "Parent meeting", RequiresApproval: false, Active: false, DisplayOrder: 2}
+		added := models.VisitPurpose{Code: "interview", NameTh: "สัมภาษณ์งาน", NameEn: "Job interview", RequiresApproval: true, Active: true, DisplayOrder: 6}
+		for _, p := range []models.VisitPurpose{updated, added} {
+			if err := repo.Save(&p); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for _, want := range []models.VisitPurpose{updated, added} {
+			if got, err := repo.GetByCode(want.Code); err != nil || *got != want {
+				t.Errorf("GetByCode(%s) = %+v, %v, want %+v", want.Code, got, err, want)
+			}
+		}
+		if active, _ := repo.List(true); len(active) != 6 {
+			t.Errorf("active purposes = %d, want 6", len(active))
+		}
+		if _, err := repo.GetByCode("missing"); err == nil {
+			t.Error("GetByCode() of a missing purpose should fail")
+		}
+	})
+}
+
+func TestExportRepository(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *sql.DB) {
+		repo := NewExportRepository(db)
+
+		count := 42
+		record, err := repo.Create(&models.ExportRecord{
+			ExportDate:  "02/06/2568 10:00",
+			Department:  "ทั้งหมด",
+			DateRange:   "01/06/2568 - 02/06/2568",
+			Format:      "excel",
+			Status:      "completed",
+			RecordCount: &count,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := repo.GetByID(record.ID)
+		if err != nil || got.Format != "excel" || got.RecordCount == nil || *got.RecordCount != count {
+			t.Errorf("GetByID() = %+v, %v", got, err)
+		}
+		history, err := repo.GetHistory()
+		if err != nil || len(history) != 1 || history[0].ID != record.ID {
+			t.Errorf("GetHistory() = %+v, %v", history, err)
+		}
+	})
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"backend/internal/clock"
+	"backend/internal/database"
+	"backend/internal/database/dbtest"
+	"backend/internal/migrate"
+	"backend/internal/models"
+	"backend/migrations"
+)
+
+// forEachBackend runs fn against every database backend with the schema migrated to the latest version
+func forEachBackend(t *testing.T, fn func(t *testing.T, db *sql.DB)) {
+	dbtest.Run(t, func(t *testing.T, db *sql.DB) {
+		fsys, err := migrations.For(string(database.DialectOf(db)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		runner, err := migrate.NewRunner(db, fsys)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := runner.Up(context.Background(), 0); err != nil {
+			t.Fatal(err)
+		}
+		fn(t, db)
+	})
+}
+
+// testClock returns the school clock (Asia/Bangkok) reading *now
+func testClock(t *testing.T, now *time.Time) *clock.Clock {
+	t.Helper()
+	bangkok, err := clock.Load("Asia/Bangkok")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bangkok.WithNow(func() time.Time { return *now })
+}
+
+// testVisitor returns the n-th visitor with a unique ID card, phone and RFID card
+func testVisitor(n int) *models.Visitor {
+	return &models.Visitor{
+		IDCard:         fmt.Sprintf("11012345%05d", n),
+		FirstName:      "ผู้มาติดต่อ",
+		LastName:       fmt.Sprintf("คนที่%d", n),
+		Phone:          fmt.Sprintf("08100000%02d", n),
+		District:       "ดุสิต",
+		Province:       "กรุงเทพมหานคร",
+		PostalCode:     "10300",
+		RFID:           fmt.Sprintf("RF%04d", n),
+		Department:     "ฝ่ายวิชาการ",
+		OfficerName:    "ยามหน้าประตู",
+		ApprovalStatus: models.ApprovalNotRequired,
+	}
+}
+
+// testDepartment returns the ID of the school's department name, creating it when missing
+func testDepartment(t *testing.T, db *sql.DB, school int, name string) int {
+	t.Helper()
+
+	departments := NewDepartmentRepository(db).ForSchool(school)
+	if d, err := departments.GetByName(name); err == nil {
+		return d.ID
+	}
+	d := &models.Department{NameTh: name, Active: true}
+	if err := departments.Create(d); err != nil {
+		t.Fatal(err)
+	}
+	return d.ID
+}
+
+// testNow is 10:00 on Monday 2 June 2025 school time
+var testNow = time.Date(2025, 6, 2, 3, 0, 0, 0, time.UTC)
+
+// seedVisits registers three visits with fixed times (registered_at ปกติมาจาก DEFAULT ของฐานข้อมูล):
+//
+//	1: 08:00 today, still on site, plate กข-1234
+//	2: 09:00 today, returned its card at 10:00, same plate
+//	3: 23:30 yesterday, ฝ่ายบุคคล, no card, still on site
+func seedVisits(t *testing.T, db *sql.DB, repo *VisitorRepository) []*models.Visitor {
+	t.Helper()
+
+	v1, v2, v3 := testVisitor(1), testVisitor(2), testVisitor(3)
+	v1.LicensePlate = "กข-1234"
+	v1.Vehicle = &models.Vehicle{PlateNumber: "กข1234", PlateDisplay: "กข 1234", Province: "กรุงเทพมหานคร", VehicleType: "car", Color: "ขาว"}
+	v2.LicensePlate = "กข-1234"
+	v2.Vehicle = &models.Vehicle{PlateNumber: "กข1234", PlateDisplay: "กข-1234", Province: "กรุงเทพมหานคร", VehicleType: "car"}
+	v3.Department = "ฝ่ายบุคคล"
+	v3.RFID = ""
+	for _, v := range []*models.Visitor{v1, v2, v3} {
+		id := testDepartment(t, db, repo.school, v.Department)
+		v.DepartmentID = &id
+	}
+
+	registered := []time.Time{
+		time.Date(2025, 6, 2, 1, 0, 0, 0, time.UTC),
+		time.Date(2025, 6, 2, 2, 0, 0, 0, time.UTC),
+		time.Date(2025, 6, 1, 16, 30, 0, 0, time.UTC),
+	}
+	for i, v := range []*models.Visitor{v1, v2, v3} {
+		if err := repo.Create(v); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.Exec(`UPDATE visitors SET registered_at = ? WHERE id = ?`, registered[i], v.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := repo.CreateReturnLog(&models.ReturnCardLog{
+		VisitorID:  v2.ID,
+		CardID:     v2.RFID,
+		Name:       v2.FirstName + " " + v2.LastName,
+		CheckIn:    "09:00",
+		CheckOut:   "10:00",
+		ReturnDate: repo.clock.TodayDate(),
+		Status:     "การคืนบัตรสำเร็จ",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return []*models.Visitor{v1, v2, v3}
+}
+
+func visitorIDs(visitors []models.Visitor) []int {
+	ids := make([]int, len(visitors))
+	for i, v := range visitors {
+		ids[i] = v.ID
+	}
+	return ids
+}
@@ -2,45 +2,48 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
 	"backend/internal/models"
+	"backend/internal/search"
+
+	"github.com/google/uuid"
 )
 
-// GetByRFID retrieves a visitor by RFID card
-func (r *VisitorRepository) GetByRFID(rfid string) (*models.Visitor, error) {
-	query := `
-		SELECT id, id_card, first_name, last_name, birth_date, phone, license_plate,
-			house_number, moo, soi, road, sub_district, district, province,
-			rfid, department, officer_name, id_card_image, registered_at, updated_at
-		FROM visitors WHERE rfid = ?
-	`
+var (
+	// ErrReturnLogNotFound is returned when the return log (or its visit) does not exist
+	ErrReturnLogNotFound = errors.New("return log not found")
+	// ErrReturnAlreadyVoided is returned when voiding a return that was already voided
+	ErrReturnAlreadyVoided = errors.New("return was already voided")
+	// ErrDuplicateReturn is returned by CreateReturnLog when the card was already returned that day
+	ErrDuplicateReturn = errors.New("card already returned today")
+)
 
-	visitor := &models.Visitor{}
-	err := r.db.QueryRow(query, rfid).Scan(
-		&visitor.ID,
-		&visitor.IDCard,
-		&visitor.FirstName,
-		&visitor.LastName,
-		&visitor.BirthDate,
-		&visitor.Phone,
-		&visitor.LicensePlate,
-		&visitor.HouseNumber,
-		&visitor.Moo,
-		&visitor.Soi,
-		&visitor.Road,
-		&visitor.SubDistrict,
-		&visitor.District,
-		&visitor.Province,
-		&visitor.RFID,
-		&visitor.Department,
-		&visitor.OfficerName,
-		&visitor.IDCardImage,
-		&visitor.RegisteredAt,
-		&visitor.UpdatedAt,
+const returnLogColumns = `id, client_id, visitor_id, card_id, name, check_in, check_out, return_date, status, created_at,
+	voided_at, voided_by, void_reason`
+
+// scanReturnLog scans a row selected with returnLogColumns
+func scanReturnLog(row rowScanner) (*models.ReturnCardLog, error) {
+	var log models.ReturnCardLog
+	var clientID sql.NullString
+	err := row.Scan(
+		&log.ID, &clientID, &log.VisitorID, &log.CardID, &log.Name, &log.CheckIn, &log.CheckOut, &log.ReturnDate, &log.Status, &log.CreatedAt,
+		&log.VoidedAt, &log.VoidedBy, &log.VoidReason,
 	)
+	if err != nil {
+		return nil, err
+	}
+	log.ClientID = clientID.String
+	return &log, nil
+}
+
+// GetByRFID retrieves a visitor by RFID card
+func (r *VisitorRepository) GetByRFID(rfid string) (*models.Visitor, error) {
+	query := `SELECT ` + visitorColumns + ` FROM visitors WHERE rfid = ? AND school_id = ?`
 
+	visitor, err := scanVisitor(r.db.QueryRow(query, rfid, r.school))
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("visitor not found")
 	}
@@ -51,7 +54,9 @@ func (r *VisitorRepository) GetByRFID(rfid string) (*models.Visitor, error) {
 	return visitor, nil
 }
 
-// CreateReturnLog creates a new return card log AND updates exit_time in visitors table
+// CreateReturnLog creates a new return card log AND updates exit_time in visitors table.
+// ล็อกแถวของผู้มาติดต่อก่อนเช็คคืนซ้ำใน transaction เดียวกัน แตะบัตรสองครั้งพร้อมกันจึงได้บันทึกเดียว
+// อีกครั้งได้ ErrDuplicateReturn
 func (r *VisitorRepository) CreateReturnLog(log *models.ReturnCardLog) error {
 	// เริ่ม transaction
 	tx, err := r.db.Begin()
@@ -60,76 +65,238 @@ func (r *VisitorRepository) CreateReturnLog(log *models.ReturnCardLog) error {
 	}
 	defer tx.Rollback()
 
-	// 1. บันทึกลง return_card_logs
+	if err := r.recordReturn(tx, log); err != nil {
+		return err
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// recordReturn does the work of CreateReturnLog inside tx (ใช้ร่วมกับการรับนักเรียนที่ปิดการเข้าพบไปพร้อมกัน)
+func (r *VisitorRepository) recordReturn(tx *sql.Tx, log *models.ReturnCardLog) error {
+	// 1. ล็อกผู้มาติดต่อ (SQLite จอง lock เขียนตั้งแต่ BEGIN) แล้วเช็คว่าบัตรถูกคืนในวันนั้นแล้วหรือยัง
+	var version int
+	err := tx.QueryRow(`SELECT version FROM visitors WHERE id = ? AND school_id = ?`+r.dialect.ForUpdate(), log.VisitorID, r.school).Scan(&version)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("failed to create return log: visitor %d not found", log.VisitorID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock visitor: %w", err)
+	}
+	duplicate, err := r.returnedOn(tx, log.CardID, sqlDate(log.ReturnDate))
+	if err != nil {
+		return err
+	}
+	if duplicate {
+		return ErrDuplicateReturn
+	}
+
+	// 2. บันทึกลง return_card_logs
+	exitTime := log.ReturnedAt
+	if exitTime.IsZero() {
+		exitTime = r.clock.Now()
+	}
 	query := `
 		INSERT INTO return_card_logs (
-			visitor_id, card_id, name, check_in, check_out, return_date, status
-		) VALUES (?, ?, ?, ?, ?, ?, ?)
+			school_id, client_id, visitor_id, card_id, name, check_in, check_out, return_date, status, returned_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	result, err := tx.Exec(query,
+	id, err := insertID(tx, r.dialect, query,
+		r.school,
+		nullIfEmpty(log.ClientID),
 		log.VisitorID,
 		log.CardID,
 		log.Name,
 		log.CheckIn,
 		log.CheckOut,
-		log.ReturnDate,
+		sqlDate(log.ReturnDate),
 		log.Status,
+		exitTime.UTC(),
 	)
 
 	if err != nil {
 		return fmt.Errorf("failed to create return log: %w", err)
 	}
+	log.ID = id
 
-	id, err := result.LastInsertId()
-	if err != nil {
-		return fmt.Errorf("failed to get last insert id: %w", err)
-	}
-	log.ID = int(id)
-
-	// 2. อัปเดต exit_time ในตาราง visitors
+	// 3. อัปเดต exit_time ในตาราง visitors
 	updateQuery := `
 		UPDATE visitors 
-		SET exit_time = NOW() 
-		WHERE id = ?
+		SET exit_time = ?, version = version + 1
+		WHERE id = ? AND school_id = ?
 	`
 
-	_, err = tx.Exec(updateQuery, log.VisitorID)
+	_, err = tx.Exec(updateQuery, exitTime.UTC(), log.VisitorID, r.school)
 	if err != nil {
 		return fmt.Errorf("failed to update exit_time: %w", err)
 	}
 
-	// Commit transaction
+	// 4. เครื่อง edge เข้าคิวไว้ส่งขึ้น server กลาง
+	if r.syncQueue && log.ClientID != "" {
+		if err := queueForSync(tx, log.ClientID, models.SyncKindReturn, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// VoidReturnLog marks a return recorded by mistake as voided and reopens the visit, in one transaction.
+// revision บอกผู้ยกเลิกและเหตุผล ถูกบันทึกในประวัติของผู้มาติดต่อ (action = void_return)
+// เวลาออกถูกล้างเฉพาะเมื่อไม่มีการคืนบัตรอื่นของรายการนี้เหลืออยู่
+// เครื่อง edge: การคืนที่ยังไม่ได้ส่งถูกเอาออกจากคิว ที่ส่งไปแล้วเข้าคิวการยกเลิกส่งตามขึ้นไป
+func (r *VisitorRepository) VoidReturnLog(id int, revision *models.VisitorRevision) (*models.ReturnCardLog, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	log, err := scanReturnLog(tx.QueryRow(`
+		SELECT `+returnLogColumns+` FROM return_card_logs WHERE id = ? AND school_id = ?`+r.dialect.ForUpdate(), id, r.school))
+	if err == sql.ErrNoRows {
+		return nil, ErrReturnLogNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get return log: %w", err)
+	}
+	if log.VoidedAt != nil {
+		return nil, ErrReturnAlreadyVoided
+	}
+
+	visitor := &models.Visitor{ID: log.VisitorID}
+	var exitTime sql.NullTime
+	err = tx.QueryRow(`
+		SELECT version, exit_time FROM visitors
+		WHERE id = ? AND school_id = ? AND deleted_at IS NULL`+r.dialect.ForUpdate(), log.VisitorID, r.school).Scan(&visitor.Version, &exitTime)
+	if err == sql.ErrNoRows {
+		return nil, ErrReturnLogNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get visitor: %w", err)
+	}
+
+	now := r.clock.Now().UTC()
+	_, err = tx.Exec(`
+		UPDATE return_card_logs SET voided_at = ?, voided_by = ?, void_reason = ?
+		WHERE id = ?
+	`, now, revision.ChangedBy, revision.Reason, log.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to void return log: %w", err)
+	}
+
+	var remaining int
+	err = tx.QueryRow(`SELECT COUNT(*) FROM return_card_logs WHERE visitor_id = ? AND voided_at IS NULL`, log.VisitorID).Scan(&remaining)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count return logs: %w", err)
+	}
+
+	set := `version = version + 1`
+	revision.Changes = nil
+	if remaining == 0 && exitTime.Valid {
+		set = `exit_time = NULL, ` + set
+		revision.Changes = append(revision.Changes, models.VisitorChange{Field: "exitTime", Old: r.clock.In(exitTime.Time).Format(time.RFC3339), New: ""})
+	}
+	result, err := tx.Exec(`UPDATE visitors SET `+set+` WHERE id = ? AND school_id = ? AND version = ?`, visitor.ID, r.school, visitor.Version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reopen visit: %w", err)
+	}
+	if err := r.recordRevision(tx, result, visitor, revision); err != nil {
+		return nil, err
+	}
+
+	if r.syncQueue && log.ClientID != "" {
+		if err := r.queueVoid(tx, log); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.VoidedAt = &now
+	log.VoidedBy = revision.ChangedBy
+	log.VoidReason = revision.Reason
+	return log, nil
+}
+
+// CheckReturnLogExists checks if a return with this client ID was already recorded
+func (r *VisitorRepository) CheckReturnLogExists(clientID string) (bool, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM return_card_logs WHERE client_id = ? AND school_id = ?`, clientID, r.school).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to check return log: %w", err)
 	}
+	return count > 0, nil
+}
 
+// queueVoid keeps the central server in step with a return voided at an edge gate (tx ของ VoidReturnLog)
+func (r *VisitorRepository) queueVoid(tx *sql.Tx, log *models.ReturnCardLog) error {
+	var status string
+	err := tx.QueryRow(`SELECT status FROM sync_queue WHERE client_id = ? AND kind = ?`, log.ClientID, models.SyncKindReturn).Scan(&status)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get sync queue entry: %w", err)
+	}
+
+	switch status {
+	case models.SyncPending:
+		// ยังไม่ได้ส่ง ไม่ต้องส่งเลย
+		if _, err := tx.Exec(`DELETE FROM sync_queue WHERE client_id = ?`, log.ClientID); err != nil {
+			return fmt.Errorf("failed to remove return from sync queue: %w", err)
+		}
+	case models.SyncSynced:
+		return queueForSync(tx, uuid.NewString(), models.SyncKindVoid, log.ID)
+	}
+	// SyncConflict: server กลางไม่ได้รับการคืนนี้ ไม่มีอะไรต้องยกเลิก
 	return nil
 }
 
-// GetReturnLogs retrieves return card logs with filters
-func (r *VisitorRepository) GetReturnLogs(search, startDate, endDate, sortOrder string) ([]models.ReturnCardLog, error) {
+// GetReturnLogByClientID retrieves the return recorded with this client ID (Idempotency-Key), voided or not
+func (r *VisitorRepository) GetReturnLogByClientID(clientID string) (*models.ReturnCardLog, error) {
+	log, err := scanReturnLog(r.db.QueryRow(`
+		SELECT `+returnLogColumns+` FROM return_card_logs WHERE client_id = ? AND school_id = ?`, clientID, r.school))
+	if err == sql.ErrNoRows {
+		return nil, ErrReturnLogNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get return log: %w", err)
+	}
+	return log, nil
+}
+
+// GetReturnLogs retrieves return card logs with filters (ไม่รวมรายการที่ถูกยกเลิก)
+func (r *VisitorRepository) GetReturnLogs(searchText, startDate, endDate, sortOrder string) ([]models.ReturnCardLog, error) {
 	query := `
-		SELECT id, visitor_id, card_id, name, check_in, check_out, return_date, status, created_at
-		FROM return_card_logs WHERE 1=1
+		SELECT ` + returnLogColumns + `
+		FROM return_card_logs WHERE school_id = ? AND voided_at IS NULL
 	`
 
-	args := []interface{}{}
+	args := []interface{}{r.school}
 
 	// Search filter
-	if search != "" {
-		query += ` AND (card_id LIKE ? OR name LIKE ?)`
-		searchTerm := "%" + search + "%"
+	if searchText != "" {
+		query += ` AND (` + r.dialect.Like(`card_id`) + ` OR ` + r.dialect.Like(`name`) + `)`
+		searchTerm := search.Contains(searchText)
 		args = append(args, searchTerm, searchTerm)
 	}
 
 	// Date range filter
 	if startDate != "" {
-		query += ` AND DATE(return_date) >= ?`
+		query += ` AND return_date >= ?`
 		args = append(args, startDate)
 	}
 	if endDate != "" {
-		query += ` AND DATE(return_date) <= ?`
+		query += ` AND return_date <= ?`
 		args = append(args, endDate)
 	}
 
@@ -150,22 +317,11 @@ func (r *VisitorRepository) GetReturnLogs(search, startDate, endDate, sortOrder
 
 	logs := []models.ReturnCardLog{}
 	for rows.Next() {
-		var log models.ReturnCardLog
-		err := rows.Scan(
-			&log.ID,
-			&log.VisitorID,
-			&log.CardID,
-			&log.Name,
-			&log.CheckIn,
-			&log.CheckOut,
-			&log.ReturnDate,
-			&log.Status,
-			&log.CreatedAt,
-		)
+		log, err := scanReturnLog(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan return log: %w", err)
 		}
-		logs = append(logs, log)
+		logs = append(logs, *log)
 	}
 
 	return logs, nil
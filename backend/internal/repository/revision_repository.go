@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"backend/internal/models"
+)
+
+// ErrVersionConflict is returned when the visit changed (or was deleted) after the client read it
+var ErrVersionConflict = errors.New("visit was changed by someone else")
+
+// Update saves the edited fields of visitor if it is still at visitor.Version, and records revision
+// in the same transaction. visitor.Version becomes the new version.
+// การแก้ไขที่เครื่อง edge ไม่ได้ส่งขึ้น server กลาง (sync เฉพาะการลงทะเบียนและคืนบัตร)
+func (r *VisitorRepository) Update(visitor *models.Visitor, revision *models.VisitorRevision) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE visitors SET
+			id_card = ?, first_name = ?, last_name = ?, birth_date = ?, phone = ?,
+			house_number = ?, moo = ?, soi = ?, road = ?, sub_district = ?, district = ?, province = ?, postal_code = ?,
+			department = ?, department_id = ?, officer_name = ?, host_name = ?, host_contact = ?, purpose_note = ?,
+			version = version + 1
+		WHERE id = ? AND school_id = ? AND version = ? AND deleted_at IS NULL
+	`,
+		visitor.IDCard, visitor.FirstName, visitor.LastName, nullSQLDate(visitor.BirthDate), visitor.Phone,
+		visitor.HouseNumber, visitor.Moo, visitor.Soi, visitor.Road, visitor.SubDistrict, visitor.District, visitor.Province, visitor.PostalCode,
+		visitor.Department, visitor.DepartmentID, visitor.OfficerName, visitor.HostName, visitor.HostContact, visitor.PurposeNote,
+		visitor.ID, r.school, visitor.Version,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update visitor: %w", err)
+	}
+	if err := r.recordRevision(tx, result, visitor, revision); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	visitor.Version++
+	return nil
+}
+
+// Delete soft-deletes visitor if it is still at visitor.Version and records revision.
+// บัตร RFID ถูกปล่อยให้ใช้กับคนอื่นได้ (เลขบัตรเดิมอยู่ในประวัติ) และลงทะเบียนเลขบัตรประชาชนเดิมใหม่ได้
+func (r *VisitorRepository) Delete(visitor *models.Visitor, revision *models.VisitorRevision) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := r.clock.Now().UTC()
+	result, err := tx.Exec(`
+		UPDATE visitors SET deleted_at = ?, rfid = NULL, version = version + 1
+		WHERE id = ? AND school_id = ? AND version = ? AND deleted_at IS NULL
+	`, now, visitor.ID, r.school, visitor.Version)
+	if err != nil {
+		return fmt.Errorf("failed to delete visitor: %w", err)
+	}
+	if err := r.recordRevision(tx, result, visitor, revision); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	visitor.Version++
+	visitor.DeletedAt = &now
+	visitor.RFID = ""
+	return nil
+}
+
+// recordRevision inserts revision once the UPDATE in result changed the visit, otherwise returns ErrVersionConflict
+func (r *VisitorRepository) recordRevision(tx *sql.Tx, result sql.Result, visitor *models.Visitor, revision *models.VisitorRevision) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update visitor: %w", err)
+	}
+	if affected == 0 {
+		return ErrVersionConflict
+	}
+
+	if revision.Changes == nil {
+		revision.Changes = []models.VisitorChange{}
+	}
+	changes, err := json.Marshal(revision.Changes)
+	if err != nil {
+		return fmt.Errorf("failed to encode changes: %w", err)
+	}
+
+	revision.VisitorID = visitor.ID
+	revision.Version = visitor.Version + 1
+	revision.ID, err = insertID(tx, r.dialect, `
+		INSERT INTO visitor_revisions (school_id, visitor_id, version, action, changes, changed_by, reason)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, r.school, revision.VisitorID, revision.Version, revision.Action, string(changes), revision.ChangedBy, revision.Reason)
+	if err != nil {
+		return fmt.Errorf("failed to record revision: %w", err)
+	}
+	return nil
+}
+
+// ListRevisions retrieves the change history of a visit, oldest first (รวมรายการที่ถูกลบแล้ว)
+func (r *VisitorRepository) ListRevisions(visitorID int) ([]models.VisitorRevision, error) {
+	rows, err := r.db.Query(`
+		SELECT id, visitor_id, version, action, changes, changed_by, reason, created_at
+		FROM visitor_revisions
+		WHERE visitor_id = ? AND school_id = ?
+		ORDER BY id ASC
+	`, visitorID, r.school)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := []models.VisitorRevision{}
+	for rows.Next() {
+		var rev models.VisitorRevision
+		var changes string
+		if err := rows.Scan(&rev.ID, &rev.VisitorID, &rev.Version, &rev.Action, &changes, &rev.ChangedBy, &rev.Reason, &rev.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan revision: %w", err)
+		}
+		if err := json.Unmarshal([]byte(changes), &rev.Changes); err != nil {
+			return nil, fmt.Errorf("failed to decode changes of revision %d: %w", rev.ID, err)
+		}
+		revisions = append(revisions, rev)
+	}
+
+	return revisions, nil
+}
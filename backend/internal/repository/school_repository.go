@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"backend/internal/database"
+	"backend/internal/models"
+)
+
+// SchoolRepository stores the schools of the district and their configuration
+type SchoolRepository struct {
+	db      *sql.DB
+	dialect database.Dialect
+}
+
+func NewSchoolRepository(db *sql.DB) *SchoolRepository {
+	return &SchoolRepository{db: db, dialect: database.DialectOf(db)}
+}
+
+const schoolColumns = `id, code, name, timezone, retention_days, active, created_at`
+
+func scanSchool(row rowScanner) (*models.School, error) {
+	var s models.School
+	err := row.Scan(&s.ID, &s.Code, &s.Name, &s.Timezone, &s.RetentionDays, &s.Active, &s.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Create inserts a new school
+func (r *SchoolRepository) Create(s *models.School) error {
+	id, err := insertID(r.db, r.dialect, `
+		INSERT INTO schools (code, name, timezone, retention_days, active)
+		VALUES (?, ?, ?, ?, ?)
+	`, s.Code, s.Name, s.Timezone, s.RetentionDays, s.Active)
+	if err != nil {
+		return fmt.Errorf("failed to create school: %w", err)
+	}
+
+	s.ID = id
+	return nil
+}
+
+// GetByID retrieves a school by ID
+func (r *SchoolRepository) GetByID(id int) (*models.School, error) {
+	return r.get(`id = ?`, id)
+}
+
+// GetByCode retrieves a school by its code (ไม่สนตัวพิมพ์ เพราะมาจาก subdomain)
+func (r *SchoolRepository) GetByCode(code string) (*models.School, error) {
+	return r.get(`code = ?`, code)
+}
+
+func (r *SchoolRepository) get(where string, arg interface{}) (*models.School, error) {
+	s, err := scanSchool(r.db.QueryRow(`SELECT `+schoolColumns+` FROM schools WHERE `+where, arg))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("school not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get school: %w", err)
+	}
+	return s, nil
+}
+
+// List retrieves every school ordered by name
+func (r *SchoolRepository) List(activeOnly bool) ([]models.School, error) {
+	query := `SELECT ` + schoolColumns + ` FROM schools`
+	if activeOnly {
+		query += ` WHERE active = TRUE`
+	}
+	query += ` ORDER BY name ASC, id ASC`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schools: %w", err)
+	}
+	defer rows.Close()
+
+	schools := []models.School{}
+	for rows.Next() {
+		s, err := scanSchool(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan school: %w", err)
+		}
+		schools = append(schools, *s)
+	}
+
+	return schools, nil
+}
+
+// Update changes the configuration of a school
+func (r *SchoolRepository) Update(s *models.School) error {
+	_, err := r.db.Exec(`
+		UPDATE schools SET code = ?, name = ?, timezone = ?, retention_days = ?, active = ?
+		WHERE id = ?
+	`, s.Code, s.Name, s.Timezone, s.RetentionDays, s.Active, s.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update school: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+)
+
+func TestSchoolRepository(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *sql.DB) {
+		repo := NewSchoolRepository(db)
+
+		main, err := repo.GetByID(models.DefaultSchoolID)
+		if err != nil || main.Code != "main" || !main.Active || main.Timezone != "" {
+			t.Fatalf("default school = %+v, %v", main, err)
+		}
+
+		school := &models.School{Code: "school-b", Name: "โรงเรียนบ้านเหนือ", Timezone: "Asia/Bangkok", RetentionDays: 90, Active: true}
+		if err := repo.Create(school); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.Create(&models.School{Code: "SCHOOL-B", Name: "ซ้ำ"}); err == nil {
+			t.Error("duplicate school code should fail")
+		}
+
+		got, err := repo.GetByCode("School-B")
+		if err != nil || got.ID != school.ID || got.RetentionDays != 90 {
+			t.Errorf("GetByCode() = %+v, %v", got, err)
+		}
+
+		got.Active = false
+		if err := repo.Update(got); err != nil {
+			t.Fatal(err)
+		}
+		if active, err := repo.List(true); err != nil || len(active) != 1 || active[0].ID != models.DefaultSchoolID {
+			t.Errorf("List(active) = %+v, %v", active, err)
+		}
+		if all, err := repo.List(false); err != nil || len(all) != 2 || all[1].Active {
+			t.Errorf("List(all) = %+v, %v", all, err)
+		}
+	})
+}
+
+// ข้อมูลของแต่ละโรงเรียนแยกกัน บัตรประชาชนและบัตร RFID เดียวกันใช้ได้ทั้งสองโรงเรียน
+func TestSchoolScoping(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *sql.DB) {
+		now := testNow
+		clk := testClock(t, &now)
+		other := &models.School{Code: "school-b", Name: "โรงเรียนบ้านเหนือ", Active: true}
+		if err := NewSchoolRepository(db).Create(other); err != nil {
+			t.Fatal(err)
+		}
+
+		schoolA := NewVisitorRepository(db, clk)
+		schoolB := NewVisitorRepository(db, clk).ForSchool(other.ID)
+		visits := seedVisits(t, db, schoolA)
+
+		v := testVisitor(1)
+		if err := schoolB.Create(v); err != nil {
+			t.Fatalf("same ID card at another school: %v", err)
+		}
+		if exists, err := schoolB.CheckRFIDExists(visits[1].RFID); err != nil || exists {
+			t.Errorf("CheckRFIDExists() of school A's card at school B = %v, %v", exists, err)
+		}
+
+		if list, err := schoolB.List(models.QueryParams{}); err != nil || len(list) != 1 || list[0].ID != v.ID {
+			t.Errorf("school B List() = %v, %v", visitorIDs(list), err)
+		}
+		if list, err := schoolA.List(models.QueryParams{}); err != nil || len(list) != 3 {
+			t.Errorf("school A List() = %v, %v", visitorIDs(list), err)
+		}
+		if _, err := schoolB.GetByID(visits[0].ID); err == nil {
+			t.Error("school B can read school A's visitor")
+		}
+		if _, err := schoolB.GetByRFID(visits[1].RFID); err == nil {
+			t.Error("school B can find school A's card")
+		}
+		if logs, err := schoolB.GetReturnLogs("", "", "", ""); err != nil || len(logs) != 0 {
+			t.Errorf("school B return logs = %+v, %v", logs, err)
+		}
+
+		totals, err := NewStatsRepository(db, clk).ForSchool(other.ID).Totals(time.Time{}, time.Now().Add(time.Hour))
+		if err != nil || totals.Visits != 1 {
+			t.Errorf("school B totals = %+v, %v", totals, err)
+		}
+	})
+}
+
+func TestPurgeRegisteredBefore(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *sql.DB) {
+		now := testNow
+		repo := NewVisitorRepository(db, testClock(t, &now))
+		visits := seedVisits(t, db, repo)
+		if _, _, err := repo.SetPhoto(visits[2].ID, "photos/3.jpg", "photos/3_thumb.jpg"); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, v := range visits {
+			edited, err := repo.GetByID(v.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			edited.Phone = "0899999999"
+			rev := &models.VisitorRevision{Action: models.RevisionUpdate, Changes: []models.VisitorChange{{Field: "phone", Old: v.Phone, New: edited.Phone}}, ChangedBy: "ธุรการ"}
+			if err := repo.Update(edited, rev); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// โรงเรียนอื่นไม่ถูกลบ
+		other := NewVisitorRepository(db, repo.clock).ForSchool(2)
+		if _, purged, err := other.PurgeRegisteredBefore(now); err != nil || purged != 0 {
+			t.Errorf("purge of an empty school = %d, %v", purged, err)
+		}
+
+		// ก่อน 09:00 วันนี้ (02:00 UTC): visitor 1 และ 3
+		keys, purged, err := repo.PurgeRegisteredBefore(time.Date(2025, 6, 2, 2, 0, 0, 0, time.UTC))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if purged != 2 || len(keys) != 2 {
+			t.Errorf("purged %d visits, photos %v, want 2 visits and 2 photos", purged, keys)
+		}
+
+		list, err := repo.List(models.QueryParams{})
+		if err != nil || len(list) != 1 || list[0].ID != visits[1].ID {
+			t.Errorf("remaining = %v, %v", visitorIDs(list), err)
+		}
+		if logs, err := repo.GetReturnLogs("", "", "", ""); err != nil || len(logs) != 1 {
+			t.Errorf("return logs of the remaining visit = %+v, %v", logs, err)
+		}
+
+		// ค่าเดิมในประวัติการแก้ไขไม่ค้างอยู่หลังลบ
+		var revisions int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM visitor_revisions WHERE visitor_id IN (?, ?)`, visits[0].ID, visits[2].ID).Scan(&revisions); err != nil || revisions != 0 {
+			t.Errorf("revisions of purged visits = %d, %v", revisions, err)
+		}
+		if list, err := repo.ListRevisions(visits[1].ID); err != nil || len(list) != 1 {
+			t.Errorf("revisions of the remaining visit = %+v, %v", list, err)
+		}
+	})
+}
@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"backend/internal/clock"
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/search"
+)
+
+const (
+	visitorSearchColumns   = `first_name, last_name, id_card, phone, license_plate, rfid`
+	returnLogSearchColumns = `name, card_id`
+
+	// phoneDigits คือเบอร์โทรที่ตัดขีด จุด และช่องว่างออก เหมือนคำค้นที่ผ่าน search.Parse
+	phoneDigits = `REPLACE(REPLACE(REPLACE(phone, '-', ''), '.', ''), ' ', '')`
+)
+
+// visitorLikeColumns are the visitor fields matched with LIKE (ค้นหาและตัวกรองรายการ)
+var visitorLikeColumns = []string{"first_name", "last_name", "id_card", phoneDigits, "license_plate", "rfid"}
+
+type SearchRepository struct {
+	db      *sql.DB
+	dialect database.Dialect
+	clock   *clock.Clock
+	school  int
+}
+
+func NewSearchRepository(db *sql.DB, clk *clock.Clock) *SearchRepository {
+	return &SearchRepository{db: db, dialect: database.DialectOf(db), clock: clk, school: models.DefaultSchoolID}
+}
+
+// ForSchool returns a copy of the repository that searches the data of school id
+func (r *SearchRepository) ForSchool(id int) *SearchRepository {
+	scoped := *r
+	scoped.school = id
+	return &scoped
+}
+
+// SearchVisitors finds visitors by name, ID card, phone, licence plate or RFID, best match first
+func (r *SearchRepository) SearchVisitors(q search.Query, limit int) ([]models.VisitorSearchHit, error) {
+	if q.IsEmpty() {
+		return []models.VisitorSearchHit{}, nil
+	}
+
+	fullText := r.dialect.FullText() && q.UsesFullText()
+	score, scoreArgs := visitorScore(q, r.dialect, fullText)
+	where, whereArgs := matchCondition(q, r.dialect, fullText, visitorSearchColumns, visitorLikeColumns)
+
+	query := `
+		SELECT id, id_card, first_name, last_name, phone, license_plate, rfid, department,
+			registered_at, exit_time, ` + score + ` AS score
+		FROM visitors
+		WHERE school_id = ? AND deleted_at IS NULL AND ` + where + `
+		ORDER BY score DESC, registered_at DESC
+		LIMIT ?
+	`
+
+	args := append(scoreArgs, r.school)
+	args = append(args, whereArgs...)
+	args = append(args, limit)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search visitors: %w", err)
+	}
+	defer rows.Close()
+
+	hits := []models.VisitorSearchHit{}
+	for rows.Next() {
+		var hit models.VisitorSearchHit
+		var firstName, lastName string
+		var licensePlate, rfid sql.NullString
+		var registeredAt time.Time
+		var exitTime sql.NullTime
+
+		err := rows.Scan(
+			&hit.ID,
+			&hit.IDCard,
+			&firstName,
+			&lastName,
+			&hit.Phone,
+			&licensePlate,
+			&rfid,
+			&hit.Department,
+			&registeredAt,
+			&exitTime,
+			&hit.Score,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan visitor search hit: %w", err)
+		}
+
+		hit.Name = firstName + " " + lastName
+		hit.LicensePlate = licensePlate.String
+		hit.RFID = rfid.String
+		hit.RegisteredAt = r.clock.In(registeredAt).Format("02/01/2006 15:04:05")
+		hit.ExitTime = "-"
+		if exitTime.Valid {
+			hit.ExitTime = r.clock.In(exitTime.Time).Format("02/01/2006 15:04:05")
+		}
+
+		hits = append(hits, hit)
+	}
+
+	return hits, nil
+}
+
+// SearchReturnLogs finds return card logs by visitor name or card ID, best match first
+func (r *SearchRepository) SearchReturnLogs(q search.Query, limit int) ([]models.ReturnLogSearchHit, error) {
+	if q.IsEmpty() {
+		return []models.ReturnLogSearchHit{}, nil
+	}
+
+	fullText := r.dialect.FullText() && q.UsesFullText()
+	score, scoreArgs := returnLogScore(q, r.dialect, fullText)
+	where, whereArgs := matchCondition(q, r.dialect, fullText, returnLogSearchColumns, []string{"name", "card_id"})
+
+	query := `
+		SELECT id, visitor_id, card_id, name, check_in, check_out, return_date, status,
+			` + score + ` AS score
+		FROM return_card_logs
+		WHERE school_id = ? AND voided_at IS NULL AND ` + where + `
+		ORDER BY score DESC, created_at DESC
+		LIMIT ?
+	`
+
+	args := append(scoreArgs, r.school)
+	args = append(args, whereArgs...)
+	args = append(args, limit)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search return logs: %w", err)
+	}
+	defer rows.Close()
+
+	hits := []models.ReturnLogSearchHit{}
+	for rows.Next() {
+		var hit models.ReturnLogSearchHit
+		var returnDate time.Time
+
+		err := rows.Scan(
+			&hit.ID,
+			&hit.VisitorID,
+			&hit.CardID,
+			&hit.Name,
+			&hit.TimeIn,
+			&hit.TimeOut,
+			&returnDate,
+			&hit.Status,
+			&hit.Score,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan return log search hit: %w", err)
+		}
+
+		hit.Date = returnDate.Format("02/01/2006")
+		hits = append(hits, hit)
+	}
+
+	return hits, nil
+}
+
+// matchCondition ใช้ดัชนี FULLTEXT เมื่อทุก term ยาวพอสำหรับ ngram (และฐานข้อมูลมีดัชนีนี้)
+// ไม่งั้นใช้ LIKE แทน (ทุก term ต้องตรงอย่างน้อยหนึ่งฟิลด์)
+func matchCondition(q search.Query, dialect database.Dialect, fullText bool, fullTextColumns string, likeColumns []string) (string, []interface{}) {
+	if fullText {
+		return `MATCH(` + fullTextColumns + `) AGAINST (? IN BOOLEAN MODE)`, []interface{}{q.BooleanMode()}
+	}
+
+	return likeCondition(q, dialect, likeColumns)
+}
+
+// likeCondition builds "every term matches at least one column" using LIKE
+func likeCondition(q search.Query, dialect database.Dialect, columns []string) (string, []interface{}) {
+	clauses := make([]string, 0, len(q.Terms))
+	args := []interface{}{}
+
+	for _, term := range q.Terms {
+		ors := make([]string, 0, len(columns))
+		for _, column := range columns {
+			ors = append(ors, dialect.Like(column))
+			args = append(args, search.Contains(term))
+		}
+		clauses = append(clauses, `(`+strings.Join(ors, ` OR `)+`)`)
+	}
+
+	return strings.Join(clauses, ` AND `), args
+}
+
+// visitorScore จัดอันดับผลลัพธ์: ตรงเลขบัตร/RFID ทั้งหมด > ชื่อเต็มขึ้นต้นด้วยคำค้น > เบอร์/ทะเบียนขึ้นต้นด้วยคำค้น
+// แล้วบวกคะแนนความเกี่ยวข้องจาก FULLTEXT
+func visitorScore(q search.Query, dialect database.Dialect, fullText bool) (string, []interface{}) {
+	score := `(CASE WHEN id_card = ? OR rfid = ? THEN 100 ELSE 0 END)
+			+ (CASE WHEN ` + dialect.Like(`CONCAT(first_name, ' ', last_name)`) + ` THEN 50 ELSE 0 END)
+			+ (CASE WHEN ` + dialect.Like(phoneDigits) + ` OR ` + dialect.Like(`license_plate`) + ` THEN 20 ELSE 0 END)`
+	args := []interface{}{
+		q.Phrase, q.Phrase,
+		search.Prefix(q.Phrase),
+		search.Prefix(q.Phrase), search.Prefix(q.Phrase),
+	}
+
+	if fullText {
+		score += `
+			+ MATCH(` + visitorSearchColumns + `) AGAINST (? IN BOOLEAN MODE)`
+		args = append(args, q.BooleanMode())
+	}
+
+	return score, args
+}
+
+func returnLogScore(q search.Query, dialect database.Dialect, fullText bool) (string, []interface{}) {
+	score := `(CASE WHEN card_id = ? THEN 100 ELSE 0 END)
+			+ (CASE WHEN ` + dialect.Like(`name`) + ` THEN 50 ELSE 0 END)`
+	args := []interface{}{q.Phrase, search.Prefix(q.Phrase)}
+
+	if fullText {
+		score += `
+			+ MATCH(` + returnLogSearchColumns + `) AGAINST (? IN BOOLEAN MODE)`
+		args = append(args, q.BooleanMode())
+	}
+
+	return score, args
+}
@@ -0,0 +1,231 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"backend/internal/clock"
+	"backend/internal/database"
+	"backend/internal/models"
+)
+
+type StatsRepository struct {
+	db      *sql.DB
+	dialect database.Dialect
+	clock   *clock.Clock
+	school  int
+}
+
+func NewStatsRepository(db *sql.DB, clk *clock.Clock) *StatsRepository {
+	return &StatsRepository{db: db, dialect: database.DialectOf(db), clock: clk, school: models.DefaultSchoolID}
+}
+
+// ForSchool returns a copy of the repository that summarises the visits of school id
+func (r *StatsRepository) ForSchool(id int) *StatsRepository {
+	scoped := *r
+	scoped.school = id
+	return &scoped
+}
+
+// localRegisteredAt แปลง registered_at (UTC) เป็นเวลาโรงเรียนด้วย offset เพื่อไม่ต้องพึ่งตาราง timezone ของ MySQL
+// ใช้ offset ของวันแรกในช่วง เขตเวลาที่มีเวลาฤดูร้อนจะคลาดหนึ่งชั่วโมงหลังวันเปลี่ยนเวลา (Asia/Bangkok ไม่มี)
+func localRegisteredAt(dialect database.Dialect) string {
+	return dialect.LocalTime(`registered_at`)
+}
+
+// statsBucketExpr returns the SQL for the first day of the bucket of each visit as YYYY-MM-DD.
+// ทุก ? ในผลลัพธ์คือ offset ของเวลาโรงเรียน
+func statsBucketExpr(dialect database.Dialect, groupBy string) string {
+	switch groupBy {
+	case models.StatsByWeek:
+		return dialect.WeekStart(localRegisteredAt(dialect))
+	case models.StatsByMonth:
+		return dialect.MonthStart(localRegisteredAt(dialect))
+	}
+	return dialect.Day(localRegisteredAt(dialect))
+}
+
+// Summary computes the statistics of visits registered in the date range
+func (r *StatsRepository) Summary(p models.StatsParams) (*models.StatsResponse, error) {
+	start, err := r.clock.StartOfDay(p.StartDate)
+	if err != nil {
+		return nil, err
+	}
+	end, err := r.clock.EndOfDay(p.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	offset := r.clock.Offset(start)
+
+	where := ` WHERE school_id = ? AND deleted_at IS NULL AND registered_at >= ? AND registered_at < ?`
+	whereArgs := []interface{}{r.school, start, end}
+	if p.DepartmentID != 0 {
+		where += ` AND department_id = ?`
+		whereArgs = append(whereArgs, p.DepartmentID)
+	}
+
+	stats := &models.StatsResponse{
+		StartDate: p.StartDate,
+		EndDate:   p.EndDate,
+		GroupBy:   p.GroupBy,
+	}
+
+	if stats.Totals, err = r.totals(where, whereArgs); err != nil {
+		return nil, err
+	}
+
+	// ช่วงเวลา (วัน/สัปดาห์/เดือน)
+	bucketExpr := statsBucketExpr(r.dialect, p.GroupBy)
+	bucketArgs := []interface{}{}
+	for range strings.Count(bucketExpr, "?") {
+		bucketArgs = append(bucketArgs, offset)
+	}
+	rows, err := r.db.Query(`
+		SELECT `+bucketExpr+` AS bucket, COUNT(*), COUNT(exit_time)
+		FROM visitors`+where+`
+		GROUP BY bucket`, append(bucketArgs, whereArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get visits per %s: %w", p.GroupBy, err)
+	}
+	counts := map[string]models.StatsBucket{}
+	for rows.Next() {
+		var b models.StatsBucket
+		if err := rows.Scan(&b.Start, &b.Visits, &b.CardsReturned); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan visits per %s: %w", p.GroupBy, err)
+		}
+		counts[b.Start] = b
+	}
+	rows.Close()
+
+	// ใส่ช่วงที่ไม่มีผู้มาติดต่อเป็น 0 ให้กราฟต่อเนื่อง
+	stats.Series = []models.StatsBucket{}
+	for _, bucketStart := range statsBuckets(p.StartDate, p.EndDate, p.GroupBy) {
+		b := counts[bucketStart]
+		b.Start = bucketStart
+		stats.Series = append(stats.Series, b)
+	}
+
+	if stats.Departments, err = r.countBy("department", where, whereArgs); err != nil {
+		return nil, err
+	}
+	if stats.Provinces, err = r.countBy("province", where, whereArgs); err != nil {
+		return nil, err
+	}
+
+	// ชั่วโมงที่มาถึง
+	stats.Hours = make([]models.StatsHour, 24)
+	for h := range stats.Hours {
+		stats.Hours[h].Hour = h
+	}
+	rows, err = r.db.Query(`
+		SELECT `+r.dialect.Hour(localRegisteredAt(r.dialect))+` AS hour, COUNT(*)
+		FROM visitors`+where+`
+		GROUP BY hour`, append([]interface{}{offset}, whereArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get visits per hour: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var hour, visits int
+		if err := rows.Scan(&hour, &visits); err != nil {
+			return nil, fmt.Errorf("failed to scan visits per hour: %w", err)
+		}
+		if hour >= 0 && hour < 24 {
+			stats.Hours[hour].Visits = visits
+		}
+	}
+	for h, hour := range stats.Hours {
+		if hour.Visits > 0 && (stats.PeakHour == nil || hour.Visits > stats.Hours[*stats.PeakHour].Visits) {
+			peak := h
+			stats.PeakHour = &peak
+		}
+	}
+
+	return stats, nil
+}
+
+// Totals computes the totals of visits registered in [from, before) (รายงานระดับเขต)
+func (r *StatsRepository) Totals(from, before time.Time) (models.StatsTotals, error) {
+	return r.totals(` WHERE school_id = ? AND deleted_at IS NULL AND registered_at >= ? AND registered_at < ?`, []interface{}{r.school, from, before})
+}
+
+// totals: จำนวนครั้ง คืนบัตรแล้ว/ยังไม่คืน และเวลาเฉลี่ยของคนที่ออกแล้ว
+func (r *StatsRepository) totals(where string, args []interface{}) (models.StatsTotals, error) {
+	var totals models.StatsTotals
+	err := r.db.QueryRow(`
+		SELECT COUNT(*),
+			COUNT(exit_time),
+			COALESCE(SUM(CASE WHEN exit_time IS NULL AND rfid IS NOT NULL THEN 1 ELSE 0 END), 0),
+			COALESCE(AVG(CASE WHEN exit_time IS NOT NULL THEN `+r.dialect.MinutesBetween(`registered_at`, `exit_time`)+` END), 0)
+		FROM visitors`+where, args...).Scan(
+		&totals.Visits,
+		&totals.CardsReturned,
+		&totals.CardsUnreturned,
+		&totals.AverageDurationMinutes,
+	)
+	if err != nil {
+		return totals, fmt.Errorf("failed to get visit totals: %w", err)
+	}
+	return totals, nil
+}
+
+// countBy counts visits per value of column, most visits first
+func (r *StatsRepository) countBy(column, where string, args []interface{}) ([]models.StatsCount, error) {
+	rows, err := r.db.Query(`
+		SELECT COALESCE(NULLIF(`+column+`, ''), 'ไม่ระบุ') AS name, COUNT(*) AS visits
+		FROM visitors`+where+`
+		GROUP BY name
+		ORDER BY visits DESC, name ASC`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count visits by %s: %w", column, err)
+	}
+	defer rows.Close()
+
+	counts := []models.StatsCount{}
+	for rows.Next() {
+		var c models.StatsCount
+		if err := rows.Scan(&c.Name, &c.Visits); err != nil {
+			return nil, fmt.Errorf("failed to scan visits by %s: %w", column, err)
+		}
+		counts = append(counts, c)
+	}
+
+	return counts, nil
+}
+
+// statsBuckets lists the first day of every bucket that overlaps [startDate, endDate]
+func statsBuckets(startDate, endDate, groupBy string) []string {
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return nil
+	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return nil
+	}
+
+	day := start
+	switch groupBy {
+	case models.StatsByWeek:
+		day = day.AddDate(0, 0, -(int(day.Weekday())+6)%7) // ถอยไปวันจันทร์
+	case models.StatsByMonth:
+		day = time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	buckets := []string{}
+	for !day.After(end) {
+		buckets = append(buckets, day.Format("2006-01-02"))
+		switch groupBy {
+		case models.StatsByWeek:
+			day = day.AddDate(0, 0, 7)
+		case models.StatsByMonth:
+			day = day.AddDate(0, 1, 0)
+		default:
+			day = day.AddDate(0, 0, 1)
+		}
+	}
+	return buckets
+}
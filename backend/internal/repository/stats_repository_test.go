@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"backend/internal/models"
+)
+
+// กราฟต้องมีครบทุกช่วง แม้ช่วงนั้นไม่มีผู้มาติดต่อ และช่วงแรกเริ่มที่ต้นสัปดาห์/ต้นเดือน
+func TestStatsBuckets(t *testing.T) {
+	tests := []struct {
+		groupBy    string
+		start, end string
+		want       []string
+	}{
+		{models.StatsByDay, "2025-02-27", "2025-03-02", []string{"2025-02-27", "2025-02-28", "2025-03-01", "2025-03-02"}},
+		// 2025-06-04 เป็นวันพุธ สัปดาห์เริ่มวันจันทร์
+		{models.StatsByWeek, "2025-06-04", "2025-06-16", []string{"2025-06-02", "2025-06-09", "2025-06-16"}},
+		// วันอาทิตย์ยังอยู่ในสัปดาห์ที่เริ่มวันจันทร์ก่อนหน้า
+		{models.StatsByWeek, "2025-06-08", "2025-06-08", []string{"2025-06-02"}},
+		{models.StatsByMonth, "2024-12-15", "2025-02-01", []string{"2024-12-01", "2025-01-01", "2025-02-01"}},
+		{models.StatsByMonth, "2025-01-31", "2025-03-01", []string{"2025-01-01", "2025-02-01", "2025-03-01"}},
+	}
+
+	for _, tt := range tests {
+		if got := statsBuckets(tt.start, tt.end, tt.groupBy); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("statsBuckets(%s, %s, %s) = %v, want %v", tt.start, tt.end, tt.groupBy, got, tt.want)
+		}
+	}
+}
+
+// ช่วงและชั่วโมงนับตามเวลาโรงเรียน visitor 3 มาเวลา 23:30 ของวันที่ 1 (16:30 UTC)
+func TestStatsSummary(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *sql.DB) {
+		now := testNow
+		clk := testClock(t, &now)
+		seedVisits(t, db, NewVisitorRepository(db, clk))
+		repo := NewStatsRepository(db, clk)
+
+		series := map[string][]models.StatsBucket{
+			models.StatsByDay:   {{Start: "2025-06-01", Visits: 1}, {Start: "2025-06-02", Visits: 2, CardsReturned: 1}},
+			models.StatsByWeek:  {{Start: "2025-05-26", Visits: 1}, {Start: "2025-06-02", Visits: 2, CardsReturned: 1}},
+			models.StatsByMonth: {{Start: "2025-06-01", Visits: 3, CardsReturned: 1}},
+		}
+		for groupBy, want := range series {
+			stats, err := repo.Summary(models.StatsParams{StartDate: "2025-06-01", EndDate: "2025-06-02", GroupBy: groupBy})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(stats.Series, want) {
+				t.Errorf("%s series = %+v, want %+v", groupBy, stats.Series, want)
+			}
+		}
+
+		stats, err := repo.Summary(models.StatsParams{StartDate: "2025-06-01", EndDate: "2025-06-02", GroupBy: models.StatsByDay})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := models.StatsTotals{Visits: 3, CardsReturned: 1, CardsUnreturned: 1, AverageDurationMinutes: 60}
+		if stats.Totals != want {
+			t.Errorf("totals = %+v, want %+v", stats.Totals, want)
+		}
+		for _, hour := range []int{8, 9, 23} {
+			if stats.Hours[hour].Visits != 1 {
+				t.Errorf("visits at %02d:00 = %d, want 1", hour, stats.Hours[hour].Visits)
+			}
+		}
+		wantDepartments := []models.StatsCount{{Name: "ฝ่ายวิชาการ", Visits: 2}, {Name: "ฝ่ายบุคคล", Visits: 1}}
+		if !reflect.DeepEqual(stats.Departments, wantDepartments) {
+			t.Errorf("departments = %+v, want %+v", stats.Departments, wantDepartments)
+		}
+
+		byDepartment, err := repo.Summary(models.StatsParams{StartDate: "2025-06-02", EndDate: "2025-06-02", GroupBy: models.StatsByDay, DepartmentID: testDepartment(t, db, models.DefaultSchoolID, "ฝ่ายบุคคล")})
+		if err != nil || byDepartment.Totals.Visits != 0 || byDepartment.PeakHour != nil {
+			t.Errorf("ฝ่ายบุคคล on 2025-06-02 = %+v, %v", byDepartment, err)
+		}
+	})
+}
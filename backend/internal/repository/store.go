@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"time"
+
+	"backend/internal/models"
+)
+
+// VisitorStore stores visits. VisitorRepository เป็นตัวจริงบน MySQL/SQLite ส่วน memory.Store ใช้ทดสอบ
+type VisitorStore interface {
+	// Create inserts the visitor and sets its ID. ถ้ามีรถจะบันทึกรถ ถ้ามาตามนัดจะผูกนัดหมาย (ErrAppointmentNotPending)
+	Create(visitor *models.Visitor) error
+	GetByID(id int) (*models.Visitor, error)
+	GetByRFID(rfid string) (*models.Visitor, error)
+	GetByClientID(clientID string) (*models.Visitor, error)
+	List(params models.QueryParams) ([]models.Visitor, error)
+	GetVisitorsForExport(params models.QueryParams) ([]models.Visitor, error)
+	// ListOnSite returns visitors registered in [from, before) who have not returned their card
+	ListOnSite(from, before time.Time) ([]models.Visitor, error)
+	CheckIDCardExists(idCard string) (bool, error)
+	CheckRFIDExists(rfid string) (bool, error)
+
+	ListPendingApprovals(hostName string) ([]models.Visitor, error)
+	// SetApproval returns ErrNotPendingApproval when the visit is not waiting for approval
+	SetApproval(id int, status, approvedBy, note string) error
+	// IssueCard returns ErrCardNotIssuable when the visit cannot get a card yet
+	IssueCard(id int, rfid string) error
+	SetPhoto(id int, photoKey, thumbnailKey string) (oldPhotoKey, oldThumbnailKey string, err error)
+
+	// Update and Delete (soft) save the visit only if it is still at visitor.Version, with the revision,
+	// otherwise return ErrVersionConflict
+	Update(visitor *models.Visitor, revision *models.VisitorRevision) error
+	Delete(visitor *models.Visitor, revision *models.VisitorRevision) error
+	ListRevisions(visitorID int) ([]models.VisitorRevision, error)
+}
+
+// ReturnLogStore stores card returns
+type ReturnLogStore interface {
+	// CheckDuplicateReturn reports whether the card was already returned today (school-local)
+	CheckDuplicateReturn(cardID string) (bool, error)
+	// CreateReturnLog records the return and sets the visitor's exit time together.
+	// Returns ErrDuplicateReturn when the card was already returned that day, checked atomically with the insert
+	CreateReturnLog(log *models.ReturnCardLog) error
+	// GetReturnLogByClientID retrieves the return recorded with a client ID (Idempotency-Key), or ErrReturnLogNotFound
+	GetReturnLogByClientID(clientID string) (*models.ReturnCardLog, error)
+	// CheckReturnLogExists reports whether a return with this client ID was already recorded (sync จากเครื่อง edge)
+	CheckReturnLogExists(clientID string) (bool, error)
+	// VoidReturnLog marks a mistaken return as voided and reopens the visit; revision gives who and why
+	VoidReturnLog(id int, revision *models.VisitorRevision) (*models.ReturnCardLog, error)
+	GetReturnLogs(search, startDate, endDate, sortOrder string) ([]models.ReturnCardLog, error)
+}
+
+// ExportStore stores the export history
+type ExportStore interface {
+	GetHistory() ([]models.ExportRecord, error)
+	Create(record *models.ExportRecord) (*models.ExportRecord, error)
+	GetByID(id int) (*models.ExportRecord, error)
+}
+
+// DepartmentStore stores the departments (ส่วนงาน) of a school
+type DepartmentStore interface {
+	List(activeOnly bool) ([]models.Department, error)
+	GetByID(id int) (*models.Department, error)
+	// GetByName matches the Thai name or the English name case-insensitively
+	GetByName(name string) (*models.Department, error)
+	Create(department *models.Department) error
+	Update(department *models.Department) error
+	// Delete returns ErrDepartmentInUse when visits refer to the department
+	Delete(id int) error
+}
+
+// WatchlistStore stores the watchlist (รายชื่อเฝ้าระวัง) of a school
+type WatchlistStore interface {
+	List(activeOnly bool) ([]models.WatchlistEntry, error)
+	GetByID(id int) (*models.WatchlistEntry, error)
+	GetByIDCard(idCard string) (*models.WatchlistEntry, error)
+	// Match returns the active entry of idCard, or nil when the person is not watched
+	Match(idCard string) (*models.WatchlistEntry, error)
+	Create(entry *models.WatchlistEntry) error
+	Update(entry *models.WatchlistEntry) error
+	Delete(id int) error
+}
+
+var (
+	_ VisitorStore    = (*VisitorRepository)(nil)
+	_ ReturnLogStore  = (*VisitorRepository)(nil)
+	_ ExportStore     = (*ExportRepository)(nil)
+	_ DepartmentStore = (*DepartmentRepository)(nil)
+	_ WatchlistStore  = (*WatchlistRepository)(nil)
+)
@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"backend/internal/clock"
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/search"
+)
+
+type StudentRepository struct {
+	db      *sql.DB
+	dialect database.Dialect
+	clock   *clock.Clock
+	school  int
+}
+
+func NewStudentRepository(db *sql.DB, clk *clock.Clock) *StudentRepository {
+	return &StudentRepository{db: db, dialect: database.DialectOf(db), clock: clk, school: models.DefaultSchoolID}
+}
+
+// ForSchool returns a copy of the repository that reads and writes the students of school id.
+// สิทธิ์รับนักเรียนไม่มี school_id ของตัวเอง กรองผ่านนักเรียนเจ้าของสิทธิ์
+func (r *StudentRepository) ForSchool(id int) *StudentRepository {
+	scoped := *r
+	scoped.school = id
+	return &scoped
+}
+
+// validAuthorisation คือเงื่อนไขสิทธิ์ที่ยังใช้ได้วันนี้ (เริ่มแล้ว ยังไม่หมดอายุ และไม่ถูกเพิกถอน)
+// ต้องส่งวันนี้ตามเวลาของโรงเรียนเป็น argument สองตัวสุดท้าย
+const validAuthorisation = `
+	ga.revoked_at IS NULL
+	AND ga.valid_from <= ?
+	AND (ga.valid_until IS NULL OR ga.valid_until >= ?)
+`
+
+// Create inserts a new student
+func (r *StudentRepository) Create(student *models.Student) error {
+	id, err := insertID(r.db, r.dialect, `
+		INSERT INTO students (school_id, student_code, first_name, last_name, class_room, active)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, r.school, student.StudentCode, student.FirstName, student.LastName, student.ClassRoom, student.Active)
+	if err != nil {
+		return fmt.Errorf("failed to create student: %w", err)
+	}
+
+	student.ID = id
+	return nil
+}
+
+// GetByID retrieves a student by ID
+func (r *StudentRepository) GetByID(id int) (*models.Student, error) {
+	var s models.Student
+	err := r.db.QueryRow(`
+		SELECT id, student_code, first_name, last_name, class_room, active, created_at
+		FROM students WHERE id = ? AND school_id = ?
+	`, id, r.school).Scan(&s.ID, &s.StudentCode, &s.FirstName, &s.LastName, &s.ClassRoom, &s.Active, &s.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("student not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get student: %w", err)
+	}
+
+	return &s, nil
+}
+
+// List retrieves active students matching the search text (name, code or class)
+func (r *StudentRepository) List(searchText string) ([]models.Student, error) {
+	query := `
+		SELECT id, student_code, first_name, last_name, class_room, active, created_at
+		FROM students WHERE school_id = ? AND active = TRUE
+	`
+	args := []interface{}{r.school}
+
+	if q := search.Parse(searchText); !q.IsEmpty() {
+		condition, searchArgs := likeCondition(q, r.dialect, []string{"student_code", "first_name", "last_name", "class_room"})
+		query += ` AND ` + condition
+		args = append(args, searchArgs...)
+	}
+	query += ` ORDER BY class_room ASC, first_name ASC, last_name ASC`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list students: %w", err)
+	}
+	defer rows.Close()
+
+	students := []models.Student{}
+	for rows.Next() {
+		var s models.Student
+		if err := rows.Scan(&s.ID, &s.StudentCode, &s.FirstName, &s.LastName, &s.ClassRoom, &s.Active, &s.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan student: %w", err)
+		}
+		students = append(students, s)
+	}
+
+	return students, nil
+}
+
+// Authorise adds a guardian who may collect the student
+func (r *StudentRepository) Authorise(auth *models.GuardianAuthorisation) error {
+	id, err := insertID(r.db, r.dialect, `
+		INSERT INTO guardian_authorisations (student_id, id_card, guardian_name, relationship, valid_from, valid_until)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, auth.StudentID, auth.IDCard, auth.GuardianName, auth.Relationship, sqlDate(auth.ValidFrom), nullSQLDate(auth.ValidUntil))
+	if err != nil {
+		return fmt.Errorf("failed to authorise guardian: %w", err)
+	}
+
+	auth.ID = id
+	return nil
+}
+
+// Revoke withdraws a guardian authorisation of the student
+func (r *StudentRepository) Revoke(studentID, authorisationID int) error {
+	result, err := r.db.Exec(`
+		UPDATE guardian_authorisations SET revoked_at = ?
+		WHERE id = ? AND student_id = ? AND revoked_at IS NULL
+		AND student_id IN (SELECT id FROM students WHERE school_id = ?)
+	`, r.clock.Now(), authorisationID, studentID, r.school)
+	if err != nil {
+		return fmt.Errorf("failed to revoke guardian: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to revoke guardian: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("authorisation not found")
+	}
+
+	return nil
+}
+
+// ListGuardians retrieves every authorisation of the student, including expired and revoked ones
+func (r *StudentRepository) ListGuardians(studentID int) ([]models.GuardianAuthorisation, error) {
+	rows, err := r.db.Query(`
+		SELECT ga.id, ga.student_id, ga.id_card, ga.guardian_name, ga.relationship,
+			ga.valid_from, ga.valid_until, ga.revoked_at, ga.created_at
+		FROM guardian_authorisations ga
+		JOIN students s ON s.id = ga.student_id
+		WHERE ga.student_id = ? AND s.school_id = ?
+		ORDER BY ga.created_at DESC
+	`, studentID, r.school)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list guardians: %w", err)
+	}
+	defer rows.Close()
+
+	guardians := []models.GuardianAuthorisation{}
+	for rows.Next() {
+		var g models.GuardianAuthorisation
+		err := rows.Scan(&g.ID, &g.StudentID, &g.IDCard, &g.GuardianName, &g.Relationship,
+			&g.ValidFrom, &g.ValidUntil, &g.RevokedAt, &g.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan guardian: %w", err)
+		}
+		guardians = append(guardians, g)
+	}
+
+	return guardians, nil
+}
+
+// ListAuthorisedStudents retrieves the students the ID card holder may collect today
+func (r *StudentRepository) ListAuthorisedStudents(idCard string) ([]models.AuthorisedStudent, error) {
+	rows, err := r.db.Query(`
+		SELECT s.id, s.student_code, s.first_name, s.last_name, s.class_room, s.active, s.created_at,
+			ga.id, ga.relationship
+		FROM guardian_authorisations ga
+		JOIN students s ON s.id = ga.student_id
+		WHERE ga.id_card = ? AND s.school_id = ? AND s.active = TRUE AND `+validAuthorisation+`
+		ORDER BY s.class_room ASC, s.first_name ASC
+	`, idCard, r.school, r.clock.Today(), r.clock.Today())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list authorised students: %w", err)
+	}
+	defer rows.Close()
+
+	students := []models.AuthorisedStudent{}
+	for rows.Next() {
+		var a models.AuthorisedStudent
+		err := rows.Scan(&a.Student.ID, &a.Student.StudentCode, &a.Student.FirstName, &a.Student.LastName,
+			&a.Student.ClassRoom, &a.Student.Active, &a.Student.CreatedAt, &a.AuthorisationID, &a.Relationship)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan authorised student: %w", err)
+		}
+		students = append(students, a)
+	}
+
+	return students, nil
+}
+
+// FindValidAuthorisation returns the authorisation letting idCard collect the student today,
+// or nil when there is none
+func (r *StudentRepository) FindValidAuthorisation(studentID int, idCard string) (*models.GuardianAuthorisation, error) {
+	var g models.GuardianAuthorisation
+	err := r.db.QueryRow(`
+		SELECT ga.id, ga.student_id, ga.id_card, ga.guardian_name, ga.relationship,
+			ga.valid_from, ga.valid_until, ga.revoked_at, ga.created_at
+		FROM guardian_authorisations ga
+		JOIN students s ON s.id = ga.student_id
+		WHERE ga.student_id = ? AND ga.id_card = ? AND s.school_id = ? AND `+validAuthorisation+`
+		ORDER BY ga.created_at DESC
+		LIMIT 1
+	`, studentID, idCard, r.school, r.clock.Today(), r.clock.Today()).Scan(&g.ID, &g.StudentID, &g.IDCard, &g.GuardianName, &g.Relationship,
+		&g.ValidFrom, &g.ValidUntil, &g.RevokedAt, &g.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check authorisation: %w", err)
+	}
+
+	return &g, nil
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+)
+
+func TestStudentPickup(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *sql.DB) {
+		now := time.Now().UTC().Truncate(time.Second)
+		clk := testClock(t, &now)
+		students := NewStudentRepository(db, clk)
+		pickups := NewPickupRepository(db, clk)
+		visitors := NewVisitorRepository(db, clk)
+
+		student := &models.Student{StudentCode: "S001", FirstName: "ด.ญ.มานี", LastName: "มีนา", ClassRoom: "ป.1/1", Active: true}
+		if err := students.Create(student); err != nil {
+			t.Fatal(err)
+		}
+		if err := students.Create(&models.Student{StudentCode: "s001", FirstName: "ซ้ำ", Active: true}); err == nil {
+			t.Error("duplicate student code should fail")
+		}
+		if list, err := students.List("S00"); err != nil || len(list) != 1 || list[0].ID != student.ID {
+			t.Errorf("List(S00) = %+v, %v", list, err)
+		}
+
+		guardian := testVisitor(1)
+		if err := visitors.Create(guardian); err != nil {
+			t.Fatal(err)
+		}
+		today := clk.TodayDate()
+		yesterday := today.AddDate(0, 0, -1)
+		valid := &models.GuardianAuthorisation{StudentID: student.ID, IDCard: guardian.IDCard, GuardianName: "แม่", Relationship: "มารดา", ValidFrom: yesterday}
+		expired := &models.GuardianAuthorisation{StudentID: student.ID, IDCard: "3101234567890", GuardianName: "น้า", Relationship: "ญาติ", ValidFrom: yesterday, ValidUntil: &yesterday}
+		for _, auth := range []*models.GuardianAuthorisation{valid, expired} {
+			if err := students.Authorise(auth); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		authorised, err := students.ListAuthorisedStudents(guardian.IDCard)
+		if err != nil || len(authorised) != 1 || authorised[0].AuthorisationID != valid.ID || authorised[0].Relationship != "มารดา" {
+			t.Errorf("ListAuthorisedStudents() = %+v, %v", authorised, err)
+		}
+		if auth, err := students.FindValidAuthorisation(student.ID, expired.IDCard); err != nil || auth != nil {
+			t.Errorf("FindValidAuthorisation() of an expired guardian = %+v, %v", auth, err)
+		}
+		auth, err := students.FindValidAuthorisation(student.ID, guardian.IDCard)
+		if err != nil || auth == nil || !auth.ValidFrom.Equal(yesterday) || auth.ValidUntil != nil {
+			t.Fatalf("FindValidAuthorisation() = %+v, %v", auth, err)
+		}
+
+		picked := []models.StudentPickup{{StudentID: student.ID, VisitorID: guardian.ID, AuthorisationID: valid.ID, OfficerName: "ยามหน้าประตู"}}
+		if err := pickups.CreatePickups(picked, nil); err != nil {
+			t.Fatal(err)
+		}
+		list, err := pickups.ListPickups(clk.Today())
+		if err != nil || len(list) != 1 || list[0].ID != picked[0].ID || list[0].StudentName != "ด.ญ.มานี มีนา" || list[0].Relationship != "มารดา" {
+			t.Errorf("ListPickups(today) = %+v, %v", list, err)
+		}
+
+		alert := &models.PickupAlert{StudentID: student.ID, IDCard: expired.IDCard, VisitorName: "น้า", Reason: "สิทธิ์หมดอายุ", OfficerName: "ยามหน้าประตู"}
+		if err := pickups.CreateAlert(alert); err != nil {
+			t.Fatal(err)
+		}
+		if alerts, err := pickups.ListAlerts(true); err != nil || len(alerts) != 1 || alerts[0].VisitorID != nil {
+			t.Errorf("ListAlerts(true) = %+v, %v", alerts, err)
+		}
+		if err := pickups.AcknowledgeAlert(alert.ID); err != nil {
+			t.Fatal(err)
+		}
+		if err := pickups.AcknowledgeAlert(alert.ID); err == nil {
+			t.Error("acknowledging twice should fail")
+		}
+		if alerts, _ := pickups.ListAlerts(true); len(alerts) != 0 {
+			t.Errorf("ListAlerts(true) after acknowledging = %+v", alerts)
+		}
+
+		if err := students.Revoke(student.ID, valid.ID); err != nil {
+			t.Fatal(err)
+		}
+		if err := students.Revoke(student.ID, valid.ID); err == nil {
+			t.Error("revoking twice should fail")
+		}
+		guardians, err := students.ListGuardians(student.ID)
+		if err != nil || len(guardians) != 2 {
+			t.Fatalf("ListGuardians() = %+v, %v", guardians, err)
+		}
+		for _, g := range guardians {
+			if (g.ID == valid.ID) != (g.RevokedAt != nil) {
+				t.Errorf("guardian %d revoked at = %v", g.ID, g.RevokedAt)
+			}
+		}
+		if authorised, _ := students.ListAuthorisedStudents(guardian.IDCard); len(authorised) != 0 {
+			t.Errorf("ListAuthorisedStudents() after revoking = %+v", authorised)
+		}
+	})
+}
+
+// การรับนักเรียนปิดการเข้าพบของผู้มารับ: คนที่ถือบัตรได้บันทึกคืนบัตร (เข้าคิว sync บนเครื่อง edge)
+// คนที่คืนบัตรไปก่อนแล้วไม่ถูกบันทึกซ้ำ
+func TestPickupClosesVisit(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *sql.DB) {
+		now := time.Date(2026, 10, 19, 8, 30, 0, 0, time.UTC)
+		clk := testClock(t, &now)
+		students := NewStudentRepository(db, clk)
+		pickups := NewPickupRepository(db, clk).QueueForSync()
+		visitors := NewVisitorRepository(db, clk)
+
+		student := &models.Student{StudentCode: "S001", FirstName: "ด.ช.มานะ", LastName: "ใจดี", Active: true}
+		if err := students.Create(student); err != nil {
+			t.Fatal(err)
+		}
+		holder, returnedEarly := testVisitor(1), testVisitor(2)
+		returnedEarly.RFID = ""
+		for _, v := range []*models.Visitor{holder, returnedEarly} {
+			if err := visitors.Create(v); err != nil {
+				t.Fatal(err)
+			}
+		}
+		auth := &models.GuardianAuthorisation{StudentID: student.ID, IDCard: holder.IDCard, GuardianName: "พ่อ", Relationship: "บิดา", ValidFrom: clk.TodayDate()}
+		if err := students.Authorise(auth); err != nil {
+			t.Fatal(err)
+		}
+
+		now = now.Add(6 * time.Hour)
+		picked := []models.StudentPickup{{StudentID: student.ID, VisitorID: holder.ID, AuthorisationID: auth.ID}}
+		returned := &models.ReturnCardLog{ClientID: "8f0c2a4e-6a43-4d9b-9d55-0d0c7f1c2b11", VisitorID: holder.ID, CardID: holder.RFID,
+			Name: "ผู้มาติดต่อ คนที่1", CheckIn: "15:30", CheckOut: "21:30", ReturnDate: clk.TodayDate(), Status: "การคืนบัตรสำเร็จ", ReturnedAt: now}
+		if err := pickups.CreatePickups(picked, returned); err != nil {
+			t.Fatal(err)
+		}
+		if !picked[0].PickedUpAt.Equal(now) {
+			t.Errorf("PickedUpAt = %v, want the clock's %v", picked[0].PickedUpAt, now)
+		}
+		if list, err := pickups.ListPickups(clk.Today()); err != nil || len(list) != 1 || !list[0].PickedUpAt.Equal(now) {
+			t.Errorf("ListPickups(today) = %+v, %v", list, err)
+		}
+		got, err := visitors.GetByID(holder.ID)
+		if err != nil || got.ExitTime == nil || !got.ExitTime.Equal(now) {
+			t.Fatalf("visitor after pickup = %+v, %v", got, err)
+		}
+		if log, err := visitors.GetReturnLogByClientID(returned.ClientID); err != nil || log.ID != returned.ID || log.CardID != holder.RFID {
+			t.Errorf("return log = %+v, %v", log, err)
+		}
+		if pending, err := NewSyncQueueRepository(db, clk).ListPending(10); err != nil || len(pending) != 1 || pending[0].RecordID != returned.ID {
+			t.Errorf("ListPending() = %+v, %v", pending, err)
+		}
+
+		// ไม่มีบัตร: บันทึกเฉพาะเวลาออก
+		auth.ID, auth.IDCard = 0, returnedEarly.IDCard
+		if err := students.Authorise(auth); err != nil {
+			t.Fatal(err)
+		}
+		later := now.Add(time.Minute)
+		noCard := &models.ReturnCardLog{VisitorID: returnedEarly.ID, ReturnedAt: later}
+		if err := pickups.CreatePickups([]models.StudentPickup{{StudentID: student.ID, VisitorID: returnedEarly.ID, AuthorisationID: auth.ID}}, noCard); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := visitors.GetByID(returnedEarly.ID); got.ExitTime == nil || !got.ExitTime.Equal(later) || noCard.ID != 0 {
+			t.Errorf("visitor without a card after pickup = %+v, log %+v", got, noCard)
+		}
+
+		// การเข้าพบที่ปิดแล้วไม่ถูกแก้เวลาออกหรือคืนบัตรซ้ำ
+		again := *returned
+		again.ID, again.ClientID, again.ReturnedAt = 0, "", later
+		if err := pickups.CreatePickups([]models.StudentPickup{{StudentID: student.ID, VisitorID: holder.ID, AuthorisationID: picked[0].AuthorisationID}}, &again); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := visitors.GetByID(holder.ID); !got.ExitTime.Equal(now) || again.ID != 0 {
+			t.Errorf("closed visit after a second pickup = %+v, log %+v", got.ExitTime, again)
+		}
+	})
+}
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"backend/internal/clock"
+	"backend/internal/models"
+)
+
+// maxSyncMessage is the length of sync_queue.message
+const maxSyncMessage = 255
+
+// SyncQueueRepository reads the records an edge gate has not sent to the central server yet.
+// รายการเข้าคิวพร้อมกับการบันทึกใน VisitorRepository (QueueForSync)
+type SyncQueueRepository struct {
+	db    *sql.DB
+	clock *clock.Clock
+}
+
+func NewSyncQueueRepository(db *sql.DB, clk *clock.Clock) *SyncQueueRepository {
+	return &SyncQueueRepository{db: db, clock: clk}
+}
+
+const syncQueueColumns = `id, client_id, kind, record_id, status, attempts, message, created_at, synced_at`
+
+func scanSyncQueueEntry(row rowScanner) (*models.SyncQueueEntry, error) {
+	var e models.SyncQueueEntry
+	err := row.Scan(&e.ID, &e.ClientID, &e.Kind, &e.RecordID, &e.Status, &e.Attempts, &e.Message, &e.CreatedAt, &e.SyncedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// queueForSync adds a record written in tx to the sync queue
+func queueForSync(tx execer, clientID, kind string, recordID int) error {
+	_, err := tx.Exec(`INSERT INTO sync_queue (client_id, kind, record_id, status) VALUES (?, ?, ?, ?)`,
+		clientID, kind, recordID, models.SyncPending)
+	if err != nil {
+		return fmt.Errorf("failed to queue %s for sync: %w", kind, err)
+	}
+	return nil
+}
+
+// ListPending retrieves the oldest entries still waiting to be sent, in the order they were recorded
+// (การลงทะเบียนจึงถูกส่งก่อนการคืนบัตรของคนเดียวกันเสมอ)
+func (r *SyncQueueRepository) ListPending(limit int) ([]models.SyncQueueEntry, error) {
+	return r.list(`WHERE status = ? ORDER BY id ASC LIMIT ?`, models.SyncPending, limit)
+}
+
+// List retrieves the newest entries, optionally only those with one status
+func (r *SyncQueueRepository) List(status string, limit int) ([]models.SyncQueueEntry, error) {
+	if status == "" {
+		return r.list(`ORDER BY id DESC LIMIT ?`, limit)
+	}
+	return r.list(`WHERE status = ? ORDER BY id DESC LIMIT ?`, status, limit)
+}
+
+func (r *SyncQueueRepository) list(where string, args ...interface{}) ([]models.SyncQueueEntry, error) {
+	rows, err := r.db.Query(`SELECT `+syncQueueColumns+` FROM sync_queue `+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sync queue: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.SyncQueueEntry{}
+	for rows.Next() {
+		e, err := scanSyncQueueEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan sync queue entry: %w", err)
+		}
+		entries = append(entries, *e)
+	}
+	return entries, rows.Err()
+}
+
+// CountByStatus counts the entries of each status
+func (r *SyncQueueRepository) CountByStatus() (map[string]int, error) {
+	rows, err := r.db.Query(`SELECT status, COUNT(*) FROM sync_queue GROUP BY status`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count sync queue: %w", err)
+	}
+	defer rows.Close()
+
+	counts := map[string]int{models.SyncPending: 0, models.SyncSynced: 0, models.SyncConflict: 0}
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan sync queue count: %w", err)
+		}
+		counts[status] = count
+	}
+	return counts, rows.Err()
+}
+
+// Item loads the record of an entry as it is sent to the central server
+func (r *SyncQueueRepository) Item(entry models.SyncQueueEntry) (*models.SyncItem, error) {
+	item := &models.SyncItem{ClientID: entry.ClientID, Kind: entry.Kind}
+
+	switch entry.Kind {
+	case models.SyncKindVisitor:
+		visitor, err := scanVisitor(r.db.QueryRow(`SELECT `+visitorColumns+` FROM visitors WHERE id = ?`, entry.RecordID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load visitor %d for sync: %w", entry.RecordID, err)
+		}
+		item.Visitor = visitor
+
+	case models.SyncKindReturn:
+		// เวลาคืนอ่านจากการคืนเอง (exit_time ของผู้มาติดต่อถูกล้างเมื่อยกเลิกการคืน) รายการก่อนมี returned_at ใช้ created_at
+		var ret models.SyncReturn
+		var visitorClientID sql.NullString
+		var returnedAt sql.NullTime
+		var createdAt time.Time
+		err := r.db.QueryRow(`
+			SELECT v.client_id, l.card_id, l.check_out, l.returned_at, l.created_at
+			FROM return_card_logs l
+			JOIN visitors v ON v.id = l.visitor_id
+			WHERE l.id = ?
+		`, entry.RecordID).Scan(&visitorClientID, &ret.CardID, &ret.CheckOut, &returnedAt, &createdAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load return log %d for sync: %w", entry.RecordID, err)
+		}
+		ret.VisitorClientID = visitorClientID.String
+		ret.ReturnedAt = createdAt
+		if returnedAt.Valid {
+			ret.ReturnedAt = returnedAt.Time
+		}
+		item.Return = &ret
+
+	case models.SyncKindVoid:
+		var void models.SyncVoid
+		var returnClientID sql.NullString
+		err := r.db.QueryRow(`
+			SELECT client_id, voided_by, void_reason FROM return_card_logs WHERE id = ?
+		`, entry.RecordID).Scan(&returnClientID, &void.VoidedBy, &void.Reason)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load voided return log %d for sync: %w", entry.RecordID, err)
+		}
+		void.ReturnClientID = returnClientID.String
+		item.Void = &void
+
+	default:
+		return nil, fmt.Errorf("unknown sync kind %q", entry.Kind)
+	}
+
+	return item, nil
+}
+
+// MarkSynced records that the central server has the entry
+func (r *SyncQueueRepository) MarkSynced(id int) error {
+	return r.mark(id, models.SyncSynced, "", r.clock.Now())
+}
+
+// MarkConflict records that the central server refused the entry; it is not sent again
+func (r *SyncQueueRepository) MarkConflict(id int, message string) error {
+	return r.mark(id, models.SyncConflict, message, r.clock.Now())
+}
+
+// MarkFailed records a failed attempt; the entry stays pending and is sent again next time
+func (r *SyncQueueRepository) MarkFailed(id int, message string) error {
+	return r.mark(id, models.SyncPending, message, time.Time{})
+}
+
+func (r *SyncQueueRepository) mark(id int, status, message string, syncedAt time.Time) error {
+	if runes := []rune(message); len(runes) > maxSyncMessage {
+		message = string(runes[:maxSyncMessage])
+	}
+	var synced interface{}
+	if !syncedAt.IsZero() {
+		synced = syncedAt
+	}
+
+	_, err := r.db.Exec(`
+		UPDATE sync_queue SET status = ?, attempts = attempts + 1, message = ?, synced_at = ?
+		WHERE id = ?
+	`, status, message, synced, id)
+	if err != nil {
+		return fmt.Errorf("failed to update sync queue entry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+)
+
+func TestSyncQueue(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *sql.DB) {
+		now := testNow
+		clk := testClock(t, &now)
+		repo := NewVisitorRepository(db, clk).QueueForSync()
+		queue := NewSyncQueueRepository(db, clk)
+
+		// ลงทะเบียนที่ประตูตอน 08:00 แล้วคืนบัตรตอน 09:30 ระหว่างเน็ตหลุด
+		registeredAt := time.Date(2025, 6, 2, 1, 0, 0, 0, time.UTC)
+		returnedAt := time.Date(2025, 6, 2, 2, 30, 0, 0, time.UTC)
+		visitor := testVisitor(1)
+		visitor.ClientID = "6f1c1a8e-8a51-4a8e-9d0e-6f2a3b4c5d01"
+		visitor.RegisteredAt = registeredAt
+		if err := repo.Create(visitor); err != nil {
+			t.Fatal(err)
+		}
+		returned := &models.ReturnCardLog{
+			ClientID:   "6f1c1a8e-8a51-4a8e-9d0e-6f2a3b4c5d02",
+			VisitorID:  visitor.ID,
+			CardID:     visitor.RFID,
+			Name:       visitor.FirstName + " " + visitor.LastName,
+			CheckIn:    "08:00",
+			CheckOut:   "09:30",
+			ReturnDate: clk.TodayDate(),
+			Status:     "การคืนบัตรสำเร็จ",
+			ReturnedAt: returnedAt,
+		}
+		if err := repo.CreateReturnLog(returned); err != nil {
+			t.Fatal(err)
+		}
+
+		// ไม่ได้อยู่ในโหมด edge ไม่เข้าคิว
+		if err := NewVisitorRepository(db, clk).Create(testVisitor(2)); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := repo.GetByClientID(visitor.ClientID)
+		if err != nil || got.ID != visitor.ID || !got.RegisteredAt.Equal(registeredAt) || got.ExitTime == nil || !got.ExitTime.Equal(returnedAt) {
+			t.Fatalf("GetByClientID() = %+v, %v", got, err)
+		}
+		if exists, err := repo.CheckReturnLogExists(returned.ClientID); err != nil || !exists {
+			t.Errorf("CheckReturnLogExists() = %v, %v", exists, err)
+		}
+
+		pending, err := queue.ListPending(10)
+		if err != nil || len(pending) != 2 {
+			t.Fatalf("ListPending() = %+v, %v", pending, err)
+		}
+		if pending[0].Kind != models.SyncKindVisitor || pending[0].ClientID != visitor.ClientID ||
+			pending[1].Kind != models.SyncKindReturn || pending[1].ClientID != returned.ClientID {
+			t.Errorf("ListPending() = %+v, want the visitor then the return", pending)
+		}
+
+		item, err := queue.Item(pending[0])
+		if err != nil || item.Visitor == nil || item.Visitor.IDCard != visitor.IDCard || !item.Visitor.RegisteredAt.Equal(registeredAt) {
+			t.Errorf("Item(visitor) = %+v, %v", item, err)
+		}
+		item, err = queue.Item(pending[1])
+		if err != nil || item.Return == nil || item.Return.VisitorClientID != visitor.ClientID ||
+			item.Return.CheckOut != "09:30" || !item.Return.ReturnedAt.Equal(returnedAt) {
+			t.Errorf("Item(return) = %+v, %v", item, err)
+		}
+
+		if err := queue.MarkFailed(pending[0].ID, "เน็ตหลุด"); err != nil {
+			t.Fatal(err)
+		}
+		if err := queue.MarkConflict(pending[1].ID, "บัตรนี้คืนที่ server กลางไปแล้ว"); err != nil {
+			t.Fatal(err)
+		}
+		pending, err = queue.ListPending(10)
+		if err != nil || len(pending) != 1 || pending[0].Attempts != 1 || pending[0].Message != "เน็ตหลุด" || pending[0].SyncedAt != nil {
+			t.Fatalf("ListPending() after a failure = %+v, %v", pending, err)
+		}
+		if err := queue.MarkSynced(pending[0].ID); err != nil {
+			t.Fatal(err)
+		}
+
+		counts, err := queue.CountByStatus()
+		if err != nil || counts[models.SyncPending] != 0 || counts[models.SyncSynced] != 1 || counts[models.SyncConflict] != 1 {
+			t.Errorf("CountByStatus() = %v, %v", counts, err)
+		}
+		conflicts, err := queue.List(models.SyncConflict, 10)
+		if err != nil || len(conflicts) != 1 || conflicts[0].ClientID != returned.ClientID || conflicts[0].SyncedAt == nil {
+			t.Errorf("List(conflict) = %+v, %v", conflicts, err)
+		}
+	})
+}
+
+// ยกเลิกการคืนบัตรที่ประตู: ยังไม่ได้ส่งก็ไม่ส่ง ส่งไปแล้วส่งการยกเลิกตามไป
+func TestSyncQueueVoid(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *sql.DB) {
+		now := testNow
+		clk := testClock(t, &now)
+		repo := NewVisitorRepository(db, clk).QueueForSync()
+		queue := NewSyncQueueRepository(db, clk)
+
+		returnedAt := time.Date(2025, 6, 2, 2, 30, 0, 0, time.UTC)
+		var returns []*models.ReturnCardLog
+		for i := 1; i <= 2; i++ {
+			visitor := testVisitor(i)
+			visitor.ClientID = fmt.Sprintf("6f1c1a8e-8a51-4a8e-9d0e-6f2a3b4c5d1%d", i)
+			if err := repo.Create(visitor); err != nil {
+				t.Fatal(err)
+			}
+			returned := &models.ReturnCardLog{
+				ClientID:   fmt.Sprintf("6f1c1a8e-8a51-4a8e-9d0e-6f2a3b4c5d2%d", i),
+				VisitorID:  visitor.ID,
+				CardID:     visitor.RFID,
+				CheckOut:   "09:30",
+				ReturnDate: clk.TodayDate(),
+				ReturnedAt: returnedAt,
+			}
+			if err := repo.CreateReturnLog(returned); err != nil {
+				t.Fatal(err)
+			}
+			returns = append(returns, returned)
+		}
+
+		// การคืนของคนแรกส่งขึ้นไปแล้ว
+		pending, err := queue.ListPending(10)
+		if err != nil || len(pending) != 4 {
+			t.Fatalf("ListPending() = %+v, %v", pending, err)
+		}
+		sent := pending[1]
+		for _, e := range pending[:2] {
+			if err := queue.MarkSynced(e.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		revision := func() *models.VisitorRevision {
+			return &models.VisitorRevision{Action: models.RevisionVoidReturn, ChangedBy: "ยามหน้า", Reason: "สแกนผิดใบ"}
+		}
+		if _, err := repo.VoidReturnLog(returns[1].ID, revision()); err != nil {
+			t.Fatal(err)
+		}
+		pending, err = queue.ListPending(10)
+		if err != nil || len(pending) != 1 || pending[0].Kind != models.SyncKindVisitor {
+			t.Fatalf("ListPending() after voiding an unsent return = %+v, %v", pending, err)
+		}
+
+		if _, err := repo.VoidReturnLog(returns[0].ID, revision()); err != nil {
+			t.Fatal(err)
+		}
+		pending, err = queue.ListPending(10)
+		if err != nil || len(pending) != 2 || pending[1].Kind != models.SyncKindVoid || pending[1].RecordID != returns[0].ID {
+			t.Fatalf("ListPending() after voiding a sent return = %+v, %v", pending, err)
+		}
+		item, err := queue.Item(pending[1])
+		if err != nil || item.Void == nil || item.Void.ReturnClientID != returns[0].ClientID || item.Void.VoidedBy != "ยามหน้า" || item.Void.Reason != "สแกนผิดใบ" {
+			t.Errorf("Item(void) = %+v, %v", item, err)
+		}
+
+		// เวลาคืนอ่านจากการคืนเอง แม้เวลาออกของผู้มาติดต่อถูกล้างแล้ว
+		item, err = queue.Item(sent)
+		if err != nil || item.Return == nil || !item.Return.ReturnedAt.Equal(returnedAt) {
+			t.Errorf("Item(return) after void = %+v, %v", item, err)
+		}
+	})
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"backend/internal/clock"
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/vehicle"
+)
+
+// execer is implemented by both *sql.DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+type VehicleRepository struct {
+	db      *sql.DB
+	dialect database.Dialect
+	clock   *clock.Clock
+	school  int
+}
+
+// NewVehicleRepository reads vehicles through the visits of a school.
+// ตาราง vehicles ใช้ร่วมกันทั้งเขต (รถคันเดียวกันไปได้หลายโรงเรียน) จึงกรองด้วย visitors.school_id
+func NewVehicleRepository(db *sql.DB, clk *clock.Clock) *VehicleRepository {
+	return &VehicleRepository{db: db, dialect: database.DialectOf(db), clock: clk, school: models.DefaultSchoolID}
+}
+
+// ForSchool returns a copy of the repository that reads the visits of school id
+func (r *VehicleRepository) ForSchool(id int) *VehicleRepository {
+	scoped := *r
+	scoped.school = id
+	return &scoped
+}
+
+// upsertVehicle inserts the vehicle or updates the existing one with the same plate and province.
+// ได้ id ของแถวเดิมเมื่อทะเบียนซ้ำ (ดู Dialect.UpsertKeepingID) สีว่างไม่ทับสีที่เคยบันทึกไว้
+// ประเภทรถว่าง (ไม่ได้ส่งมา) ไม่ทับประเภทเดิม รถใหม่เป็น car
+func upsertVehicle(db querier, dialect database.Dialect, v *models.Vehicle) error {
+	assignments := []string{
+		`plate_display = ` + dialect.Excluded("plate_display"),
+		`color = CASE WHEN ` + dialect.Excluded("color") + ` = '' THEN vehicles.color ELSE ` + dialect.Excluded("color") + ` END`,
+	}
+	vehicleType := v.VehicleType
+	if vehicleType != "" {
+		assignments = append(assignments, `vehicle_type = `+dialect.Excluded("vehicle_type"))
+	} else {
+		vehicleType = vehicle.TypeCar
+	}
+
+	query := `
+		INSERT INTO vehicles (plate_number, plate_display, province, vehicle_type, color)
+		VALUES (?, ?, ?, ?, ?)
+	` + dialect.UpsertKeepingID([]string{"plate_number", "province"}, assignments...)
+
+	id, err := insertID(db, dialect, query,
+		v.PlateNumber,
+		v.PlateDisplay,
+		v.Province,
+		vehicleType,
+		v.Color,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to upsert vehicle: %w", err)
+	}
+
+	v.ID = id
+	return nil
+}
+
+// GetVisitsByPlate retrieves every visit made with the given normalized plate, newest first.
+// province เป็นตัวเลือก ถ้าว่างจะค้นทุกจังหวัด
+func (r *VehicleRepository) GetVisitsByPlate(plateNumber, province string) ([]models.VehicleVisitResponse, error) {
+	query := vehicleVisitSelect + ` WHERE v.school_id = ? AND v.deleted_at IS NULL AND ve.plate_number = ?`
+	args := []interface{}{r.school, plateNumber}
+
+	if province != "" {
+		query += ` AND ve.province = ?`
+		args = append(args, province)
+	}
+
+	query += ` ORDER BY v.registered_at DESC`
+
+	return r.queryVisits(query, args...)
+}
+
+// GetParked retrieves the visitor vehicles whose driver has not returned the card yet,
+// including visits registered on an earlier day (รถค้างคืน)
+func (r *VehicleRepository) GetParked() ([]models.VehicleVisitResponse, error) {
+	query := vehicleVisitSelect + `
+		WHERE v.school_id = ? AND v.deleted_at IS NULL AND v.exit_time IS NULL
+		ORDER BY v.registered_at ASC
+	`
+
+	return r.queryVisits(query, r.school)
+}
+
+const vehicleVisitSelect = `
+	SELECT v.id, v.first_name, v.last_name, v.id_card, v.phone, v.department,
+		ve.plate_display, ve.province, ve.vehicle_type, ve.color,
+		v.registered_at, v.exit_time
+	FROM visitors v
+	JOIN vehicles ve ON ve.id = v.vehicle_id
+`
+
+func (r *VehicleRepository) queryVisits(query string, args ...interface{}) ([]models.VehicleVisitResponse, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query vehicle visits: %w", err)
+	}
+	defer rows.Close()
+
+	visits := []models.VehicleVisitResponse{}
+	for rows.Next() {
+		var visit models.VehicleVisitResponse
+		var firstName, lastName string
+		var department sql.NullString
+		var registeredAt time.Time
+		var exitTime sql.NullTime
+
+		err := rows.Scan(
+			&visit.VisitorID,
+			&firstName,
+			&lastName,
+			&visit.IDCard,
+			&visit.Phone,
+			&department,
+			&visit.PlateNumber,
+			&visit.Province,
+			&visit.VehicleType,
+			&visit.Color,
+			&registeredAt,
+			&exitTime,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan vehicle visit: %w", err)
+		}
+
+		visit.Name = firstName + " " + lastName
+		visit.Department = department.String
+		visit.RegisteredAt = r.clock.In(registeredAt).Format("02/01/2006 15:04:05")
+		visit.ExitTime = "-"
+		if exitTime.Valid {
+			visit.ExitTime = r.clock.In(exitTime.Time).Format("02/01/2006 15:04:05")
+		}
+
+		visits = append(visits, visit)
+	}
+
+	return visits, nil
+}
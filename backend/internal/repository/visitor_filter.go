@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"strings"
+
+	"backend/internal/clock"
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/search"
+	"backend/internal/vehicle"
+)
+
+// visitDurationMinutes คือระยะเวลาที่อยู่ในโรงเรียน (คนที่ยังไม่ออกนับถึงปัจจุบัน)
+// เวลาปัจจุบันมาจาก clock เป็น argument แรก ไม่ใช้ NOW() ของฐานข้อมูล
+func visitDurationMinutes(dialect database.Dialect) string {
+	return dialect.MinutesBetween(`registered_at`, `COALESCE(exit_time, ?)`)
+}
+
+// filterBuilder collects WHERE conditions and their arguments
+type filterBuilder struct {
+	conditions []string
+	args       []interface{}
+}
+
+// where adds a condition; every condition is joined with AND
+func (b *filterBuilder) where(condition string, args ...interface{}) *filterBuilder {
+	b.conditions = append(b.conditions, condition)
+	b.args = append(b.args, args...)
+	return b
+}
+
+// sql returns the conditions as " AND ..." to append after "WHERE 1=1"
+func (b *filterBuilder) sql() string {
+	if len(b.conditions) == 0 {
+		return ""
+	}
+	return " AND " + strings.Join(b.conditions, " AND ")
+}
+
+// visitorFilters builds the shared filters used by List and GetVisitorsForExport.
+// startDate/endDate เป็นวันตามเวลาของโรงเรียน จึงแปลงเป็นช่วงเวลา UTC ก่อนเทียบกับ registered_at
+func visitorFilters(params models.QueryParams, clk *clock.Clock, dialect database.Dialect) *filterBuilder {
+	b := &filterBuilder{}
+	b.where(`deleted_at IS NULL`)
+
+	// Search filter (ทุกคำต้องตรงกับชื่อ นามสกุล เลขบัตร เบอร์โทร ทะเบียนรถ หรือ RFID)
+	if q := search.Parse(params.Search); !q.IsEmpty() {
+		condition, args := likeCondition(q, dialect, visitorLikeColumns)
+		b.where(condition, args...)
+	}
+
+	// DepartmentIDs ว่าง = ทุกส่วนงาน
+	if len(params.DepartmentIDs) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(params.DepartmentIDs)), ", ")
+		args := make([]interface{}, len(params.DepartmentIDs))
+		for i, id := range params.DepartmentIDs {
+			args[i] = id
+		}
+		b.where(`department_id IN (`+placeholders+`)`, args...)
+	}
+	if params.Province != "" {
+		b.where(`province = ?`, params.Province)
+	}
+	if params.LicensePlate != "" {
+		b.where(dialect.Like(`REPLACE(REPLACE(REPLACE(license_plate, ' ', ''), '-', ''), '.', '')`),
+			search.Contains(vehicle.NormalizePlate(params.LicensePlate)))
+	}
+	if params.OfficerName != "" {
+		b.where(dialect.Like(`officer_name`), search.Contains(params.OfficerName))
+	}
+
+	if start, err := clk.StartOfDay(params.StartDate); err == nil {
+		b.where(`registered_at >= ?`, start)
+	}
+	if end, err := clk.EndOfDay(params.EndDate); err == nil {
+		b.where(`registered_at < ?`, end)
+	}
+
+	switch params.Status {
+	case models.VisitorStatusOnSite:
+		b.where(`exit_time IS NULL`)
+	case models.VisitorStatusCheckedOut:
+		b.where(`exit_time IS NOT NULL`)
+	}
+
+	if params.MinDuration != nil {
+		b.where(visitDurationMinutes(dialect)+` >= ?`, clk.Now(), *params.MinDuration)
+	}
+	if params.MaxDuration != nil {
+		b.where(visitDurationMinutes(dialect)+` <= ?`, clk.Now(), *params.MaxDuration)
+	}
+
+	if params.HasRFID != nil {
+		if *params.HasRFID {
+			b.where(`rfid IS NOT NULL AND rfid <> ''`)
+		} else {
+			b.where(`(rfid IS NULL OR rfid = '')`)
+		}
+	}
+
+	return b
+}
+
+// visitorOrderBy returns the ORDER BY clause for the requested sort field and direction.
+// sortOrder รับ asc/desc และค่าเดิม oldest/latest
+func visitorOrderBy(params models.QueryParams) string {
+	direction := "DESC"
+	if params.SortBy == models.SortByName {
+		direction = "ASC"
+	}
+	switch params.SortOrder {
+	case "oldest", "asc":
+		direction = "ASC"
+	case "latest", "desc":
+		direction = "DESC"
+	}
+
+	switch params.SortBy {
+	case models.SortByName:
+		return ` ORDER BY first_name ` + direction + `, last_name ` + direction + `, id ` + direction
+	case models.SortByExitTime:
+		return ` ORDER BY exit_time IS NULL, exit_time ` + direction + `, id ` + direction
+	default:
+		return ` ORDER BY registered_at ` + direction
+	}
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/clock"
+	"backend/internal/database"
+	"backend/internal/models"
+)
+
+// ช่วงวันที่ของรายการผู้มาติดต่อต้องเป็นวันตามเวลาโรงเรียน ไม่ว่าเครื่อง server จะตั้ง TZ อะไร
+func TestVisitorFiltersDateRangeIsSchoolLocal(t *testing.T) {
+	for _, name := range []string{"UTC", "Asia/Bangkok", "America/Los_Angeles", "Asia/Tokyo"} {
+		t.Run("TZ="+name, func(t *testing.T) {
+			loc, err := time.LoadLocation(name)
+			if err != nil {
+				t.Skip(err)
+			}
+			saved := time.Local
+			time.Local = loc
+			defer func() { time.Local = saved }()
+
+			clk, err := clock.Load("Asia/Bangkok")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			b := visitorFilters(models.QueryParams{StartDate: "2025-06-01", EndDate: "2025-06-02"}, clk, database.MySQL)
+
+			if sql := b.sql(); !strings.Contains(sql, "registered_at >= ?") || !strings.Contains(sql, "registered_at < ?") {
+				t.Fatalf("sql() = %q, want a half-open registered_at range", sql)
+			}
+			if len(b.args) != 2 {
+				t.Fatalf("args = %v, want 2", b.args)
+			}
+
+			wantStart := time.Date(2025, 5, 31, 17, 0, 0, 0, time.UTC)
+			wantEnd := time.Date(2025, 6, 2, 17, 0, 0, 0, time.UTC)
+			if start := b.args[0].(time.Time); !start.Equal(wantStart) {
+				t.Errorf("start = %s, want %s", start, wantStart)
+			}
+			if end := b.args[1].(time.Time); !end.Equal(wantEnd) {
+				t.Errorf("end = %s, want %s", end, wantEnd)
+			}
+		})
+	}
+}
@@ -3,37 +3,74 @@ package repository
 import (
 	"database/sql"
 	"fmt"
-	"strings"
 	"time"
 
+	"backend/internal/address"
+	"backend/internal/clock"
+	"backend/internal/database"
 	"backend/internal/models"
 )
 
 type VisitorRepository struct {
-	db *sql.DB
+	db      *sql.DB
+	dialect database.Dialect
+	clock   *clock.Clock
+	school  int // ทุกคำสั่งเห็นเฉพาะข้อมูลของโรงเรียนนี้
+
+	// syncQueue: เครื่อง edge ที่ประตู ต้องเข้าคิว sync_queue ทุกครั้งที่ลงทะเบียนหรือคืนบัตร
+	syncQueue bool
+}
+
+func NewVisitorRepository(db *sql.DB, clk *clock.Clock) *VisitorRepository {
+	return &VisitorRepository{db: db, dialect: database.DialectOf(db), clock: clk, school: models.DefaultSchoolID}
 }
 
-func NewVisitorRepository(db *sql.DB) *VisitorRepository {
-	return &VisitorRepository{db: db}
+// ForSchool returns a copy of the repository that reads and writes the data of school id
+func (r *VisitorRepository) ForSchool(id int) *VisitorRepository {
+	scoped := *r
+	scoped.school = id
+	return &scoped
 }
 
-// Create inserts a new visitor into the database
+// QueueForSync makes Create and CreateReturnLog also queue the record for the central server,
+// in the same transaction (โหมด edge)
+func (r *VisitorRepository) QueueForSync() *VisitorRepository {
+	r.syncQueue = true
+	return r
+}
+
+// Create inserts a new visitor into the database.
+// ถ้ามีข้อมูลรถ จะบันทึก/อัปเดตตาราง vehicles และถ้ามาตามนัด จะผูกกับนัดหมายใน transaction เดียวกัน
 func (r *VisitorRepository) Create(visitor *models.Visitor) error {
-	query := `
-		INSERT INTO visitors (
-			id_card, first_name, last_name, birth_date, phone, license_plate,
-			house_number, moo, soi, road, sub_district, district, province,
-			rfid, department, officer_name, id_card_image
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
 
-	result, err := r.db.Exec(query,
+	if visitor.Vehicle != nil {
+		if err := upsertVehicle(tx, r.dialect, visitor.Vehicle); err != nil {
+			return err
+		}
+		visitor.VehicleID = &visitor.Vehicle.ID
+	}
+
+	columns := `
+		school_id, client_id, id_card, first_name, last_name, birth_date, phone, license_plate, vehicle_id,
+		house_number, moo, soi, road, sub_district, district, province, postal_code,
+		rfid, department, department_id, officer_name, id_card_image,
+		host_name, host_contact, purpose, purpose_note, approval_status`
+	values := `?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?`
+	args := []interface{}{
+		r.school,
+		nullIfEmpty(visitor.ClientID),
 		visitor.IDCard,
 		visitor.FirstName,
 		visitor.LastName,
-		visitor.BirthDate,
+		nullSQLDate(visitor.BirthDate),
 		visitor.Phone,
 		visitor.LicensePlate,
+		visitor.VehicleID,
 		visitor.HouseNumber,
 		visitor.Moo,
 		visitor.Soi,
@@ -41,59 +78,59 @@ func (r *VisitorRepository) Create(visitor *models.Visitor) error {
 		visitor.SubDistrict,
 		visitor.District,
 		visitor.Province,
-		visitor.RFID,
+		visitor.PostalCode,
+		nullIfEmpty(visitor.RFID),
 		visitor.Department,
+		visitor.DepartmentID,
 		visitor.OfficerName,
 		visitor.IDCardImage,
-	)
+		visitor.HostName,
+		visitor.HostContact,
+		visitor.Purpose,
+		visitor.PurposeNote,
+		visitor.ApprovalStatus,
+	}
+
+	// เวลาลงทะเบียนจริงที่ประตู (รายการที่ sync มาจากเครื่อง edge) ไม่งั้นใช้นาฬิกาของโรงเรียน
+	if visitor.RegisteredAt.IsZero() {
+		visitor.RegisteredAt = r.clock.Now()
+	}
+	columns += `, registered_at`
+	values += `, ?`
+	args = append(args, visitor.RegisteredAt.UTC())
+
+	query := `INSERT INTO visitors (` + columns + `) VALUES (` + values + `)`
 
+	id, err := insertID(tx, r.dialect, query, args...)
 	if err != nil {
 		return fmt.Errorf("failed to create visitor: %w", err)
 	}
 
-	id, err := result.LastInsertId()
-	if err != nil {
-		return fmt.Errorf("failed to get last insert id: %w", err)
+	if r.syncQueue && visitor.ClientID != "" {
+		if err := queueForSync(tx, visitor.ClientID, models.SyncKindVisitor, id); err != nil {
+			return err
+		}
+	}
+
+	if visitor.AppointmentID != nil {
+		if err := markAppointmentArrived(tx, r.school, *visitor.AppointmentID, id, r.clock.Now()); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	visitor.ID = int(id)
+	visitor.ID = id
 	return nil
 }
 
 // GetByID retrieves a visitor by ID
 func (r *VisitorRepository) GetByID(id int) (*models.Visitor, error) {
-	query := `
-		SELECT id, id_card, first_name, last_name, birth_date, phone, license_plate,
-			house_number, moo, soi, road, sub_district, district, province,
-			rfid, department, officer_name, id_card_image, registered_at, exit_time, updated_at
-		FROM visitors WHERE id = ?
-	`
-
-	visitor := &models.Visitor{}
-	err := r.db.QueryRow(query, id).Scan(
-		&visitor.ID,
-		&visitor.IDCard,
-		&visitor.FirstName,
-		&visitor.LastName,
-		&visitor.BirthDate,
-		&visitor.Phone,
-		&visitor.LicensePlate,
-		&visitor.HouseNumber,
-		&visitor.Moo,
-		&visitor.Soi,
-		&visitor.Road,
-		&visitor.SubDistrict,
-		&visitor.District,
-		&visitor.Province,
-		&visitor.RFID,
-		&visitor.Department,
-		&visitor.OfficerName,
-		&visitor.IDCardImage,
-		&visitor.RegisteredAt,
-		&visitor.ExitTime,
-		&visitor.UpdatedAt,
-	)
+	query := `SELECT ` + visitorColumns + ` FROM visitors WHERE id = ? AND school_id = ? AND deleted_at IS NULL`
 
+	visitor, err := scanVisitor(r.db.QueryRow(query, id, r.school))
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("visitor not found")
 	}
@@ -104,48 +141,56 @@ func (r *VisitorRepository) GetByID(id int) (*models.Visitor, error) {
 	return visitor, nil
 }
 
-// List retrieves visitors with filters
-func (r *VisitorRepository) List(params models.QueryParams) ([]models.Visitor, error) {
-	query := `
-		SELECT id, id_card, first_name, last_name, birth_date, phone, license_plate,
-			house_number, moo, soi, road, sub_district, district, province,
-			rfid, department, officer_name, id_card_image,
-			registered_at, exit_time, updated_at
-		FROM visitors WHERE 1=1
-	`
-
-	args := []interface{}{}
+// GetByClientID retrieves a visitor by the UUID given when it was registered
+func (r *VisitorRepository) GetByClientID(clientID string) (*models.Visitor, error) {
+	query := `SELECT ` + visitorColumns + ` FROM visitors WHERE client_id = ? AND school_id = ?`
 
-	// Search filter
-	if params.Search != "" {
-		query += ` AND (first_name LIKE ? OR last_name LIKE ? OR id_card LIKE ?)`
-		searchTerm := "%" + params.Search + "%"
-		args = append(args, searchTerm, searchTerm, searchTerm)
+	visitor, err := scanVisitor(r.db.QueryRow(query, clientID, r.school))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("visitor not found")
 	}
-
-	// Department filter
-	if params.Department != "" && params.Department != "ทั้งหมด" {
-		query += ` AND department = ?`
-		args = append(args, params.Department)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get visitor by client ID: %w", err)
 	}
 
-	// Date range filter
-	if params.StartDate != "" {
-		query += ` AND DATE(registered_at) >= ?`
-		args = append(args, params.StartDate)
-	}
-	if params.EndDate != "" {
-		query += ` AND DATE(registered_at) <= ?`
-		args = append(args, params.EndDate)
+	return visitor, nil
+}
+
+// ListOnSite retrieves visitors registered in [from, before) who have not returned their card
+func (r *VisitorRepository) ListOnSite(from, before time.Time) ([]models.Visitor, error) {
+	query := `SELECT ` + visitorColumns + ` FROM visitors
+		WHERE school_id = ? AND exit_time IS NULL AND approval_status <> ? AND deleted_at IS NULL
+		AND registered_at >= ? AND registered_at < ?
+		ORDER BY registered_at ASC`
+
+	rows, err := r.db.Query(query, r.school, models.ApprovalRejected, from, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list visitors on site: %w", err)
 	}
+	defer rows.Close()
 
-	// Sort order
-	if params.SortOrder == "oldest" {
-		query += ` ORDER BY registered_at ASC`
-	} else {
-		query += ` ORDER BY registered_at DESC`
+	visitors := []models.Visitor{}
+	for rows.Next() {
+		v, err := scanVisitor(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan visitor: %w", err)
+		}
+		visitors = append(visitors, *v)
 	}
 
+	return visitors, nil
+}
+
+// List retrieves visitors with filters
+func (r *VisitorRepository) List(params models.QueryParams) ([]models.Visitor, error) {
+	query := `SELECT ` + visitorColumns + ` FROM visitors WHERE school_id = ?`
+
+	filters := visitorFilters(params, r.clock, r.dialect)
+	query += filters.sql()
+	args := append([]interface{}{r.school}, filters.args...)
+
+	query += visitorOrderBy(params)
+
 	// Pagination
 	if params.Limit > 0 {
 		query += ` LIMIT ?`
@@ -166,58 +211,42 @@ func (r *VisitorRepository) List(params models.QueryParams) ([]models.Visitor, e
 
 	visitors := []models.Visitor{}
 	for rows.Next() {
-		var v models.Visitor
-		err := rows.Scan(
-			&v.ID,
-			&v.IDCard,
-			&v.FirstName,
-			&v.LastName,
-			&v.BirthDate,
-			&v.Phone,
-			&v.LicensePlate,
-			&v.HouseNumber,
-			&v.Moo,
-			&v.Soi,
-			&v.Road,
-			&v.SubDistrict,
-			&v.District,
-			&v.Province,
-			&v.RFID,
-			&v.Department,
-			&v.OfficerName,
-			&v.IDCardImage,
-			&v.RegisteredAt,
-			&v.ExitTime,
-			&v.UpdatedAt,
-		)
+		v, err := scanVisitor(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan visitor: %w", err)
 		}
-		visitors = append(visitors, v)
+		visitors = append(visitors, *v)
 	}
 
 	return visitors, nil
 }
 
 // ⭐ เพิ่มฟังก์ชันนี้สำหรับ Export
-func (r *VisitorRepository) GetVisitorsForExport(startDate, endDate, department string) ([]models.Visitor, error) {
+func (r *VisitorRepository) GetVisitorsForExport(params models.QueryParams) ([]models.Visitor, error) {
 	query := `
 		SELECT 
 			id, id_card, first_name, last_name, birth_date, phone, license_plate,
-			house_number, moo, soi, road, sub_district, district, province,
-			rfid, department, officer_name, registered_at, exit_time
+			house_number, moo, soi, road, sub_district, district, province, postal_code,
+			rfid, department, officer_name, host_name, purpose, registered_at, exit_time
 		FROM visitors 
-		WHERE DATE(registered_at) BETWEEN ? AND ?
+		WHERE school_id = ?
 	`
 
-	args := []interface{}{startDate, endDate}
+	filters := visitorFilters(params, r.clock, r.dialect)
+	query += filters.sql()
+	args := append([]interface{}{r.school}, filters.args...)
 
-	if department != "" && department != "ทั้งหมด" {
-		query += ` AND department = ?`
-		args = append(args, department)
-	}
+	query += visitorOrderBy(params)
+
+	if params.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, params.Limit)
 
-	query += ` ORDER BY registered_at DESC`
+		if params.Page > 0 {
+			query += ` OFFSET ?`
+			args = append(args, (params.Page-1)*params.Limit)
+		}
+	}
 
 	rows, err := r.db.Query(query, args...)
 	if err != nil {
@@ -229,14 +258,14 @@ func (r *VisitorRepository) GetVisitorsForExport(startDate, endDate, department
 	for rows.Next() {
 		var v models.Visitor
 		var birthDate sql.NullTime
-		var licensePlate, houseNumber, moo, soi, road, subDistrict, district, province sql.NullString
+		var licensePlate, houseNumber, moo, soi, road, subDistrict, district, province, postalCode, rfid sql.NullString
 		var exitTime sql.NullTime
 
 		err := rows.Scan(
 			&v.ID, &v.IDCard, &v.FirstName, &v.LastName,
 			&birthDate, &v.Phone, &licensePlate,
-			&houseNumber, &moo, &soi, &road, &subDistrict, &district, &province,
-			&v.RFID, &v.Department, &v.OfficerName,
+			&houseNumber, &moo, &soi, &road, &subDistrict, &district, &province, &postalCode,
+			&rfid, &v.Department, &v.OfficerName, &v.HostName, &v.Purpose,
 			&v.RegisteredAt, &exitTime,
 		)
 		if err != nil {
@@ -254,6 +283,8 @@ func (r *VisitorRepository) GetVisitorsForExport(startDate, endDate, department
 		v.SubDistrict = subDistrict.String
 		v.District = district.String
 		v.Province = province.String
+		v.PostalCode = postalCode.String
+		v.RFID = rfid.String
 
 		if exitTime.Valid {
 			v.ExitTime = &exitTime.Time
@@ -261,7 +292,7 @@ func (r *VisitorRepository) GetVisitorsForExport(startDate, endDate, department
 
 		// สร้างชื่อเต็มและที่อยู่
 		v.Name = v.FirstName + " " + v.LastName
-		v.Address = buildFullAddress(&v)
+		v.Address = address.Format(address.FromVisitor(&v), address.Full)
 
 		visitors = append(visitors, v)
 	}
@@ -269,11 +300,45 @@ func (r *VisitorRepository) GetVisitorsForExport(startDate, endDate, department
 	return visitors, nil
 }
 
-// CheckIDCardExists checks if ID card already exists
+// SetPhoto stores the keys of the visitor's photo and returns the keys it replaced
+func (r *VisitorRepository) SetPhoto(id int, photoKey, thumbnailKey string) (oldPhotoKey, oldThumbnailKey string, err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var oldPhoto, oldThumbnail sql.NullString
+	err = tx.QueryRow(`
+		SELECT photo_key, photo_thumbnail_key FROM visitors WHERE id = ? AND school_id = ? AND deleted_at IS NULL`+r.dialect.ForUpdate(), id, r.school).Scan(&oldPhoto, &oldThumbnail)
+	if err == sql.ErrNoRows {
+		return "", "", fmt.Errorf("visitor not found")
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get visitor photo: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE visitors SET photo_key = ?, photo_thumbnail_key = ?, photo_taken_at = ?, version = version + 1
+		WHERE id = ?
+	`, photoKey, thumbnailKey, r.clock.Now(), id)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to set visitor photo: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", "", fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return oldPhoto.String, oldThumbnail.String, nil
+}
+
+// CheckIDCardExists checks if ID card already exists.
+// ไม่นับรายการที่ถูกลบ เหมือน uq_visitors_school_id_card (migration 018)
 func (r *VisitorRepository) CheckIDCardExists(idCard string) (bool, error) {
-	query := `SELECT COUNT(*) FROM visitors WHERE id_card = ?`
+	query := `SELECT COUNT(*) FROM visitors WHERE id_card = ? AND school_id = ? AND deleted_at IS NULL`
 	var count int
-	err := r.db.QueryRow(query, idCard).Scan(&count)
+	err := r.db.QueryRow(query, idCard, r.school).Scan(&count)
 	if err != nil {
 		return false, fmt.Errorf("failed to check id card: %w", err)
 	}
@@ -282,26 +347,34 @@ func (r *VisitorRepository) CheckIDCardExists(idCard string) (bool, error) {
 
 // CheckRFIDExists checks if RFID already exists
 func (r *VisitorRepository) CheckRFIDExists(rfid string) (bool, error) {
-	query := `SELECT COUNT(*) FROM visitors WHERE rfid = ?`
+	query := `SELECT COUNT(*) FROM visitors WHERE rfid = ? AND school_id = ?`
 	var count int
-	err := r.db.QueryRow(query, rfid).Scan(&count)
+	err := r.db.QueryRow(query, rfid, r.school).Scan(&count)
 	if err != nil {
 		return false, fmt.Errorf("failed to check rfid: %w", err)
 	}
 	return count > 0, nil
 }
 
-// CheckDuplicateReturn เช็คว่าบัตรถูกคืนวันนี้แล้วหรือยัง
+// CheckDuplicateReturn เช็คว่าบัตรถูกคืนวันนี้แล้วหรือยัง (วันนี้ตามเวลาของโรงเรียน ไม่นับที่ถูกยกเลิก)
+// CreateReturnLog เช็คซ้ำอีกครั้งใน transaction ของมันเอง
 func (r *VisitorRepository) CheckDuplicateReturn(cardId string) (bool, error) {
+	return r.returnedOn(r.db, cardId, r.clock.Today())
+}
+
+// returnedOn reports whether the card has a return on date (YYYY-MM-DD) that was not voided
+func (r *VisitorRepository) returnedOn(db querier, cardID, date string) (bool, error) {
 	query := `
 		SELECT COUNT(*) 
 		FROM return_card_logs 
 		WHERE card_id = ? 
-		AND DATE(return_date) = CURDATE()
+		AND return_date = ?
+		AND school_id = ?
+		AND voided_at IS NULL
 	`
 
 	var count int
-	err := r.db.QueryRow(query, cardId).Scan(&count)
+	err := db.QueryRow(query, cardID, date, r.school).Scan(&count)
 	if err != nil {
 		return false, fmt.Errorf("failed to check duplicate return: %w", err)
 	}
@@ -309,76 +382,58 @@ func (r *VisitorRepository) CheckDuplicateReturn(cardId string) (bool, error) {
 	return count > 0, nil
 }
 
-// FormatAddress จัดรูปแบบที่อยู่แบบสั้น
-func FormatAddress(v *models.Visitor) string {
-	parts := []string{}
-
-	if v.HouseNumber != "" {
-		parts = append(parts, v.HouseNumber)
-	}
-	if v.Moo != "" {
-		parts = append(parts, "ม."+v.Moo)
-	}
-	if v.Soi != "" {
-		parts = append(parts, "ซ."+v.Soi)
-	}
-	if v.Road != "" {
-		parts = append(parts, "ถ."+v.Road)
-	}
-	if v.SubDistrict != "" {
-		parts = append(parts, "ต."+v.SubDistrict)
-	}
-	if v.District != "" {
-		parts = append(parts, "อ."+v.District)
-	}
-	if v.Province != "" {
-		parts = append(parts, "จ."+v.Province)
-	}
-
-	if len(parts) == 0 {
-		return "-"
+// PurgeRegisteredBefore deletes the visits registered before the cutoff (ระยะเก็บข้อมูลของโรงเรียน)
+// and returns the storage keys of their photos for the caller to delete.
+// การคืนบัตรถูกลบตามไปด้วย (ON DELETE CASCADE) ประวัติการรับนักเรียนลบก่อนเพราะอ้างถึงผู้มาติดต่อโดยตรง
+// ประวัติการแก้ไขก็ลบไปพร้อมกัน เพราะเก็บค่าเดิมของข้อมูลส่วนตัวไว้
+func (r *VisitorRepository) PurgeRegisteredBefore(cutoff time.Time) (photoKeys []string, purged int, err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	return strings.Join(parts, " ")
-}
-
-// ⭐ buildFullAddress สร้างที่อยู่เต็มสำหรับ Export
-func buildFullAddress(v *models.Visitor) string {
-	parts := []string{}
+	const expired = `SELECT id FROM visitors WHERE school_id = ? AND registered_at < ?`
 
-	if v.HouseNumber != "" {
-		parts = append(parts, "บ้านเลขที่ "+v.HouseNumber)
-	}
-	if v.Moo != "" {
-		parts = append(parts, "หมู่ "+v.Moo)
+	rows, err := tx.Query(`
+		SELECT photo_key, photo_thumbnail_key FROM visitors
+		WHERE school_id = ? AND registered_at < ? AND photo_key IS NOT NULL
+	`, r.school, cutoff)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list expired photos: %w", err)
 	}
-	if v.Soi != "" {
-		parts = append(parts, "ซอย "+v.Soi)
+	for rows.Next() {
+		var photo, thumbnail sql.NullString
+		if err := rows.Scan(&photo, &thumbnail); err != nil {
+			rows.Close()
+			return nil, 0, fmt.Errorf("failed to scan expired photo: %w", err)
+		}
+		for _, key := range []sql.NullString{photo, thumbnail} {
+			if key.String != "" {
+				photoKeys = append(photoKeys, key.String)
+			}
+		}
 	}
-	if v.Road != "" {
-		parts = append(parts, "ถนน "+v.Road)
+	rows.Close()
+
+	if _, err := tx.Exec(`DELETE FROM student_pickups WHERE visitor_id IN (`+expired+`)`, r.school, cutoff); err != nil {
+		return nil, 0, fmt.Errorf("failed to purge pickups: %w", err)
 	}
-	if v.SubDistrict != "" {
-		parts = append(parts, "ตำบล "+v.SubDistrict)
+	if _, err := tx.Exec(`DELETE FROM visitor_revisions WHERE visitor_id IN (`+expired+`)`, r.school, cutoff); err != nil {
+		return nil, 0, fmt.Errorf("failed to purge revisions: %w", err)
 	}
-	if v.District != "" {
-		parts = append(parts, "อำเภอ "+v.District)
+	result, err := tx.Exec(`DELETE FROM visitors WHERE school_id = ? AND registered_at < ?`, r.school, cutoff)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to purge visitors: %w", err)
 	}
-	if v.Province != "" {
-		parts = append(parts, "จังหวัด "+v.Province)
+	n, err := result.RowsAffected()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to purge visitors: %w", err)
 	}
 
-	if len(parts) == 0 {
-		return "-"
+	if err := tx.Commit(); err != nil {
+		return nil, 0, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	return strings.Join(parts, " ")
-}
-
-// FormatThaiDate formats date to Thai format
-func FormatThaiDate(t *time.Time) string {
-	if t == nil {
-		return "-"
-	}
-	return t.Format("02/01/2006")
+	return photoKeys, int(n), nil
 }
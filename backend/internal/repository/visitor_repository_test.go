@@ -0,0 +1,504 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/search"
+)
+
+func TestVisitorRepository(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *sql.DB) {
+		now := testNow
+		repo := NewVisitorRepository(db, testClock(t, &now))
+
+		birth := time.Date(1990, 5, 17, 0, 0, 0, 0, time.UTC)
+		v := testVisitor(1)
+		v.BirthDate = &birth
+		if err := repo.Create(v); err != nil {
+			t.Fatal(err)
+		}
+		got, err := repo.GetByID(v.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.BirthDate == nil || !got.BirthDate.Equal(birth) || got.RFID != "RF0001" || got.ExitTime != nil {
+			t.Errorf("stored visitor = %+v", got)
+		}
+		if got.RegisteredAt.IsZero() {
+			t.Error("registered at should come from the database default")
+		}
+
+		// เลขบัตรประชาชนและ RFID ซ้ำไม่ได้ RFID เทียบแบบไม่สนตัวพิมพ์
+		if err := repo.Create(testVisitor(1)); err == nil {
+			t.Error("duplicate ID card should fail")
+		}
+		if byCard, err := repo.GetByRFID("rf0001"); err != nil || byCard.ID != v.ID {
+			t.Errorf("GetByRFID(rf0001) = %+v, %v", byCard, err)
+		}
+		if exists, _ := repo.CheckIDCardExists(v.IDCard); !exists {
+			t.Error("CheckIDCardExists() = false")
+		}
+
+		oldPhoto, oldThumb, err := repo.SetPhoto(v.ID, "photos/1.jpg", "photos/1_thumb.jpg")
+		if err != nil || oldPhoto != "" || oldThumb != "" {
+			t.Fatalf("first SetPhoto() = %q, %q, %v", oldPhoto, oldThumb, err)
+		}
+		oldPhoto, oldThumb, err = repo.SetPhoto(v.ID, "photos/2.jpg", "photos/2_thumb.jpg")
+		if err != nil || oldPhoto != "photos/1.jpg" || oldThumb != "photos/1_thumb.jpg" {
+			t.Errorf("second SetPhoto() = %q, %q, %v", oldPhoto, oldThumb, err)
+		}
+		if _, _, err := repo.SetPhoto(99, "x", "y"); err == nil {
+			t.Error("SetPhoto() of a missing visitor should fail")
+		}
+	})
+}
+
+func TestVisitorListFilters(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *sql.DB) {
+		now := testNow
+		repo := NewVisitorRepository(db, testClock(t, &now))
+		visits := seedVisits(t, db, repo)
+		v1, v2, v3 := visits[0].ID, visits[1].ID, visits[2].ID
+
+		sixty, ninety := 60, 90
+		withRFID, withoutRFID := true, false
+		tests := []struct {
+			name   string
+			params models.QueryParams
+			want   []int
+		}{
+			{"latest first", models.QueryParams{}, []int{v2, v1, v3}},
+			{"by name", models.QueryParams{SortBy: models.SortByName}, []int{v1, v2, v3}},
+			{"by exit time", models.QueryParams{SortBy: models.SortByExitTime}, []int{v2, v3, v1}},
+			{"school-local today", models.QueryParams{StartDate: "2025-06-02", EndDate: "2025-06-02", SortOrder: "oldest"}, []int{v1, v2}},
+			{"school-local yesterday", models.QueryParams{StartDate: "2025-06-01", EndDate: "2025-06-01"}, []int{v3}},
+			{"department", models.QueryParams{DepartmentIDs: []int{*visits[2].DepartmentID}}, []int{v3}},
+			{"several departments", models.QueryParams{DepartmentIDs: []int{*visits[0].DepartmentID, *visits[2].DepartmentID}}, []int{v2, v1, v3}},
+			{"on site", models.QueryParams{Status: models.VisitorStatusOnSite, SortOrder: "oldest"}, []int{v3, v1}},
+			{"checked out", models.QueryParams{Status: models.VisitorStatusCheckedOut}, []int{v2}},
+			{"stayed at least 90 minutes", models.QueryParams{MinDuration: &ninety, SortOrder: "oldest"}, []int{v3, v1}},
+			{"stayed at most 60 minutes", models.QueryParams{MaxDuration: &sixty}, []int{v2}},
+			{"has card", models.QueryParams{HasRFID: &withRFID, SortOrder: "oldest"}, []int{v1, v2}},
+			{"no card", models.QueryParams{HasRFID: &withoutRFID}, []int{v3}},
+			{"plate ignores spaces and dashes", models.QueryParams{LicensePlate: "กข 1234", SortOrder: "oldest"}, []int{v1, v2}},
+			{"search", models.QueryParams{Search: "คนที่2"}, []int{v2}},
+			{"paging", models.QueryParams{SortOrder: "oldest", Page: 2, Limit: 2}, []int{v2}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := repo.List(tt.params)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if ids := visitorIDs(got); !reflect.DeepEqual(ids, tt.want) {
+					t.Errorf("List() ids = %v, want %v", ids, tt.want)
+				}
+			})
+		}
+
+		exported, err := repo.GetVisitorsForExport(models.QueryParams{StartDate: "2025-06-02", EndDate: "2025-06-02", SortOrder: "oldest"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ids := visitorIDs(exported); !reflect.DeepEqual(ids, []int{v1, v2}) || exported[1].ExitTime == nil || exported[0].Address == "" {
+			t.Errorf("GetVisitorsForExport() = %+v", exported)
+		}
+
+		start, end, _ := repo.clock.DayRange("2025-06-02")
+		onSite, err := repo.ListOnSite(start, end)
+		if err != nil || !reflect.DeepEqual(visitorIDs(onSite), []int{v1}) {
+			t.Errorf("ListOnSite() = %v, %v", visitorIDs(onSite), err)
+		}
+	})
+}
+
+func TestReturnCardLogs(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *sql.DB) {
+		now := testNow
+		repo := NewVisitorRepository(db, testClock(t, &now))
+		visits := seedVisits(t, db, repo)
+
+		returned, err := repo.GetByID(visits[1].ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if returned.ExitTime == nil || !returned.ExitTime.Equal(testNow) {
+			t.Errorf("exit time = %v, want %v", returned.ExitTime, testNow)
+		}
+
+		// คืนซ้ำวันเดียวกันไม่ได้ วันถัดไปได้ (วันตามเวลาโรงเรียน)
+		if dup, err := repo.CheckDuplicateReturn("RF0002"); err != nil || !dup {
+			t.Errorf("same-day CheckDuplicateReturn() = %v, %v", dup, err)
+		}
+		now = testNow.Add(21 * time.Hour) // 07:00 ของวันที่ 3
+		if dup, _ := repo.CheckDuplicateReturn("RF0002"); dup {
+			t.Error("next-day return should not be a duplicate")
+		}
+
+		logs, err := repo.GetReturnLogs("rf0002", "2025-06-02", "2025-06-02", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(logs) != 1 || !logs[0].ReturnDate.Equal(time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)) || logs[0].CheckOut != "10:00" {
+			t.Errorf("GetReturnLogs() = %+v", logs)
+		}
+		if logs, _ := repo.GetReturnLogs("", "2025-06-03", "", ""); len(logs) != 0 {
+			t.Errorf("GetReturnLogs() after the range = %+v", logs)
+		}
+
+		if err := repo.CreateReturnLog(&models.ReturnCardLog{VisitorID: 99, CardID: "RF9999", ReturnDate: repo.clock.TodayDate()}); err == nil {
+			t.Error("return log of a missing visitor should fail")
+		}
+	})
+}
+
+func TestVoidReturnLog(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *sql.DB) {
+		now := testNow
+		repo := NewVisitorRepository(db, testClock(t, &now))
+		visits := seedVisits(t, db, repo)
+
+		logs, err := repo.GetReturnLogs("RF0002", "", "", "")
+		if err != nil || len(logs) != 1 {
+			t.Fatalf("GetReturnLogs() = %+v, %v", logs, err)
+		}
+
+		revision := &models.VisitorRevision{Action: models.RevisionVoidReturn, ChangedBy: "ยามหน้า", Reason: "สแกนผิดใบ"}
+		voided, err := repo.VoidReturnLog(logs[0].ID, revision)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if voided.VoidedAt == nil || voided.VoidedBy != "ยามหน้า" || voided.VisitorID != visits[1].ID {
+			t.Errorf("voided log = %+v", voided)
+		}
+		if _, err := repo.VoidReturnLog(logs[0].ID, revision); !errors.Is(err, ErrReturnAlreadyVoided) {
+			t.Errorf("second VoidReturnLog() = %v, want ErrReturnAlreadyVoided", err)
+		}
+		if _, err := repo.VoidReturnLog(99, revision); !errors.Is(err, ErrReturnLogNotFound) {
+			t.Errorf("VoidReturnLog(99) = %v, want ErrReturnLogNotFound", err)
+		}
+		if _, err := repo.ForSchool(2).VoidReturnLog(logs[0].ID, revision); !errors.Is(err, ErrReturnLogNotFound) {
+			t.Errorf("school 2 VoidReturnLog() = %v, want ErrReturnLogNotFound", err)
+		}
+
+		// รายการกลับเป็นยังอยู่ในโรงเรียน การคืนที่ยกเลิกไม่นับเป็นคืนซ้ำและไม่อยู่ในรายงาน
+		reopened, err := repo.GetByID(visits[1].ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reopened.ExitTime != nil || reopened.Version != 3 {
+			t.Errorf("reopened visit = exit %v version %d", reopened.ExitTime, reopened.Version)
+		}
+		if dup, err := repo.CheckDuplicateReturn("RF0002"); err != nil || dup {
+			t.Errorf("CheckDuplicateReturn() after void = %v, %v", dup, err)
+		}
+		if logs, _ := repo.GetReturnLogs("", "", "", ""); len(logs) != 0 {
+			t.Errorf("GetReturnLogs() after void = %+v", logs)
+		}
+
+		revisions, err := repo.ListRevisions(visits[1].ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(revisions) != 1 || revisions[0].Action != models.RevisionVoidReturn || revisions[0].Reason != "สแกนผิดใบ" ||
+			len(revisions[0].Changes) != 1 || revisions[0].Changes[0].Field != "exitTime" || revisions[0].Changes[0].Old == "" {
+			t.Errorf("revisions = %+v", revisions)
+		}
+	})
+}
+
+// แตะบัตรใบเดียวพร้อมกันหลายครั้งต้องได้บันทึกคืนบัตรเดียว
+func TestConcurrentReturns(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *sql.DB) {
+		now := testNow
+		repo := NewVisitorRepository(db, testClock(t, &now))
+		v := testVisitor(1)
+		if err := repo.Create(v); err != nil {
+			t.Fatal(err)
+		}
+
+		const taps = 20
+		errs := make([]error, taps)
+		var wg sync.WaitGroup
+		for i := 0; i < taps; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				errs[i] = repo.CreateReturnLog(&models.ReturnCardLog{
+					ClientID:   fmt.Sprintf("00000000-0000-4000-8000-%012d", i),
+					VisitorID:  v.ID,
+					CardID:     v.RFID,
+					CheckOut:   "10:00",
+					ReturnDate: repo.clock.TodayDate(),
+				})
+			}(i)
+		}
+		wg.Wait()
+
+		created := 0
+		for _, err := range errs {
+			switch {
+			case err == nil:
+				created++
+			case !errors.Is(err, ErrDuplicateReturn):
+				t.Errorf("CreateReturnLog() = %v", err)
+			}
+		}
+		if created != 1 {
+			t.Errorf("%d of %d concurrent returns were recorded, want 1", created, taps)
+		}
+		if logs, err := repo.GetReturnLogs("", "", "", ""); err != nil || len(logs) != 1 {
+			t.Errorf("GetReturnLogs() = %d logs, %v", len(logs), err)
+		}
+		if got, err := repo.GetByID(v.ID); err != nil || got.Version != 2 {
+			t.Errorf("visitor version after concurrent returns = %+v, %v", got, err)
+		}
+	})
+}
+
+func TestVehiclesAndSearch(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *sql.DB) {
+		now := testNow
+		clk := testClock(t, &now)
+		visits := seedVisits(t, db, NewVisitorRepository(db, clk))
+
+		// ทะเบียนเดิมใช้รถคันเดิม สีว่างไม่ทับสีเดิม
+		if *visits[0].VehicleID != *visits[1].VehicleID {
+			t.Errorf("vehicle ids = %d, %d, want the same vehicle", *visits[0].VehicleID, *visits[1].VehicleID)
+		}
+		vehicles := NewVehicleRepository(db, clk)
+		byPlate, err := vehicles.GetVisitsByPlate("กข1234", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(byPlate) != 2 || byPlate[0].VisitorID != visits[1].ID || byPlate[0].Color != "ขาว" || byPlate[0].PlateNumber != "กข-1234" {
+			t.Errorf("GetVisitsByPlate() = %+v", byPlate)
+		}
+		parked, err := vehicles.GetParked()
+		if err != nil || len(parked) != 1 || parked[0].VisitorID != visits[0].ID || parked[0].RegisteredAt != "02/06/2025 08:00:00" {
+			t.Errorf("GetParked() = %+v, %v", parked, err)
+		}
+
+		searches := NewSearchRepository(db, clk)
+		hits, err := searches.SearchVisitors(search.Parse("RF0002"), 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(hits) == 0 || hits[0].ID != visits[1].ID || hits[0].Score < 100 || hits[0].ExitTime != "02/06/2025 10:00:00" {
+			t.Errorf("SearchVisitors(RF0002) = %+v", hits)
+		}
+		// visitor 3 ไม่มีบัตร (rfid เป็น NULL)
+		if hits, err := searches.SearchVisitors(search.Parse("คนที่"), 10); err != nil || len(hits) != 3 {
+			t.Errorf("SearchVisitors(คนที่) = %d visitors, %v, want 3", len(hits), err)
+		}
+		// เบอร์ที่เก็บแบบมีขีดหาเจอทั้งเมื่อพิมพ์ติดกันและพิมพ์มีขีด
+		if _, err := db.Exec(`UPDATE visitors SET phone = '081-000-0003' WHERE id = ?`, visits[2].ID); err != nil {
+			t.Fatal(err)
+		}
+		for _, raw := range []string{"0810000003", "081-000-0003", "081 000 0003"} {
+			if hits, err := searches.SearchVisitors(search.Parse(raw), 10); err != nil || len(hits) != 1 || hits[0].ID != visits[2].ID {
+				t.Errorf("SearchVisitors(%s) = %+v, %v", raw, hits, err)
+			}
+		}
+		logs, err := searches.SearchReturnLogs(search.Parse("RF0002"), 10)
+		if err != nil || len(logs) != 1 || logs[0].Date != "02/06/2025" {
+			t.Errorf("SearchReturnLogs(RF0002) = %+v, %v", logs, err)
+		}
+	})
+}
+
+// การลงทะเบียนที่ไม่ได้ส่งประเภทรถ (เช่น sync จากเครื่อง edge) ไม่ทับประเภทเดิม
+// และรถที่ลงทะเบียนเมื่อวานแต่ยังไม่คืนบัตรยังนับว่าจอดอยู่
+func TestVehicleTypeAndOvernightParking(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *sql.DB) {
+		now := testNow
+		clk := testClock(t, &now)
+		visitors := NewVisitorRepository(db, clk)
+
+		van := testVisitor(1)
+		van.LicensePlate = "ขค-5678"
+		van.Vehicle = &models.Vehicle{PlateNumber: "ขค5678", PlateDisplay: "ขค 5678", Province: "นนทบุรี", VehicleType: "van"}
+		untyped := testVisitor(2)
+		untyped.LicensePlate = "ขค-5678"
+		untyped.Vehicle = &models.Vehicle{PlateNumber: "ขค5678", PlateDisplay: "ขค-5678", Province: "นนทบุรี"}
+		fresh := testVisitor(3)
+		fresh.LicensePlate = "งจ-9"
+		fresh.Vehicle = &models.Vehicle{PlateNumber: "งจ9", PlateDisplay: "งจ 9", Province: "นนทบุรี"}
+		for _, v := range []*models.Visitor{van, untyped, fresh} {
+			if err := visitors.Create(v); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		var vehicleType string
+		if err := db.QueryRow(`SELECT vehicle_type FROM vehicles WHERE id = ?`, van.Vehicle.ID).Scan(&vehicleType); err != nil || vehicleType != "van" {
+			t.Errorf("vehicle_type = %q, %v, want van", vehicleType, err)
+		}
+		if err := db.QueryRow(`SELECT vehicle_type FROM vehicles WHERE id = ?`, fresh.Vehicle.ID).Scan(&vehicleType); err != nil || vehicleType != "car" {
+			t.Errorf("new vehicle_type = %q, %v, want car", vehicleType, err)
+		}
+
+		// ลงทะเบียน 23:30 เมื่อวาน
+		if _, err := db.Exec(`UPDATE visitors SET registered_at = ? WHERE id = ?`, time.Date(2025, 6, 1, 16, 30, 0, 0, time.UTC), van.ID); err != nil {
+			t.Fatal(err)
+		}
+		parked, err := NewVehicleRepository(db, clk).GetParked()
+		if err != nil || len(parked) != 3 || parked[0].VisitorID != van.ID || parked[0].VehicleType != "van" {
+			t.Errorf("GetParked() = %+v, %v", parked, err)
+		}
+	})
+}
+
+func TestVisitFromAppointment(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *sql.DB) {
+		now := testNow
+		clk := testClock(t, &now)
+		appointments := NewAppointmentRepository(db, clk)
+		visitors := NewVisitorRepository(db, clk)
+
+		appt := &models.Appointment{
+			FirstName:     "สมชาย",
+			LastName:      "ใจดี",
+			HostName:      "ครูสมศรี",
+			ExpectedFrom:  time.Date(2025, 6, 2, 2, 0, 0, 0, time.UTC),
+			ExpectedUntil: time.Date(2025, 6, 2, 5, 0, 0, 0, time.UTC),
+			Status:        models.AppointmentPending,
+		}
+		if err := appointments.Create(appt); err != nil {
+			t.Fatal(err)
+		}
+		if list, err := appointments.List("2025-06-02", models.AppointmentPending); err != nil || len(list) != 1 || !list[0].ExpectedFrom.Equal(appt.ExpectedFrom) {
+			t.Errorf("List(2025-06-02) = %+v, %v", list, err)
+		}
+		if list, _ := appointments.List("2025-06-03", ""); len(list) != 0 {
+			t.Errorf("List(2025-06-03) = %+v", list)
+		}
+
+		v := testVisitor(1)
+		v.AppointmentID = &appt.ID
+		if err := visitors.Create(v); err != nil {
+			t.Fatal(err)
+		}
+		arrived, err := appointments.GetByID(appt.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if arrived.Status != models.AppointmentArrived || arrived.VisitorID == nil || *arrived.VisitorID != v.ID || arrived.ArrivedAt == nil || !arrived.ArrivedAt.Equal(testNow) {
+			t.Errorf("appointment after arrival = %+v", arrived)
+		}
+
+		// QR เดิมใช้ซ้ำไม่ได้ และผู้มาติดต่อคนที่สองต้องไม่ถูกบันทึก
+		again := testVisitor(2)
+		again.AppointmentID = &appt.ID
+		if err := visitors.Create(again); !errors.Is(err, ErrAppointmentNotPending) {
+			t.Errorf("reused appointment error = %v", err)
+		}
+		if exists, _ := visitors.CheckIDCardExists(again.IDCard); exists {
+			t.Error("visitor of a rejected appointment was stored")
+		}
+		if err := appointments.Cancel(appt.ID); !errors.Is(err, ErrAppointmentNotPending) {
+			t.Errorf("Cancel() of an arrived appointment = %v", err)
+		}
+	})
+}
+
+func TestVisitorRevisions(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *sql.DB) {
+		now := testNow
+		repo := NewVisitorRepository(db, testClock(t, &now))
+
+		v := testVisitor(1)
+		if err := repo.Create(v); err != nil {
+			t.Fatal(err)
+		}
+		stale, err := repo.GetByID(v.ID)
+		if err != nil || stale.Version != 1 {
+			t.Fatalf("new visitor = %+v, %v", stale, err)
+		}
+
+		edited := *stale
+		edited.Phone = "0899999999"
+		rev := &models.VisitorRevision{
+			Action:    models.RevisionUpdate,
+			Changes:   []models.VisitorChange{{Field: "phone", Old: stale.Phone, New: edited.Phone}},
+			ChangedBy: "ธุรการ",
+			Reason:    "พิมพ์เบอร์ผิด",
+		}
+		if err := repo.Update(&edited, rev); err != nil {
+			t.Fatal(err)
+		}
+		if edited.Version != 2 || rev.Version != 2 || rev.ID == 0 {
+			t.Errorf("after Update() version = %d, revision = %+v", edited.Version, rev)
+		}
+
+		// แก้จากข้อมูลที่อ่านไว้ก่อนหน้าไม่ได้
+		stale.LastName = "ใจงาม"
+		if err := repo.Update(stale, &models.VisitorRevision{Action: models.RevisionUpdate, ChangedBy: "ยาม"}); !errors.Is(err, ErrVersionConflict) {
+			t.Errorf("stale Update() = %v, want ErrVersionConflict", err)
+		}
+		if got, _ := repo.GetByID(v.ID); got.Phone != edited.Phone || got.LastName != v.LastName || got.Version != 2 {
+			t.Errorf("stored visitor = %+v", got)
+		}
+
+		// บันทึกคืนบัตรก็นับเป็นการเปลี่ยนแปลง
+		if err := repo.CreateReturnLog(&models.ReturnCardLog{VisitorID: v.ID, CardID: v.RFID, ReturnDate: repo.clock.TodayDate()}); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.Delete(&edited, &models.VisitorRevision{Action: models.RevisionDelete, ChangedBy: "ธุรการ", Reason: "ซ้ำ"}); !errors.Is(err, ErrVersionConflict) {
+			t.Errorf("Delete() after a return = %v, want ErrVersionConflict", err)
+		}
+
+		current, err := repo.GetByID(v.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.Delete(current, &models.VisitorRevision{Action: models.RevisionDelete, ChangedBy: "ธุรการ", Reason: "ซ้ำ"}); err != nil {
+			t.Fatal(err)
+		}
+		if current.DeletedAt == nil || current.RFID != "" {
+			t.Errorf("deleted visitor = %+v", current)
+		}
+		if _, err := repo.GetByID(v.ID); err == nil {
+			t.Error("GetByID() returned a deleted visit")
+		}
+		if list, err := repo.List(models.QueryParams{}); err != nil || len(list) != 0 {
+			t.Errorf("List() after delete = %+v, %v", list, err)
+		}
+		if exists, _ := repo.CheckRFIDExists(v.RFID); exists {
+			t.Error("RFID of a deleted visit is still taken")
+		}
+		if exists, _ := repo.CheckIDCardExists(v.IDCard); exists {
+			t.Error("ID card of a deleted visit is still taken")
+		}
+
+		// ลงทะเบียนคนเดิมใหม่ได้ แต่ยังซ้ำกับรายการที่ไม่ถูกลบไม่ได้
+		again := testVisitor(1)
+		if err := repo.Create(again); err != nil {
+			t.Fatalf("Create() with the ID card of a deleted visit = %v", err)
+		}
+		dup := testVisitor(2)
+		dup.IDCard = v.IDCard
+		if err := repo.Create(dup); err == nil {
+			t.Error("Create() with a live ID card should fail")
+		}
+
+		revisions, err := repo.ListRevisions(v.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(revisions) != 2 || revisions[0].Changes[0].New != "0899999999" || revisions[0].Reason != "พิมพ์เบอร์ผิด" ||
+			revisions[1].Action != models.RevisionDelete || revisions[1].Version != 4 || revisions[1].CreatedAt.IsZero() {
+			t.Errorf("revisions = %+v", revisions)
+		}
+		if other, err := repo.ForSchool(2).ListRevisions(v.ID); err != nil || len(other) != 0 {
+			t.Errorf("school 2 revisions = %+v, %v", other, err)
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"database/sql"
+
+	"backend/internal/models"
+)
+
+// visitorColumns is the column list read by scanVisitor
+const visitorColumns = `
+	id, client_id, id_card, first_name, last_name, birth_date, phone, license_plate, vehicle_id,
+	house_number, moo, soi, road, sub_district, district, province, postal_code,
+	rfid, department, department_id, officer_name, id_card_image,
+	photo_key, photo_thumbnail_key, photo_taken_at,
+	host_name, host_contact, purpose, purpose_note,
+	approval_status, approved_by, approved_at, approval_note,
+	registered_at, exit_time, updated_at, version, deleted_at
+`
+
+// scanVisitor reads one row selected with visitorColumns.
+// คอลัมน์ที่เป็น NULL ได้จะอ่านผ่าน sql.Null* แล้วแปลงเป็นค่าว่าง
+func scanVisitor(row rowScanner) (*models.Visitor, error) {
+	v := &models.Visitor{}
+	var licensePlate, houseNumber, moo, soi, road, subDistrict, district, province sql.NullString
+	var clientID, rfid, department, officerName, idCardImage sql.NullString
+	var photoKey, photoThumbnailKey sql.NullString
+	var vehicleID, departmentID sql.NullInt64
+
+	err := row.Scan(
+		&v.ID,
+		&clientID,
+		&v.IDCard,
+		&v.FirstName,
+		&v.LastName,
+		&v.BirthDate,
+		&v.Phone,
+		&licensePlate,
+		&vehicleID,
+		&houseNumber,
+		&moo,
+		&soi,
+		&road,
+		&subDistrict,
+		&district,
+		&province,
+		&v.PostalCode,
+		&rfid,
+		&department,
+		&departmentID,
+		&officerName,
+		&idCardImage,
+		&photoKey,
+		&photoThumbnailKey,
+		&v.PhotoTakenAt,
+		&v.HostName,
+		&v.HostContact,
+		&v.Purpose,
+		&v.PurposeNote,
+		&v.ApprovalStatus,
+		&v.ApprovedBy,
+		&v.ApprovedAt,
+		&v.ApprovalNote,
+		&v.RegisteredAt,
+		&v.ExitTime,
+		&v.UpdatedAt,
+		&v.Version,
+		&v.DeletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	v.ClientID = clientID.String
+	v.LicensePlate = licensePlate.String
+	v.HouseNumber = houseNumber.String
+	v.Moo = moo.String
+	v.Soi = soi.String
+	v.Road = road.String
+	v.SubDistrict = subDistrict.String
+	v.District = district.String
+	v.Province = province.String
+	v.RFID = rfid.String
+	v.Department = department.String
+	v.OfficerName = officerName.String
+	v.IDCardImage = idCardImage.String
+	v.PhotoKey = photoKey.String
+	v.PhotoThumbnailKey = photoThumbnailKey.String
+
+	if vehicleID.Valid {
+		id := int(vehicleID.Int64)
+		v.VehicleID = &id
+	}
+	if departmentID.Valid {
+		id := int(departmentID.Int64)
+		v.DepartmentID = &id
+	}
+
+	return v, nil
+}
+
+// nullIfEmpty stores empty strings as NULL (ใช้กับคอลัมน์ UNIQUE เช่น rfid)
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"backend/internal/database"
+	"backend/internal/models"
+)
+
+// WatchlistRepository stores the watchlist of a school
+type WatchlistRepository struct {
+	db      *sql.DB
+	dialect database.Dialect
+	school  int
+}
+
+func NewWatchlistRepository(db *sql.DB) *WatchlistRepository {
+	return &WatchlistRepository{db: db, dialect: database.DialectOf(db), school: models.DefaultSchoolID}
+}
+
+// ForSchool returns a copy of the repository that works on the watchlist of school id
+func (r *WatchlistRepository) ForSchool(id int) *WatchlistRepository {
+	scoped := *r
+	scoped.school = id
+	return &scoped
+}
+
+const watchlistColumns = `id, id_card, name, reason, added_by, active, created_at`
+
+func scanWatchlistEntry(row rowScanner) (*models.WatchlistEntry, error) {
+	var e models.WatchlistEntry
+	if err := row.Scan(&e.ID, &e.IDCard, &e.Name, &e.Reason, &e.AddedBy, &e.Active, &e.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// List retrieves the watchlist, newest first
+func (r *WatchlistRepository) List(activeOnly bool) ([]models.WatchlistEntry, error) {
+	query := `SELECT ` + watchlistColumns + ` FROM watchlist WHERE school_id = ?`
+	if activeOnly {
+		query += ` AND active = TRUE`
+	}
+	query += ` ORDER BY id DESC`
+
+	rows, err := r.db.Query(query, r.school)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list watchlist: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.WatchlistEntry{}
+	for rows.Next() {
+		e, err := scanWatchlistEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan watchlist entry: %w", err)
+		}
+		entries = append(entries, *e)
+	}
+
+	return entries, nil
+}
+
+// GetByID retrieves a watchlist entry by ID
+func (r *WatchlistRepository) GetByID(id int) (*models.WatchlistEntry, error) {
+	return r.get(`id = ?`, id)
+}
+
+// GetByIDCard retrieves the entry of an ID card, active or not
+func (r *WatchlistRepository) GetByIDCard(idCard string) (*models.WatchlistEntry, error) {
+	return r.get(`id_card = ?`, idCard)
+}
+
+func (r *WatchlistRepository) get(where string, arg interface{}) (*models.WatchlistEntry, error) {
+	e, err := scanWatchlistEntry(r.db.QueryRow(`SELECT `+watchlistColumns+` FROM watchlist WHERE school_id = ? AND `+where, r.school, arg))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("watchlist entry not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get watchlist entry: %w", err)
+	}
+	return e, nil
+}
+
+// Match returns the active entry of idCard, or nil when the person is not watched
+func (r *WatchlistRepository) Match(idCard string) (*models.WatchlistEntry, error) {
+	e, err := scanWatchlistEntry(r.db.QueryRow(`
+		SELECT `+watchlistColumns+` FROM watchlist
+		WHERE school_id = ? AND id_card = ? AND active = TRUE
+	`, r.school, idCard))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check watchlist: %w", err)
+	}
+	return e, nil
+}
+
+// Create adds a person to the watchlist
+func (r *WatchlistRepository) Create(e *models.WatchlistEntry) error {
+	id, err := insertID(r.db, r.dialect, `
+		INSERT INTO watchlist (school_id, id_card, name, reason, added_by, active)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, r.school, e.IDCard, e.Name, e.Reason, e.AddedBy, e.Active)
+	if err != nil {
+		return fmt.Errorf("failed to create watchlist entry: %w", err)
+	}
+
+	e.ID = id
+	return nil
+}
+
+// Update changes a watchlist entry
+func (r *WatchlistRepository) Update(e *models.WatchlistEntry) error {
+	_, err := r.db.Exec(`
+		UPDATE watchlist SET id_card = ?, name = ?, reason = ?, added_by = ?, active = ?
+		WHERE id = ? AND school_id = ?
+	`, e.IDCard, e.Name, e.Reason, e.AddedBy, e.Active, e.ID, r.school)
+	if err != nil {
+		return fmt.Errorf("failed to update watchlist entry: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a watchlist entry
+func (r *WatchlistRepository) Delete(id int) error {
+	if _, err := r.db.Exec(`DELETE FROM watchlist WHERE id = ? AND school_id = ?`, id, r.school); err != nil {
+		return fmt.Errorf("failed to delete watchlist entry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"database/sql"
+	"testing"
+
+	"backend/internal/models"
+)
+
+func TestWatchlistRepository(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *sql.DB) {
+		repo := NewWatchlistRepository(db)
+
+		e := &models.WatchlistEntry{IDCard: "1101234500001", Name: "สมชาย ใจดี", Reason: "ศาลสั่งห้ามเข้าใกล้นักเรียน", AddedBy: "ผอ.", Active: true}
+		if err := repo.Create(e); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.Create(&models.WatchlistEntry{IDCard: e.IDCard, Reason: "ซ้ำ", AddedBy: "ผอ."}); err == nil {
+			t.Error("duplicate ID card should fail")
+		}
+
+		if got, err := repo.Match(e.IDCard); err != nil || got == nil || got.ID != e.ID || got.CreatedAt.IsZero() {
+			t.Errorf("Match() = %+v, %v", got, err)
+		}
+		if got, err := repo.Match("1101234500002"); err != nil || got != nil {
+			t.Errorf("Match() of someone not watched = %+v, %v", got, err)
+		}
+
+		// รายชื่อของโรงเรียนอื่นไม่ถูกตรวจ
+		other := repo.ForSchool(2)
+		if got, err := other.Match(e.IDCard); err != nil || got != nil {
+			t.Errorf("school 2 Match() = %+v, %v", got, err)
+		}
+		if _, err := other.GetByID(e.ID); err == nil {
+			t.Error("school 2 can read school 1's entry")
+		}
+
+		e.Active = false
+		if err := repo.Update(e); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := repo.Match(e.IDCard); err != nil || got != nil {
+			t.Errorf("Match() of an inactive entry = %+v, %v", got, err)
+		}
+		if got, err := repo.GetByIDCard(e.IDCard); err != nil || got.Active {
+			t.Errorf("GetByIDCard() = %+v, %v", got, err)
+		}
+		if list, err := repo.List(true); err != nil || len(list) != 0 {
+			t.Errorf("active watchlist = %+v, %v", list, err)
+		}
+
+		if err := repo.Delete(e.ID); err != nil {
+			t.Fatal(err)
+		}
+		if list, err := repo.List(false); err != nil || len(list) != 0 {
+			t.Errorf("watchlist after delete = %+v, %v", list, err)
+		}
+	})
+}
@@ -0,0 +1,93 @@
+// Package retention deletes visits older than each school's retention period.
+//
+// โรงเรียนที่ตั้ง retention_days ไว้ (0 = เก็บตลอด) จะถูกลบข้อมูลผู้มาติดต่อที่ลงทะเบียนก่อน
+// "วันนี้ - retention_days" ตามเวลาของโรงเรียน พร้อมรูปถ่ายในที่เก็บไฟล์ ตรวจวันละครั้ง
+package retention
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"backend/internal/clock"
+	"backend/internal/repository"
+	"backend/internal/storage"
+	"backend/internal/tenant"
+)
+
+// checkInterval is how often the purger runs
+const checkInterval = 24 * time.Hour
+
+// Purger removes expired visits of every active school
+type Purger struct {
+	schools  *repository.SchoolRepository
+	visitors *repository.VisitorRepository
+	files    storage.Storage
+	clock    *clock.Clock
+}
+
+// NewPurger creates a purger; clk is the clock of schools without a timezone of their own
+func NewPurger(schools *repository.SchoolRepository, visitors *repository.VisitorRepository, files storage.Storage, clk *clock.Clock) *Purger {
+	return &Purger{schools: schools, visitors: visitors, files: files, clock: clk}
+}
+
+// Run purges once a day until ctx is cancelled
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := p.Purge(); err != nil {
+			log.Printf("Failed to purge expired visits: %v", err)
+		} else if n > 0 {
+			log.Printf("Purged %d expired visits", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge deletes the expired visits of every school and returns how many were deleted.
+// รูปที่ลบไม่สำเร็จแค่บันทึก log ไว้ ข้อมูลในฐานข้อมูลถูกลบไปแล้ว
+func (p *Purger) Purge() (int, error) {
+	schools, err := p.schools.List(true)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for i := range schools {
+		school := &schools[i]
+		if school.RetentionDays <= 0 {
+			continue
+		}
+
+		clk, err := tenant.Clock(school, p.clock)
+		if err != nil {
+			return total, err
+		}
+		cutoffDate := clk.TodayDate().AddDate(0, 0, -school.RetentionDays).Format("2006-01-02")
+		cutoff, err := clk.StartOfDay(cutoffDate)
+		if err != nil {
+			return total, err
+		}
+
+		keys, n, err := p.visitors.ForSchool(school.ID).PurgeRegisteredBefore(cutoff)
+		if err != nil {
+			return total, fmt.Errorf("failed to purge school %s: %w", school.Code, err)
+		}
+		total += n
+
+		for _, key := range keys {
+			if err := p.files.Delete(key); err != nil {
+				log.Printf("Failed to delete photo %s of a purged visit: %v", key, err)
+			}
+		}
+	}
+
+	return total, nil
+}
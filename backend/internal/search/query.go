@@ -0,0 +1,128 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NgramTokenSize ต้องตรงกับค่า ngram_token_size ของ MySQL (ค่าเริ่มต้นคือ 2)
+const NgramTokenSize = 2
+
+// Query represents a parsed search string
+type Query struct {
+	Raw    string
+	Terms  []string
+	Phrase string
+}
+
+// Parse แยกคำค้นหาออกเป็น term สำหรับค้นหาหลายฟิลด์
+// เช่น "สมชาย ใจดี" -> ["สมชาย", "ใจดี"], "081-234-5678" -> ["0812345678"]
+func Parse(raw string) Query {
+	q := Query{Raw: strings.TrimSpace(raw)}
+
+	for _, field := range strings.Fields(q.Raw) {
+		term := normalizeTerm(field)
+		if term != "" {
+			q.Terms = append(q.Terms, term)
+		}
+	}
+	q.Phrase = strings.Join(q.Terms, " ")
+
+	return q
+}
+
+// IsEmpty reports whether the query has no searchable terms
+func (q Query) IsEmpty() bool {
+	return len(q.Terms) == 0
+}
+
+// BooleanMode สร้าง query สำหรับ MATCH ... AGAINST (? IN BOOLEAN MODE)
+// ทุก term ต้องตรง (+) และค้นหาแบบ prefix (*)
+func (q Query) BooleanMode() string {
+	parts := make([]string, 0, len(q.Terms))
+	for _, term := range q.Terms {
+		parts = append(parts, "+"+term+"*")
+	}
+	return strings.Join(parts, " ")
+}
+
+// UsesFullText reports whether every term can be matched with the ngram index.
+// Terms shorter than the token size can only be matched with LIKE,
+// and so can numbers: เบอร์โทรที่เก็บไว้อาจมีขีดคั่น ต้องเทียบกับค่าที่ตัดขีดออกแล้ว
+func (q Query) UsesFullText() bool {
+	if q.IsEmpty() {
+		return false
+	}
+	for _, term := range q.Terms {
+		if len([]rune(term)) < NgramTokenSize || IsNumber(term) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsNumber reports whether a parsed term is all digits (เลขบัตร เบอร์โทร)
+func IsNumber(term string) bool {
+	if term == "" {
+		return false
+	}
+	for _, r := range term {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// Prefix returns a LIKE pattern matching values that start with s
+func Prefix(s string) string {
+	return escapeLike(s) + "%"
+}
+
+// Contains returns a LIKE pattern matching values that contain s
+func Contains(s string) string {
+	return "%" + escapeLike(s) + "%"
+}
+
+// normalizeTerm ตัดเครื่องหมายที่เป็น operator ของ boolean mode ออก
+// และรวมตัวเลขที่คั่นด้วย "-" หรือ "." (เบอร์โทร, เลขบัตร) ให้เป็นตัวเลขติดกัน
+func normalizeTerm(s string) string {
+	if isSeparatedDigits(s) {
+		return strings.Map(func(r rune) rune {
+			if unicode.IsDigit(r) {
+				return r
+			}
+			return -1
+		}, s)
+	}
+
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '+', '-', '<', '>', '(', ')', '~', '*', '"', '@', '\'', '\\':
+			return -1
+		}
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+func isSeparatedDigits(s string) bool {
+	hasDigit := false
+	for _, r := range s {
+		switch {
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case r == '-' || r == '.':
+		default:
+			return false
+		}
+	}
+	return hasDigit
+}
+
+func escapeLike(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return replacer.Replace(s)
+}
@@ -0,0 +1,75 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		raw   string
+		terms []string
+	}{
+		{"สมชาย ใจดี", []string{"สมชาย", "ใจดี"}},
+		{"  สมชาย   ใจดี  ", []string{"สมชาย", "ใจดี"}},
+		{"081-234-5678", []string{"0812345678"}},
+		{"081.234.5678", []string{"0812345678"}},
+		{"1-1012-34567-89-0", []string{"1101234567890"}},
+		{"กข-1234", []string{"กข1234"}},
+		{`+สมชาย* "ใจดี"`, []string{"สมชาย", "ใจดี"}},
+		{"- ---", nil},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			q := Parse(tt.raw)
+			if !reflect.DeepEqual(q.Terms, tt.terms) {
+				t.Errorf("Parse(%q).Terms = %q, want %q", tt.raw, q.Terms, tt.terms)
+			}
+			if q.IsEmpty() != (len(tt.terms) == 0) {
+				t.Errorf("Parse(%q).IsEmpty() = %v", tt.raw, q.IsEmpty())
+			}
+		})
+	}
+}
+
+func TestQueryClassification(t *testing.T) {
+	tests := []struct {
+		raw      string
+		number   bool
+		fullText bool
+		boolean  string
+	}{
+		{"สมชาย ใจดี", false, true, "+สมชาย* +ใจดี*"},
+		{"RF0002", false, true, "+RF0002*"},
+		{"081-234-5678", true, false, "+0812345678*"},
+		{"1101234567890", true, false, "+1101234567890*"},
+		{"ก", false, false, "+ก*"},
+		{"สมชาย ก", false, false, "+สมชาย* +ก*"},
+		{"", false, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			q := Parse(tt.raw)
+			number := len(q.Terms) > 0 && IsNumber(q.Terms[0])
+			if number != tt.number {
+				t.Errorf("IsNumber(%q) = %v, want %v", q.Terms, number, tt.number)
+			}
+			if got := q.UsesFullText(); got != tt.fullText {
+				t.Errorf("UsesFullText() = %v, want %v", got, tt.fullText)
+			}
+			if got := q.BooleanMode(); got != tt.boolean {
+				t.Errorf("BooleanMode() = %q, want %q", got, tt.boolean)
+			}
+		})
+	}
+}
+
+func TestLikePatterns(t *testing.T) {
+	if got := Prefix("50%_off"); got != `50\%\_off%` {
+		t.Errorf("Prefix() = %q", got)
+	}
+	if got := Contains(`a\b`); got != `%a\\b%` {
+		t.Errorf("Contains() = %q", got)
+	}
+}
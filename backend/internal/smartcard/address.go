@@ -0,0 +1,82 @@
+package smartcard
+
+import (
+	"strings"
+
+	"backend/internal/address"
+)
+
+// Address is the card address split into the registration form fields
+type Address struct {
+	HouseNumber string `json:"houseNumber"`
+	Moo         string `json:"moo"`
+	Soi         string `json:"soi"`
+	Road        string `json:"road"`
+	SubDistrict string `json:"subDistrict"`
+	District    string `json:"district"`
+	Province    string `json:"province"`
+}
+
+// SplitAddress splits the '#'-separated card address.
+// ช่องที่ว่างจะถูกข้าม จึงดูชนิดของแต่ละส่วนจากคำนำหน้า (หมู่ที่ ซอย ถนน ตำบล/แขวง อำเภอ/เขต จังหวัด)
+// ไม่ใช่จากตำแหน่ง ส่วนแรกที่ไม่มีคำนำหน้าถือเป็นบ้านเลขที่
+func SplitAddress(s string) Address {
+	var a Address
+	for _, part := range strings.Split(s, "#") {
+		part = strings.Join(strings.Fields(part), " ")
+		if part == "" {
+			continue
+		}
+
+		switch {
+		case hasAnyPrefix(part, "หมู่บ้าน"):
+			a.HouseNumber = joinNonEmpty(a.HouseNumber, part)
+		case hasAnyPrefix(part, "หมู่ที่", "หมู่"):
+			a.Moo = trimAnyPrefix(part, "หมู่ที่", "หมู่")
+		case hasAnyPrefix(part, "ตรอก"):
+			// แบบฟอร์มไม่มีช่องตรอก เก็บรวมกับซอยโดยคงคำว่าตรอกไว้
+			a.Soi = joinNonEmpty(a.Soi, part)
+		case hasAnyPrefix(part, "ซอย", "ซ."):
+			a.Soi = joinNonEmpty(a.Soi, trimAnyPrefix(part, "ซอย", "ซ."))
+		case hasAnyPrefix(part, "ถนน", "ถ."):
+			a.Road = trimAnyPrefix(part, "ถนน", "ถ.")
+		case hasAnyPrefix(part, "ตำบล", "ต.", "แขวง"):
+			a.SubDistrict = address.NormalizeSubDistrict(part)
+		case hasAnyPrefix(part, "อำเภอ", "อ.", "เขต"):
+			a.District = address.NormalizeDistrict(part)
+		case hasAnyPrefix(part, "จังหวัด", "จ.", "กรุงเทพ"):
+			a.Province = address.NormalizeProvince(part)
+		case a.HouseNumber == "":
+			a.HouseNumber = trimAnyPrefix(part, "บ้านเลขที่", "เลขที่")
+		default:
+			// ส่วนอื่น ๆ เช่น ชื่อหมู่บ้าน/อาคาร ต่อท้ายบ้านเลขที่
+			a.HouseNumber = joinNonEmpty(a.HouseNumber, part)
+		}
+	}
+	return a
+}
+
+func hasAnyPrefix(s string, prefixes ...string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func trimAnyPrefix(s string, prefixes ...string) string {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return strings.TrimSpace(strings.TrimPrefix(s, prefix))
+		}
+	}
+	return s
+}
+
+func joinNonEmpty(a, b string) string {
+	if a == "" {
+		return b
+	}
+	return a + " " + b
+}
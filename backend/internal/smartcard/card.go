@@ -0,0 +1,220 @@
+// Package smartcard แปลงข้อมูลที่โปรแกรมอ่านบัตรประชาชน (card-reader agent) ส่งมา
+// ให้เป็นข้อมูลสำหรับลงทะเบียนผู้มาติดต่อ
+//
+// ข้อมูลบนบัตรเป็น TIS-620 เติมช่องว่างท้ายฟิลด์ วันที่เป็น พ.ศ. แบบ YYYYMMDD
+// ชื่อและที่อยู่คั่นแต่ละส่วนด้วย '#'
+package smartcard
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/thaidate"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+var (
+	ErrInvalidCID = errors.New("เลขประจำตัวประชาชนไม่ถูกต้อง")
+	ErrNoName     = errors.New("ไม่พบชื่อบนบัตร")
+)
+
+// Payload is the raw card data sent by the reader agent.
+// ฟิลด์ข้อความเป็น byte ตามที่อ่านได้จาก APDU (ใน JSON เป็น base64) ยังไม่ได้แปลง encoding
+type Payload struct {
+	CID         string `json:"cid"`
+	ThaiName    []byte `json:"thaiName"` // คำนำหน้า#ชื่อ#ชื่อกลาง#นามสกุล
+	EnglishName []byte `json:"englishName"`
+	BirthDate   string `json:"birthDate"` // YYYYMMDD (พ.ศ.)
+	Gender      string `json:"gender"`    // 1 = ชาย, 2 = หญิง
+	Issuer      []byte `json:"issuer"`
+	IssueDate   string `json:"issueDate"`  // YYYYMMDD (พ.ศ.)
+	ExpireDate  string `json:"expireDate"` // YYYYMMDD (พ.ศ.) หรือ 99999999 = ตลอดชีพ
+	Address     []byte `json:"address"`    // บ้านเลขที่#หมู่ที่#ตรอก#ซอย#ถนน#ตำบล#อำเภอ#จังหวัด
+	Photo       []byte `json:"photo"`      // JPEG (ถ้า agent อ่านรูปมาด้วย)
+}
+
+// Name is a name split into its parts
+type Name struct {
+	Title      string `json:"title"`
+	FirstName  string `json:"firstName"`
+	MiddleName string `json:"middleName"`
+	LastName   string `json:"lastName"`
+}
+
+// Card is the decoded content of a Thai national ID card
+type Card struct {
+	CID         string     `json:"cid"`
+	ThaiName    Name       `json:"thaiName"`
+	EnglishName Name       `json:"englishName"`
+	BirthDate   *time.Time `json:"birthDate"`
+	Gender      string     `json:"gender"`
+	Issuer      string     `json:"issuer"`
+	IssueDate   *time.Time `json:"issueDate"`
+	ExpireDate  *time.Time `json:"expireDate"` // nil = ตลอดชีพ หรืออ่านไม่ได้
+	Address     Address    `json:"address"`
+	Photo       []byte     `json:"-"`
+
+	// Warnings คือสิ่งที่เจ้าหน้าที่ควรตรวจเอง เช่น บัตรหมดอายุ หรือวันเกิดไม่ครบ
+	Warnings []string `json:"warnings"`
+}
+
+// Parse decodes the payload. now is used to check the card expiry.
+func Parse(p Payload, now time.Time) (*Card, error) {
+	cid := strings.TrimSpace(p.CID)
+	if !ValidCID(cid) {
+		return nil, ErrInvalidCID
+	}
+
+	card := &Card{
+		CID:         cid,
+		ThaiName:    splitName(decodeTIS620(p.ThaiName)),
+		EnglishName: splitName(decodeTIS620(p.EnglishName)),
+		Gender:      strings.TrimSpace(p.Gender),
+		Issuer:      decodeTIS620(p.Issuer),
+		Address:     SplitAddress(decodeTIS620(p.Address)),
+		Photo:       p.Photo,
+		Warnings:    []string{},
+	}
+	if card.ThaiName.FirstName == "" && card.EnglishName.FirstName == "" {
+		return nil, ErrNoName
+	}
+
+	var err error
+	if card.BirthDate, err = ParseBEDate(p.BirthDate); err != nil {
+		card.Warnings = append(card.Warnings, "วันเกิดบนบัตรไม่ครบ กรุณาตรวจสอบ")
+	}
+	if card.IssueDate, err = ParseBEDate(p.IssueDate); err != nil {
+		card.Warnings = append(card.Warnings, "อ่านวันออกบัตรไม่ได้")
+	}
+	if !isLifetime(p.ExpireDate) {
+		card.ExpireDate, err = ParseBEDate(p.ExpireDate)
+		switch {
+		case err != nil:
+			card.Warnings = append(card.Warnings, "อ่านวันหมดอายุบัตรไม่ได้")
+		case card.ExpireDate.Before(startOfDay(now)):
+			card.Warnings = append(card.Warnings, "บัตรประชาชนหมดอายุแล้ว")
+		}
+	}
+
+	return card, nil
+}
+
+// ToCreateVisitorRequest fills the registration form from the card.
+// ใช้ชื่อภาษาไทยเป็นหลัก ถ้าไม่มี (เช่น บัตรชาวต่างชาติ) ใช้ชื่อภาษาอังกฤษ
+func (c *Card) ToCreateVisitorRequest() models.CreateVisitorRequest {
+	name := c.ThaiName
+	if name.FirstName == "" {
+		name = c.EnglishName
+	}
+	firstName := name.FirstName
+	if name.MiddleName != "" {
+		firstName += " " + name.MiddleName
+	}
+
+	req := models.CreateVisitorRequest{
+		IDCard:      c.CID,
+		FirstName:   firstName,
+		LastName:    name.LastName,
+		HouseNumber: c.Address.HouseNumber,
+		Moo:         c.Address.Moo,
+		Soi:         c.Address.Soi,
+		Road:        c.Address.Road,
+		SubDistrict: c.Address.SubDistrict,
+		District:    c.Address.District,
+		Province:    c.Address.Province,
+	}
+	if c.BirthDate != nil {
+		birthDate := c.BirthDate.Format("2006-01-02")
+		req.BirthDate = &birthDate
+	}
+	if len(c.Photo) > 0 {
+		req.IDCardImage = "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(c.Photo)
+	}
+
+	return req
+}
+
+// ValidCID checks the 13 digits and the mod-11 check digit of a Thai citizen ID
+func ValidCID(cid string) bool {
+	if len(cid) != 13 {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 13; i++ {
+		if cid[i] < '0' || cid[i] > '9' {
+			return false
+		}
+		if i < 12 {
+			sum += int(cid[i]-'0') * (13 - i)
+		}
+	}
+	return (11-sum%11)%10 == int(cid[12]-'0')
+}
+
+// ParseBEDate parses a YYYYMMDD Buddhist-era date into a Gregorian date.
+// บัตรของผู้ที่ไม่ทราบวัน/เดือนเกิดจะเป็น 00 ถือว่าอ่านไม่ได้
+func ParseBEDate(s string) (*time.Time, error) {
+	s = strings.TrimSpace(s)
+	if len(s) != 8 {
+		return nil, fmt.Errorf("invalid date: %q", s)
+	}
+
+	// แปลงปีก่อน เพราะปีอธิกสุรทินต้องคิดจากปี ค.ศ. (29 ก.พ.)
+	year, err := strconv.Atoi(s[:4])
+	if err != nil || year <= thaidate.BuddhistEraOffset {
+		return nil, fmt.Errorf("invalid date: %q", s)
+	}
+	t, err := time.Parse("20060102", fmt.Sprintf("%04d%s", year-thaidate.BuddhistEraOffset, s[4:]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid date: %q", s)
+	}
+	return &t, nil
+}
+
+func isLifetime(s string) bool {
+	s = strings.TrimSpace(s)
+	return s == "99999999" || s == ""
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// decodeTIS620 converts card bytes to UTF-8 and removes the padding.
+// ใช้ Windows-874 ซึ่งครอบคลุม TIS-620 ทั้งหมด
+func decodeTIS620(b []byte) string {
+	decoded, err := charmap.Windows874.NewDecoder().Bytes(b)
+	if err != nil {
+		return ""
+	}
+	return strings.Trim(string(decoded), " \x00")
+}
+
+// splitName splits "title#first#middle#last"
+func splitName(s string) Name {
+	parts := strings.Split(s, "#")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+
+	var n Name
+	switch len(parts) {
+	case 1:
+		n.FirstName = parts[0]
+	case 2:
+		n.FirstName, n.LastName = parts[0], parts[1]
+	case 3:
+		n.Title, n.FirstName, n.LastName = parts[0], parts[1], parts[2]
+	default:
+		n.Title, n.FirstName, n.MiddleName = parts[0], parts[1], parts[2]
+		n.LastName = strings.Join(parts[3:], " ")
+	}
+	return n
+}
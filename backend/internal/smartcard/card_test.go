@@ -0,0 +1,186 @@
+package smartcard
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// now ใช้วันที่คงที่ เพื่อให้ผลการตรวจวันหมดอายุบัตรไม่ขึ้นกับวันที่รันเทสต์
+var now = time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+
+func loadPayload(t *testing.T, name string) Payload {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var p Payload
+	if err := json.Unmarshal(data, &p); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestParseSamplePayloads(t *testing.T) {
+	tests := []struct {
+		sample    string
+		firstName string
+		lastName  string
+		birthDate string
+		address   Address
+		warnings  []string
+	}{
+		{
+			sample:    "provincial",
+			firstName: "สมชาย",
+			lastName:  "ใจดี",
+			birthDate: "1980-08-15",
+			address: Address{
+				HouseNumber: "99/1",
+				Moo:         "4",
+				Soi:         "สุขใจ",
+				Road:        "พหลโยธิน",
+				SubDistrict: "คลองหนึ่ง",
+				District:    "คลองหลวง",
+				Province:    "ปทุมธานี",
+			},
+			warnings: []string{},
+		},
+		{
+			sample:    "bangkok",
+			firstName: "สมหญิง มณี",
+			lastName:  "รักเรียน",
+			birthDate: "2000-02-29",
+			address: Address{
+				HouseNumber: "123",
+				SubDistrict: "ลาดยาว",
+				District:    "จตุจักร",
+				Province:    "กรุงเทพมหานคร",
+			},
+			warnings: []string{},
+		},
+		{
+			sample:    "unknown_birth_expired",
+			firstName: "บัวผัน",
+			lastName:  "ศรีสุข",
+			address: Address{
+				HouseNumber: "45/6 หมู่บ้านสวนสน",
+				Moo:         "2",
+				Soi:         "ตรอกวัดโพธิ์",
+				SubDistrict: "รอบเวียง",
+				District:    "เมืองเชียงราย",
+				Province:    "เชียงราย",
+			},
+			warnings: []string{"วันเกิดบนบัตรไม่ครบ กรุณาตรวจสอบ", "บัตรประชาชนหมดอายุแล้ว"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sample, func(t *testing.T) {
+			card, err := Parse(loadPayload(t, tt.sample), now)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			req := card.ToCreateVisitorRequest()
+			if req.FirstName != tt.firstName || req.LastName != tt.lastName {
+				t.Errorf("name = %q %q, want %q %q", req.FirstName, req.LastName, tt.firstName, tt.lastName)
+			}
+			if req.IDCard != card.CID {
+				t.Errorf("IDCard = %q, want %q", req.IDCard, card.CID)
+			}
+
+			gotBirth := ""
+			if req.BirthDate != nil {
+				gotBirth = *req.BirthDate
+			}
+			if gotBirth != tt.birthDate {
+				t.Errorf("BirthDate = %q, want %q", gotBirth, tt.birthDate)
+			}
+
+			if card.Address != tt.address {
+				t.Errorf("Address = %+v, want %+v", card.Address, tt.address)
+			}
+			if req.Moo != tt.address.Moo || req.Province != tt.address.Province {
+				t.Errorf("request address = %q/%q, want %q/%q", req.Moo, req.Province, tt.address.Moo, tt.address.Province)
+			}
+
+			if !reflect.DeepEqual(card.Warnings, tt.warnings) {
+				t.Errorf("Warnings = %q, want %q", card.Warnings, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestParseRejectsInvalidCID(t *testing.T) {
+	p := loadPayload(t, "provincial")
+	p.CID = "1101700123457" // check digit ผิด
+
+	if _, err := Parse(p, now); err != ErrInvalidCID {
+		t.Fatalf("Parse() error = %v, want %v", err, ErrInvalidCID)
+	}
+}
+
+func TestParseEnglishNameOnly(t *testing.T) {
+	p := loadPayload(t, "provincial")
+	p.ThaiName = nil
+
+	card, err := Parse(p, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := card.ToCreateVisitorRequest()
+	if req.FirstName != "Somchai" || req.LastName != "Jaidee" {
+		t.Errorf("name = %q %q, want Somchai Jaidee", req.FirstName, req.LastName)
+	}
+}
+
+func TestParseBEDate(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "25230815", want: "1980-08-15"},
+		{in: "25430229", want: "2000-02-29"},
+		{in: "25420229", wantErr: true}, // 1999 ไม่ใช่ปีอธิกสุรทิน
+		{in: "25100000", wantErr: true},
+		{in: "2523081", wantErr: true},
+		{in: "abcdefgh", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseBEDate(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseBEDate(%q) = %v, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseBEDate(%q) error = %v", tt.in, err)
+			continue
+		}
+		if got.Format("2006-01-02") != tt.want {
+			t.Errorf("ParseBEDate(%q) = %s, want %s", tt.in, got.Format("2006-01-02"), tt.want)
+		}
+	}
+}
+
+func TestValidCID(t *testing.T) {
+	for cid, want := range map[string]bool{
+		"1101700123456": true,
+		"3100500987657": true,
+		"1101700123450": false,
+		"110170012345":  false,
+		"11017001234a6": false,
+	} {
+		if got := ValidCID(cid); got != want {
+			t.Errorf("ValidCID(%q) = %v, want %v", cid, got, want)
+		}
+	}
+}
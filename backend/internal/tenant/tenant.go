@@ -0,0 +1,187 @@
+// Package tenant sends each request to the handlers of the school it belongs to.
+//
+// deployment เดียวของเขตมีหลายโรงเรียน แต่ละโรงเรียนได้ชุด handler ของตัวเอง (repository ที่กรอง school_id,
+// clock ตาม timezone ของโรงเรียน, event bus และงานเบื้องหลังแยกกัน) สร้างครั้งแรกที่มี request เข้ามา
+// โรงเรียนมาจาก subdomain ใต้ TENANT_BASE_DOMAIN ก่อน แล้วจึงเป็น header X-School ซึ่งต้องมาพร้อม key
+// ของโรงเรียนนั้น (key ของเครื่อง edge หรือ key ของเขต) เพราะใครก็ส่ง header ได้
+// ถ้าไม่มีทั้งสองอย่างเป็นโรงเรียนแรก (id 1) deployment โรงเรียนเดียวจึงใช้ได้เหมือนเดิมโดยไม่ต้องตั้งค่า
+package tenant
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"backend/internal/clock"
+	"backend/internal/models"
+)
+
+// Header carries the school code when the school is not in the host name
+const Header = "X-School"
+
+// ErrUnverified is returned for a Header the request has no credential of that school for
+var ErrUnverified = errors.New("school header without a credential of the school")
+
+// Schools looks up schools (repository.SchoolRepository)
+type Schools interface {
+	GetByID(id int) (*models.School, error)
+	GetByCode(code string) (*models.School, error)
+}
+
+// Builder creates the handler of one school. ctx ถูกยกเลิกเมื่อค่าของโรงเรียนเปลี่ยน ให้หยุดงานเบื้องหลังของชุดเดิม
+type Builder func(ctx context.Context, school *models.School) (http.Handler, error)
+
+// Verifier reports whether the request carries a credential bound to school (เช่น key ของเครื่องในโรงเรียนนั้น)
+type Verifier func(r *http.Request, school *models.School) (bool, error)
+
+// Router resolves the school of each request and serves it with that school's handler
+type Router struct {
+	schools    Schools
+	baseDomain string
+	build      Builder
+	verify     Verifier
+
+	mu      sync.Mutex
+	tenants map[int]*built
+}
+
+type built struct {
+	handler http.Handler
+	cancel  context.CancelFunc
+}
+
+func NewRouter(schools Schools, baseDomain string, build Builder) *Router {
+	return &Router{
+		schools:    schools,
+		baseDomain: strings.ToLower(strings.Trim(baseDomain, ".")),
+		build:      build,
+		tenants:    make(map[int]*built),
+	}
+}
+
+// WithVerifier accepts Header from requests that verify passes; ไม่ตั้ง = ไม่รับ header เลย
+func (rt *Router) WithVerifier(verify Verifier) *Router {
+	rt.verify = verify
+	return rt
+}
+
+// hostCode returns the school code in the host name, or ""
+func (rt *Router) hostCode(r *http.Request) string {
+	if rt.baseDomain != "" {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		host = strings.ToLower(host)
+		if sub, ok := strings.CutSuffix(host, "."+rt.baseDomain); ok && sub != "" {
+			return sub
+		}
+	}
+	return ""
+}
+
+// School returns the school the request belongs to
+func (rt *Router) School(r *http.Request) (*models.School, error) {
+	if code := rt.hostCode(r); code != "" {
+		return rt.schools.GetByCode(code)
+	}
+
+	code := strings.TrimSpace(r.Header.Get(Header))
+	if code == "" {
+		return rt.schools.GetByID(models.DefaultSchoolID)
+	}
+	if rt.verify == nil {
+		return nil, ErrUnverified
+	}
+	school, err := rt.schools.GetByCode(code)
+	if err != nil {
+		// ไม่บอกว่ามีโรงเรียนนี้หรือไม่จนกว่าจะยืนยันได้
+		return nil, ErrUnverified
+	}
+	ok, err := rt.verify(r, school)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify school %s: %w", school.Code, err)
+	}
+	if !ok {
+		return nil, ErrUnverified
+	}
+	return school, nil
+}
+
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	school, err := rt.School(r)
+	if errors.Is(err, ErrUnverified) {
+		respondWithError(w, http.StatusUnauthorized, "X-School needs a device or district key of the school")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "School not found")
+		return
+	}
+	if !school.Active {
+		respondWithError(w, http.StatusForbidden, "School is disabled")
+		return
+	}
+
+	handler, err := rt.handler(school)
+	if err != nil {
+		log.Printf("Failed to set up school %s: %v", school.Code, err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to set up school")
+		return
+	}
+	handler.ServeHTTP(w, r)
+}
+
+// handler returns the school's handler, building it on first use
+func (rt *Router) handler(school *models.School) (http.Handler, error) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	if t, ok := rt.tenants[school.ID]; ok {
+		return t.handler, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	handler, err := rt.build(ctx, school)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	rt.tenants[school.ID] = &built{handler: handler, cancel: cancel}
+	return handler, nil
+}
+
+// Reset drops the handler of school id so the next request builds it from the current configuration
+func (rt *Router) Reset(id int) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	if t, ok := rt.tenants[id]; ok {
+		t.cancel()
+		delete(rt.tenants, id)
+	}
+}
+
+// Clock returns the clock of the school's timezone, or fallback when the school has none set
+func Clock(school *models.School, fallback *clock.Clock) (*clock.Clock, error) {
+	if school.Timezone == "" {
+		return fallback, nil
+	}
+	clk, err := clock.Load(school.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone of school %s: %w", school.Code, err)
+	}
+	return clk, nil
+}
+
+func respondWithError(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
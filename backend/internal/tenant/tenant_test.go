@@ -0,0 +1,126 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/internal/models"
+)
+
+type fakeSchools map[int]*models.School
+
+func (f fakeSchools) GetByID(id int) (*models.School, error) {
+	if s, ok := f[id]; ok {
+		return s, nil
+	}
+	return nil, fmt.Errorf("school not found")
+}
+
+func (f fakeSchools) GetByCode(code string) (*models.School, error) {
+	for _, s := range f {
+		if s.Code == code {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("school not found")
+}
+
+func TestRouter(t *testing.T) {
+	schools := fakeSchools{
+		1: {ID: 1, Code: "main", Active: true},
+		2: {ID: 2, Code: "school-b", Active: true},
+		3: {ID: 3, Code: "closed", Active: false},
+	}
+
+	builds := map[int]int{}
+	var cancelled []context.Context
+	rt := NewRouter(schools, "visitor.district.go.th.", func(ctx context.Context, s *models.School) (http.Handler, error) {
+		builds[s.ID]++
+		cancelled = append(cancelled, ctx)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(s.Code))
+		}), nil
+	}).WithVerifier(func(r *http.Request, s *models.School) (bool, error) {
+		// key ของแต่ละโรงเรียนคือ "key-" + code
+		return r.Header.Get("X-Device-Key") == "key-"+s.Code, nil
+	})
+
+	tests := []struct {
+		host, header, key string
+		wantCode          int
+		wantBody          string
+	}{
+		{"localhost:8080", "", "", http.StatusOK, "main"},
+		{"localhost:8080", "school-b", "key-school-b", http.StatusOK, "school-b"},
+		// header อย่างเดียวหรือ key ของโรงเรียนอื่น ไม่พาไปโรงเรียนนั้น
+		{"localhost:8080", "school-b", "", http.StatusUnauthorized, ""},
+		{"localhost:8080", "school-b", "key-main", http.StatusUnauthorized, ""},
+		{"localhost:8080", "nowhere", "key-nowhere", http.StatusUnauthorized, ""},
+		{"School-B.visitor.district.go.th", "", "", http.StatusOK, "school-b"},
+		// subdomain มาก่อน header
+		{"main.visitor.district.go.th:443", "school-b", "", http.StatusOK, "main"},
+		{"visitor.district.go.th", "", "", http.StatusOK, "main"},
+		{"nowhere.visitor.district.go.th", "", "", http.StatusNotFound, ""},
+		{"localhost", "closed", "key-closed", http.StatusForbidden, ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/api/visitors", nil)
+		req.Host = tt.host
+		if tt.header != "" {
+			req.Header.Set(Header, tt.header)
+		}
+		if tt.key != "" {
+			req.Header.Set("X-Device-Key", tt.key)
+		}
+		rec := httptest.NewRecorder()
+		rt.ServeHTTP(rec, req)
+		if rec.Code != tt.wantCode || (tt.wantBody != "" && rec.Body.String() != tt.wantBody) {
+			t.Errorf("%s %s %s = %d %s, want %d %s", tt.host, tt.header, tt.key, rec.Code, rec.Body.String(), tt.wantCode, tt.wantBody)
+		}
+	}
+
+	if builds[1] != 1 || builds[2] != 1 {
+		t.Errorf("builds = %v, want each school built once", builds)
+	}
+
+	rt.Reset(2)
+	if cancelled[1].Err() == nil {
+		t.Error("Reset did not cancel the school's context")
+	}
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(Header, "school-b")
+	req.Header.Set("X-Device-Key", "key-school-b")
+	rt.ServeHTTP(httptest.NewRecorder(), req)
+	if builds[2] != 2 {
+		t.Errorf("school-b built %d times after Reset, want 2", builds[2])
+	}
+}
+
+// ไม่ได้ตั้ง Verifier ไม่รับ header เลย
+func TestHeaderWithoutVerifier(t *testing.T) {
+	rt := NewRouter(fakeSchools{1: {ID: 1, Code: "main", Active: true}, 2: {ID: 2, Code: "school-b", Active: true}}, "", func(context.Context, *models.School) (http.Handler, error) {
+		return http.NotFoundHandler(), nil
+	})
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(Header, "school-b")
+	req.Header.Set("X-Device-Key", "key-school-b")
+	rec := httptest.NewRecorder()
+	rt.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", rec.Code)
+	}
+}
+
+func TestBuildFailure(t *testing.T) {
+	rt := NewRouter(fakeSchools{1: {ID: 1, Code: "main", Active: true}}, "", func(context.Context, *models.School) (http.Handler, error) {
+		return nil, fmt.Errorf("invalid timezone")
+	})
+	rec := httptest.NewRecorder()
+	rt.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", rec.Code)
+	}
+}
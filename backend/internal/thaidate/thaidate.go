@@ -0,0 +1,203 @@
+// Package thaidate จัดรูปแบบและอ่านวันที่แบบไทย: ปี พ.ศ./ค.ศ., ชื่อเดือนไทย และเลขไทย
+package thaidate
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BuddhistEraOffset คือผลต่างระหว่าง พ.ศ. กับ ค.ศ.
+const BuddhistEraOffset = 543
+
+// Calendars
+const (
+	CalendarCE = "ce" // ค.ศ.
+	CalendarBE = "be" // พ.ศ.
+)
+
+// Month styles
+const (
+	MonthNumeric = "numeric" // 02/01/2568
+	MonthShort   = "short"   // 2 ม.ค. 2568
+	MonthLong    = "long"    // 2 มกราคม 2568
+)
+
+var (
+	longMonths = [12]string{
+		"มกราคม", "กุมภาพันธ์", "มีนาคม", "เมษายน", "พฤษภาคม", "มิถุนายน",
+		"กรกฎาคม", "สิงหาคม", "กันยายน", "ตุลาคม", "พฤศจิกายน", "ธันวาคม",
+	}
+	shortMonths = [12]string{
+		"ม.ค.", "ก.พ.", "มี.ค.", "เม.ย.", "พ.ค.", "มิ.ย.",
+		"ก.ค.", "ส.ค.", "ก.ย.", "ต.ค.", "พ.ย.", "ธ.ค.",
+	}
+)
+
+// Formatter formats dates in one calendar, month style and digit set.
+// ค่าศูนย์ (Formatter{}) ให้ผลเหมือนรูปแบบเดิมของระบบ: ค.ศ. เดือนเป็นตัวเลข เลขอารบิก
+//
+// Date, ShortDate, Time และ DateTime รับเวลาเป็นจุดเวลา (instant) แล้วแปลงเป็น Location ก่อนแสดง
+// ส่วน CalendarDate ใช้กับคอลัมน์ DATE (วันเกิด วันคืนบัตร) ซึ่งไม่มีเวลาจึงไม่ต้องแปลง
+type Formatter struct {
+	Calendar   string         `json:"calendar"`
+	Month      string         `json:"month"`
+	ThaiDigits bool           `json:"thaiDigits"`
+	Location   *time.Location `json:"-"`
+}
+
+// Validate checks the calendar and month style
+func (f Formatter) Validate() error {
+	switch f.Calendar {
+	case "", CalendarCE, CalendarBE:
+	default:
+		return fmt.Errorf("calendar must be ce or be")
+	}
+	switch f.Month {
+	case "", MonthNumeric, MonthShort, MonthLong:
+	default:
+		return fmt.Errorf("month must be numeric, short or long")
+	}
+	return nil
+}
+
+// Year returns the year of t in the formatter's calendar
+func (f Formatter) Year(t time.Time) int {
+	if f.Calendar == CalendarBE {
+		return t.Year() + BuddhistEraOffset
+	}
+	return t.Year()
+}
+
+// Date formats the local day of an instant, e.g. 02/01/2568, 2 ม.ค. 2568 or 2 มกราคม 2568
+func (f Formatter) Date(t time.Time) string {
+	return f.CalendarDate(f.local(t))
+}
+
+// ShortDate formats the local day of an instant with a two-digit year, e.g. 02/01/68 or 2 ม.ค. 68
+func (f Formatter) ShortDate(t time.Time) string {
+	return f.ShortCalendarDate(f.local(t))
+}
+
+// Time formats the local hours and minutes of an instant, e.g. 09:36
+func (f Formatter) Time(t time.Time) string {
+	return f.digits(f.local(t).Format("15:04"))
+}
+
+// DateTime formats the local day and time of an instant, e.g. 02/01/2568 09:36:12
+func (f Formatter) DateTime(t time.Time) string {
+	return f.Date(t) + " " + f.digits(f.local(t).Format("15:04:05"))
+}
+
+// CalendarDate formats a date-only value as is
+func (f Formatter) CalendarDate(t time.Time) string {
+	return f.digits(f.date(t, strconv.Itoa(f.Year(t))))
+}
+
+// ShortCalendarDate formats a date-only value with a two-digit year
+func (f Formatter) ShortCalendarDate(t time.Time) string {
+	return f.digits(f.date(t, fmt.Sprintf("%02d", f.Year(t)%100)))
+}
+
+// MonthYear formats the month of a date-only value, e.g. 01/2568, ม.ค. 2568 or มกราคม 2568
+func (f Formatter) MonthYear(t time.Time) string {
+	year := strconv.Itoa(f.Year(t))
+	switch f.Month {
+	case MonthShort:
+		return f.digits(shortMonths[t.Month()-1] + " " + year)
+	case MonthLong:
+		return f.digits(longMonths[t.Month()-1] + " " + year)
+	}
+	return f.digits(fmt.Sprintf("%02d/%s", int(t.Month()), year))
+}
+
+func (f Formatter) local(t time.Time) time.Time {
+	if f.Location == nil {
+		return t
+	}
+	return t.In(f.Location)
+}
+
+func (f Formatter) date(t time.Time, year string) string {
+	switch f.Month {
+	case MonthShort:
+		return fmt.Sprintf("%d %s %s", t.Day(), shortMonths[t.Month()-1], year)
+	case MonthLong:
+		return fmt.Sprintf("%d %s %s", t.Day(), longMonths[t.Month()-1], year)
+	}
+	return fmt.Sprintf("%02d/%02d/%s", t.Day(), int(t.Month()), year)
+}
+
+// Digits converts the digits of an already formatted value, e.g. a stored "09:36"
+func (f Formatter) Digits(s string) string {
+	return f.digits(s)
+}
+
+func (f Formatter) digits(s string) string {
+	if f.ThaiDigits {
+		return ToThaiDigits(s)
+	}
+	return s
+}
+
+// ToThaiDigits replaces 0-9 with ๐-๙
+func ToThaiDigits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return '๐' + (r - '0')
+		}
+		return r
+	}, s)
+}
+
+// FromThaiDigits replaces ๐-๙ with 0-9
+func FromThaiDigits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '๐' && r <= '๙' {
+			return '0' + (r - '๐')
+		}
+		return r
+	}, s)
+}
+
+// ParseDate reads a date typed or sent by the client in either calendar.
+// รับ YYYY-MM-DD หรือ DD/MM/YYYY (เลขไทยได้) ปีตั้งแต่ 2400 ขึ้นไปถือเป็น พ.ศ.
+// คืนวันที่ ค.ศ. เวลา 00:00 UTC
+func ParseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(FromThaiDigits(s))
+
+	var day, month, year string
+	switch {
+	case len(s) == 10 && s[4] == '-' && s[7] == '-':
+		year, month, day = s[:4], s[5:7], s[8:]
+	case len(s) == 10 && s[2] == '/' && s[5] == '/':
+		day, month, year = s[:2], s[3:5], s[6:]
+	default:
+		return time.Time{}, fmt.Errorf("invalid date: %q", s)
+	}
+
+	y, err := strconv.Atoi(year)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date: %q", s)
+	}
+	// แปลงปีก่อน parse เพราะ 29 ก.พ. ต้องคิดจากปี ค.ศ.
+	if y >= 2400 {
+		y -= BuddhistEraOffset
+	}
+
+	t, err := time.Parse("2006-01-02", fmt.Sprintf("%04d-%s-%s", y, month, day))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date: %q", s)
+	}
+	return t, nil
+}
+
+// NormalizeDate converts a date in either calendar to YYYY-MM-DD (ค.ศ.) for SQL
+func NormalizeDate(s string) (string, error) {
+	t, err := ParseDate(s)
+	if err != nil {
+		return "", err
+	}
+	return t.Format("2006-01-02"), nil
+}
@@ -0,0 +1,128 @@
+package thaidate
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatter(t *testing.T) {
+	at := time.Date(2025, 1, 2, 9, 36, 12, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		f         Formatter
+		date      string
+		shortDate string
+		dateTime  string
+	}{
+		{"zero value keeps the old format", Formatter{}, "02/01/2025", "02/01/25", "02/01/2025 09:36:12"},
+		{"buddhist era", Formatter{Calendar: CalendarBE}, "02/01/2568", "02/01/68", "02/01/2568 09:36:12"},
+		{"short month", Formatter{Calendar: CalendarBE, Month: MonthShort}, "2 ม.ค. 2568", "2 ม.ค. 68", "2 ม.ค. 2568 09:36:12"},
+		{"long month", Formatter{Calendar: CalendarBE, Month: MonthLong}, "2 มกราคม 2568", "2 มกราคม 68", "2 มกราคม 2568 09:36:12"},
+		{"thai digits", Formatter{Calendar: CalendarBE, ThaiDigits: true}, "๐๒/๐๑/๒๕๖๘", "๐๒/๐๑/๖๘", "๐๒/๐๑/๒๕๖๘ ๐๙:๓๖:๑๒"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.f.Date(at); got != tt.date {
+				t.Errorf("Date() = %q, want %q", got, tt.date)
+			}
+			if got := tt.f.ShortDate(at); got != tt.shortDate {
+				t.Errorf("ShortDate() = %q, want %q", got, tt.shortDate)
+			}
+			if got := tt.f.DateTime(at); got != tt.dateTime {
+				t.Errorf("DateTime() = %q, want %q", got, tt.dateTime)
+			}
+		})
+	}
+}
+
+func TestFormatterValidate(t *testing.T) {
+	if err := (Formatter{Calendar: CalendarBE, Month: MonthLong}).Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+	if err := (Formatter{Calendar: "bc"}).Validate(); err == nil {
+		t.Error("Validate() accepted calendar bc")
+	}
+	if err := (Formatter{Month: "roman"}).Validate(); err == nil {
+		t.Error("Validate() accepted month roman")
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"2025-01-02", "2025-01-02"},
+		{"2568-01-02", "2025-01-02"},
+		{"02/01/2025", "2025-01-02"},
+		{"02/01/2568", "2025-01-02"},
+		{"๐๒/๐๑/๒๕๖๘", "2025-01-02"},
+		{" 2025-01-02 ", "2025-01-02"},
+		// 2567 = ค.ศ. 2024 ซึ่งเป็นปีอธิกสุรทิน
+		{"29/02/2567", "2024-02-29"},
+		{"29/02/2568", ""},
+		{"2/1/2568", ""},
+		{"02-01-2568", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := NormalizeDate(tt.in)
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("NormalizeDate() = %q, want an error", got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("NormalizeDate() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestThaiDigits(t *testing.T) {
+	if got := ToThaiDigits("09:36 น."); got != "๐๙:๓๖ น." {
+		t.Errorf("ToThaiDigits() = %q", got)
+	}
+	if got := FromThaiDigits("๐๙:๓๖ น."); got != "09:36 น." {
+		t.Errorf("FromThaiDigits() = %q", got)
+	}
+}
+
+// เวลาที่อ่านจากฐานข้อมูลเป็น UTC ต้องแสดงเป็นเวลาโรงเรียน ไม่ขึ้นกับ TZ ของเครื่อง
+func TestFormatterConvertsToLocation(t *testing.T) {
+	bangkok, err := time.LoadLocation("Asia/Bangkok")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	for _, name := range []string{"UTC", "America/Los_Angeles", "Asia/Tokyo"} {
+		t.Run("TZ="+name, func(t *testing.T) {
+			loc, err := time.LoadLocation(name)
+			if err != nil {
+				t.Skip(err)
+			}
+			saved := time.Local
+			time.Local = loc
+			defer func() { time.Local = saved }()
+
+			f := Formatter{Calendar: CalendarBE, Location: bangkok}
+			registeredAt := time.Date(2025, 6, 1, 17, 30, 0, 0, time.UTC)
+
+			if got := f.DateTime(registeredAt); got != "02/06/2568 00:30:00" {
+				t.Errorf("DateTime() = %q, want 02/06/2568 00:30:00", got)
+			}
+			if got := f.Time(registeredAt.In(time.Local)); got != "00:30" {
+				t.Errorf("Time() = %q, want 00:30", got)
+			}
+
+			// วันเกิดเป็นคอลัมน์ DATE อ่านกลับมาเป็นเที่ยงคืน UTC ต้องไม่เลื่อนวัน
+			birthDate := time.Date(1980, 8, 15, 0, 0, 0, 0, time.UTC)
+			if got := f.CalendarDate(birthDate); got != "15/08/2523" {
+				t.Errorf("CalendarDate() = %q, want 15/08/2523", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// apiKeyPrefix ทำให้เห็นได้ทันทีว่าเป็น key ของเครื่องอ่านบัตร (เช่นเวลาหลุดไปอยู่ใน log)
+const apiKeyPrefix = "dvk_"
+
+// NewAPIKey returns a random device API key and its hash.
+// เก็บเฉพาะ hash ในฐานข้อมูล key จริงแสดงให้ผู้ดูแลเห็นครั้งเดียวตอนสร้าง
+func NewAPIKey() (key, hash string, err error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	key = apiKeyPrefix + hex.EncodeToString(b)
+	return key, HashAPIKey(key), nil
+}
+
+// HashAPIKey returns the hex SHA-256 of the key
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(key)))
+	return hex.EncodeToString(sum[:])
+}
+
+// MatchAPIKey compares a presented key with a stored hash in constant time
+func MatchAPIKey(key, hash string) bool {
+	if key == "" || hash == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(HashAPIKey(key)), []byte(hash)) == 1
+}
+
+// APIKeyHint returns the start of a key, enough to tell keys apart
+func APIKeyHint(key string) string {
+	if len(key) <= len(apiKeyPrefix)+4 {
+		return key
+	}
+	return key[:len(apiKeyPrefix)+4]
+}
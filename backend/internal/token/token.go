@@ -0,0 +1,96 @@
+package token
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Token kinds
+const (
+	KindAppointment = "appt"
+	KindVisit       = "visit"
+)
+
+const version = "v1"
+
+var (
+	ErrMalformed = errors.New("token is malformed")
+	ErrSignature = errors.New("token signature is invalid")
+	ErrExpired   = errors.New("token has expired")
+	ErrKind      = errors.New("token kind does not match")
+)
+
+// Claims is the signed content of a token
+type Claims struct {
+	Kind      string `json:"k"`
+	ID        int    `json:"id"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// Signer creates and verifies HMAC-SHA256 signed tokens.
+// รูปแบบ: v1.<payload base64url>.<signature base64url> สั้นพอสำหรับ QR code
+type Signer struct {
+	secret []byte
+	now    func() time.Time
+}
+
+func NewSigner(secret string) *Signer {
+	return &Signer{secret: []byte(secret), now: time.Now}
+}
+
+// WithNow returns a signer with the same secret that checks expiry against now (ใช้ในการทดสอบ)
+func (s *Signer) WithNow(now func() time.Time) *Signer {
+	return &Signer{secret: s.secret, now: now}
+}
+
+// Sign returns a token for the given kind and record ID that expires at expiresAt
+func (s *Signer) Sign(kind string, id int, expiresAt time.Time) (string, error) {
+	payload, err := json.Marshal(Claims{Kind: kind, ID: id, ExpiresAt: expiresAt.Unix()})
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return version + "." + encoded + "." + s.signature(encoded), nil
+}
+
+// Verify checks the signature, kind and expiry and returns the claims
+func (s *Signer) Verify(token, kind string) (*Claims, error) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 || parts[0] != version {
+		return nil, ErrMalformed
+	}
+
+	if !hmac.Equal([]byte(parts[2]), []byte(s.signature(parts[1]))) {
+		return nil, ErrSignature
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrMalformed
+	}
+	if claims.Kind != kind {
+		return nil, ErrKind
+	}
+	if s.now().Unix() > claims.ExpiresAt {
+		return &claims, ErrExpired
+	}
+
+	return &claims, nil
+}
+
+func (s *Signer) signature(encodedPayload string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(version + "." + encodedPayload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,82 @@
+package token
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignVerify(t *testing.T) {
+	now := time.Date(2025, 6, 2, 3, 0, 0, 0, time.UTC)
+	signer := NewSigner("secret").WithNow(func() time.Time { return now })
+
+	signed, err := signer.Sign(KindVisit, 42, now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(signed, "v1.") || strings.Count(signed, ".") != 2 {
+		t.Fatalf("Sign() = %q", signed)
+	}
+
+	claims, err := signer.Verify(" "+signed+"\n", KindVisit)
+	if err != nil || claims.ID != 42 || claims.Kind != KindVisit || claims.ExpiresAt != now.Add(time.Hour).Unix() {
+		t.Fatalf("Verify() = %+v, %v", claims, err)
+	}
+
+	parts := strings.Split(signed, ".")
+	forged, _ := NewSigner("other").Sign(KindVisit, 42, now.Add(time.Hour))
+	tests := []struct {
+		name  string
+		token string
+		kind  string
+		want  error
+	}{
+		{"other kind", signed, KindAppointment, ErrKind},
+		{"other secret", forged, KindVisit, ErrSignature},
+		{"changed payload", parts[0] + "." + parts[1] + "x." + parts[2], KindVisit, ErrSignature},
+		{"other version", "v2." + parts[1] + "." + parts[2], KindVisit, ErrMalformed},
+		{"not a token", "forged", KindVisit, ErrMalformed},
+		{"empty", "", KindVisit, ErrMalformed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := signer.Verify(tt.token, tt.kind); !errors.Is(err, tt.want) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	// หมดอายุแล้วยังได้ claims กลับมา ให้บอกได้ว่าเป็นของใคร
+	now = now.Add(2 * time.Hour)
+	claims, err = signer.Verify(signed, KindVisit)
+	if !errors.Is(err, ErrExpired) || claims == nil || claims.ID != 42 {
+		t.Errorf("Verify() after expiry = %+v, %v", claims, err)
+	}
+}
+
+func TestAPIKey(t *testing.T) {
+	key, hash, err := NewAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(key, "dvk_") || len(key) != len("dvk_")+48 {
+		t.Errorf("NewAPIKey() = %q", key)
+	}
+	if other, _, _ := NewAPIKey(); other == key {
+		t.Error("NewAPIKey() returned the same key twice")
+	}
+
+	if !MatchAPIKey(key, hash) || !MatchAPIKey(" "+key+" ", hash) {
+		t.Error("MatchAPIKey() rejected the key")
+	}
+	if MatchAPIKey(key+"0", hash) || MatchAPIKey("", hash) || MatchAPIKey(key, "") {
+		t.Error("MatchAPIKey() accepted a wrong key")
+	}
+	if hint := APIKeyHint(key); hint != key[:8] {
+		t.Errorf("APIKeyHint() = %q", hint)
+	}
+	if hint := APIKeyHint("dvk_1"); hint != "dvk_1" {
+		t.Errorf("APIKeyHint(short) = %q", hint)
+	}
+}
@@ -0,0 +1,73 @@
+package vehicle
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Vehicle types accepted for visitor vehicles
+const (
+	TypeCar        = "car"
+	TypePickup     = "pickup"
+	TypeVan        = "van"
+	TypeMotorcycle = "motorcycle"
+	TypeTruck      = "truck"
+	TypeBus        = "bus"
+	TypeOther      = "other"
+)
+
+// Types maps each vehicle type to its Thai label
+var Types = map[string]string{
+	TypeCar:        "รถยนต์",
+	TypePickup:     "รถกระบะ",
+	TypeVan:        "รถตู้",
+	TypeMotorcycle: "รถจักรยานยนต์",
+	TypeTruck:      "รถบรรทุก",
+	TypeBus:        "รถบัส",
+	TypeOther:      "อื่นๆ",
+}
+
+// IsValidType reports whether t is a known vehicle type
+func IsValidType(t string) bool {
+	_, ok := Types[t]
+	return ok
+}
+
+// NormalizePlate แปลงทะเบียนรถให้อยู่ในรูปแบบเดียวกันสำหรับค้นหา
+// ตัดช่องว่าง/ขีด/จุด แปลงเลขไทยเป็นเลขอารบิก และตัวอักษรอังกฤษเป็นตัวใหญ่
+// เช่น "1กข - ๑๒๓๔" -> "1กข1234"
+func NormalizePlate(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= '๐' && r <= '๙':
+			b.WriteRune('0' + (r - '๐'))
+		case unicode.IsDigit(r), isThaiLetter(r):
+			b.WriteRune(r)
+		case unicode.IsLetter(r):
+			b.WriteRune(unicode.ToUpper(r))
+		}
+	}
+	return b.String()
+}
+
+// FormatPlate คืนทะเบียนสำหรับแสดงผล โดยเว้นวรรคระหว่างหมวดอักษรกับเลขทะเบียน
+// เช่น "1กข1234" -> "1กข 1234"
+func FormatPlate(s string) string {
+	normalized := NormalizePlate(s)
+	runes := []rune(normalized)
+
+	split := len(runes)
+	for split > 0 && unicode.IsDigit(runes[split-1]) {
+		split--
+	}
+	if split == 0 || split == len(runes) {
+		return normalized
+	}
+
+	return string(runes[:split]) + " " + string(runes[split:])
+}
+
+func isThaiLetter(r rune) bool {
+	return r >= 'ก' && r <= 'ฮ'
+}
@@ -0,0 +1,30 @@
+// Package migrations embeds the SQL migrations into the server binary.
+//
+// ไฟล์ NNN_name.sql คือ migration ขาขึ้น และ NNN_name.down.sql คือขาย้อนกลับ
+// เลข NNN ต้องไม่ซ้ำกันและห้ามแก้ไฟล์ที่ถูก apply ไปแล้ว ให้เพิ่มไฟล์ใหม่แทน
+//
+// แต่ละฐานข้อมูลมีชุดไฟล์ของตัวเองในโฟลเดอร์ตามชื่อ driver (mysql/, postgres/, sqlite/)
+// เลขเดียวกันต้องให้ผลเป็นโครงสร้างเดียวกัน เพิ่ม migration ใหม่จึงต้องเพิ่มทุกโฟลเดอร์
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+)
+
+//go:embed mysql/*.sql postgres/*.sql sqlite/*.sql
+var files embed.FS
+
+// Drivers lists the database drivers that have migrations
+var Drivers = []string{"mysql", "postgres", "sqlite"}
+
+// For returns the migrations of a database driver
+func For(driver string) (fs.FS, error) {
+	for _, d := range Drivers {
+		if d == driver {
+			return fs.Sub(files, driver)
+		}
+	}
+	return nil, fmt.Errorf("no migrations for database driver %q", driver)
+}
@@ -0,0 +1,3 @@
+-- migrations/001_create_visitors_table.down.sql
+
+DROP TABLE IF EXISTS visitors;
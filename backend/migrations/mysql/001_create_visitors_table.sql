@@ -1,9 +1,6 @@
--- Create database
-CREATE DATABASE IF NOT EXISTS visitor_system
-CHARACTER SET utf8mb4
-COLLATE utf8mb4_unicode_ci;
-
-USE visitor_system;
+-- migrations/001_create_visitors_table.sql
+-- ฐานข้อมูลต้องสร้างไว้ก่อน (CREATE DATABASE visitor_system CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci)
+-- migration รันกับฐานข้อมูลที่ตั้งใน DB_NAME
 
 -- Create visitors table
 CREATE TABLE IF NOT EXISTS visitors (
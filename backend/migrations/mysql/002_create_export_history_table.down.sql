@@ -0,0 +1,3 @@
+-- migrations/002_create_export_history_table.down.sql
+
+DROP TABLE IF EXISTS export_history;
@@ -1,3 +1,4 @@
+-- migrations/002_create_export_history_table.sql
 -- สร้างตาราง export_history สำหรับเก็บประวัติการส่งออกข้อมูล
 CREATE TABLE IF NOT EXISTS export_history (
     id INT AUTO_INCREMENT PRIMARY KEY,
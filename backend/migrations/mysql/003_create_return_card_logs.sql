@@ -1,4 +1,4 @@
--- migrations/002_create_return_card_logs.sql
+-- migrations/003_create_return_card_logs.sql
 
 CREATE TABLE IF NOT EXISTS return_card_logs (
     id INT AUTO_INCREMENT PRIMARY KEY,
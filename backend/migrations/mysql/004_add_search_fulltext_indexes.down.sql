@@ -0,0 +1,7 @@
+-- migrations/004_add_search_fulltext_indexes.down.sql
+
+ALTER TABLE return_card_logs
+    DROP INDEX ft_return_card_logs_search;
+
+ALTER TABLE visitors
+    DROP INDEX ft_visitors_search;
@@ -0,0 +1,11 @@
+-- migrations/004_add_search_fulltext_indexes.sql
+-- ดัชนี FULLTEXT แบบ ngram สำหรับค้นหาชื่อภาษาไทย เบอร์โทร ทะเบียนรถ และ RFID
+-- (ngram_token_size ต้องเป็น 2 ซึ่งเป็นค่าเริ่มต้นของ MySQL)
+
+ALTER TABLE visitors
+    ADD FULLTEXT INDEX ft_visitors_search (first_name, last_name, id_card, phone, license_plate, rfid)
+    WITH PARSER ngram;
+
+ALTER TABLE return_card_logs
+    ADD FULLTEXT INDEX ft_return_card_logs_search (name, card_id)
+    WITH PARSER ngram;
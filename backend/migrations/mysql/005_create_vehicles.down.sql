@@ -0,0 +1,9 @@
+-- migrations/005_create_vehicles.down.sql
+-- ทะเบียนรถยังอยู่ใน visitors.license_plate จึงไม่เสียข้อมูล (ยกเว้นจังหวัด/ประเภท/สีของรถ)
+
+ALTER TABLE visitors
+    DROP FOREIGN KEY fk_visitors_vehicle,
+    DROP INDEX idx_vehicle_id,
+    DROP COLUMN vehicle_id;
+
+DROP TABLE IF EXISTS vehicles;
@@ -0,0 +1,33 @@
+-- migrations/005_create_vehicles.sql
+-- ยานพาหนะของผู้มาติดต่อ ผูกกับการเข้าเยี่ยมแต่ละครั้งผ่าน visitors.vehicle_id
+
+CREATE TABLE IF NOT EXISTS vehicles (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    plate_number VARCHAR(20) NOT NULL COMMENT 'ทะเบียนที่ normalize แล้ว (ไม่มีช่องว่าง/ขีด)',
+    plate_display VARCHAR(30) NOT NULL COMMENT 'ทะเบียนสำหรับแสดงผล',
+    province VARCHAR(100) NOT NULL DEFAULT '' COMMENT 'จังหวัดที่จดทะเบียน',
+    vehicle_type VARCHAR(20) NOT NULL DEFAULT 'car',
+    color VARCHAR(50) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_vehicles_plate_province (plate_number, province),
+    INDEX idx_vehicles_plate_number (plate_number)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+ALTER TABLE visitors
+    ADD COLUMN vehicle_id INT NULL AFTER license_plate,
+    ADD INDEX idx_vehicle_id (vehicle_id),
+    ADD CONSTRAINT fk_visitors_vehicle FOREIGN KEY (vehicle_id) REFERENCES vehicles(id) ON DELETE SET NULL;
+
+-- ย้ายทะเบียนรถเดิมที่เป็นข้อความอิสระเข้าตาราง vehicles (ไม่ทราบจังหวัด)
+INSERT IGNORE INTO vehicles (plate_number, plate_display)
+SELECT REPLACE(REPLACE(REPLACE(license_plate, ' ', ''), '-', ''), '.', ''), MIN(license_plate)
+FROM visitors
+WHERE license_plate IS NOT NULL AND license_plate <> ''
+GROUP BY REPLACE(REPLACE(REPLACE(license_plate, ' ', ''), '-', ''), '.', '');
+
+UPDATE visitors v
+JOIN vehicles ve
+    ON ve.plate_number = REPLACE(REPLACE(REPLACE(v.license_plate, ' ', ''), '-', ''), '.', '')
+    AND ve.province = ''
+SET v.vehicle_id = ve.id
+WHERE v.license_plate IS NOT NULL AND v.license_plate <> '';
@@ -0,0 +1,4 @@
+-- migrations/006_add_visitor_postal_code.down.sql
+
+ALTER TABLE visitors
+    DROP COLUMN postal_code;
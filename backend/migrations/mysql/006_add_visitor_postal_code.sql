@@ -0,0 +1,5 @@
+-- migrations/006_add_visitor_postal_code.sql
+-- รหัสไปรษณีย์ของที่อยู่ผู้มาติดต่อ (ตรวจกับข้อมูลอ้างอิงเขตการปกครองตอนลงทะเบียน)
+
+ALTER TABLE visitors
+    ADD COLUMN postal_code VARCHAR(5) NOT NULL DEFAULT '' AFTER province;
@@ -0,0 +1,3 @@
+-- migrations/007_create_appointments.down.sql
+
+DROP TABLE IF EXISTS appointments;
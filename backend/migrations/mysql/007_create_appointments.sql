@@ -0,0 +1,25 @@
+-- migrations/007_create_appointments.sql
+-- การนัดหมายล่วงหน้า (ผู้ปกครองพบครู, ผู้ส่งของ ฯลฯ) พร้อม QR เชิญ
+
+CREATE TABLE IF NOT EXISTS appointments (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    id_card VARCHAR(13) NOT NULL DEFAULT '',
+    first_name VARCHAR(100) NOT NULL,
+    last_name VARCHAR(100) NOT NULL,
+    phone VARCHAR(15) NOT NULL DEFAULT '',
+    license_plate VARCHAR(20) NOT NULL DEFAULT '',
+    host_name VARCHAR(200) NOT NULL COMMENT 'ผู้ที่ผู้มาติดต่อต้องการพบ',
+    host_contact VARCHAR(200) NOT NULL DEFAULT '' COMMENT 'ช่องทางแจ้งเตือนผู้รับนัด',
+    department VARCHAR(200) NOT NULL DEFAULT '',
+    purpose VARCHAR(255) NOT NULL DEFAULT '',
+    expected_from DATETIME NOT NULL,
+    expected_until DATETIME NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    visitor_id INT NULL COMMENT 'การเข้าเยี่ยมที่เกิดจากนัดหมายนี้',
+    created_by VARCHAR(200) NOT NULL DEFAULT '',
+    arrived_at DATETIME NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (visitor_id) REFERENCES visitors(id) ON DELETE SET NULL,
+    INDEX idx_appointments_expected_from (expected_from),
+    INDEX idx_appointments_status (status)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,15 @@
+-- migrations/008_add_visit_host_purpose_approval.down.sql
+-- rfid ยังเป็น NULL ได้ เพราะผู้ที่ยังไม่ได้รับบัตรไม่มีค่าที่จะใส่ให้ไม่ซ้ำกัน
+
+ALTER TABLE visitors
+    DROP INDEX idx_visitors_approval,
+    DROP COLUMN approval_note,
+    DROP COLUMN approved_at,
+    DROP COLUMN approved_by,
+    DROP COLUMN approval_status,
+    DROP COLUMN purpose_note,
+    DROP COLUMN purpose,
+    DROP COLUMN host_contact,
+    DROP COLUMN host_name;
+
+DROP TABLE IF EXISTS visit_purposes;
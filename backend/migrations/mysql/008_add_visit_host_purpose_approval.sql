@@ -0,0 +1,36 @@
+-- migrations/008_add_visit_host_purpose_approval.sql
+-- ผู้ที่มาพบ วัตถุประสงค์ และขั้นตอนขออนุมัติจากผู้รับนัดก่อนออกบัตร
+
+CREATE TABLE IF NOT EXISTS visit_purposes (
+    code VARCHAR(50) PRIMARY KEY,
+    name_th VARCHAR(200) NOT NULL,
+    name_en VARCHAR(200) NOT NULL DEFAULT '',
+    requires_approval BOOLEAN NOT NULL DEFAULT FALSE COMMENT 'ต้องให้ผู้รับนัดอนุมัติก่อนออกบัตร',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    display_order INT NOT NULL DEFAULT 0
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+INSERT IGNORE INTO visit_purposes (code, name_th, name_en, requires_approval, display_order) VALUES
+    ('pickup_student', 'รับนักเรียน', 'Student pickup', FALSE, 1),
+    ('meeting', 'ประชุม / พบครู', 'Meeting', TRUE, 2),
+    ('contact_office', 'ติดต่อธุรการ', 'Office business', FALSE, 3),
+    ('delivery', 'ส่งของ / ส่งเอกสาร', 'Delivery', FALSE, 4),
+    ('maintenance', 'ซ่อมบำรุง', 'Maintenance', TRUE, 5),
+    ('other', 'อื่นๆ', 'Other', FALSE, 99);
+
+-- บัตรจะถูกออกหลังอนุมัติ จึงต้องให้ rfid เป็น NULL ได้ (UNIQUE ยอมให้มีหลาย NULL)
+ALTER TABLE visitors
+    MODIFY COLUMN rfid VARCHAR(50) NULL;
+
+UPDATE visitors SET rfid = NULL WHERE rfid = '';
+
+ALTER TABLE visitors
+    ADD COLUMN host_name VARCHAR(200) NOT NULL DEFAULT '' AFTER id_card_image,
+    ADD COLUMN host_contact VARCHAR(200) NOT NULL DEFAULT '' AFTER host_name,
+    ADD COLUMN purpose VARCHAR(50) NOT NULL DEFAULT '' AFTER host_contact,
+    ADD COLUMN purpose_note VARCHAR(255) NOT NULL DEFAULT '' AFTER purpose,
+    ADD COLUMN approval_status VARCHAR(20) NOT NULL DEFAULT 'not_required' AFTER purpose_note,
+    ADD COLUMN approved_by VARCHAR(200) NOT NULL DEFAULT '' AFTER approval_status,
+    ADD COLUMN approved_at DATETIME NULL AFTER approved_by,
+    ADD COLUMN approval_note VARCHAR(255) NOT NULL DEFAULT '' AFTER approved_at,
+    ADD INDEX idx_visitors_approval (approval_status, host_name);
@@ -0,0 +1,56 @@
+-- migrations/009_create_student_pickup.sql
+-- นักเรียน ผู้มีสิทธิ์รับนักเรียน (ผูกกับเลขบัตรประชาชน) ประวัติการรับ และการแจ้งเตือนเมื่อถูกปฏิเสธ
+
+CREATE TABLE IF NOT EXISTS students (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    student_code VARCHAR(20) NOT NULL UNIQUE COMMENT 'เลขประจำตัวนักเรียน',
+    first_name VARCHAR(100) NOT NULL,
+    last_name VARCHAR(100) NOT NULL,
+    class_room VARCHAR(50) NOT NULL DEFAULT '' COMMENT 'ชั้น/ห้อง เช่น ป.3/2',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_students_name (first_name, last_name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS guardian_authorisations (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    student_id INT NOT NULL,
+    id_card VARCHAR(13) NOT NULL COMMENT 'เลขบัตรประชาชนผู้มีสิทธิ์รับ',
+    guardian_name VARCHAR(200) NOT NULL,
+    relationship VARCHAR(50) NOT NULL COMMENT 'ความสัมพันธ์ เช่น บิดา มารดา ญาติ',
+    valid_from DATE NOT NULL,
+    valid_until DATE NULL,
+    revoked_at DATETIME NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (student_id) REFERENCES students(id) ON DELETE CASCADE,
+    INDEX idx_guardian_id_card (id_card),
+    INDEX idx_guardian_student (student_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS student_pickups (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    student_id INT NOT NULL,
+    visitor_id INT NOT NULL,
+    authorisation_id INT NOT NULL,
+    officer_name VARCHAR(200) NOT NULL DEFAULT '',
+    picked_up_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (student_id) REFERENCES students(id),
+    FOREIGN KEY (visitor_id) REFERENCES visitors(id),
+    FOREIGN KEY (authorisation_id) REFERENCES guardian_authorisations(id),
+    INDEX idx_pickups_picked_up_at (picked_up_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS pickup_alerts (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    student_id INT NOT NULL,
+    visitor_id INT NULL,
+    id_card VARCHAR(13) NOT NULL,
+    visitor_name VARCHAR(255) NOT NULL DEFAULT '',
+    reason VARCHAR(255) NOT NULL,
+    officer_name VARCHAR(200) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    acknowledged_at DATETIME NULL,
+    FOREIGN KEY (student_id) REFERENCES students(id),
+    FOREIGN KEY (visitor_id) REFERENCES visitors(id) ON DELETE SET NULL,
+    INDEX idx_pickup_alerts_created_at (created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
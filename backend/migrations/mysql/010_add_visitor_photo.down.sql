@@ -0,0 +1,7 @@
+-- migrations/010_add_visitor_photo.down.sql
+-- ไฟล์รูปใน storage ไม่ถูกลบ
+
+ALTER TABLE visitors
+    DROP COLUMN photo_taken_at,
+    DROP COLUMN photo_thumbnail_key,
+    DROP COLUMN photo_key;
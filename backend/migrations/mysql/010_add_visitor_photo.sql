@@ -0,0 +1,7 @@
+-- migrations/010_add_visitor_photo.sql
+-- รูปถ่ายผู้มาติดต่อจากกล้องหน้าป้อม (ตัวไฟล์อยู่ใน storage เก็บแค่ key ไว้ที่นี่)
+
+ALTER TABLE visitors
+    ADD COLUMN photo_key VARCHAR(255) NULL AFTER id_card_image,
+    ADD COLUMN photo_thumbnail_key VARCHAR(255) NULL AFTER photo_key,
+    ADD COLUMN photo_taken_at DATETIME NULL AFTER photo_thumbnail_key;
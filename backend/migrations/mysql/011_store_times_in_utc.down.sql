@@ -0,0 +1,26 @@
+-- migrations/011_store_times_in_utc.down.sql
+-- เลื่อนคอลัมน์ DATETIME กลับเป็นเวลาเครื่องเดิม (@old_offset ต้องตรงกับตอน migrate ขึ้น)
+-- exit_time คงเป็น DATETIME
+
+SET @old_offset = '+07:00';
+SET time_zone = '+00:00';
+
+UPDATE appointments SET
+    expected_from = CONVERT_TZ(expected_from, '+00:00', @old_offset),
+    expected_until = CONVERT_TZ(expected_until, '+00:00', @old_offset),
+    arrived_at = CONVERT_TZ(arrived_at, '+00:00', @old_offset);
+
+UPDATE visitors SET approved_at = CONVERT_TZ(approved_at, '+00:00', @old_offset)
+WHERE approved_at IS NOT NULL;
+
+UPDATE visitors SET photo_taken_at = CONVERT_TZ(photo_taken_at, '+00:00', @old_offset)
+WHERE photo_taken_at IS NOT NULL;
+
+UPDATE visitors SET exit_time = CONVERT_TZ(exit_time, '+00:00', @old_offset)
+WHERE exit_time IS NOT NULL;
+
+UPDATE guardian_authorisations SET revoked_at = CONVERT_TZ(revoked_at, '+00:00', @old_offset)
+WHERE revoked_at IS NOT NULL;
+
+UPDATE pickup_alerts SET acknowledged_at = CONVERT_TZ(acknowledged_at, '+00:00', @old_offset)
+WHERE acknowledged_at IS NOT NULL;
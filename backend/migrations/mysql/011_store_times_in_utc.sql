@@ -0,0 +1,49 @@
+-- migrations/011_store_times_in_utc.sql
+-- ตั้งแต่นี้ server เชื่อมต่อด้วย loc=UTC และ time_zone '+00:00' ทุกเวลาในฐานข้อมูลเป็น UTC
+-- คอลัมน์ TIMESTAMP เก็บเป็น UTC อยู่แล้วไม่ต้องแก้ ส่วน DATETIME เดิมถูกเขียนเป็นเวลาเครื่อง
+-- จึงต้องเลื่อนกลับเป็น UTC ครั้งเดียว
+--
+-- @old_offset คือ timezone ของเครื่องที่รัน server/MySQL ก่อนหน้านี้ (ค่าเริ่มต้นเวลาไทย)
+-- ถ้าเดิมเครื่องตั้งเป็น UTC อยู่แล้ว ให้เปลี่ยนเป็น '+00:00' ก่อนรัน
+-- คอลัมน์ DATE (วันเกิด วันคืนบัตร ช่วงสิทธิ์รับนักเรียน) เป็นวันตามปฏิทินโรงเรียน ไม่ต้องแปลง
+
+SET @old_offset = '+07:00';
+SET time_zone = '+00:00';
+
+UPDATE appointments SET
+    expected_from = CONVERT_TZ(expected_from, @old_offset, '+00:00'),
+    expected_until = CONVERT_TZ(expected_until, @old_offset, '+00:00'),
+    arrived_at = CONVERT_TZ(arrived_at, @old_offset, '+00:00');
+
+UPDATE visitors SET approved_at = CONVERT_TZ(approved_at, @old_offset, '+00:00')
+WHERE approved_at IS NOT NULL;
+
+UPDATE visitors SET photo_taken_at = CONVERT_TZ(photo_taken_at, @old_offset, '+00:00')
+WHERE photo_taken_at IS NOT NULL;
+
+UPDATE guardian_authorisations SET revoked_at = CONVERT_TZ(revoked_at, @old_offset, '+00:00')
+WHERE revoked_at IS NOT NULL;
+
+UPDATE pickup_alerts SET acknowledged_at = CONVERT_TZ(acknowledged_at, @old_offset, '+00:00')
+WHERE acknowledged_at IS NOT NULL;
+
+-- exit_time ถูกเพิ่มนอก migration บางเครื่องเป็น TIMESTAMP บางเครื่องเป็น DATETIME บางเครื่อง (ติดตั้งใหม่) ยังไม่มี
+-- แปลงเฉพาะกรณี DATETIME แล้วกำหนดให้เป็น DATETIME เหมือนคอลัมน์เวลาอื่น
+SET @exit_time_type = (
+    SELECT DATA_TYPE FROM information_schema.COLUMNS
+    WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'visitors' AND COLUMN_NAME = 'exit_time'
+);
+SET @convert_exit_time = IF(@exit_time_type = 'datetime',
+    'UPDATE visitors SET exit_time = CONVERT_TZ(exit_time, @old_offset, ''+00:00'') WHERE exit_time IS NOT NULL',
+    'DO 0');
+PREPARE stmt FROM @convert_exit_time;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+-- TIMESTAMP -> DATETIME แปลงค่าตาม time_zone ของ session ซึ่งเป็น UTC แล้ว
+SET @alter_exit_time = IF(@exit_time_type IS NULL,
+    'ALTER TABLE visitors ADD COLUMN exit_time DATETIME NULL AFTER registered_at',
+    'ALTER TABLE visitors MODIFY COLUMN exit_time DATETIME NULL');
+PREPARE stmt FROM @alter_exit_time;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
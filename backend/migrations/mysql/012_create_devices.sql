@@ -0,0 +1,29 @@
+-- migrations/012_create_devices.sql
+-- เครื่องอ่านบัตร RFID (ประตูเข้า ประตูออก โต๊ะประชาสัมพันธ์) และ log การแตะบัตรไว้ตรวจปัญหา
+
+CREATE TABLE IF NOT EXISTS devices (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    location VARCHAR(200) NOT NULL DEFAULT '',
+    role VARCHAR(20) NOT NULL COMMENT 'check_in, check_out หรือ roll_call',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    key_hash CHAR(64) NOT NULL COMMENT 'SHA-256 ของ API key (ไม่เก็บ key จริง)',
+    key_hint VARCHAR(12) NOT NULL DEFAULT '' COMMENT 'ต้นของ key ไว้ดูว่าเครื่องใช้ key ไหน',
+    last_seen_at DATETIME NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS device_taps (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    device_id INT NOT NULL,
+    card_uid VARCHAR(50) NOT NULL,
+    role VARCHAR(20) NOT NULL COMMENT 'role ของเครื่องตอนที่แตะ',
+    result VARCHAR(20) NOT NULL,
+    visitor_id INT NULL,
+    message VARCHAR(255) NOT NULL DEFAULT '',
+    tapped_at DATETIME NOT NULL,
+    FOREIGN KEY (device_id) REFERENCES devices(id) ON DELETE CASCADE,
+    FOREIGN KEY (visitor_id) REFERENCES visitors(id) ON DELETE SET NULL,
+    INDEX idx_device_taps_device (device_id, tapped_at),
+    INDEX idx_device_taps_card (card_uid, tapped_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,3 @@
+-- migrations/013_create_watchlist.down.sql
+
+DROP TABLE IF EXISTS watchlist;
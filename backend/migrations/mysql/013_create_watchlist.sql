@@ -0,0 +1,14 @@
+-- migrations/013_create_watchlist.sql
+-- รายชื่อเฝ้าระวังของโรงเรียน ตรวจด้วยเลขบัตรประชาชนตอนลงทะเบียน
+-- พบแล้วไม่ห้ามลงทะเบียน แต่แจ้งป้อมยามทุกจุดผ่าน event visitor.watchlist_hit
+
+CREATE TABLE IF NOT EXISTS watchlist (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    id_card VARCHAR(13) NOT NULL,
+    name VARCHAR(200) NOT NULL DEFAULT '',
+    reason VARCHAR(255) NOT NULL,
+    added_by VARCHAR(100) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE INDEX uq_watchlist_id_card (id_card)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
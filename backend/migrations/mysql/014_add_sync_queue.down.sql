@@ -0,0 +1,12 @@
+-- migrations/014_add_sync_queue.down.sql
+-- รายการที่ยังไม่ได้ส่งขึ้น server กลางจะหายไปด้วย
+
+DROP TABLE IF EXISTS sync_queue;
+
+ALTER TABLE return_card_logs
+    DROP INDEX idx_return_card_logs_client_id,
+    DROP COLUMN client_id;
+
+ALTER TABLE visitors
+    DROP INDEX idx_visitors_client_id,
+    DROP COLUMN client_id;
@@ -0,0 +1,25 @@
+-- migrations/014_add_sync_queue.sql
+-- โหมด edge: เครื่องที่ประตูลงทะเบียน/คืนบัตรได้แม้เน็ตหลุด แล้วส่งขึ้น server กลางทีหลัง
+-- client_id เป็น UUID ที่สร้างตอนบันทึก ใช้จับคู่รายการเดียวกันระหว่างเครื่องและกันส่งซ้ำ
+
+ALTER TABLE visitors
+    ADD COLUMN client_id VARCHAR(36) NULL AFTER id,
+    ADD UNIQUE INDEX idx_visitors_client_id (client_id);
+
+ALTER TABLE return_card_logs
+    ADD COLUMN client_id VARCHAR(36) NULL AFTER id,
+    ADD UNIQUE INDEX idx_return_card_logs_client_id (client_id);
+
+-- รายการที่รอส่งขึ้น server กลาง (ใช้เฉพาะเครื่อง edge)
+CREATE TABLE IF NOT EXISTS sync_queue (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    client_id VARCHAR(36) NOT NULL UNIQUE,
+    kind VARCHAR(20) NOT NULL COMMENT 'visitor หรือ return',
+    record_id INT NOT NULL COMMENT 'id ในตาราง visitors หรือ return_card_logs ของเครื่องนี้',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' COMMENT 'pending, synced หรือ conflict',
+    attempts INT NOT NULL DEFAULT 0,
+    message VARCHAR(255) NOT NULL DEFAULT '' COMMENT 'ข้อผิดพลาดล่าสุดหรือเหตุผลที่ขัดแย้ง',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    synced_at DATETIME NULL,
+    INDEX idx_sync_queue_status (status, id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
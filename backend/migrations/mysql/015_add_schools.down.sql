@@ -0,0 +1,41 @@
+-- migrations/015_add_schools.down.sql
+-- ย้อนได้เฉพาะตอนที่มีโรงเรียนเดียว ข้อมูลที่ซ้ำกันข้ามโรงเรียนจะทำให้สร้าง UNIQUE เดิมไม่ได้
+
+ALTER TABLE watchlist
+    DROP INDEX uq_watchlist_school_id_card,
+    ADD UNIQUE INDEX uq_watchlist_id_card (id_card),
+    DROP COLUMN school_id;
+
+ALTER TABLE devices
+    DROP INDEX idx_devices_school,
+    DROP COLUMN school_id;
+
+ALTER TABLE pickup_alerts
+    DROP INDEX idx_pickup_alerts_school,
+    DROP COLUMN school_id;
+
+ALTER TABLE students
+    DROP INDEX uq_students_school_code,
+    ADD UNIQUE INDEX student_code (student_code),
+    DROP COLUMN school_id;
+
+ALTER TABLE appointments
+    DROP INDEX idx_appointments_school,
+    DROP COLUMN school_id;
+
+ALTER TABLE export_history
+    DROP INDEX idx_export_history_school,
+    DROP COLUMN school_id;
+
+ALTER TABLE return_card_logs
+    DROP INDEX idx_return_card_logs_school,
+    DROP COLUMN school_id;
+
+ALTER TABLE visitors
+    DROP INDEX uq_visitors_school_id_card,
+    DROP INDEX uq_visitors_school_rfid,
+    ADD UNIQUE INDEX id_card (id_card),
+    ADD UNIQUE INDEX rfid (rfid),
+    DROP COLUMN school_id;
+
+DROP TABLE IF EXISTS schools;
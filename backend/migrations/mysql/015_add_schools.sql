@@ -0,0 +1,55 @@
+-- migrations/015_add_schools.sql
+-- หลายโรงเรียนในเขตใช้ deployment เดียวกัน ทุกตารางของโรงเรียนมี school_id
+-- ข้อมูลเดิมเป็นของโรงเรียนแรก (id 1) ซึ่งสร้างไว้ให้ แก้ชื่อ/code ได้ภายหลังผ่าน /api/district/schools
+-- เลขบัตรประชาชน บัตร RFID และเลขประจำตัวนักเรียนไม่ซ้ำภายในโรงเรียนเดียวกัน (คนเดียวกันไปได้หลายโรงเรียน)
+-- vehicles และ visit_purposes ใช้ร่วมกันทั้งเขต (รถคันเดียวกันคือคันเดียวกัน ทุกรายการอ่านผ่าน visitors)
+
+CREATE TABLE IF NOT EXISTS schools (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    code VARCHAR(50) NOT NULL UNIQUE COMMENT 'ใช้เป็น subdomain และ header X-School',
+    name VARCHAR(200) NOT NULL,
+    timezone VARCHAR(64) NOT NULL DEFAULT '',
+    departments TEXT NULL COMMENT 'JSON array ของชื่อหน่วยงาน',
+    retention_days INT NOT NULL DEFAULT 0 COMMENT '0 = เก็บตลอด',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+INSERT INTO schools (id, code, name) VALUES (1, 'main', 'โรงเรียน');
+
+ALTER TABLE visitors
+    ADD COLUMN school_id INT NOT NULL DEFAULT 1 AFTER id,
+    DROP INDEX id_card,
+    DROP INDEX rfid,
+    ADD UNIQUE INDEX uq_visitors_school_id_card (school_id, id_card),
+    ADD UNIQUE INDEX uq_visitors_school_rfid (school_id, rfid);
+
+ALTER TABLE return_card_logs
+    ADD COLUMN school_id INT NOT NULL DEFAULT 1 AFTER id,
+    ADD INDEX idx_return_card_logs_school (school_id, return_date);
+
+ALTER TABLE export_history
+    ADD COLUMN school_id INT NOT NULL DEFAULT 1 AFTER id,
+    ADD INDEX idx_export_history_school (school_id, created_at);
+
+ALTER TABLE appointments
+    ADD COLUMN school_id INT NOT NULL DEFAULT 1 AFTER id,
+    ADD INDEX idx_appointments_school (school_id, expected_from);
+
+ALTER TABLE students
+    ADD COLUMN school_id INT NOT NULL DEFAULT 1 AFTER id,
+    DROP INDEX student_code,
+    ADD UNIQUE INDEX uq_students_school_code (school_id, student_code);
+
+ALTER TABLE pickup_alerts
+    ADD COLUMN school_id INT NOT NULL DEFAULT 1 AFTER id,
+    ADD INDEX idx_pickup_alerts_school (school_id, created_at);
+
+ALTER TABLE devices
+    ADD COLUMN school_id INT NOT NULL DEFAULT 1 AFTER id,
+    ADD INDEX idx_devices_school (school_id);
+
+ALTER TABLE watchlist
+    ADD COLUMN school_id INT NOT NULL DEFAULT 1 AFTER id,
+    DROP INDEX uq_watchlist_id_card,
+    ADD UNIQUE INDEX uq_watchlist_school_id_card (school_id, id_card);
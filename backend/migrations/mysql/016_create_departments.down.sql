@@ -0,0 +1,14 @@
+-- migrations/016_create_departments.down.sql
+
+ALTER TABLE schools ADD COLUMN departments TEXT NULL COMMENT 'JSON array ของชื่อหน่วยงาน' AFTER timezone;
+
+UPDATE schools s SET departments = (
+    SELECT JSON_ARRAYAGG(d.name_th) FROM departments d
+    WHERE d.school_id = s.id AND d.active = TRUE
+);
+
+ALTER TABLE visitors
+    DROP INDEX idx_visitors_school_department,
+    DROP COLUMN department_id;
+
+DROP TABLE IF EXISTS departments;
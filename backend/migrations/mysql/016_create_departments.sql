@@ -0,0 +1,59 @@
+-- migrations/016_create_departments.sql
+-- ส่วนงานที่ผู้มาติดต่อเลือกได้ แยกตามโรงเรียน (เดิมเป็นข้อความอิสระใน visitors.department และ schools.departments)
+-- visitors.department ยังเก็บชื่อ ณ วันที่มาติดต่อไว้แสดงผล ส่วน department_id ใช้กรองและอ้างอิง
+-- ต้องใช้ MySQL 8.0 ขึ้นไป (JSON_TABLE)
+
+CREATE TABLE IF NOT EXISTS departments (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    school_id INT NOT NULL DEFAULT 1,
+    name_th VARCHAR(200) NOT NULL,
+    name_en VARCHAR(200) NOT NULL DEFAULT '',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    display_order INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE INDEX uq_departments_school_name (school_id, name_th),
+    INDEX idx_departments_school_order (school_id, display_order)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 1. รายชื่อที่ตั้งไว้ใน schools.departments ตามลำดับเดิม
+INSERT IGNORE INTO departments (school_id, name_th, display_order)
+SELECT s.id, TRIM(j.name), j.pos
+FROM schools s,
+    JSON_TABLE(COALESCE(NULLIF(s.departments, ''), '[]'), '$[*]' COLUMNS (
+        pos FOR ORDINALITY,
+        name VARCHAR(200) PATH '$'
+    )) AS j
+WHERE TRIM(j.name) <> '';
+
+-- 2. โรงเรียนที่ยังไม่มีรายชื่อ ได้ชุดเดียวกับที่หน้าลงทะเบียนเคยใช้
+INSERT IGNORE INTO departments (school_id, name_th, name_en, display_order)
+SELECT s.id, d.name_th, d.name_en, d.display_order
+FROM schools s
+CROSS JOIN (
+    SELECT 'สำนักงานผู้อำนวยการ' AS name_th, 'Director''s Office' AS name_en, 1 AS display_order
+    UNION ALL SELECT 'กลุ่มบริหารงานบุคคล', 'Personnel Administration', 2
+    UNION ALL SELECT 'กลุ่มบริหารวิชาการ', 'Academic Affairs', 3
+    UNION ALL SELECT 'กลุ่มบริหารทั่วไป', 'General Administration', 4
+    UNION ALL SELECT 'กลุ่มบริหารงบประมาน', 'Budget Administration', 5
+    UNION ALL SELECT 'ติดต่อ ข้าราชการครู', 'Teachers', 6
+    UNION ALL SELECT 'นักพัฒนา', 'Developers', 7
+    UNION ALL SELECT 'พ่อค้า และ แม่ค้า', 'Vendors', 8
+    UNION ALL SELECT 'ร้านค้าสหกรณ์โรงเรียน', 'School Cooperative Store', 9
+) d
+WHERE NOT EXISTS (SELECT 1 FROM departments x WHERE x.school_id = s.id);
+
+-- 3. ข้อความอื่นที่เคยบันทึกไว้ เก็บเป็นส่วนงานที่ปิดใช้งาน (ยังกรองรายงานย้อนหลังได้ ผู้ดูแลเปิดใช้หรือแก้ชื่อได้)
+INSERT IGNORE INTO departments (school_id, name_th, active, display_order)
+SELECT DISTINCT v.school_id, TRIM(v.department), FALSE, 1000
+FROM visitors v
+WHERE TRIM(COALESCE(v.department, '')) <> '';
+
+ALTER TABLE visitors
+    ADD COLUMN department_id INT NULL AFTER department,
+    ADD INDEX idx_visitors_school_department (school_id, department_id);
+
+UPDATE visitors v
+JOIN departments d ON d.school_id = v.school_id AND d.name_th = TRIM(v.department)
+SET v.department_id = d.id;
+
+ALTER TABLE schools DROP COLUMN departments;
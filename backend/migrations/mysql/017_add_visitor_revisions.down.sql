@@ -0,0 +1,8 @@
+-- migrations/017_add_visitor_revisions.down.sql
+-- รายการที่ถูกลบแบบ soft delete จะกลับมาแสดง ประวัติการแก้ไขหายไปด้วย
+
+DROP TABLE IF EXISTS visitor_revisions;
+
+ALTER TABLE visitors
+    DROP COLUMN deleted_at,
+    DROP COLUMN version;
@@ -0,0 +1,22 @@
+-- migrations/017_add_visitor_revisions.sql
+-- แก้ไขและลบรายการผู้มาติดต่อ (PATCH / DELETE /api/visitors/{id})
+-- version เพิ่มทุกครั้งที่รายการเปลี่ยน ใช้เป็น ETag กันการแก้ทับกัน (updated_at ละเอียดแค่วินาที)
+-- ลบแบบ soft delete: deleted_at ไม่ว่าง = ถูกลบ ไม่แสดงในรายการ รายงาน และการค้นหา
+
+ALTER TABLE visitors
+    ADD COLUMN version INT NOT NULL DEFAULT 1,
+    ADD COLUMN deleted_at DATETIME NULL;
+
+-- ประวัติการแก้ไขของแต่ละรายการ: ค่าเดิม ค่าใหม่ ใครแก้ และเหตุผล
+CREATE TABLE IF NOT EXISTS visitor_revisions (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    school_id INT NOT NULL DEFAULT 1,
+    visitor_id INT NOT NULL,
+    version INT NOT NULL COMMENT 'version ของรายการหลังแก้',
+    action VARCHAR(20) NOT NULL COMMENT 'update หรือ delete',
+    changes TEXT NOT NULL COMMENT 'JSON array ของ {field, old, new}',
+    changed_by VARCHAR(100) NOT NULL,
+    reason VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_visitor_revisions_visitor (visitor_id, id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
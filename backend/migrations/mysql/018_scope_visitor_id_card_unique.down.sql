@@ -0,0 +1,7 @@
+-- migrations/018_scope_visitor_id_card_unique.down.sql
+-- ใช้ไม่ได้ถ้ามีเลขบัตรซ้ำกับรายการที่ถูกลบแล้ว
+
+ALTER TABLE visitors
+    DROP INDEX uq_visitors_school_id_card,
+    DROP COLUMN live_id_card,
+    ADD UNIQUE INDEX uq_visitors_school_id_card (school_id, id_card);
@@ -0,0 +1,8 @@
+-- migrations/018_scope_visitor_id_card_unique.sql
+-- เลขบัตรประชาชนไม่ซ้ำเฉพาะรายการที่ยังไม่ถูกลบ ลบรายการที่ลงทะเบียนผิดแล้วลงทะเบียนคนเดิมใหม่ได้
+-- MySQL ไม่มี partial index จึงใช้คอลัมน์ generated ที่เป็น NULL เมื่อถูกลบ (UNIQUE ไม่นับ NULL)
+
+ALTER TABLE visitors
+    ADD COLUMN live_id_card VARCHAR(13) AS (CASE WHEN deleted_at IS NULL THEN id_card END) VIRTUAL,
+    DROP INDEX uq_visitors_school_id_card,
+    ADD UNIQUE INDEX uq_visitors_school_id_card (school_id, live_id_card);
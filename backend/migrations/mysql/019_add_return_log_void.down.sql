@@ -0,0 +1,7 @@
+-- migrations/019_add_return_log_void.down.sql
+-- การคืนบัตรที่ถูกยกเลิกจะกลับมานับเป็นการคืนบัตรปกติ
+
+ALTER TABLE return_card_logs
+    DROP COLUMN void_reason,
+    DROP COLUMN voided_by,
+    DROP COLUMN voided_at;
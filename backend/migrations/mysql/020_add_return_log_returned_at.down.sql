@@ -0,0 +1,4 @@
+-- migrations/020_add_return_log_returned_at.down.sql
+
+ALTER TABLE return_card_logs
+    DROP COLUMN returned_at;
@@ -0,0 +1,4 @@
+-- migrations/postgres/001_create_visitors_table.down.sql
+
+DROP TABLE IF EXISTS visitors;
+DROP FUNCTION IF EXISTS set_updated_at();
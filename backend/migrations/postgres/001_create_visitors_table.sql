@@ -0,0 +1,48 @@
+-- migrations/postgres/001_create_visitors_table.sql
+-- ชุด migration ของ PostgreSQL ใช้เลขเดียวกับ mysql/ ทุกไฟล์ ไฟล์ที่ไม่มีอะไรต้องทำใน PostgreSQL มีแต่ comment
+-- ฐานข้อมูลต้องสร้างไว้ก่อน (CREATE DATABASE visitor_system ENCODING 'UTF8') เวลาทุกคอลัมน์เป็น TIMESTAMPTZ
+-- rfid เป็น NULL ได้ตั้งแต่แรกเหมือน sqlite/
+-- citext ให้เทียบเลขบัตร/RFID แบบไม่สนตัวพิมพ์เหมือน utf8mb4_unicode_ci (เป็น trusted extension เจ้าของฐานข้อมูลติดตั้งเองได้)
+
+CREATE EXTENSION IF NOT EXISTS citext;
+
+CREATE TABLE IF NOT EXISTS visitors (
+    id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    id_card VARCHAR(13) NOT NULL UNIQUE,
+    first_name VARCHAR(100) NOT NULL,
+    last_name VARCHAR(100) NOT NULL,
+    birth_date DATE,
+    phone VARCHAR(15) NOT NULL,
+    license_plate VARCHAR(20),
+    house_number VARCHAR(50),
+    moo VARCHAR(20),
+    soi VARCHAR(100),
+    road VARCHAR(100),
+    sub_district VARCHAR(100),
+    district VARCHAR(100),
+    province VARCHAR(100),
+    rfid CITEXT NULL UNIQUE,
+    department VARCHAR(200),
+    officer_name VARCHAR(200),
+    id_card_image TEXT,
+    registered_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_registered_at ON visitors(registered_at);
+CREATE INDEX IF NOT EXISTS idx_name ON visitors(first_name, last_name);
+CREATE INDEX IF NOT EXISTS idx_department ON visitors(department);
+
+-- แทน ON UPDATE CURRENT_TIMESTAMP ของ MySQL (ถ้า UPDATE ตั้ง updated_at เองจะไม่ทับ)
+CREATE OR REPLACE FUNCTION set_updated_at() RETURNS trigger AS $$
+BEGIN
+    IF NEW.updated_at IS NOT DISTINCT FROM OLD.updated_at THEN
+        NEW.updated_at := CURRENT_TIMESTAMP;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_visitors_updated_at ON visitors;
+CREATE TRIGGER trg_visitors_updated_at
+BEFORE UPDATE ON visitors FOR EACH ROW EXECUTE FUNCTION set_updated_at();
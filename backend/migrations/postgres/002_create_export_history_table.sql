@@ -0,0 +1,17 @@
+-- migrations/postgres/002_create_export_history_table.sql
+-- สร้างตาราง export_history สำหรับเก็บประวัติการส่งออกข้อมูล
+
+CREATE TABLE IF NOT EXISTS export_history (
+    id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    export_date VARCHAR(20) NOT NULL,
+    department VARCHAR(100) NOT NULL,
+    date_range VARCHAR(100) NOT NULL,
+    format VARCHAR(20) NOT NULL DEFAULT 'Excel',
+    status VARCHAR(20) NOT NULL DEFAULT 'เสร็จสิ้น',
+    record_count INT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_export_date ON export_history(export_date);
+CREATE INDEX IF NOT EXISTS idx_export_department ON export_history(department);
+CREATE INDEX IF NOT EXISTS idx_export_created_at ON export_history(created_at);
@@ -0,0 +1,16 @@
+-- migrations/postgres/003_create_return_card_logs.sql
+
+CREATE TABLE IF NOT EXISTS return_card_logs (
+    id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    visitor_id INT NOT NULL REFERENCES visitors(id) ON DELETE CASCADE,
+    card_id CITEXT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    check_in VARCHAR(10) NOT NULL,
+    check_out VARCHAR(10) NOT NULL,
+    return_date DATE NOT NULL,
+    status VARCHAR(50) DEFAULT 'การคืนบัตรสำเร็จ',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_card_id ON return_card_logs(card_id);
+CREATE INDEX IF NOT EXISTS idx_return_date ON return_card_logs(return_date);
@@ -0,0 +1 @@
+-- migrations/postgres/004_add_search_fulltext_indexes.down.sql
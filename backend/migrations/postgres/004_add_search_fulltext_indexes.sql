@@ -0,0 +1,2 @@
+-- migrations/postgres/004_add_search_fulltext_indexes.sql
+-- PostgreSQL ไม่มีดัชนี FULLTEXT แบบ ngram ของ MySQL การค้นหาใช้ ILIKE ทุกคำ (Dialect.FullText)
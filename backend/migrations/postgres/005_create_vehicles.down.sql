@@ -0,0 +1,6 @@
+-- migrations/postgres/005_create_vehicles.down.sql
+-- ทะเบียนรถยังอยู่ใน visitors.license_plate จึงไม่เสียข้อมูล (ยกเว้นจังหวัด/ประเภท/สีของรถ)
+
+ALTER TABLE visitors DROP COLUMN IF EXISTS vehicle_id;
+
+DROP TABLE IF EXISTS vehicles;
@@ -0,0 +1,31 @@
+-- migrations/postgres/005_create_vehicles.sql
+-- ยานพาหนะของผู้มาติดต่อ ผูกกับการเข้าเยี่ยมแต่ละครั้งผ่าน visitors.vehicle_id
+
+CREATE TABLE IF NOT EXISTS vehicles (
+    id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    plate_number CITEXT NOT NULL,
+    plate_display VARCHAR(30) NOT NULL,
+    province VARCHAR(100) NOT NULL DEFAULT '',
+    vehicle_type VARCHAR(20) NOT NULL DEFAULT 'car',
+    color VARCHAR(50) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (plate_number, province)
+);
+
+ALTER TABLE visitors ADD COLUMN IF NOT EXISTS vehicle_id INT NULL REFERENCES vehicles(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_vehicle_id ON visitors(vehicle_id);
+
+-- ย้ายทะเบียนรถเดิมที่เป็นข้อความอิสระเข้าตาราง vehicles (ไม่ทราบจังหวัด)
+INSERT INTO vehicles (plate_number, plate_display)
+SELECT REPLACE(REPLACE(REPLACE(license_plate, ' ', ''), '-', ''), '.', ''), MIN(license_plate)
+FROM visitors
+WHERE license_plate IS NOT NULL AND license_plate <> ''
+GROUP BY REPLACE(REPLACE(REPLACE(license_plate, ' ', ''), '-', ''), '.', '')
+ON CONFLICT DO NOTHING;
+
+UPDATE visitors v SET vehicle_id = ve.id
+FROM vehicles ve
+WHERE ve.plate_number = REPLACE(REPLACE(REPLACE(v.license_plate, ' ', ''), '-', ''), '.', '')
+AND ve.province = ''
+AND v.license_plate IS NOT NULL AND v.license_plate <> '';
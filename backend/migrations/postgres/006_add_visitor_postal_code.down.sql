@@ -0,0 +1,3 @@
+-- migrations/postgres/006_add_visitor_postal_code.down.sql
+
+ALTER TABLE visitors DROP COLUMN IF EXISTS postal_code;
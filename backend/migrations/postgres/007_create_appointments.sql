@@ -0,0 +1,25 @@
+-- migrations/postgres/007_create_appointments.sql
+-- การนัดหมายล่วงหน้า (ผู้ปกครองพบครู, ผู้ส่งของ ฯลฯ) พร้อม QR เชิญ
+
+CREATE TABLE IF NOT EXISTS appointments (
+    id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    id_card VARCHAR(13) NOT NULL DEFAULT '',
+    first_name VARCHAR(100) NOT NULL,
+    last_name VARCHAR(100) NOT NULL,
+    phone VARCHAR(15) NOT NULL DEFAULT '',
+    license_plate VARCHAR(20) NOT NULL DEFAULT '',
+    host_name VARCHAR(200) NOT NULL,
+    host_contact VARCHAR(200) NOT NULL DEFAULT '',
+    department VARCHAR(200) NOT NULL DEFAULT '',
+    purpose VARCHAR(255) NOT NULL DEFAULT '',
+    expected_from TIMESTAMPTZ NOT NULL,
+    expected_until TIMESTAMPTZ NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    visitor_id INT NULL REFERENCES visitors(id) ON DELETE SET NULL,
+    created_by VARCHAR(200) NOT NULL DEFAULT '',
+    arrived_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_appointments_expected_from ON appointments(expected_from);
+CREATE INDEX IF NOT EXISTS idx_appointments_status ON appointments(status);
@@ -0,0 +1,15 @@
+-- migrations/postgres/008_add_visit_host_purpose_approval.down.sql
+
+DROP INDEX IF EXISTS idx_visitors_approval;
+
+ALTER TABLE visitors
+    DROP COLUMN IF EXISTS approval_note,
+    DROP COLUMN IF EXISTS approved_at,
+    DROP COLUMN IF EXISTS approved_by,
+    DROP COLUMN IF EXISTS approval_status,
+    DROP COLUMN IF EXISTS purpose_note,
+    DROP COLUMN IF EXISTS purpose,
+    DROP COLUMN IF EXISTS host_contact,
+    DROP COLUMN IF EXISTS host_name;
+
+DROP TABLE IF EXISTS visit_purposes;
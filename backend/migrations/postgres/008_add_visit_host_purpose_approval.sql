@@ -0,0 +1,35 @@
+-- migrations/postgres/008_add_visit_host_purpose_approval.sql
+-- ผู้ที่มาพบ วัตถุประสงค์ และขั้นตอนขออนุมัติจากผู้รับนัดก่อนออกบัตร
+
+CREATE TABLE IF NOT EXISTS visit_purposes (
+    code VARCHAR(50) PRIMARY KEY,
+    name_th VARCHAR(200) NOT NULL,
+    name_en VARCHAR(200) NOT NULL DEFAULT '',
+    requires_approval BOOLEAN NOT NULL DEFAULT FALSE,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    display_order INT NOT NULL DEFAULT 0
+);
+
+INSERT INTO visit_purposes (code, name_th, name_en, requires_approval, display_order) VALUES
+    ('pickup_student', 'รับนักเรียน', 'Student pickup', FALSE, 1),
+    ('meeting', 'ประชุม / พบครู', 'Meeting', TRUE, 2),
+    ('contact_office', 'ติดต่อธุรการ', 'Office business', FALSE, 3),
+    ('delivery', 'ส่งของ / ส่งเอกสาร', 'Delivery', FALSE, 4),
+    ('maintenance', 'ซ่อมบำรุง', 'Maintenance', TRUE, 5),
+    ('other', 'อื่นๆ', 'Other', FALSE, 99)
+ON CONFLICT (code) DO NOTHING;
+
+-- rfid เป็น NULL ได้ตั้งแต่ 001 เหลือแค่ล้างค่าว่าง
+UPDATE visitors SET rfid = NULL WHERE rfid = '';
+
+ALTER TABLE visitors
+    ADD COLUMN IF NOT EXISTS host_name VARCHAR(200) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS host_contact VARCHAR(200) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS purpose VARCHAR(50) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS purpose_note VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS approval_status VARCHAR(20) NOT NULL DEFAULT 'not_required',
+    ADD COLUMN IF NOT EXISTS approved_by VARCHAR(200) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS approved_at TIMESTAMPTZ NULL,
+    ADD COLUMN IF NOT EXISTS approval_note VARCHAR(255) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_visitors_approval ON visitors(approval_status, host_name);
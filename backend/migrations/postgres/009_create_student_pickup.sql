@@ -0,0 +1,54 @@
+-- migrations/postgres/009_create_student_pickup.sql
+-- นักเรียน ผู้มีสิทธิ์รับนักเรียน (ผูกกับเลขบัตรประชาชน) ประวัติการรับ และการแจ้งเตือนเมื่อถูกปฏิเสธ
+
+CREATE TABLE IF NOT EXISTS students (
+    id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    student_code CITEXT NOT NULL UNIQUE,
+    first_name VARCHAR(100) NOT NULL,
+    last_name VARCHAR(100) NOT NULL,
+    class_room VARCHAR(50) NOT NULL DEFAULT '',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_students_name ON students(first_name, last_name);
+
+CREATE TABLE IF NOT EXISTS guardian_authorisations (
+    id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    student_id INT NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+    id_card VARCHAR(13) NOT NULL,
+    guardian_name VARCHAR(200) NOT NULL,
+    relationship VARCHAR(50) NOT NULL,
+    valid_from DATE NOT NULL,
+    valid_until DATE NULL,
+    revoked_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_guardian_id_card ON guardian_authorisations(id_card);
+CREATE INDEX IF NOT EXISTS idx_guardian_student ON guardian_authorisations(student_id);
+
+CREATE TABLE IF NOT EXISTS student_pickups (
+    id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    student_id INT NOT NULL REFERENCES students(id),
+    visitor_id INT NOT NULL REFERENCES visitors(id),
+    authorisation_id INT NOT NULL REFERENCES guardian_authorisations(id),
+    officer_name VARCHAR(200) NOT NULL DEFAULT '',
+    picked_up_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_pickups_picked_up_at ON student_pickups(picked_up_at);
+
+CREATE TABLE IF NOT EXISTS pickup_alerts (
+    id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    student_id INT NOT NULL REFERENCES students(id),
+    visitor_id INT NULL REFERENCES visitors(id) ON DELETE SET NULL,
+    id_card VARCHAR(13) NOT NULL,
+    visitor_name VARCHAR(255) NOT NULL DEFAULT '',
+    reason VARCHAR(255) NOT NULL,
+    officer_name VARCHAR(200) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    acknowledged_at TIMESTAMPTZ NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_pickup_alerts_created_at ON pickup_alerts(created_at);
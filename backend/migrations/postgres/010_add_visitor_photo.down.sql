@@ -0,0 +1,7 @@
+-- migrations/postgres/010_add_visitor_photo.down.sql
+-- ไฟล์รูปใน storage ไม่ถูกลบ
+
+ALTER TABLE visitors
+    DROP COLUMN IF EXISTS photo_taken_at,
+    DROP COLUMN IF EXISTS photo_thumbnail_key,
+    DROP COLUMN IF EXISTS photo_key;
@@ -0,0 +1,3 @@
+-- migrations/postgres/011_store_times_in_utc.down.sql
+
+ALTER TABLE visitors DROP COLUMN IF EXISTS exit_time;
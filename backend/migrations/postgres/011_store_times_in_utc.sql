@@ -0,0 +1,5 @@
+-- migrations/postgres/011_store_times_in_utc.sql
+-- ฐานข้อมูล PostgreSQL เก็บเวลาเป็น TIMESTAMPTZ ตั้งแต่แรก ไม่มีข้อมูลเก่าต้องเลื่อน
+-- เหลือแค่ exit_time ที่ฝั่ง MySQL เพิ่มใน migration นี้
+
+ALTER TABLE visitors ADD COLUMN IF NOT EXISTS exit_time TIMESTAMPTZ NULL;
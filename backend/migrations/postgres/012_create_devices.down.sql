@@ -0,0 +1,4 @@
+-- migrations/postgres/012_create_devices.down.sql
+
+DROP TABLE IF EXISTS device_taps;
+DROP TABLE IF EXISTS devices;
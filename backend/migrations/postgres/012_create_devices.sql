@@ -0,0 +1,28 @@
+-- migrations/postgres/012_create_devices.sql
+-- เครื่องอ่านบัตร RFID (ประตูเข้า ประตูออก โต๊ะประชาสัมพันธ์) และ log การแตะบัตรไว้ตรวจปัญหา
+
+CREATE TABLE IF NOT EXISTS devices (
+    id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    location VARCHAR(200) NOT NULL DEFAULT '',
+    role VARCHAR(20) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    key_hash CHAR(64) NOT NULL,
+    key_hint VARCHAR(12) NOT NULL DEFAULT '',
+    last_seen_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS device_taps (
+    id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    device_id INT NOT NULL REFERENCES devices(id) ON DELETE CASCADE,
+    card_uid CITEXT NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    result VARCHAR(20) NOT NULL,
+    visitor_id INT NULL REFERENCES visitors(id) ON DELETE SET NULL,
+    message VARCHAR(255) NOT NULL DEFAULT '',
+    tapped_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_device_taps_device ON device_taps(device_id, tapped_at);
+CREATE INDEX IF NOT EXISTS idx_device_taps_card ON device_taps(card_uid, tapped_at);
@@ -0,0 +1,24 @@
+-- migrations/postgres/014_add_sync_queue.sql
+-- โหมด edge: เครื่องที่ประตูลงทะเบียน/คืนบัตรได้แม้เน็ตหลุด แล้วส่งขึ้น server กลางทีหลัง
+-- client_id เป็น UUID ที่สร้างตอนบันทึก ใช้จับคู่รายการเดียวกันระหว่างเครื่องและกันส่งซ้ำ
+
+ALTER TABLE visitors ADD COLUMN IF NOT EXISTS client_id VARCHAR(36) NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_visitors_client_id ON visitors(client_id);
+
+ALTER TABLE return_card_logs ADD COLUMN IF NOT EXISTS client_id VARCHAR(36) NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_return_card_logs_client_id ON return_card_logs(client_id);
+
+-- รายการที่รอส่งขึ้น server กลาง (ใช้เฉพาะเครื่อง edge)
+CREATE TABLE IF NOT EXISTS sync_queue (
+    id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    client_id VARCHAR(36) NOT NULL UNIQUE,
+    kind VARCHAR(20) NOT NULL,
+    record_id INT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    message VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    synced_at TIMESTAMPTZ NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_sync_queue_status ON sync_queue(status, id);
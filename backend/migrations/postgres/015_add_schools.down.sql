@@ -0,0 +1,25 @@
+-- migrations/postgres/015_add_schools.down.sql
+-- ย้อนได้เฉพาะตอนที่มีโรงเรียนเดียว ข้อมูลที่ซ้ำกันข้ามโรงเรียนจะทำให้สร้าง UNIQUE เดิมไม่ได้
+
+DROP INDEX IF EXISTS uq_watchlist_school_id_card;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_watchlist_id_card ON watchlist(id_card);
+ALTER TABLE watchlist DROP COLUMN IF EXISTS school_id;
+
+ALTER TABLE devices DROP COLUMN IF EXISTS school_id;
+ALTER TABLE pickup_alerts DROP COLUMN IF EXISTS school_id;
+
+DROP INDEX IF EXISTS uq_students_school_code;
+ALTER TABLE students ADD CONSTRAINT students_student_code_key UNIQUE (student_code);
+ALTER TABLE students DROP COLUMN IF EXISTS school_id;
+
+ALTER TABLE appointments DROP COLUMN IF EXISTS school_id;
+ALTER TABLE export_history DROP COLUMN IF EXISTS school_id;
+ALTER TABLE return_card_logs DROP COLUMN IF EXISTS school_id;
+
+DROP INDEX IF EXISTS uq_visitors_school_id_card;
+DROP INDEX IF EXISTS uq_visitors_school_rfid;
+ALTER TABLE visitors ADD CONSTRAINT visitors_id_card_key UNIQUE (id_card);
+ALTER TABLE visitors ADD CONSTRAINT visitors_rfid_key UNIQUE (rfid);
+ALTER TABLE visitors DROP COLUMN IF EXISTS school_id;
+
+DROP TABLE IF EXISTS schools;
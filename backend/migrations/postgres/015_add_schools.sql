@@ -0,0 +1,48 @@
+-- migrations/postgres/015_add_schools.sql
+-- หลายโรงเรียนในเขตใช้ deployment เดียวกัน ทุกตารางของโรงเรียนมี school_id
+-- ข้อมูลเดิมเป็นของโรงเรียนแรก (id 1) ซึ่งสร้างไว้ให้ แก้ชื่อ/code ได้ภายหลังผ่าน /api/district/schools
+-- เลขบัตรประชาชน บัตร RFID และเลขประจำตัวนักเรียนไม่ซ้ำภายในโรงเรียนเดียวกัน (คนเดียวกันไปได้หลายโรงเรียน)
+-- vehicles และ visit_purposes ใช้ร่วมกันทั้งเขต (รถคันเดียวกันคือคันเดียวกัน ทุกรายการอ่านผ่าน visitors)
+
+CREATE TABLE IF NOT EXISTS schools (
+    id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    code CITEXT NOT NULL UNIQUE,
+    name VARCHAR(200) NOT NULL,
+    timezone VARCHAR(64) NOT NULL DEFAULT '',
+    departments TEXT NULL,
+    retention_days INT NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO schools (id, code, name) VALUES (1, 'main', 'โรงเรียน');
+SELECT setval(pg_get_serial_sequence('schools', 'id'), 1);
+
+ALTER TABLE visitors ADD COLUMN IF NOT EXISTS school_id INT NOT NULL DEFAULT 1;
+ALTER TABLE visitors DROP CONSTRAINT IF EXISTS visitors_id_card_key;
+ALTER TABLE visitors DROP CONSTRAINT IF EXISTS visitors_rfid_key;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_visitors_school_id_card ON visitors(school_id, id_card);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_visitors_school_rfid ON visitors(school_id, rfid);
+
+ALTER TABLE return_card_logs ADD COLUMN IF NOT EXISTS school_id INT NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_return_card_logs_school ON return_card_logs(school_id, return_date);
+
+ALTER TABLE export_history ADD COLUMN IF NOT EXISTS school_id INT NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_export_history_school ON export_history(school_id, created_at);
+
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS school_id INT NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_appointments_school ON appointments(school_id, expected_from);
+
+ALTER TABLE students ADD COLUMN IF NOT EXISTS school_id INT NOT NULL DEFAULT 1;
+ALTER TABLE students DROP CONSTRAINT IF EXISTS students_student_code_key;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_students_school_code ON students(school_id, student_code);
+
+ALTER TABLE pickup_alerts ADD COLUMN IF NOT EXISTS school_id INT NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_pickup_alerts_school ON pickup_alerts(school_id, created_at);
+
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS school_id INT NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_devices_school ON devices(school_id);
+
+ALTER TABLE watchlist ADD COLUMN IF NOT EXISTS school_id INT NOT NULL DEFAULT 1;
+DROP INDEX IF EXISTS uq_watchlist_id_card;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_watchlist_school_id_card ON watchlist(school_id, id_card);
@@ -0,0 +1,13 @@
+-- migrations/postgres/016_create_departments.down.sql
+
+ALTER TABLE schools ADD COLUMN IF NOT EXISTS departments TEXT NULL;
+
+UPDATE schools s SET departments = (
+    SELECT json_agg(d.name_th ORDER BY d.display_order, d.id)::text FROM departments d
+    WHERE d.school_id = s.id AND d.active = TRUE
+);
+
+DROP INDEX IF EXISTS idx_visitors_school_department;
+ALTER TABLE visitors DROP COLUMN IF EXISTS department_id;
+
+DROP TABLE IF EXISTS departments;
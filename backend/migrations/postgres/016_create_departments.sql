@@ -0,0 +1,57 @@
+-- migrations/postgres/016_create_departments.sql
+-- ส่วนงานที่ผู้มาติดต่อเลือกได้ แยกตามโรงเรียน (เดิมเป็นข้อความอิสระใน visitors.department และ schools.departments)
+-- visitors.department ยังเก็บชื่อ ณ วันที่มาติดต่อไว้แสดงผล ส่วน department_id ใช้กรองและอ้างอิง
+
+CREATE TABLE IF NOT EXISTS departments (
+    id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    school_id INT NOT NULL DEFAULT 1,
+    name_th VARCHAR(200) NOT NULL,
+    name_en VARCHAR(200) NOT NULL DEFAULT '',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    display_order INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_departments_school_name ON departments(school_id, name_th);
+CREATE INDEX IF NOT EXISTS idx_departments_school_order ON departments(school_id, display_order);
+
+-- 1. รายชื่อที่ตั้งไว้ใน schools.departments ตามลำดับเดิม
+INSERT INTO departments (school_id, name_th, display_order)
+SELECT s.id, TRIM(j.name), j.pos
+FROM schools s
+CROSS JOIN LATERAL json_array_elements_text(COALESCE(NULLIF(s.departments, ''), '[]')::json) WITH ORDINALITY AS j(name, pos)
+WHERE TRIM(j.name) <> ''
+ON CONFLICT DO NOTHING;
+
+-- 2. โรงเรียนที่ยังไม่มีรายชื่อ ได้ชุดเดียวกับที่หน้าลงทะเบียนเคยใช้
+INSERT INTO departments (school_id, name_th, name_en, display_order)
+SELECT s.id, d.name_th, d.name_en, d.display_order
+FROM schools s
+CROSS JOIN (VALUES
+    ('สำนักงานผู้อำนวยการ', 'Director''s Office', 1),
+    ('กลุ่มบริหารงานบุคคล', 'Personnel Administration', 2),
+    ('กลุ่มบริหารวิชาการ', 'Academic Affairs', 3),
+    ('กลุ่มบริหารทั่วไป', 'General Administration', 4),
+    ('กลุ่มบริหารงบประมาน', 'Budget Administration', 5),
+    ('ติดต่อ ข้าราชการครู', 'Teachers', 6),
+    ('นักพัฒนา', 'Developers', 7),
+    ('พ่อค้า และ แม่ค้า', 'Vendors', 8),
+    ('ร้านค้าสหกรณ์โรงเรียน', 'School Cooperative Store', 9)
+) AS d(name_th, name_en, display_order)
+WHERE NOT EXISTS (SELECT 1 FROM departments x WHERE x.school_id = s.id)
+ON CONFLICT DO NOTHING;
+
+-- 3. ข้อความอื่นที่เคยบันทึกไว้ เก็บเป็นส่วนงานที่ปิดใช้งาน (ยังกรองรายงานย้อนหลังได้ ผู้ดูแลเปิดใช้หรือแก้ชื่อได้)
+INSERT INTO departments (school_id, name_th, active, display_order)
+SELECT DISTINCT v.school_id, TRIM(v.department), FALSE, 1000
+FROM visitors v
+WHERE TRIM(COALESCE(v.department, '')) <> ''
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE visitors ADD COLUMN IF NOT EXISTS department_id INT NULL;
+CREATE INDEX IF NOT EXISTS idx_visitors_school_department ON visitors(school_id, department_id);
+
+UPDATE visitors v SET department_id = d.id
+FROM departments d
+WHERE d.school_id = v.school_id AND d.name_th = TRIM(v.department);
+
+ALTER TABLE schools DROP COLUMN IF EXISTS departments;
@@ -0,0 +1,7 @@
+-- migrations/postgres/017_add_visitor_revisions.down.sql
+-- รายการที่ถูกลบแบบ soft delete จะกลับมาแสดง ประวัติการแก้ไขหายไปด้วย
+
+DROP TABLE IF EXISTS visitor_revisions;
+
+ALTER TABLE visitors DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE visitors DROP COLUMN IF EXISTS version;
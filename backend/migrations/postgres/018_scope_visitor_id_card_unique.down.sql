@@ -0,0 +1,5 @@
+-- migrations/postgres/018_scope_visitor_id_card_unique.down.sql
+-- ใช้ไม่ได้ถ้ามีเลขบัตรซ้ำกับรายการที่ถูกลบแล้ว
+
+DROP INDEX IF EXISTS uq_visitors_school_id_card;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_visitors_school_id_card ON visitors(school_id, id_card);
@@ -0,0 +1,6 @@
+-- migrations/postgres/019_add_return_log_void.down.sql
+-- การคืนบัตรที่ถูกยกเลิกจะกลับมานับเป็นการคืนบัตรปกติ
+
+ALTER TABLE return_card_logs DROP COLUMN IF EXISTS void_reason;
+ALTER TABLE return_card_logs DROP COLUMN IF EXISTS voided_by;
+ALTER TABLE return_card_logs DROP COLUMN IF EXISTS voided_at;
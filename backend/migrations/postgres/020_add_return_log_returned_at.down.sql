@@ -0,0 +1,3 @@
+-- migrations/postgres/020_add_return_log_returned_at.down.sql
+
+ALTER TABLE return_card_logs DROP COLUMN IF EXISTS returned_at;
@@ -0,0 +1,39 @@
+-- migrations/sqlite/001_create_visitors_table.sql
+-- ชุด migration ของ SQLite ใช้เลขเดียวกับ mysql/ ทุกไฟล์ ไฟล์ที่ไม่มีอะไรต้องทำใน SQLite มีแต่ comment
+-- เวลาเก็บเป็นข้อความ UTC รูปแบบเดียวกับที่ driver เขียน ค่าเริ่มต้นจึงใช้ strftime แทน CURRENT_TIMESTAMP
+-- rfid เป็น NULL ได้ตั้งแต่แรก (SQLite แก้คอลัมน์แบบ MODIFY COLUMN ของ 008 ไม่ได้)
+-- COLLATE NOCASE ให้เทียบเลขบัตร/RFID แบบไม่สนตัวพิมพ์เหมือน utf8mb4_unicode_ci
+
+CREATE TABLE IF NOT EXISTS visitors (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    id_card VARCHAR(13) NOT NULL UNIQUE,
+    first_name VARCHAR(100) NOT NULL,
+    last_name VARCHAR(100) NOT NULL,
+    birth_date DATE,
+    phone VARCHAR(15) NOT NULL,
+    license_plate VARCHAR(20),
+    house_number VARCHAR(50),
+    moo VARCHAR(20),
+    soi VARCHAR(100),
+    road VARCHAR(100),
+    sub_district VARCHAR(100),
+    district VARCHAR(100),
+    province VARCHAR(100),
+    rfid VARCHAR(50) NULL UNIQUE COLLATE NOCASE,
+    department VARCHAR(200),
+    officer_name VARCHAR(200),
+    id_card_image TEXT,
+    registered_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%S+00:00', 'now')),
+    updated_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%S+00:00', 'now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_registered_at ON visitors(registered_at);
+CREATE INDEX IF NOT EXISTS idx_name ON visitors(first_name, last_name);
+CREATE INDEX IF NOT EXISTS idx_department ON visitors(department);
+
+-- แทน ON UPDATE CURRENT_TIMESTAMP ของ MySQL
+CREATE TRIGGER IF NOT EXISTS trg_visitors_updated_at
+AFTER UPDATE ON visitors FOR EACH ROW WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+    UPDATE visitors SET updated_at = (strftime('%Y-%m-%d %H:%M:%S+00:00', 'now')) WHERE id = NEW.id;
+END;
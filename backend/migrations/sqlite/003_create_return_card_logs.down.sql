@@ -0,0 +1,3 @@
+-- migrations/sqlite/003_create_return_card_logs.down.sql
+
+DROP TABLE IF EXISTS return_card_logs;
@@ -0,0 +1,17 @@
+-- migrations/sqlite/003_create_return_card_logs.sql
+
+CREATE TABLE IF NOT EXISTS return_card_logs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    visitor_id INT NOT NULL,
+    card_id VARCHAR(50) NOT NULL COLLATE NOCASE,
+    name VARCHAR(255) NOT NULL,
+    check_in VARCHAR(10) NOT NULL,
+    check_out VARCHAR(10) NOT NULL,
+    return_date DATE NOT NULL,
+    status VARCHAR(50) DEFAULT 'การคืนบัตรสำเร็จ',
+    created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%S+00:00', 'now')),
+    FOREIGN KEY (visitor_id) REFERENCES visitors(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_card_id ON return_card_logs(card_id);
+CREATE INDEX IF NOT EXISTS idx_return_date ON return_card_logs(return_date);
@@ -0,0 +1 @@
+-- migrations/sqlite/004_add_search_fulltext_indexes.down.sql
@@ -0,0 +1,2 @@
+-- migrations/sqlite/004_add_search_fulltext_indexes.sql
+-- SQLite ไม่มีดัชนี FULLTEXT แบบ ngram การค้นหาใช้ LIKE ทุกคำ (Dialect.FullText)
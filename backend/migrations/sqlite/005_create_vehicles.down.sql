@@ -0,0 +1,57 @@
+-- migrations/sqlite/005_create_vehicles.down.sql
+-- ทะเบียนรถยังอยู่ใน visitors.license_plate จึงไม่เสียข้อมูล (ยกเว้นจังหวัด/ประเภท/สีของรถ)
+-- SQLite ลบคอลัมน์ที่เป็น foreign key ไม่ได้ ต้องสร้างตาราง visitors ใหม่ตามโครงสร้างของ 001
+-- ปิด foreign_keys ระหว่างนั้น ไม่งั้นการลบตารางเดิมจะลบ return_card_logs ตามไปด้วย
+
+PRAGMA foreign_keys = OFF;
+
+CREATE TABLE visitors_without_vehicle (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    id_card VARCHAR(13) NOT NULL UNIQUE,
+    first_name VARCHAR(100) NOT NULL,
+    last_name VARCHAR(100) NOT NULL,
+    birth_date DATE,
+    phone VARCHAR(15) NOT NULL,
+    license_plate VARCHAR(20),
+    house_number VARCHAR(50),
+    moo VARCHAR(20),
+    soi VARCHAR(100),
+    road VARCHAR(100),
+    sub_district VARCHAR(100),
+    district VARCHAR(100),
+    province VARCHAR(100),
+    rfid VARCHAR(50) NULL UNIQUE COLLATE NOCASE,
+    department VARCHAR(200),
+    officer_name VARCHAR(200),
+    id_card_image TEXT,
+    registered_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%S+00:00', 'now')),
+    updated_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%S+00:00', 'now'))
+);
+
+INSERT INTO visitors_without_vehicle (
+    id, id_card, first_name, last_name, birth_date, phone, license_plate,
+    house_number, moo, soi, road, sub_district, district, province,
+    rfid, department, officer_name, id_card_image, registered_at, updated_at
+)
+SELECT
+    id, id_card, first_name, last_name, birth_date, phone, license_plate,
+    house_number, moo, soi, road, sub_district, district, province,
+    rfid, department, officer_name, id_card_image, registered_at, updated_at
+FROM visitors;
+
+DROP TABLE visitors;
+ALTER TABLE visitors_without_vehicle RENAME TO visitors;
+
+CREATE INDEX IF NOT EXISTS idx_registered_at ON visitors(registered_at);
+CREATE INDEX IF NOT EXISTS idx_name ON visitors(first_name, last_name);
+CREATE INDEX IF NOT EXISTS idx_department ON visitors(department);
+
+CREATE TRIGGER IF NOT EXISTS trg_visitors_updated_at
+AFTER UPDATE ON visitors FOR EACH ROW WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+    UPDATE visitors SET updated_at = (strftime('%Y-%m-%d %H:%M:%S+00:00', 'now')) WHERE id = NEW.id;
+END;
+
+PRAGMA foreign_keys = ON;
+
+DROP TABLE IF EXISTS vehicles;
@@ -0,0 +1,31 @@
+-- migrations/sqlite/005_create_vehicles.sql
+-- ยานพาหนะของผู้มาติดต่อ ผูกกับการเข้าเยี่ยมแต่ละครั้งผ่าน visitors.vehicle_id
+
+CREATE TABLE IF NOT EXISTS vehicles (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    plate_number VARCHAR(20) NOT NULL COLLATE NOCASE,
+    plate_display VARCHAR(30) NOT NULL,
+    province VARCHAR(100) NOT NULL DEFAULT '',
+    vehicle_type VARCHAR(20) NOT NULL DEFAULT 'car',
+    color VARCHAR(50) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%S+00:00', 'now')),
+    UNIQUE (plate_number, province)
+);
+
+ALTER TABLE visitors ADD COLUMN vehicle_id INT NULL REFERENCES vehicles(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_vehicle_id ON visitors(vehicle_id);
+
+-- ย้ายทะเบียนรถเดิมที่เป็นข้อความอิสระเข้าตาราง vehicles (ไม่ทราบจังหวัด)
+INSERT OR IGNORE INTO vehicles (plate_number, plate_display)
+SELECT REPLACE(REPLACE(REPLACE(license_plate, ' ', ''), '-', ''), '.', ''), MIN(license_plate)
+FROM visitors
+WHERE license_plate IS NOT NULL AND license_plate <> ''
+GROUP BY REPLACE(REPLACE(REPLACE(license_plate, ' ', ''), '-', ''), '.', '');
+
+UPDATE visitors SET vehicle_id = (
+    SELECT ve.id FROM vehicles ve
+    WHERE ve.plate_number = REPLACE(REPLACE(REPLACE(visitors.license_plate, ' ', ''), '-', ''), '.', '')
+    AND ve.province = ''
+)
+WHERE license_plate IS NOT NULL AND license_plate <> '';
@@ -0,0 +1,3 @@
+-- migrations/sqlite/006_add_visitor_postal_code.down.sql
+
+ALTER TABLE visitors DROP COLUMN postal_code;
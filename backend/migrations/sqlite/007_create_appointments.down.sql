@@ -0,0 +1,3 @@
+-- migrations/sqlite/007_create_appointments.down.sql
+
+DROP TABLE IF EXISTS appointments;
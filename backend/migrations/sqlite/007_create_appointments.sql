@@ -0,0 +1,26 @@
+-- migrations/sqlite/007_create_appointments.sql
+-- การนัดหมายล่วงหน้า (ผู้ปกครองพบครู, ผู้ส่งของ ฯลฯ) พร้อม QR เชิญ
+
+CREATE TABLE IF NOT EXISTS appointments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    id_card VARCHAR(13) NOT NULL DEFAULT '',
+    first_name VARCHAR(100) NOT NULL,
+    last_name VARCHAR(100) NOT NULL,
+    phone VARCHAR(15) NOT NULL DEFAULT '',
+    license_plate VARCHAR(20) NOT NULL DEFAULT '',
+    host_name VARCHAR(200) NOT NULL,
+    host_contact VARCHAR(200) NOT NULL DEFAULT '',
+    department VARCHAR(200) NOT NULL DEFAULT '',
+    purpose VARCHAR(255) NOT NULL DEFAULT '',
+    expected_from DATETIME NOT NULL,
+    expected_until DATETIME NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    visitor_id INT NULL,
+    created_by VARCHAR(200) NOT NULL DEFAULT '',
+    arrived_at DATETIME NULL,
+    created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%S+00:00', 'now')),
+    FOREIGN KEY (visitor_id) REFERENCES visitors(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_appointments_expected_from ON appointments(expected_from);
+CREATE INDEX IF NOT EXISTS idx_appointments_status ON appointments(status);
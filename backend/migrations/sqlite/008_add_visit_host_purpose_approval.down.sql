@@ -0,0 +1,14 @@
+-- migrations/sqlite/008_add_visit_host_purpose_approval.down.sql
+
+DROP INDEX IF EXISTS idx_visitors_approval;
+
+ALTER TABLE visitors DROP COLUMN approval_note;
+ALTER TABLE visitors DROP COLUMN approved_at;
+ALTER TABLE visitors DROP COLUMN approved_by;
+ALTER TABLE visitors DROP COLUMN approval_status;
+ALTER TABLE visitors DROP COLUMN purpose_note;
+ALTER TABLE visitors DROP COLUMN purpose;
+ALTER TABLE visitors DROP COLUMN host_contact;
+ALTER TABLE visitors DROP COLUMN host_name;
+
+DROP TABLE IF EXISTS visit_purposes;
@@ -0,0 +1,6 @@
+-- migrations/sqlite/009_create_student_pickup.down.sql
+
+DROP TABLE IF EXISTS pickup_alerts;
+DROP TABLE IF EXISTS student_pickups;
+DROP TABLE IF EXISTS guardian_authorisations;
+DROP TABLE IF EXISTS students;
@@ -0,0 +1,60 @@
+-- migrations/sqlite/009_create_student_pickup.sql
+-- นักเรียน ผู้มีสิทธิ์รับนักเรียน (ผูกกับเลขบัตรประชาชน) ประวัติการรับ และการแจ้งเตือนเมื่อถูกปฏิเสธ
+
+CREATE TABLE IF NOT EXISTS students (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    student_code VARCHAR(20) NOT NULL UNIQUE COLLATE NOCASE,
+    first_name VARCHAR(100) NOT NULL,
+    last_name VARCHAR(100) NOT NULL,
+    class_room VARCHAR(50) NOT NULL DEFAULT '',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%S+00:00', 'now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_students_name ON students(first_name, last_name);
+
+CREATE TABLE IF NOT EXISTS guardian_authorisations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    student_id INT NOT NULL,
+    id_card VARCHAR(13) NOT NULL,
+    guardian_name VARCHAR(200) NOT NULL,
+    relationship VARCHAR(50) NOT NULL,
+    valid_from DATE NOT NULL,
+    valid_until DATE NULL,
+    revoked_at DATETIME NULL,
+    created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%S+00:00', 'now')),
+    FOREIGN KEY (student_id) REFERENCES students(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_guardian_id_card ON guardian_authorisations(id_card);
+CREATE INDEX IF NOT EXISTS idx_guardian_student ON guardian_authorisations(student_id);
+
+CREATE TABLE IF NOT EXISTS student_pickups (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    student_id INT NOT NULL,
+    visitor_id INT NOT NULL,
+    authorisation_id INT NOT NULL,
+    officer_name VARCHAR(200) NOT NULL DEFAULT '',
+    picked_up_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%S+00:00', 'now')),
+    FOREIGN KEY (student_id) REFERENCES students(id),
+    FOREIGN KEY (visitor_id) REFERENCES visitors(id),
+    FOREIGN KEY (authorisation_id) REFERENCES guardian_authorisations(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pickups_picked_up_at ON student_pickups(picked_up_at);
+
+CREATE TABLE IF NOT EXISTS pickup_alerts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    student_id INT NOT NULL,
+    visitor_id INT NULL,
+    id_card VARCHAR(13) NOT NULL,
+    visitor_name VARCHAR(255) NOT NULL DEFAULT '',
+    reason VARCHAR(255) NOT NULL,
+    officer_name VARCHAR(200) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%S+00:00', 'now')),
+    acknowledged_at DATETIME NULL,
+    FOREIGN KEY (student_id) REFERENCES students(id),
+    FOREIGN KEY (visitor_id) REFERENCES visitors(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_pickup_alerts_created_at ON pickup_alerts(created_at);
@@ -0,0 +1,6 @@
+-- migrations/sqlite/010_add_visitor_photo.down.sql
+-- ไฟล์รูปใน storage ไม่ถูกลบ
+
+ALTER TABLE visitors DROP COLUMN photo_taken_at;
+ALTER TABLE visitors DROP COLUMN photo_thumbnail_key;
+ALTER TABLE visitors DROP COLUMN photo_key;
@@ -0,0 +1,3 @@
+-- migrations/sqlite/011_store_times_in_utc.down.sql
+
+ALTER TABLE visitors DROP COLUMN exit_time;
@@ -0,0 +1,5 @@
+-- migrations/sqlite/011_store_times_in_utc.sql
+-- ฐานข้อมูล SQLite เก็บเวลาเป็น UTC ตั้งแต่แรก ไม่มีข้อมูลเก่าต้องเลื่อน
+-- เหลือแค่ exit_time ที่ฝั่ง MySQL เพิ่มใน migration นี้
+
+ALTER TABLE visitors ADD COLUMN exit_time DATETIME NULL;
@@ -0,0 +1,30 @@
+-- migrations/sqlite/012_create_devices.sql
+-- เครื่องอ่านบัตร RFID (ประตูเข้า ประตูออก โต๊ะประชาสัมพันธ์) และ log การแตะบัตรไว้ตรวจปัญหา
+
+CREATE TABLE IF NOT EXISTS devices (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(100) NOT NULL,
+    location VARCHAR(200) NOT NULL DEFAULT '',
+    role VARCHAR(20) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    key_hash CHAR(64) NOT NULL,
+    key_hint VARCHAR(12) NOT NULL DEFAULT '',
+    last_seen_at DATETIME NULL,
+    created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%S+00:00', 'now'))
+);
+
+CREATE TABLE IF NOT EXISTS device_taps (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    device_id INT NOT NULL,
+    card_uid VARCHAR(50) NOT NULL COLLATE NOCASE,
+    role VARCHAR(20) NOT NULL,
+    result VARCHAR(20) NOT NULL,
+    visitor_id INT NULL,
+    message VARCHAR(255) NOT NULL DEFAULT '',
+    tapped_at DATETIME NOT NULL,
+    FOREIGN KEY (device_id) REFERENCES devices(id) ON DELETE CASCADE,
+    FOREIGN KEY (visitor_id) REFERENCES visitors(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_device_taps_device ON device_taps(device_id, tapped_at);
+CREATE INDEX IF NOT EXISTS idx_device_taps_card ON device_taps(card_uid, tapped_at);
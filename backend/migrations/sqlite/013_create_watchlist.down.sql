@@ -0,0 +1,3 @@
+-- migrations/sqlite/013_create_watchlist.down.sql
+
+DROP TABLE IF EXISTS watchlist;
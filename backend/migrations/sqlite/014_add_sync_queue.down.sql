@@ -0,0 +1,10 @@
+-- migrations/sqlite/014_add_sync_queue.down.sql
+-- รายการที่ยังไม่ได้ส่งขึ้น server กลางจะหายไปด้วย
+
+DROP TABLE IF EXISTS sync_queue;
+
+DROP INDEX IF EXISTS idx_return_card_logs_client_id;
+ALTER TABLE return_card_logs DROP COLUMN client_id;
+
+DROP INDEX IF EXISTS idx_visitors_client_id;
+ALTER TABLE visitors DROP COLUMN client_id;
@@ -0,0 +1,34 @@
+-- migrations/sqlite/015_add_schools.down.sql
+-- ย้อนได้เฉพาะตอนที่มีโรงเรียนเดียว ข้อมูลที่ซ้ำกันข้ามโรงเรียนจะทำให้สร้าง UNIQUE เดิมไม่ได้
+-- UNIQUE เดิมกลับมาเป็น index แทนการเขียนไว้ในคอลัมน์ (ผลเหมือนกัน)
+
+DROP INDEX IF EXISTS uq_watchlist_school_id_card;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_watchlist_id_card ON watchlist(id_card);
+ALTER TABLE watchlist DROP COLUMN school_id;
+
+DROP INDEX IF EXISTS idx_devices_school;
+ALTER TABLE devices DROP COLUMN school_id;
+
+DROP INDEX IF EXISTS idx_pickup_alerts_school;
+ALTER TABLE pickup_alerts DROP COLUMN school_id;
+
+DROP INDEX IF EXISTS idx_appointments_school;
+ALTER TABLE appointments DROP COLUMN school_id;
+
+DROP INDEX IF EXISTS idx_export_history_school;
+ALTER TABLE export_history DROP COLUMN school_id;
+
+DROP INDEX IF EXISTS idx_return_card_logs_school;
+ALTER TABLE return_card_logs DROP COLUMN school_id;
+
+DROP INDEX IF EXISTS uq_students_school_code;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_students_code ON students(student_code);
+ALTER TABLE students DROP COLUMN school_id;
+
+DROP INDEX IF EXISTS uq_visitors_school_id_card;
+DROP INDEX IF EXISTS uq_visitors_school_rfid;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_visitors_id_card ON visitors(id_card);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_visitors_rfid ON visitors(rfid);
+ALTER TABLE visitors DROP COLUMN school_id;
+
+DROP TABLE IF EXISTS schools;
@@ -0,0 +1,135 @@
+-- migrations/sqlite/015_add_schools.sql
+-- หลายโรงเรียนในเขตใช้ deployment เดียวกัน ทุกตารางของโรงเรียนมี school_id
+-- ข้อมูลเดิมเป็นของโรงเรียนแรก (id 1) ซึ่งสร้างไว้ให้ แก้ชื่อ/code ได้ภายหลังผ่าน /api/district/schools
+-- vehicles และ visit_purposes ใช้ร่วมกันทั้งเขต (รถคันเดียวกันคือคันเดียวกัน ทุกรายการอ่านผ่าน visitors)
+--
+-- UNIQUE ที่เขียนไว้ในคอลัมน์ (id_card, rfid, student_code) ลบด้วย ALTER ไม่ได้ จึงสร้างตาราง visitors
+-- และ students ใหม่แล้วคัดลอกข้อมูล ปิด foreign key ระหว่างนั้นไม่ให้ DROP TABLE ลบแถวที่อ้างถึงตามไปด้วย
+
+CREATE TABLE IF NOT EXISTS schools (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    code VARCHAR(50) NOT NULL UNIQUE COLLATE NOCASE,
+    name VARCHAR(200) NOT NULL,
+    timezone VARCHAR(64) NOT NULL DEFAULT '',
+    departments TEXT NULL,
+    retention_days INT NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%S+00:00', 'now'))
+);
+
+INSERT INTO schools (id, code, name) VALUES (1, 'main', 'โรงเรียน');
+
+PRAGMA foreign_keys = OFF;
+
+CREATE TABLE visitors_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    school_id INT NOT NULL DEFAULT 1,
+    client_id VARCHAR(36) NULL,
+    id_card VARCHAR(13) NOT NULL,
+    first_name VARCHAR(100) NOT NULL,
+    last_name VARCHAR(100) NOT NULL,
+    birth_date DATE,
+    phone VARCHAR(15) NOT NULL,
+    license_plate VARCHAR(20),
+    vehicle_id INT NULL REFERENCES vehicles(id) ON DELETE SET NULL,
+    house_number VARCHAR(50),
+    moo VARCHAR(20),
+    soi VARCHAR(100),
+    road VARCHAR(100),
+    sub_district VARCHAR(100),
+    district VARCHAR(100),
+    province VARCHAR(100),
+    postal_code VARCHAR(5) NOT NULL DEFAULT '',
+    rfid VARCHAR(50) NULL COLLATE NOCASE,
+    department VARCHAR(200),
+    officer_name VARCHAR(200),
+    id_card_image TEXT,
+    host_name VARCHAR(200) NOT NULL DEFAULT '',
+    host_contact VARCHAR(200) NOT NULL DEFAULT '',
+    purpose VARCHAR(50) NOT NULL DEFAULT '',
+    purpose_note VARCHAR(255) NOT NULL DEFAULT '',
+    approval_status VARCHAR(20) NOT NULL DEFAULT 'not_required',
+    approved_by VARCHAR(200) NOT NULL DEFAULT '',
+    approved_at DATETIME NULL,
+    approval_note VARCHAR(255) NOT NULL DEFAULT '',
+    photo_key VARCHAR(255) NULL,
+    photo_thumbnail_key VARCHAR(255) NULL,
+    photo_taken_at DATETIME NULL,
+    registered_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%S+00:00', 'now')),
+    exit_time DATETIME NULL,
+    updated_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%S+00:00', 'now'))
+);
+
+INSERT INTO visitors_new (
+    id, client_id, id_card, first_name, last_name, birth_date, phone, license_plate, vehicle_id,
+    house_number, moo, soi, road, sub_district, district, province, postal_code,
+    rfid, department, officer_name, id_card_image,
+    host_name, host_contact, purpose, purpose_note, approval_status, approved_by, approved_at, approval_note,
+    photo_key, photo_thumbnail_key, photo_taken_at, registered_at, exit_time, updated_at
+)
+SELECT
+    id, client_id, id_card, first_name, last_name, birth_date, phone, license_plate, vehicle_id,
+    house_number, moo, soi, road, sub_district, district, province, postal_code,
+    rfid, department, officer_name, id_card_image,
+    host_name, host_contact, purpose, purpose_note, approval_status, approved_by, approved_at, approval_note,
+    photo_key, photo_thumbnail_key, photo_taken_at, registered_at, exit_time, updated_at
+FROM visitors;
+
+DROP TABLE visitors;
+ALTER TABLE visitors_new RENAME TO visitors;
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_visitors_school_id_card ON visitors(school_id, id_card);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_visitors_school_rfid ON visitors(school_id, rfid);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_visitors_client_id ON visitors(client_id);
+CREATE INDEX IF NOT EXISTS idx_registered_at ON visitors(registered_at);
+CREATE INDEX IF NOT EXISTS idx_name ON visitors(first_name, last_name);
+CREATE INDEX IF NOT EXISTS idx_department ON visitors(department);
+CREATE INDEX IF NOT EXISTS idx_vehicle_id ON visitors(vehicle_id);
+CREATE INDEX IF NOT EXISTS idx_visitors_approval ON visitors(approval_status, host_name);
+
+CREATE TRIGGER IF NOT EXISTS trg_visitors_updated_at
+AFTER UPDATE ON visitors FOR EACH ROW WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+    UPDATE visitors SET updated_at = (strftime('%Y-%m-%d %H:%M:%S+00:00', 'now')) WHERE id = NEW.id;
+END;
+
+CREATE TABLE students_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    school_id INT NOT NULL DEFAULT 1,
+    student_code VARCHAR(20) NOT NULL COLLATE NOCASE,
+    first_name VARCHAR(100) NOT NULL,
+    last_name VARCHAR(100) NOT NULL,
+    class_room VARCHAR(50) NOT NULL DEFAULT '',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%S+00:00', 'now'))
+);
+
+INSERT INTO students_new (id, student_code, first_name, last_name, class_room, active, created_at)
+SELECT id, student_code, first_name, last_name, class_room, active, created_at FROM students;
+
+DROP TABLE students;
+ALTER TABLE students_new RENAME TO students;
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_students_school_code ON students(school_id, student_code);
+CREATE INDEX IF NOT EXISTS idx_students_name ON students(first_name, last_name);
+
+PRAGMA foreign_keys = ON;
+
+ALTER TABLE return_card_logs ADD COLUMN school_id INT NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_return_card_logs_school ON return_card_logs(school_id, return_date);
+
+ALTER TABLE export_history ADD COLUMN school_id INT NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_export_history_school ON export_history(school_id, created_at);
+
+ALTER TABLE appointments ADD COLUMN school_id INT NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_appointments_school ON appointments(school_id, expected_from);
+
+ALTER TABLE pickup_alerts ADD COLUMN school_id INT NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_pickup_alerts_school ON pickup_alerts(school_id, created_at);
+
+ALTER TABLE devices ADD COLUMN school_id INT NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_devices_school ON devices(school_id);
+
+ALTER TABLE watchlist ADD COLUMN school_id INT NOT NULL DEFAULT 1;
+DROP INDEX IF EXISTS uq_watchlist_id_card;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_watchlist_school_id_card ON watchlist(school_id, id_card);
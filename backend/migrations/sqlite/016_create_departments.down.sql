@@ -0,0 +1,16 @@
+-- migrations/sqlite/016_create_departments.down.sql
+
+ALTER TABLE schools ADD COLUMN departments TEXT NULL;
+
+UPDATE schools SET departments = (
+    SELECT json_group_array(name_th) FROM (
+        SELECT name_th FROM departments
+        WHERE school_id = schools.id AND active = TRUE
+        ORDER BY display_order, id
+    )
+);
+
+DROP INDEX IF EXISTS idx_visitors_school_department;
+ALTER TABLE visitors DROP COLUMN department_id;
+
+DROP TABLE IF EXISTS departments;
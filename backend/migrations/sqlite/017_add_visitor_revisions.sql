@@ -0,0 +1,22 @@
+-- migrations/sqlite/017_add_visitor_revisions.sql
+-- แก้ไขและลบรายการผู้มาติดต่อ (PATCH / DELETE /api/visitors/{id})
+-- version เพิ่มทุกครั้งที่รายการเปลี่ยน ใช้เป็น ETag กันการแก้ทับกัน (updated_at ละเอียดแค่วินาที)
+-- ลบแบบ soft delete: deleted_at ไม่ว่าง = ถูกลบ ไม่แสดงในรายการ รายงาน และการค้นหา
+
+ALTER TABLE visitors ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE visitors ADD COLUMN deleted_at DATETIME NULL;
+
+-- ประวัติการแก้ไขของแต่ละรายการ: ค่าเดิม ค่าใหม่ ใครแก้ และเหตุผล
+CREATE TABLE IF NOT EXISTS visitor_revisions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    school_id INT NOT NULL DEFAULT 1,
+    visitor_id INT NOT NULL,
+    version INT NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    changes TEXT NOT NULL,
+    changed_by VARCHAR(100) NOT NULL,
+    reason VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%S+00:00', 'now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_visitor_revisions_visitor ON visitor_revisions(visitor_id, id);
@@ -0,0 +1,5 @@
+-- migrations/sqlite/018_scope_visitor_id_card_unique.sql
+-- เลขบัตรประชาชนไม่ซ้ำเฉพาะรายการที่ยังไม่ถูกลบ ลบรายการที่ลงทะเบียนผิดแล้วลงทะเบียนคนเดิมใหม่ได้
+
+DROP INDEX IF EXISTS uq_visitors_school_id_card;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_visitors_school_id_card ON visitors(school_id, id_card) WHERE deleted_at IS NULL;
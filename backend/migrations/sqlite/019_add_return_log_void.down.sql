@@ -0,0 +1,6 @@
+-- migrations/sqlite/019_add_return_log_void.down.sql
+-- การคืนบัตรที่ถูกยกเลิกจะกลับมานับเป็นการคืนบัตรปกติ
+
+ALTER TABLE return_card_logs DROP COLUMN void_reason;
+ALTER TABLE return_card_logs DROP COLUMN voided_by;
+ALTER TABLE return_card_logs DROP COLUMN voided_at;
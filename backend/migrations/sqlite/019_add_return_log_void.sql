@@ -0,0 +1,8 @@
+-- migrations/sqlite/019_add_return_log_void.sql
+-- ยกเลิกการคืนบัตรที่บันทึกผิด (ยามสแกนบัตรผิดใบ) POST /api/return-logs/{id}/void
+-- รายการที่ถูกยกเลิกยังเก็บไว้ แต่ไม่นับตอนเช็คคืนซ้ำ และไม่แสดงในประวัติการคืนบัตรหรือการค้นหา
+-- ผู้มาติดต่อกลับเป็นสถานะยังอยู่ในโรงเรียน และบันทึกไว้ใน visitor_revisions (action = void_return)
+
+ALTER TABLE return_card_logs ADD COLUMN voided_at DATETIME NULL;
+ALTER TABLE return_card_logs ADD COLUMN voided_by VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE return_card_logs ADD COLUMN void_reason VARCHAR(255) NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+-- migrations/sqlite/020_add_return_log_returned_at.down.sql
+
+ALTER TABLE return_card_logs DROP COLUMN returned_at;
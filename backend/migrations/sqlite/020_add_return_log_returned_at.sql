@@ -0,0 +1,5 @@
+-- migrations/sqlite/020_add_return_log_returned_at.sql
+-- เวลาที่คืนบัตรจริงของแต่ละการคืน (เดิมอ่านจาก visitors.exit_time ซึ่งถูกล้างเมื่อยกเลิกการคืนบัตร)
+-- เครื่อง edge ส่งเวลานี้ขึ้น server กลาง รายการเดิมใช้ created_at แทน
+
+ALTER TABLE return_card_logs ADD COLUMN returned_at DATETIME NULL;
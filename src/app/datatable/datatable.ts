@@ -8,7 +8,7 @@ import { MatNativeDateModule } from '@angular/material/core';
 import { MatIconModule } from '@angular/material/icon';
 import { RouterLink } from '@angular/router';
 import { Router } from '@angular/router';
-import { VisitorService, VisitorListResponse, Visitor as VisitorData } from '../services/visitor.service';
+import { VisitorService, VisitorListResponse, Visitor as VisitorData, Department, ALL_DEPARTMENTS } from '../services/visitor.service';
 
 interface Visitor {
   id: number;
@@ -42,6 +42,11 @@ export class DatatableComponent implements OnInit {
   startDate: Date | null = null;
   endDate: Date | null = null;
 
+  // Department filter
+  readonly allDepartments = ALL_DEPARTMENTS;
+  departments: Department[] = [];
+  selectedDepartment: string = ALL_DEPARTMENTS;
+
   // Data
   visitors = signal<Visitor[]>([]);
   isLoading = signal<boolean>(false);
@@ -60,15 +65,24 @@ export class DatatableComponent implements OnInit {
   ) { }
 
   ngOnInit() {
+    this.loadDepartments();
     this.loadVisitors();
   }
 
+  loadDepartments() {
+    this.visitorService.getDepartments(true).subscribe({
+      next: (departments) => this.departments = departments,
+      error: (err) => console.error('Error loading departments:', err)
+    });
+  }
+
   loadVisitors() {
     this.isLoading.set(true);
     this.error.set(null);
 
     const params: any = {
-      sortOrder: 'latest'
+      sortOrder: 'latest',
+      department: this.selectedDepartment
     };
 
     if (this.startDate) {
@@ -166,9 +180,14 @@ export class DatatableComponent implements OnInit {
     }
   }
 
+  onDepartmentChange() {
+    this.loadVisitors();
+  }
+
   clearDateFilter() {
     this.startDate = null;
     this.endDate = null;
+    this.selectedDepartment = ALL_DEPARTMENTS;
     this.loadVisitors();
   }
 
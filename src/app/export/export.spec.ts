@@ -28,12 +28,21 @@ describe('ExportComponent', () => {
     httpMock.verify(); // ตรวจสอบว่าไม่มี HTTP request ที่ค้างอยู่
   });
 
+  // ngOnInit โหลดรายการส่วนงานด้วย ต้องตอบ request นี้ก่อน verify
+  function flushDepartments(): void {
+    const req = httpMock.expectOne((request) =>
+      request.url === 'http://localhost:8080/api/departments'
+    );
+    expect(req.request.method).toBe('GET');
+    req.flush([]);
+  }
+
   it('should create', () => {
     expect(component).toBeTruthy();
   });
 
   it('should have default form values', () => {
-    expect(component.selectedDepartment).toBe('ทั้งหมด');
+    expect(component.selectedDepartment).toBe('all');
     expect(component.startDate).toBeTruthy();
     expect(component.endDate).toBeTruthy();
   });
@@ -63,6 +72,7 @@ describe('ExportComponent', () => {
     ];
 
     component.ngOnInit();
+    flushDepartments();
 
     const req = httpMock.expectOne('http://localhost:8080/api/export-history');
     expect(req.request.method).toBe('GET');
@@ -74,6 +84,7 @@ describe('ExportComponent', () => {
 
   it('should handle empty export history', () => {
     component.ngOnInit();
+    flushDepartments();
 
     const req = httpMock.expectOne('http://localhost:8080/api/export-history');
     req.flush([]);
@@ -314,6 +325,12 @@ describe('ExportComponent', () => {
     expect(component.selectedDepartment).toBe('กลุ่มบริหารวิชาการ');
   });
 
+  it('should label all departments as ทั้งหมด', () => {
+    expect(component.departmentLabel).toBe('ทั้งหมด');
+    component.selectedDepartment = 'กลุ่มบริหารวิชาการ';
+    expect(component.departmentLabel).toBe('กลุ่มบริหารวิชาการ');
+  });
+
   it('should update date range', () => {
     component.startDate = '2025-01-01';
     component.endDate = '2025-01-31';
@@ -352,6 +369,7 @@ describe('ExportComponent', () => {
     spyOn(console, 'error');
     
     component.ngOnInit();
+    flushDepartments();
 
     const req = httpMock.expectOne('http://localhost:8080/api/export-history');
     req.error(new ErrorEvent('Network error'));
@@ -362,7 +380,7 @@ describe('ExportComponent', () => {
 
   it('should show warning when no data found', () => {
     spyOn(component, 'showWarningAlert');
-    component.selectedDepartment = 'ทั้งหมด';
+    component.selectedDepartment = 'all';
     component.startDate = '2025-12-01';
     component.endDate = '2025-12-10';
 
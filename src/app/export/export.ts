@@ -3,6 +3,7 @@ import { CommonModule } from '@angular/common';
 import { FormsModule } from '@angular/forms';
 import { HttpClient, HttpParams } from '@angular/common/http';
 import * as XLSX from 'xlsx';
+import { VisitorService, Department, ALL_DEPARTMENTS } from '../services/visitor.service';
 
 interface ExportRecord {
   id?: number;
@@ -42,7 +43,9 @@ export class ExportComponent implements OnInit {
   private apiUrl = 'http://localhost:8080/api'; // ปรับตาม API ของคุณ
 
   // Form data
-  selectedDepartment: string = 'ทั้งหมด';
+  readonly allDepartments = ALL_DEPARTMENTS;
+  departments: Department[] = [];
+  selectedDepartment: string = ALL_DEPARTMENTS;
   startDate: string = '';
   endDate: string = '';
 
@@ -55,7 +58,7 @@ export class ExportComponent implements OnInit {
   originalExportHistory: ExportRecord[] = [];
   exportHistory: ExportRecord[] = [];
 
-  constructor(private http: HttpClient) { }
+  constructor(private http: HttpClient, private visitorService: VisitorService) { }
 
   ngOnInit(): void {
     const today = new Date();
@@ -67,9 +70,27 @@ export class ExportComponent implements OnInit {
     this.endDate = `${year}-${month}-${day}`;
 
     this.loadSweetAlert();
+    this.loadDepartments();
     this.loadExportHistory();
   }
 
+  /**
+   * โหลดรายการส่วนงาน (รวมที่ปิดใช้งาน เพื่อส่งออกข้อมูลย้อนหลังได้)
+   */
+  loadDepartments(): void {
+    this.visitorService.getDepartments(true).subscribe({
+      next: (departments) => this.departments = departments,
+      error: (error) => console.error('Error loading departments:', error)
+    });
+  }
+
+  /**
+   * ชื่อส่วนงานที่เลือกสำหรับแสดงผล ชื่อไฟล์ และประวัติการส่งออก
+   */
+  get departmentLabel(): string {
+    return this.selectedDepartment === ALL_DEPARTMENTS ? 'ทั้งหมด' : this.selectedDepartment;
+  }
+
   loadSweetAlert(): void {
     if (typeof (window as any).Swal !== 'undefined') {
       this.Swal = (window as any).Swal;
@@ -119,7 +140,7 @@ export class ExportComponent implements OnInit {
     const formattedEndDate = this.formatDateThai(this.endDate);
 
     this.showExportConfirmation({
-      department: this.selectedDepartment,
+      department: this.departmentLabel,
       startDate: formattedStartDate,
       endDate: formattedEndDate,
       format: 'Excel'
@@ -183,13 +204,10 @@ export class ExportComponent implements OnInit {
     this.showLoadingAlert();
 
     // สร้าง query parameters
-    let params = new HttpParams()
+    const params = new HttpParams()
       .set('startDate', this.startDate)
-      .set('endDate', this.endDate);
-
-    if (this.selectedDepartment !== 'ทั้งหมด') {
-      params = params.set('department', this.selectedDepartment);
-    }
+      .set('endDate', this.endDate)
+      .set('department', this.selectedDepartment);
 
     // ดึงข้อมูลจาก API
     this.http.get<Visitor[]>(`${this.apiUrl}/visitors/export`, { params }).subscribe({
@@ -256,7 +274,7 @@ export class ExportComponent implements OnInit {
     ws['!cols'] = colWidths;
 
     // สร้างชื่อไฟล์
-    const fileName = `ผู้มาติดต่อ_${this.selectedDepartment}_${this.formatDateForFile(this.startDate)}_${this.formatDateForFile(this.endDate)}.xlsx`;
+    const fileName = `ผู้มาติดต่อ_${this.departmentLabel}_${this.formatDateForFile(this.startDate)}_${this.formatDateForFile(this.endDate)}.xlsx`;
 
     // ดาวน์โหลดไฟล์
     XLSX.writeFile(wb, fileName);
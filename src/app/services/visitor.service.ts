@@ -44,6 +44,17 @@ export interface RFIDCardResponse {
   checkOut: string;
 }
 
+export interface Department {
+  id: number;
+  nameTh: string;
+  nameEn: string;
+  active: boolean;
+  displayOrder: number;
+}
+
+// ค่าตัวกรองส่วนงานที่หมายถึงทุกส่วนงาน (ใช้ทั้งหน้ารายการและการส่งออก)
+export const ALL_DEPARTMENTS = 'all';
+
 export interface ReturnCardHistoryResponse {
   no: number;
   cardId: string;
@@ -59,6 +70,7 @@ export interface ReturnCardHistoryResponse {
 })
 export class VisitorService {
   private apiUrl = 'http://localhost:8080/api/visitors';
+  private departmentsUrl = 'http://localhost:8080/api/departments';
 
   constructor(private http: HttpClient) {}
 
@@ -67,12 +79,19 @@ export class VisitorService {
     return this.http.post<Visitor>(this.apiUrl, visitor);
   }
 
+  // ดึงรายการส่วนงาน (all = รวมที่ปิดใช้งาน ยังใช้กรองข้อมูลย้อนหลังได้)
+  getDepartments(all = false): Observable<Department[]> {
+    const httpParams = all ? new HttpParams().set('all', 'true') : new HttpParams();
+    return this.http.get<Department[]>(this.departmentsUrl, { params: httpParams });
+  }
+
   // ดึงรายการ Visitors
   getVisitors(params?: {
     search?: string;
     startDate?: string;
     endDate?: string;
     sortOrder?: string;
+    department?: string;
   }): Observable<VisitorListResponse[]> {
     let httpParams = new HttpParams();
     
@@ -88,6 +107,9 @@ export class VisitorService {
     if (params?.sortOrder) {
       httpParams = httpParams.set('sortOrder', params.sortOrder);
     }
+    if (params?.department) {
+      httpParams = httpParams.set('department', params.department);
+    }
 
     return this.http.get<VisitorListResponse[]>(this.apiUrl, { params: httpParams });
   }